@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/labstack/echo/v4"
+)
+
+// userIDKey 認証したユーザーIDを echo.Context に保存するキー
+const userIDKey = "auth.user_id"
+
+// ErrInvalidCredentials 認証情報が存在しない、または無効化されている
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// KeyAuthenticator 平文のAPIキーからユーザーIDを返す
+// キーが使えない場合は ErrInvalidCredentials を（wrap して）返す
+type KeyAuthenticator func(ctx context.Context, key string) (uint64, error)
+
+// Middleware Authorization: Bearer <apikey> が付いたリクエストを認証し、ユーザーIDを UserID で取り出せるようにする
+// キーが無効・無効化済みの場合は401を返す。ヘッダーが無いリクエストは（認証の導入までは）そのまま処理する
+func Middleware(authenticate KeyAuthenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			header := ctx.Request().Header.Get(echo.HeaderAuthorization)
+			if header == "" {
+				return next(ctx)
+			}
+			scheme, key, ok := strings.Cut(header, " ")
+			if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(key) == "" {
+				return httperr.Unauthorized("Authorization header must be in the form \"Bearer <api key>\"")
+			}
+
+			userID, err := authenticate(ctx.Request().Context(), strings.TrimSpace(key))
+			if err != nil {
+				if errors.Is(err, ErrInvalidCredentials) {
+					return httperr.Unauthorized("The API key is invalid or has been revoked")
+				}
+				return httperr.Internal("Failed to authenticate API key", err)
+			}
+			ctx.Set(userIDKey, userID)
+			return next(ctx)
+		}
+	}
+}
+
+// RequireUser パスパラメータ param のユーザー以外のAPIキーで認証したリクエストを403で拒否する（Middleware の後に使う）
+// APIキーの無いリクエストは（認証の導入までは）そのまま処理する。param が数値でない場合の400はハンドラーに任せる
+func RequireUser(param string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			keyUserID, ok := UserID(ctx)
+			if !ok {
+				return next(ctx)
+			}
+			userID, err := strconv.ParseUint(ctx.Param(param), 10, 64)
+			if err == nil && userID != keyUserID {
+				return httperr.Forbidden("The API key belongs to another user")
+			}
+			return next(ctx)
+		}
+	}
+}
+
+// UserID 認証したユーザーのIDを返す（認証していないリクエストでは false）
+func UserID(ctx echo.Context) (uint64, bool) {
+	userID, ok := ctx.Get(userIDKey).(uint64)
+	return userID, ok
+}
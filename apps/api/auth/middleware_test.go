@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/labstack/echo/v4"
+)
+
+// fakeAuthenticator alice-key はユーザー1、revoked-key は無効化済み、broken-key はDBの障害として扱う
+func fakeAuthenticator(ctx context.Context, key string) (uint64, error) {
+	switch key {
+	case "alice-key":
+		return 1, nil
+	case "broken-key":
+		return 0, errors.New("connection refused")
+	}
+	return 0, fmt.Errorf("unknown key: %w", ErrInvalidCredentials)
+}
+
+func TestMiddleware_RequireUser(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = httperr.Handler
+	api := e.Group("/api", Middleware(fakeAuthenticator))
+	api.GET("/users/:id/summary", func(ctx echo.Context) error {
+		userID, ok := UserID(ctx)
+		return ctx.String(http.StatusOK, fmt.Sprint(userID, ok))
+	}, RequireUser("id"))
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		want          int
+		wantBody      string
+	}{
+		{"own id", "/api/users/1/summary", "Bearer alice-key", http.StatusOK, "1 true"},
+		{"lowercase scheme", "/api/users/1/summary", "bearer alice-key", http.StatusOK, "1 true"},
+		{"foreign id", "/api/users/2/summary", "Bearer alice-key", http.StatusForbidden, ""},
+		{"non-numeric id left to the handler", "/api/users/abc/summary", "Bearer alice-key", http.StatusOK, "1 true"},
+		{"revoked key", "/api/users/1/summary", "Bearer revoked-key", http.StatusUnauthorized, ""},
+		{"revoked key on a foreign id", "/api/users/2/summary", "Bearer revoked-key", http.StatusUnauthorized, ""},
+		{"not a bearer token", "/api/users/1/summary", "Basic YWxpY2U6cGFzcw==", http.StatusUnauthorized, ""},
+		{"empty key", "/api/users/1/summary", "Bearer ", http.StatusUnauthorized, ""},
+		{"authenticator failure", "/api/users/1/summary", "Bearer broken-key", http.StatusInternalServerError, ""},
+		{"no header", "/api/users/2/summary", "", http.StatusOK, "0 false"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set(echo.HeaderAuthorization, tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body.String())
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/keeee21/commit-town/api/db"
+	"github.com/keeee21/commit-town/api/events"
+	"github.com/keeee21/commit-town/api/migrations"
+	"github.com/keeee21/commit-town/api/models"
+	"github.com/keeee21/commit-town/api/repository"
+	"github.com/keeee21/commit-town/api/usecase"
+)
+
+// seedDays number of days of commit history to generate, ending today
+const seedDays = 14
+
+type seedRepo struct {
+	Owner    string
+	Name     string
+	IsPublic bool
+}
+
+type seedUser struct {
+	GitHubUserID   uint64
+	GitHubUsername string
+	Email          string
+	Repositories   []seedRepo
+}
+
+var seedUsers = []seedUser{
+	{
+		GitHubUserID:   90000001,
+		GitHubUsername: "alice-dev",
+		Email:          "alice@example.com",
+		Repositories: []seedRepo{
+			{Owner: "alice-dev", Name: "dotfiles", IsPublic: true},
+			{Owner: "alice-dev", Name: "side-project", IsPublic: false},
+		},
+	},
+	{
+		GitHubUserID:   90000002,
+		GitHubUsername: "bob-codes",
+		Email:          "bob@example.com",
+		Repositories: []seedRepo{
+			{Owner: "bob-codes", Name: "blog", IsPublic: true},
+		},
+	},
+	{
+		GitHubUserID:   90000003,
+		GitHubUsername: "carol-commits",
+		Email:          "carol@example.com",
+		Repositories: []seedRepo{
+			{Owner: "carol-commits", Name: "compiler", IsPublic: true},
+			{Owner: "town-org", Name: "commit-town", IsPublic: true},
+		},
+	},
+}
+
+func main() {
+	// Load .env file
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	// Never seed anything other than a local database
+	env := os.Getenv("APP_ENV")
+	if env != "development" && env != "local" {
+		log.Fatalf("Refusing to seed: APP_ENV must be \"development\" or \"local\" (got %q)", env)
+	}
+
+	// Seeding is a one-off local task, so no statement timeout is applied
+	database, err := db.NewDatabase(os.Getenv("DATABASE_URL"), 0, slog.Default())
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	if err := migrations.Up(database); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	userRepo := repository.NewUserRepository(database)
+	repoRepo := repository.NewRepoRepository(database)
+	repoLogRepo := repository.NewRepoDailyCommitLogRepository(database)
+	userLogRepo := repository.NewUserDailyCommitLogRepository(database)
+	streakRepo := repository.NewStreakRepository(database)
+	achievementRepo := repository.NewAchievementRepository(database)
+	repoStreakRepo := repository.NewRepoStreakRepository(database)
+	freezeRepo := repository.NewStreakFreezeRepository(database)
+
+	aggregationUsecase := usecase.NewAggregationUsecase(repoLogRepo, userLogRepo, false)
+	streakUsecase := usecase.NewStreakUsecase(userRepo, userLogRepo, streakRepo, repoLogRepo, repoStreakRepo, freezeRepo, events.NewBus(), 0, 1)
+	achievementUsecase := usecase.NewAchievementUsecase(userRepo, achievementRepo, streakRepo, userLogRepo)
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	since := today.AddDate(0, 0, -(seedDays - 1))
+
+	for userIndex, su := range seedUsers {
+		user := &models.User{
+			GitHubUserID:   su.GitHubUserID,
+			GitHubUsername: su.GitHubUsername,
+			Email:          su.Email,
+		}
+		if err := userRepo.Upsert(ctx, user); err != nil {
+			log.Fatalf("Failed to seed user %s: %v", su.GitHubUsername, err)
+		}
+
+		for repoIndex, sr := range su.Repositories {
+			repo := &models.UserRepository{
+				UserID:    user.ID,
+				RepoOwner: sr.Owner,
+				RepoName:  sr.Name,
+				IsPublic:  sr.IsPublic,
+			}
+			if err := repoRepo.Upsert(ctx, repo); err != nil {
+				log.Fatalf("Failed to seed repository %s/%s: %v", sr.Owner, sr.Name, err)
+			}
+
+			for day := 0; day < seedDays; day++ {
+				count := sampleCommitCount(userIndex, repoIndex, day)
+				if count == 0 {
+					continue
+				}
+				commitLog := &models.RepoDailyCommitLog{
+					UserRepoID:  repo.ID,
+					CommitDate:  since.AddDate(0, 0, day),
+					CommitCount: count,
+				}
+				if err := repoLogRepo.Upsert(ctx, commitLog); err != nil {
+					log.Fatalf("Failed to seed commit log for %s/%s: %v", sr.Owner, sr.Name, err)
+				}
+			}
+			if err := streakUsecase.RecalculateRepoStreaks(ctx, repo.ID); err != nil {
+				log.Fatalf("Failed to recalculate streaks for %s/%s: %v", sr.Owner, sr.Name, err)
+			}
+		}
+
+		if err := aggregationUsecase.RebuildRange(ctx, user.ID, since, today); err != nil {
+			log.Fatalf("Failed to aggregate commits for %s: %v", su.GitHubUsername, err)
+		}
+		if err := streakUsecase.RecalculateStreaks(ctx, user.ID); err != nil {
+			log.Fatalf("Failed to recalculate streaks for %s: %v", su.GitHubUsername, err)
+		}
+		if err := achievementUsecase.Evaluate(ctx, user.ID); err != nil {
+			log.Fatalf("Failed to evaluate achievements for %s: %v", su.GitHubUsername, err)
+		}
+
+		log.Printf("Seeded %s with %d repositories", su.GitHubUsername, len(su.Repositories))
+	}
+
+	log.Println("Seeding completed successfully")
+}
+
+// sampleCommitCount returns a deterministic commit count so re-runs write identical rows.
+// Some days are left empty so the seeded users end up with different streaks.
+func sampleCommitCount(userIndex, repoIndex, day int) int {
+	if (day+userIndex*3)%(5+userIndex) == 0 {
+		return 0
+	}
+	return (day*7+repoIndex*3+userIndex*5)%6 + repoIndex
+}
@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/keeee21/commit-town/api/repository"
+	"github.com/keeee21/commit-town/api/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+type AchievementController struct {
+	achievementUsecase *usecase.AchievementUsecase
+}
+
+func NewAchievementController(achievementUsecase *usecase.AchievementUsecase) *AchievementController {
+	return &AchievementController{achievementUsecase: achievementUsecase}
+}
+
+// ListAchievements ユーザーの獲得済みバッジ一覧を取得
+func (achievementController *AchievementController) ListAchievements(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	achievements, err := achievementController.achievementUsecase.ListAchievements(ctx.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.UserNotFound()
+		}
+		return httperr.Internal("Failed to list achievements", err)
+	}
+
+	return ctx.JSON(http.StatusOK, achievements)
+}
@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/keeee21/commit-town/api/maintenance"
+	"github.com/keeee21/commit-town/api/pagination"
+	"github.com/keeee21/commit-town/api/repository"
+	"github.com/keeee21/commit-town/api/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultRecomputeConcurrency = 4
+	maxRecomputeConcurrency     = 16
+
+	defaultAtRiskHours = 6
+	maxAtRiskHours     = 24
+)
+
+type AdminController struct {
+	userUsecase         *usecase.UserUsecase
+	pipelineUsecase     *usecase.PipelineUsecase
+	repositoryUsecase   *usecase.RepositoryUsecase
+	leaderboardUsecase  *usecase.LeaderboardUsecase
+	notificationUsecase *usecase.NotificationUsecase
+	maintenanceMode     *maintenance.Mode
+	page                pagination.Config
+}
+
+func NewAdminController(userUsecase *usecase.UserUsecase, pipelineUsecase *usecase.PipelineUsecase, repositoryUsecase *usecase.RepositoryUsecase, leaderboardUsecase *usecase.LeaderboardUsecase, notificationUsecase *usecase.NotificationUsecase, maintenanceMode *maintenance.Mode, page pagination.Config) *AdminController {
+	return &AdminController{
+		userUsecase:         userUsecase,
+		pipelineUsecase:     pipelineUsecase,
+		repositoryUsecase:   repositoryUsecase,
+		leaderboardUsecase:  leaderboardUsecase,
+		notificationUsecase: notificationUsecase,
+		maintenanceMode:     maintenanceMode,
+		page:                page,
+	}
+}
+
+// Recompute 全ユーザーの日次集計とstreakを計算し直す（?concurrency=N、デフォルト4・上限16）
+func (adminController *AdminController) Recompute(ctx echo.Context) error {
+	concurrency := defaultRecomputeConcurrency
+	if v := ctx.QueryParam("concurrency"); v != "" {
+		var err error
+		concurrency, err = strconv.Atoi(v)
+		if err != nil || concurrency < 1 || concurrency > maxRecomputeConcurrency {
+			return httperr.ValidationFailed("concurrency must be between 1 and 16")
+		}
+	}
+
+	res, err := adminController.pipelineUsecase.RecomputeAll(ctx.Request().Context(), concurrency)
+	if err != nil {
+		return httperr.Internal("Failed to recompute users", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// ReconcileUser ユーザーの日次集計をリポジトリ別日次ログと突き合わせ、食い違う日を直す（定期実行を待たずに直す場合）
+func (adminController *AdminController) ReconcileUser(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	res, err := adminController.pipelineUsecase.ReconcileUser(ctx.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.UserNotFound()
+		}
+		return httperr.Internal("Failed to reconcile user", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// DeactivateStaleRepositories ?days= 日以上コミットが記録されていない登録リポジトリを一括で無効化（GitHub APIの無駄な呼び出しを減らす）
+func (adminController *AdminController) DeactivateStaleRepositories(ctx echo.Context) error {
+	days, err := strconv.Atoi(ctx.QueryParam("days"))
+	if err != nil || days < 1 {
+		return httperr.ValidationFailed("days must be a positive integer")
+	}
+
+	res, err := adminController.repositoryUsecase.DeactivateStale(ctx.Request().Context(), days, time.Now())
+	if err != nil {
+		return httperr.Internal("Failed to deactivate stale repositories", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// CleanupRawData ?older_than_days= 日より前の日次ログの RawData（GitHub APIのレスポンス）を一括で削除（コミット数は残す）
+func (adminController *AdminController) CleanupRawData(ctx echo.Context) error {
+	days, err := strconv.Atoi(ctx.QueryParam("older_than_days"))
+	if err != nil || days < usecase.MinRawDataRetentionDays {
+		return httperr.ValidationFailed(fmt.Sprintf("older_than_days must be an integer of at least %d", usecase.MinRawDataRetentionDays))
+	}
+
+	res, err := adminController.repositoryUsecase.CleanupRawData(ctx.Request().Context(), days, time.Now())
+	if err != nil {
+		return httperr.Internal("Failed to clean up raw data", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// ListSyncRuns 同期ジョブの実行記録を新しい順に取得（?limit=N、デフォルト・上限は PAGE_SIZE_DEFAULT・PAGE_SIZE_MAX）
+func (adminController *AdminController) ListSyncRuns(ctx echo.Context) error {
+	limit := adminController.page.Limit(ctx)
+
+	runs, err := adminController.pipelineUsecase.ListSyncRuns(ctx.Request().Context(), limit)
+	if err != nil {
+		return httperr.Internal("Failed to list sync runs", err)
+	}
+
+	return ctx.JSON(http.StatusOK, dto.SyncJobRunsResponse{Runs: runs})
+}
+
+// ListAtRiskUsers streakが途切れそうなユーザーを取得（?hours=N、ローカルの1日が N 時間以内に終わるユーザー。デフォルト6・上限24）
+func (adminController *AdminController) ListAtRiskUsers(ctx echo.Context) error {
+	hours := defaultAtRiskHours
+	if v := ctx.QueryParam("hours"); v != "" {
+		var err error
+		hours, err = strconv.Atoi(v)
+		if err != nil || hours < 1 || hours > maxAtRiskHours {
+			return httperr.ValidationFailed("hours must be between 1 and 24")
+		}
+	}
+
+	res, err := adminController.notificationUsecase.ListAtRiskUsers(ctx.Request().Context(), time.Now(), time.Duration(hours)*time.Hour)
+	if err != nil {
+		return httperr.Internal("Failed to list at-risk users", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// GetLeaderboardCacheStats ランキングのキャッシュのヒット数・ミス数を取得
+func (adminController *AdminController) GetLeaderboardCacheStats(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, adminController.leaderboardUsecase.CacheStats())
+}
+
+// ClearLeaderboardCache ランキングのキャッシュを捨てる（集計を直した直後などに最新の結果を返すため）
+func (adminController *AdminController) ClearLeaderboardCache(ctx echo.Context) error {
+	adminController.leaderboardUsecase.ClearCache()
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// GetMaintenance メンテナンスモードの状態を取得
+func (adminController *AdminController) GetMaintenance(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, dto.MaintenanceResponse{Enabled: adminController.maintenanceMode.Enabled()})
+}
+
+// SetMaintenance メンテナンスモードを切り替える（再起動すると MAINTENANCE_MODE の値に戻る）
+func (adminController *AdminController) SetMaintenance(ctx echo.Context) error {
+	var req dto.MaintenanceRequest
+	if err := ctx.Bind(&req); err != nil {
+		return httperr.InvalidRequest("Invalid request body")
+	}
+	if err := ctx.Validate(&req); err != nil {
+		return err
+	}
+
+	adminController.maintenanceMode.Set(*req.Enabled)
+	log.Printf("Maintenance mode set to %t", *req.Enabled)
+	return ctx.JSON(http.StatusOK, dto.MaintenanceResponse{Enabled: *req.Enabled})
+}
+
+// RevokeTokens ユーザーのトークンの版を上げ、発行済みのトークンを全て無効にする（ログアウトや漏洩時）
+func (adminController *AdminController) RevokeTokens(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	res, err := adminController.userUsecase.RevokeTokens(ctx.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.UserNotFound()
+		}
+		return httperr.Internal("Failed to revoke tokens", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/keeee21/commit-town/api/repository"
+	"github.com/keeee21/commit-town/api/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+type APIKeyController struct {
+	apiKeyUsecase *usecase.APIKeyUsecase
+}
+
+func NewAPIKeyController(apiKeyUsecase *usecase.APIKeyUsecase) *APIKeyController {
+	return &APIKeyController{apiKeyUsecase: apiKeyUsecase}
+}
+
+// ListKeys ユーザーのAPIキーの一覧を取得（平文のキーは返さない）
+func (apiKeyController *APIKeyController) ListKeys(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	res, err := apiKeyController.apiKeyUsecase.ListKeys(ctx.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.UserNotFound()
+		}
+		return httperr.Internal("Failed to list API keys", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// CreateKey APIキーを作成（平文のキーはこのレスポンスでしか返さない）
+func (apiKeyController *APIKeyController) CreateKey(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	var req dto.CreateAPIKeyRequest
+	if err := ctx.Bind(&req); err != nil {
+		return httperr.InvalidRequest("Invalid request body")
+	}
+	if err := ctx.Validate(&req); err != nil {
+		return err
+	}
+	if strings.TrimSpace(req.Label) == "" {
+		return httperr.ValidationFailed("label must not be blank")
+	}
+
+	res, err := apiKeyController.apiKeyUsecase.CreateKey(ctx.Request().Context(), userID, req.Label)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.UserNotFound()
+		}
+		return httperr.Internal("Failed to create API key", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// RevokeKey APIキーを無効化（以降そのキーでの認証は401になる）
+func (apiKeyController *APIKeyController) RevokeKey(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+	keyID, err := parseIDParam(ctx, "key_id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	err = apiKeyController.apiKeyUsecase.RevokeKey(ctx.Request().Context(), userID, keyID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return httperr.NotFound("API key not found")
+		case errors.Is(err, usecase.ErrAPIKeyNotOwned):
+			return httperr.Forbidden("API key does not belong to the user")
+		}
+		return httperr.Internal("Failed to revoke API key", err)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/keeee21/commit-town/api/limits"
+	"github.com/keeee21/commit-town/api/repository"
+	"github.com/keeee21/commit-town/api/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+// defaultCalendarDays since省略時に返す日数（今日を含む）
+const defaultCalendarDays = 365
+
+type CalendarController struct {
+	calendarUsecase *usecase.CalendarUsecase
+	limits          limits.Limits
+}
+
+func NewCalendarController(calendarUsecase *usecase.CalendarUsecase, limits limits.Limits) *CalendarController {
+	return &CalendarController{calendarUsecase: calendarUsecase, limits: limits}
+}
+
+// GetCalendar ユーザーの日ごとのコミット数を取得（?since=&until=、since省略時は直近365日。期間の上限は MAX_HISTORY_DAYS）
+// ?year= を指定するとその年の1月1日〜12月31日を返す（since/until とは併用できない）
+// ?repo_id= を指定するとユーザーの登録リポジトリ1つ分だけを返す
+func (calendarController *CalendarController) GetCalendar(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	var repoID uint64
+	if v := ctx.QueryParam("repo_id"); v != "" {
+		repoID, err = strconv.ParseUint(v, 10, 64)
+		if err != nil || repoID == 0 {
+			return httperr.ValidationFailed("repo_id must be a positive integer")
+		}
+	}
+
+	var since, until time.Time
+	if v := ctx.QueryParam("year"); v != "" {
+		if ctx.QueryParam("since") != "" || ctx.QueryParam("until") != "" {
+			return httperr.ValidationFailed("year cannot be combined with since or until")
+		}
+		year, err := strconv.Atoi(v)
+		if err != nil || year < 1 || year > 9999 {
+			return httperr.ValidationFailed("year must be between 1 and 9999")
+		}
+		since = time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		until = time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	} else {
+		since, until, err = parseDateRange(ctx)
+		if err != nil {
+			return httperr.InvalidRequest(err.Error())
+		}
+		if ctx.QueryParam("since") == "" {
+			since = calendarController.limits.DefaultSince(until, defaultCalendarDays)
+		}
+		if err := calendarController.limits.CheckRange(since, until); err != nil {
+			return httperr.ValidationFailed(err.Error())
+		}
+	}
+
+	if repoID != 0 {
+		calendar, err := calendarController.calendarUsecase.GetRepoCalendar(ctx.Request().Context(), userID, repoID, since, until)
+		if err != nil {
+			switch {
+			case errors.Is(err, repository.ErrNotFound):
+				return httperr.NotFound("Repository not found")
+			case errors.Is(err, usecase.ErrRepositoryNotOwned):
+				return httperr.Forbidden("Repository does not belong to the user")
+			}
+			return httperr.Internal("Failed to get commit calendar", err)
+		}
+		return jsonWithLastModified(ctx, http.StatusOK, calendar, calendar.LastModified)
+	}
+
+	calendar, err := calendarController.calendarUsecase.GetCalendar(ctx.Request().Context(), userID, since, until)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.UserNotFound()
+		}
+		return httperr.Internal("Failed to get commit calendar", err)
+	}
+
+	return jsonWithLastModified(ctx, http.StatusOK, calendar, calendar.LastModified)
+}
+
+// GetDay ユーザーの1日分の合計コミット数と登録リポジトリごとの内訳を取得（:date は YYYY-MM-DD）
+func (calendarController *CalendarController) GetDay(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	date, err := time.Parse(dateLayout, ctx.Param("date"))
+	if err != nil {
+		return httperr.InvalidRequest("date must be in YYYY-MM-DD format")
+	}
+
+	day, err := calendarController.calendarUsecase.GetDay(ctx.Request().Context(), userID, date)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return httperr.UserNotFound()
+		case errors.Is(err, usecase.ErrDateInFuture):
+			return httperr.ValidationFailed("date must not be in the future")
+		}
+		return httperr.Internal("Failed to get commit day", err)
+	}
+
+	return jsonWithETag(ctx, http.StatusOK, day)
+}
+
+// GetWeekdayActivity ユーザーの曜日ごとの合計コミット数を取得（?since=&until=、since省略時は上限の MAX_HISTORY_DAYS 日分）
+func (calendarController *CalendarController) GetWeekdayActivity(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	since, until, err := parseDateRange(ctx)
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+	if ctx.QueryParam("since") == "" {
+		since = calendarController.limits.DefaultSince(until, calendarController.limits.MaxHistoryDays)
+	}
+	if err := calendarController.limits.CheckRange(since, until); err != nil {
+		return httperr.ValidationFailed(err.Error())
+	}
+
+	activity, err := calendarController.calendarUsecase.GetWeekdayActivity(ctx.Request().Context(), userID, since, until)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.UserNotFound()
+		}
+		return httperr.Internal("Failed to get weekday activity", err)
+	}
+
+	return jsonWithETag(ctx, http.StatusOK, activity)
+}
@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keeee21/commit-town/api/internal/testdb"
+	"github.com/keeee21/commit-town/api/limits"
+	"github.com/keeee21/commit-town/api/models"
+	"github.com/keeee21/commit-town/api/repository"
+	"github.com/keeee21/commit-town/api/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+// 返した Last-Modified を If-Modified-Since に付けた次のリクエストは、日次ログが更新されるまで304になる
+func TestCalendarController_GetCalendar_IfModifiedSince(t *testing.T) {
+	ctx := context.Background()
+	database := testdb.Open(t)
+	userRepo := repository.NewUserRepository(database)
+	userLogRepo := repository.NewUserDailyCommitLogRepository(database)
+	calendarUsecase := usecase.NewCalendarUsecase(userRepo, userLogRepo, repository.NewRepoRepository(database), repository.NewRepoDailyCommitLogRepository(database))
+	calendarController := NewCalendarController(calendarUsecase, limits.New(0, 0))
+
+	user := &models.User{GitHubUserID: 1, GitHubUsername: "alice", Email: "alice@example.com", Timezone: "UTC"}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	yesterday := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+	if err := userLogRepo.Upsert(ctx, &models.UserDailyCommitLog{UserID: user.ID, Date: yesterday, TotalCommits: 2}); err != nil {
+		t.Fatalf("failed to store daily log: %v", err)
+	}
+
+	e := echo.New()
+	get := func(ifModifiedSince string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/users/"+strconv.FormatUint(user.ID, 10)+"/calendar", nil)
+		if ifModifiedSince != "" {
+			req.Header.Set("If-Modified-Since", ifModifiedSince)
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(strconv.FormatUint(user.ID, 10))
+		if err := calendarController.GetCalendar(c); err != nil {
+			t.Fatalf("GetCalendar returned an error: %v", err)
+		}
+		return rec
+	}
+
+	first := get("")
+	if first.Code != http.StatusOK {
+		t.Fatalf("first status = %d, want %d", first.Code, http.StatusOK)
+	}
+	lastModified := first.Header().Get(echo.HeaderLastModified)
+	if !strings.HasSuffix(lastModified, " GMT") {
+		t.Fatalf("Last-Modified = %q, want an HTTP date in GMT", lastModified)
+	}
+	if _, err := http.ParseTime(lastModified); err != nil {
+		t.Fatalf("Last-Modified = %q is not an HTTP date: %v", lastModified, err)
+	}
+
+	repeated := get(lastModified)
+	if repeated.Code != http.StatusNotModified {
+		t.Errorf("repeated status = %d, want %d", repeated.Code, http.StatusNotModified)
+	}
+	if repeated.Body.Len() != 0 {
+		t.Errorf("304 response has a body: %q", repeated.Body.String())
+	}
+
+	// Last-Modified は秒単位のため、秒が変わってから更新する
+	time.Sleep(1100 * time.Millisecond)
+	if err := userLogRepo.Upsert(ctx, &models.UserDailyCommitLog{UserID: user.ID, Date: yesterday, TotalCommits: 3}); err != nil {
+		t.Fatalf("failed to update daily log: %v", err)
+	}
+	changed := get(lastModified)
+	if changed.Code != http.StatusOK {
+		t.Errorf("status after the log changed = %d, want %d", changed.Code, http.StatusOK)
+	}
+	if got := changed.Header().Get(echo.HeaderLastModified); got == lastModified {
+		t.Errorf("Last-Modified = %q after the log changed, want a later time", got)
+	}
+}
@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/keeee21/commit-town/api/openapi"
+	"github.com/labstack/echo/v4"
+)
+
+// swaggerUIHTML /openapi.yaml を読み込むSwagger UI
+const swaggerUIHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <title>Commit Town API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css" />
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({ url: "/openapi.yaml", dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>`
+
+type DocsController struct{}
+
+func NewDocsController() *DocsController {
+	return &DocsController{}
+}
+
+// Spec OpenAPI仕様を返す
+func (docsController *DocsController) Spec(ctx echo.Context) error {
+	return ctx.Blob(http.StatusOK, "application/yaml", openapi.Spec())
+}
+
+// UI Swagger UIを返す
+func (docsController *DocsController) UI(ctx echo.Context) error {
+	return ctx.HTML(http.StatusOK, swaggerUIHTML)
+}
@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// httpMethods OpenAPI の Path Item に書けるオペレーション
+var httpMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true, "options": true, "head": true, "patch": true, "trace": true,
+}
+
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+func TestDocsController_Spec(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/openapi.yaml", nil)
+	rec := httptest.NewRecorder()
+	if err := NewDocsController().Spec(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("Spec returned an error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get(echo.HeaderContentType); got != "application/yaml" {
+		t.Errorf("Content-Type = %q, want application/yaml", got)
+	}
+
+	var spec map[string]any
+	if err := yaml.Unmarshal(rec.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("served spec is not valid YAML: %v", err)
+	}
+	for _, err := range validateSpec(spec) {
+		t.Error(err)
+	}
+}
+
+// validateSpec OpenAPI 3 の構造として読めるかを確かめる（必須項目、オペレーション、パスパラメーター、$ref の参照先）
+func validateSpec(spec map[string]any) []error {
+	var errs []error
+	if version, _ := spec["openapi"].(string); !strings.HasPrefix(version, "3.") {
+		errs = append(errs, fmt.Errorf("openapi = %q, want 3.x", spec["openapi"]))
+	}
+	info, _ := spec["info"].(map[string]any)
+	if title, _ := info["title"].(string); title == "" {
+		errs = append(errs, fmt.Errorf("info.title is missing"))
+	}
+	if version, _ := info["version"].(string); version == "" {
+		errs = append(errs, fmt.Errorf("info.version is missing"))
+	}
+
+	paths, _ := spec["paths"].(map[string]any)
+	if len(paths) == 0 {
+		errs = append(errs, fmt.Errorf("paths is empty"))
+	}
+	operationIDs := make(map[string]string)
+	for path, item := range paths {
+		if !strings.HasPrefix(path, "/") {
+			errs = append(errs, fmt.Errorf("path %q does not start with /", path))
+		}
+		pathItem, ok := item.(map[string]any)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: path item is not an object", path))
+			continue
+		}
+		shared := paramNames(spec, pathItem["parameters"])
+		for method, op := range pathItem {
+			if method == "parameters" || method == "summary" || method == "description" {
+				continue
+			}
+			where := strings.ToUpper(method) + " " + path
+			if !httpMethods[method] {
+				errs = append(errs, fmt.Errorf("%s: unknown operation %q", path, method))
+				continue
+			}
+			operation, ok := op.(map[string]any)
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s: operation is not an object", where))
+				continue
+			}
+			if responses, _ := operation["responses"].(map[string]any); len(responses) == 0 {
+				errs = append(errs, fmt.Errorf("%s: responses is empty", where))
+			}
+			if id, _ := operation["operationId"].(string); id != "" {
+				if other, dup := operationIDs[id]; dup {
+					errs = append(errs, fmt.Errorf("%s: operationId %q is also used by %s", where, id, other))
+				}
+				operationIDs[id] = where
+			}
+			declared := paramNames(spec, operation["parameters"])
+			for _, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+				if !shared[match[1]] && !declared[match[1]] {
+					errs = append(errs, fmt.Errorf("%s: path parameter %q is not declared", where, match[1]))
+				}
+			}
+		}
+	}
+
+	walkRefs(spec, func(ref string) {
+		if _, ok := resolveRef(spec, ref); !ok {
+			errs = append(errs, fmt.Errorf("$ref %q does not resolve", ref))
+		}
+	})
+	return errs
+}
+
+// paramNames in: path のパラメーター名（$ref は参照先を読む）
+func paramNames(spec map[string]any, params any) map[string]bool {
+	names := make(map[string]bool)
+	list, _ := params.([]any)
+	for _, p := range list {
+		param, _ := p.(map[string]any)
+		if ref, ok := param["$ref"].(string); ok {
+			resolved, _ := resolveRef(spec, ref)
+			param, _ = resolved.(map[string]any)
+		}
+		if in, _ := param["in"].(string); in == "path" {
+			name, _ := param["name"].(string)
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// walkRefs 仕様の中の全ての $ref を訪れる
+func walkRefs(node any, visit func(ref string)) {
+	switch v := node.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if ref, ok := child.(string); ok && key == "$ref" {
+				visit(ref)
+				continue
+			}
+			walkRefs(child, visit)
+		}
+	case []any:
+		for _, child := range v {
+			walkRefs(child, visit)
+		}
+	}
+}
+
+// resolveRef 同じ文書内の参照（#/components/...）を辿る
+func resolveRef(spec map[string]any, ref string) (any, bool) {
+	pointer, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return nil, false
+	}
+	var node any = spec
+	for _, token := range strings.Split(pointer, "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		object, ok := node.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if node, ok = object[token]; !ok {
+			return nil, false
+		}
+	}
+	return node, true
+}
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// jsonWithETag レスポンスボディのハッシュをETagとして付けてJSONを返す
+// If-None-Match が一致した場合はボディを返さず304を返す。ポーリングされる参照系エンドポイントで使う
+func jsonWithETag(ctx echo.Context, status int, body interface{}) error {
+	return jsonWithLastModified(ctx, status, body, time.Time{})
+}
+
+// jsonWithLastModified jsonWithETag に加えて lastModified を Last-Modified として付け、If-Modified-Since にも304を返す
+// If-None-Match がある場合はそちらを優先する（RFC 9110）。lastModified がゼロ値の場合は Last-Modified を付けない
+func jsonWithLastModified(ctx echo.Context, status int, body interface{}, lastModified time.Time) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(b)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	res := ctx.Response()
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set("ETag", etag)
+	if !lastModified.IsZero() {
+		// HTTPの日付は秒単位のGMT
+		res.Header().Set(echo.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	req := ctx.Request()
+	if ifNoneMatch := req.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		if etagMatches(ifNoneMatch, etag) {
+			return ctx.NoContent(http.StatusNotModified)
+		}
+	} else if notModifiedSince(req.Header.Get("If-Modified-Since"), lastModified) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+	return ctx.JSONBlob(status, b)
+}
+
+// notModifiedSince If-Modified-Since の時刻以降に lastModified が更新されていないか（秒未満は切り捨てて比べる）
+func notModifiedSince(ifModifiedSince string, lastModified time.Time) bool {
+	if ifModifiedSince == "" || lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// etagMatches If-None-Match（カンマ区切り・弱いETag・* を含む）が etag と一致するか
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// serveWithETag body を jsonWithLastModified で返し、header を付けたリクエストの結果を返す
+func serveWithETag(t *testing.T, body any, lastModified time.Time, header map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/users/1/summary", nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	if err := jsonWithLastModified(e.NewContext(req, rec), http.StatusOK, body, lastModified); err != nil {
+		t.Fatalf("jsonWithLastModified returned an error: %v", err)
+	}
+	return rec
+}
+
+func TestJSONWithETag_RepeatedRequestReturnsNotModified(t *testing.T) {
+	body := map[string]int{"total_commits": 42}
+
+	first := serveWithETag(t, body, time.Time{}, nil)
+	if first.Code != http.StatusOK {
+		t.Fatalf("first status = %d, want %d", first.Code, http.StatusOK)
+	}
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("first response has no ETag")
+	}
+
+	repeated := serveWithETag(t, body, time.Time{}, map[string]string{"If-None-Match": etag})
+	if repeated.Code != http.StatusNotModified {
+		t.Errorf("repeated status = %d, want %d", repeated.Code, http.StatusNotModified)
+	}
+	if repeated.Body.Len() != 0 {
+		t.Errorf("304 response has a body: %q", repeated.Body.String())
+	}
+	if got := repeated.Header().Get("ETag"); got != etag {
+		t.Errorf("304 ETag = %q, want %q", got, etag)
+	}
+
+	changed := serveWithETag(t, map[string]int{"total_commits": 43}, time.Time{}, map[string]string{"If-None-Match": etag})
+	if changed.Code != http.StatusOK {
+		t.Errorf("status after the body changed = %d, want %d", changed.Code, http.StatusOK)
+	}
+}
+
+func TestJSONWithLastModified(t *testing.T) {
+	body := map[string]int{"total_commits": 42}
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC)
+	etag := serveWithETag(t, body, modified, nil).Header().Get("ETag")
+
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   int
+	}{
+		{"no conditional headers", nil, http.StatusOK},
+		{"not modified since", map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}, http.StatusNotModified},
+		{"modified since", map[string]string{"If-Modified-Since": modified.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusOK},
+		{"invalid date", map[string]string{"If-Modified-Since": "yesterday"}, http.StatusOK},
+		{"stale etag wins over date", map[string]string{"If-None-Match": `"stale"`, "If-Modified-Since": modified.Format(http.TimeFormat)}, http.StatusOK},
+		{"matching etag", map[string]string{"If-None-Match": etag}, http.StatusNotModified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveWithETag(t, body, modified, tt.header)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if got := rec.Header().Get(echo.HeaderLastModified); got != modified.Format(http.TimeFormat) {
+				t.Errorf("Last-Modified = %q, want %q", got, modified.Format(http.TimeFormat))
+			}
+		})
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	const etag = `"abc"`
+	tests := []struct {
+		ifNoneMatch string
+		want        bool
+	}{
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"xyz", "abc"`, true},
+		{`*`, true},
+		{`"xyz"`, false},
+		{`abc`, false},
+		{``, false},
+	}
+	for _, tt := range tests {
+		if got := etagMatches(tt.ifNoneMatch, etag); got != tt.want {
+			t.Errorf("etagMatches(%q) = %v, want %v", tt.ifNoneMatch, got, tt.want)
+		}
+	}
+}
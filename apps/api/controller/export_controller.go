@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/keeee21/commit-town/api/models"
+	"github.com/keeee21/commit-town/api/repository"
+	"github.com/keeee21/commit-town/api/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+// csvFlushInterval 何行ごとにレスポンスへフラッシュするか（JSONエクスポートの日次ログも同じ）
+const csvFlushInterval = 500
+
+type ExportController struct {
+	exportUsecase *usecase.ExportUsecase
+}
+
+func NewExportController(exportUsecase *usecase.ExportUsecase) *ExportController {
+	return &ExportController{exportUsecase: exportUsecase}
+}
+
+// ExportCSV ユーザーの日次コミット履歴をCSVでストリーミング出力
+func (exportController *ExportController) ExportCSV(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	since, until, err := parseDateRange(ctx)
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	res := ctx.Response()
+	writer := csv.NewWriter(res)
+	rows := 0
+	started := false
+
+	err = exportController.exportUsecase.StreamDailyLogs(ctx.Request().Context(), userID, since, until, func(commitLog *models.UserDailyCommitLog) error {
+		// 最初の行を書く時点でヘッダーを確定させる（それ以前のエラーはJSONで返せるようにする）
+		if !started {
+			startCSV(res, userID)
+			started = true
+			if err := writer.Write([]string{"date", "total_commits"}); err != nil {
+				return err
+			}
+		}
+
+		if err := writer.Write([]string{
+			commitLog.Date.UTC().Format(dateLayout),
+			strconv.Itoa(commitLog.TotalCommits),
+		}); err != nil {
+			return err
+		}
+
+		rows++
+		if rows%csvFlushInterval == 0 {
+			writer.Flush()
+			res.Flush()
+		}
+		return writer.Error()
+	})
+
+	if err != nil {
+		if started {
+			// ストリーミング開始後はステータスを変更できないため、ログのみ残して打ち切る
+			log.Printf("Failed to stream CSV export for user %d: %v", userID, err)
+			return nil
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.UserNotFound()
+		}
+		return httperr.Internal("Failed to export commit history", err)
+	}
+
+	if !started {
+		startCSV(res, userID)
+		if err := writer.Write([]string{"date", "total_commits"}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ExportJSON ユーザーのプロフィール・登録リポジトリ・streak・日次コミットログを1つのJSONでストリーミング出力（?since= で日次ログの開始日を指定）
+// 日次ログ以外を先に書き出し、日次ログは1日ずつ書き出すため、期間が長くてもメモリを使い過ぎない
+func (exportController *ExportController) ExportJSON(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	since, until, err := parseDateRange(ctx)
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	export, err := exportController.exportUsecase.GetUserExport(ctx.Request().Context(), userID, since)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.UserNotFound()
+		}
+		return httperr.Internal("Failed to export user data", err)
+	}
+	head, err := json.Marshal(export)
+	if err != nil {
+		return httperr.Internal("Failed to export user data", err)
+	}
+
+	res := ctx.Response()
+	res.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="user-%d.json"`, userID))
+	res.WriteHeader(http.StatusOK)
+
+	// 日次ログ以外のオブジェクトの閉じ括弧を外し、後ろに daily_logs の配列を続ける
+	if _, err := res.Write(append(head[:len(head)-1], `,"daily_logs":[`...)); err != nil {
+		return nil
+	}
+	rows := 0
+	err = exportController.exportUsecase.StreamDailyLogs(ctx.Request().Context(), userID, since, until, func(commitLog *models.UserDailyCommitLog) error {
+		b, err := json.Marshal(dto.UserExportDailyLog{
+			Date:         commitLog.Date.UTC().Format(dateLayout),
+			TotalCommits: commitLog.TotalCommits,
+		})
+		if err != nil {
+			return err
+		}
+		if rows > 0 {
+			b = append([]byte{','}, b...)
+		}
+		if _, err := res.Write(b); err != nil {
+			return err
+		}
+
+		rows++
+		if rows%csvFlushInterval == 0 {
+			res.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		// ストリーミング開始後はステータスを変更できないため、ログのみ残して打ち切る（途中までのJSONは不正になる）
+		log.Printf("Failed to stream JSON export for user %d: %v", userID, err)
+		return nil
+	}
+
+	_, err = res.Write([]byte("]}"))
+	if err != nil {
+		log.Printf("Failed to finish JSON export for user %d: %v", userID, err)
+	}
+	return nil
+}
+
+// GetChanges 差分同期用に、?since=（RFC 3339）より後に更新された日次ログとstreakを取得（省略時は全件）
+// 次回はレスポンスの now を since に渡す
+func (exportController *ExportController) GetChanges(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	var since time.Time
+	if s := ctx.QueryParam("since"); s != "" {
+		since, err = time.Parse(time.RFC3339, s)
+		if err != nil {
+			return httperr.ValidationFailed("since must be an RFC 3339 timestamp")
+		}
+	}
+
+	res, err := exportController.exportUsecase.GetChanges(ctx.Request().Context(), userID, since)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.UserNotFound()
+		}
+		return httperr.Internal("Failed to get changes", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// startCSV CSVダウンロード用のヘッダーを書き込む
+func startCSV(res *echo.Response, userID uint64) {
+	res.Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="commit-history-%d.csv"`, userID))
+	res.WriteHeader(http.StatusOK)
+}
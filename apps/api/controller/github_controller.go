@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/keeee21/commit-town/api/internal/github"
+	"github.com/keeee21/commit-town/api/repository"
+	"github.com/keeee21/commit-town/api/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+type GitHubController struct {
+	githubUsecase *usecase.GitHubUsecase
+}
+
+func NewGitHubController(githubUsecase *usecase.GitHubUsecase) *GitHubController {
+	return &GitHubController{githubUsecase: githubUsecase}
+}
+
+// ListUserRepos 登録前のプレビュー用に、GitHubユーザーがオーナーの公開リポジトリを取得
+// 登録済みかを判定するユーザーはAPIキーで認証していればそのユーザー、なければ ?user_id=（省略可）
+func (githubController *GitHubController) ListUserRepos(ctx echo.Context) error {
+	userID, err := requestUserID(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	res, err := githubController.githubUsecase.ListUserRepos(ctx.Request().Context(), ctx.Param("username"), userID)
+	if err != nil {
+		var rateLimitErr *github.RateLimitError
+		switch {
+		case errors.Is(err, usecase.ErrInvalidGitHubUsername):
+			return httperr.ValidationFailed("username must be a valid GitHub username")
+		case errors.Is(err, github.ErrUserNotFound):
+			return httperr.NotFound("GitHub user not found")
+		case errors.Is(err, repository.ErrNotFound):
+			return httperr.UserNotFound()
+		case errors.As(err, &rateLimitErr):
+			ctx.Response().Header().Set("Retry-After", strconv.Itoa(int(time.Until(rateLimitErr.ResetAt).Seconds())+1))
+			return httperr.RateLimited("GitHub rate limit reached, retry later")
+		}
+		return httperr.Internal("Failed to list GitHub repositories", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/keeee21/commit-town/api/internal/github"
+	"github.com/keeee21/commit-town/api/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+type GitHubWebhookController struct {
+	webhookUsecase *usecase.GitHubWebhookUsecase
+	secret         string // GitHubのWebhookに設定したシークレット（空の場合は全て拒否する）
+}
+
+func NewGitHubWebhookController(webhookUsecase *usecase.GitHubWebhookUsecase, secret string) *GitHubWebhookController {
+	return &GitHubWebhookController{webhookUsecase: webhookUsecase, secret: secret}
+}
+
+// ReceiveWebhook GitHubのWebhookを受け取り、push されたリポジトリを同期する
+// X-Hub-Signature-256 の署名が一致しない場合は401、ボディが不正な場合は400を返す。ping と扱わないイベントは204を返す
+func (webhookController *GitHubWebhookController) ReceiveWebhook(ctx echo.Context) error {
+	if webhookController.secret == "" {
+		return httperr.Unauthorized("GitHub webhooks are not configured")
+	}
+
+	// ボディの大きさは /api のボディサイズ上限で制限される
+	body, err := io.ReadAll(ctx.Request().Body)
+	if err != nil {
+		return httperr.InvalidRequest("Failed to read request body")
+	}
+	if !github.VerifyWebhookSignature(webhookController.secret, body, ctx.Request().Header.Get(github.HeaderWebhookSignature)) {
+		return httperr.Unauthorized("The webhook signature is missing or invalid")
+	}
+
+	push, err := github.ParseWebhook(ctx.Request().Header.Get(github.HeaderWebhookEvent), body)
+	if err != nil {
+		switch {
+		case errors.Is(err, github.ErrInvalidWebhookPayload):
+			return httperr.InvalidRequest(err.Error())
+		case errors.Is(err, github.ErrUnsupportedWebhookEvent):
+			return ctx.NoContent(http.StatusNoContent)
+		}
+		return httperr.Internal("Failed to parse webhook", err)
+	}
+	if push == nil {
+		return ctx.NoContent(http.StatusNoContent)
+	}
+
+	queued, err := webhookController.webhookUsecase.HandlePush(ctx.Request().Context(), push)
+	if err != nil {
+		return httperr.Internal("Failed to handle push", err)
+	}
+	return ctx.JSON(http.StatusAccepted, dto.GitHubWebhookResponse{Queued: queued})
+}
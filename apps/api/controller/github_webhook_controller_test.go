@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/keeee21/commit-town/api/internal/github"
+	"github.com/keeee21/commit-town/api/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+const testWebhookSecret = "s3cret"
+
+// githubFixture internal/github/testdata のWebhookのペイロード
+func githubFixture(t *testing.T, name string) string {
+	t.Helper()
+	body, err := os.ReadFile("../internal/github/testdata/" + name)
+	if err != nil {
+		t.Fatalf("failed to read fixture %s: %v", name, err)
+	}
+	return string(body)
+}
+
+func signWebhook(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// receiveWebhook secret を設定した受け口に event のWebhookを送る
+// DBを使わずに済むよう、ユースケースはリポジトリを引かないリクエスト（ping・コミットの無い push など）でだけ使う
+func receiveWebhook(secret, event, body, signature string) *httptest.ResponseRecorder {
+	e := echo.New()
+	e.HTTPErrorHandler = httperr.Handler
+	webhookController := NewGitHubWebhookController(usecase.NewGitHubWebhookUsecase(nil, nil, 1), secret)
+	e.POST("/api/webhooks/github", webhookController.ReceiveWebhook)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/webhooks/github", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(github.HeaderWebhookEvent, event)
+	if signature != "" {
+		req.Header.Set(github.HeaderWebhookSignature, signature)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGitHubWebhookController_ReceiveWebhook(t *testing.T) {
+	push := githubFixture(t, "webhook_push.json")
+	ping := githubFixture(t, "webhook_ping.json")
+	malformed := githubFixture(t, "webhook_malformed.json")
+	branchDeleted := `{"ref":"refs/heads/old","repository":{"name":"town","owner":{"login":"alice"}},"commits":[]}`
+
+	tests := []struct {
+		name      string
+		secret    string
+		event     string
+		body      string
+		signature string
+		want      int
+	}{
+		{"ping", testWebhookSecret, github.EventPing, ping, signWebhook(testWebhookSecret, ping), http.StatusNoContent},
+		{"malformed push", testWebhookSecret, github.EventPush, malformed, signWebhook(testWebhookSecret, malformed), http.StatusBadRequest},
+		{"push missing fields", testWebhookSecret, github.EventPush, `{}`, signWebhook(testWebhookSecret, `{}`), http.StatusBadRequest},
+		{"push without commits", testWebhookSecret, github.EventPush, branchDeleted, signWebhook(testWebhookSecret, branchDeleted), http.StatusAccepted},
+		{"unsupported event", testWebhookSecret, "issues", ping, signWebhook(testWebhookSecret, ping), http.StatusNoContent},
+		{"missing signature", testWebhookSecret, github.EventPush, push, "", http.StatusUnauthorized},
+		{"signed with another secret", testWebhookSecret, github.EventPush, push, signWebhook("other", push), http.StatusUnauthorized},
+		{"malformed body with a bad signature", testWebhookSecret, github.EventPush, malformed, signWebhook("other", malformed), http.StatusUnauthorized},
+		{"secret not configured", "", github.EventPing, ping, signWebhook("", ping), http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := receiveWebhook(tt.secret, tt.event, tt.body, tt.signature)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
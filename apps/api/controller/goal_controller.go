@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/keeee21/commit-town/api/models"
+	"github.com/keeee21/commit-town/api/repository"
+	"github.com/keeee21/commit-town/api/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+type GoalController struct {
+	goalUsecase *usecase.GoalUsecase
+}
+
+func NewGoalController(goalUsecase *usecase.GoalUsecase) *GoalController {
+	return &GoalController{goalUsecase: goalUsecase}
+}
+
+// GetGoal 今の期間の目標と進捗を取得（?period=month|week、デフォルトは month）
+func (goalController *GoalController) GetGoal(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	period := ctx.QueryParam("period")
+	switch period {
+	case "":
+		period = models.GoalPeriodMonth
+	case models.GoalPeriodMonth, models.GoalPeriodWeek:
+	default:
+		return httperr.ValidationFailed("period must be month or week")
+	}
+
+	res, err := goalController.goalUsecase.GetGoal(ctx.Request().Context(), userID, period, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return httperr.UserNotFound()
+		case errors.Is(err, usecase.ErrGoalNotSet):
+			return httperr.NotFound("Goal is not set for the current period")
+		}
+		return httperr.Internal("Failed to get goal", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// SetGoal 今の期間の目標を設定（設定済みなら目標数を変更）
+func (goalController *GoalController) SetGoal(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	var req dto.SetGoalRequest
+	if err := ctx.Bind(&req); err != nil {
+		return httperr.InvalidRequest("Invalid request body")
+	}
+	if err := ctx.Validate(&req); err != nil {
+		return err
+	}
+
+	res, err := goalController.goalUsecase.SetGoal(ctx.Request().Context(), userID, &req, time.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.UserNotFound()
+		}
+		return httperr.Internal("Failed to set goal", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
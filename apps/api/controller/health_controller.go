@@ -2,7 +2,10 @@ package controller
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/keeee21/commit-town/api/httperr"
 	"github.com/keeee21/commit-town/api/usecase"
 	"github.com/labstack/echo/v4"
 )
@@ -12,7 +15,37 @@ type HealthController struct {
 }
 
 type HealthResponse struct {
-	Status string `json:"status"`
+	Status        string `json:"status"` // ok / degraded（スキーマが古い）/ unavailable（DBに接続できない）
+	Version       string `json:"version"`
+	DBOK          bool   `json:"db_ok"`
+	SchemaCurrent bool   `json:"schema_current"`
+
+	GitHub *GitHubHealthResponse             `json:"github,omitempty"` // /readyz のみ
+	Ready  *bool                             `json:"ready,omitempty"`  // /readyz のみ。必須のチェックが全て通ったか
+	Checks map[string]ReadinessCheckResponse `json:"checks,omitempty"` // /readyz のみ。キーは database / github / scheduler
+}
+
+type ReadinessCheckResponse struct {
+	Status     string                 `json:"status"`   // ok / fail
+	Required   bool                   `json:"required"` // true の場合は fail で503になる（READYZ_REQUIRED_CHECKS）
+	Detail     string                 `json:"detail,omitempty"`
+	DurationMS int64                  `json:"duration_ms"`
+	Jobs       []SchedulerJobResponse `json:"jobs,omitempty"` // scheduler のみ
+}
+
+type SchedulerJobResponse struct {
+	Name                  string     `json:"name"`
+	IntervalSeconds       int64      `json:"interval_seconds"`
+	LastSuccessAt         *time.Time `json:"last_success_at"`          // 起動後に成功していなければ null
+	LastSuccessAgeSeconds *int64     `json:"last_success_age_seconds"` // 起動後に成功していなければ null
+	Stale                 bool       `json:"stale"`                    // 間隔の2倍を超えて成功していない
+}
+
+type GitHubHealthResponse struct {
+	Reachable          bool       `json:"reachable"`
+	RateLimitLimit     *int       `json:"rate_limit_limit"`     // 到達できなかった場合は null
+	RateLimitRemaining *int       `json:"rate_limit_remaining"` // 到達できなかった場合は null
+	RateLimitResetAt   *time.Time `json:"rate_limit_reset_at"`  // 到達できなかった場合は null
 }
 
 // NewHealthController creates a new health controller
@@ -22,15 +55,117 @@ func NewHealthController(healthUsecase usecase.HealthUsecase) *HealthController
 	}
 }
 
+// Check DBに接続できない場合のみ503を返す（未適用のマイグレーションは degraded として200で返す）
 func (h *HealthController) Check(c echo.Context) error {
-	status, err := h.healthUsecase.Check(c.Request().Context())
+	res, code, err := h.check(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(code, res)
+}
+
+// Ready DB・GitHub・スケジューラーをそれぞれのタイムアウトで確認し、チェックごとの結果を返す
+// READYZ_REQUIRED_CHECKS（デフォルトは database）に含まれるチェックが失敗した場合のみ503、それ以外の失敗は degraded として200で返す
+// ?deps=github は以前の互換のために受け付ける（GitHubは常に確認する）
+func (h *HealthController) Ready(c echo.Context) error {
+	if deps := c.QueryParam("deps"); deps != "" {
+		for _, dep := range strings.Split(deps, ",") {
+			if strings.TrimSpace(dep) != "github" {
+				return httperr.ValidationFailed("deps must be a comma-separated list of: github")
+			}
+		}
+	}
+
+	readiness, err := h.healthUsecase.CheckReadiness(c.Request().Context())
+	if err != nil {
+		return httperr.Internal("Readiness check failed", err)
+	}
+
+	res := &HealthResponse{
+		Status:        "ok",
+		Version:       readiness.Health.Version,
+		DBOK:          readiness.Health.DBOK,
+		SchemaCurrent: readiness.Health.SchemaCurrent,
+		GitHub:        toGitHubHealthResponse(readiness.GitHub),
+		Ready:         &readiness.Ready,
+		Checks:        make(map[string]ReadinessCheckResponse, len(readiness.Checks)),
+	}
+	now := time.Now()
+	for _, check := range readiness.Checks {
+		checkRes := ReadinessCheckResponse{
+			Status:     "ok",
+			Required:   check.Required,
+			Detail:     check.Detail,
+			DurationMS: check.Duration.Milliseconds(),
+		}
+		if !check.OK {
+			checkRes.Status = "fail"
+		}
+		if check.Name == usecase.CheckScheduler {
+			checkRes.Jobs = toSchedulerJobResponses(readiness.Scheduler, now)
+		}
+		res.Checks[check.Name] = checkRes
+		if !check.OK || check.Detail != "" {
+			res.Status = "degraded"
+		}
+	}
+
+	code := http.StatusOK
+	if !readiness.Ready {
+		res.Status = "unavailable"
+		code = http.StatusServiceUnavailable
+	}
+	return c.JSON(code, res)
+}
+
+func toGitHubHealthResponse(githubStatus *usecase.GitHubStatus) *GitHubHealthResponse {
+	res := &GitHubHealthResponse{Reachable: githubStatus.Reachable}
+	if githubStatus.RateLimit != nil {
+		res.RateLimitLimit = &githubStatus.RateLimit.Limit
+		res.RateLimitRemaining = &githubStatus.RateLimit.Remaining
+		res.RateLimitResetAt = &githubStatus.RateLimit.ResetAt
+	}
+	return res
+}
+
+func toSchedulerJobResponses(jobs []usecase.SchedulerJobStatus, now time.Time) []SchedulerJobResponse {
+	res := make([]SchedulerJobResponse, 0, len(jobs))
+	for _, job := range jobs {
+		jobRes := SchedulerJobResponse{
+			Name:            job.Name,
+			IntervalSeconds: int64(job.Interval / time.Second),
+			LastSuccessAt:   job.LastSuccess,
+			Stale:           job.Stale,
+		}
+		if job.LastSuccess != nil {
+			age := int64(now.Sub(*job.LastSuccess) / time.Second)
+			jobRes.LastSuccessAgeSeconds = &age
+		}
+		res = append(res, jobRes)
+	}
+	return res
+}
+
+// check DBとマイグレーションの状態からレスポンスとステータスコードを組み立てる
+func (h *HealthController) check(c echo.Context) (*HealthResponse, int, error) {
+	health, err := h.healthUsecase.Check(c.Request().Context())
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": err.Error(),
-		})
+		return nil, 0, httperr.Internal("Health check failed", err)
 	}
 
-	return c.JSON(http.StatusOK, HealthResponse{
-		Status: status,
-	})
+	res := &HealthResponse{
+		Status:        "ok",
+		Version:       health.Version,
+		DBOK:          health.DBOK,
+		SchemaCurrent: health.SchemaCurrent,
+	}
+	code := http.StatusOK
+	switch {
+	case !health.DBOK:
+		res.Status = "unavailable"
+		code = http.StatusServiceUnavailable
+	case !health.SchemaCurrent:
+		res.Status = "degraded"
+	}
+	return res, code, nil
 }
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/keeee21/commit-town/api/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+// fakeHealthUsecase CheckReadiness が readiness を返す HealthUsecase
+type fakeHealthUsecase struct {
+	readiness *usecase.ReadinessStatus
+}
+
+func (f *fakeHealthUsecase) Check(ctx context.Context) (*usecase.HealthStatus, error) {
+	return f.readiness.Health, nil
+}
+
+func (f *fakeHealthUsecase) CheckGitHub(ctx context.Context) *usecase.GitHubStatus {
+	return f.readiness.GitHub
+}
+
+func (f *fakeHealthUsecase) CheckReadiness(ctx context.Context) (*usecase.ReadinessStatus, error) {
+	return f.readiness, nil
+}
+
+func TestHealthController_Ready(t *testing.T) {
+	check := func(name string, ok, required bool, detail string) usecase.ReadinessCheck {
+		return usecase.ReadinessCheck{Name: name, OK: ok, Required: required, Detail: detail}
+	}
+	tests := []struct {
+		name       string
+		ready      bool
+		checks     []usecase.ReadinessCheck
+		wantCode   int
+		wantStatus string
+		wantChecks map[string]string
+	}{
+		{"all healthy", true, []usecase.ReadinessCheck{
+			check(usecase.CheckDatabase, true, true, ""), check(usecase.CheckGitHub, true, false, ""), check(usecase.CheckScheduler, true, false, ""),
+		}, http.StatusOK, "ok", map[string]string{usecase.CheckDatabase: "ok", usecase.CheckGitHub: "ok", usecase.CheckScheduler: "ok"}},
+		{"optional github down", true, []usecase.ReadinessCheck{
+			check(usecase.CheckDatabase, true, true, ""), check(usecase.CheckGitHub, false, false, "GitHub API is unreachable"), check(usecase.CheckScheduler, true, false, ""),
+		}, http.StatusOK, "degraded", map[string]string{usecase.CheckDatabase: "ok", usecase.CheckGitHub: "fail", usecase.CheckScheduler: "ok"}},
+		{"pending migrations", true, []usecase.ReadinessCheck{
+			check(usecase.CheckDatabase, true, true, "migrations are pending"), check(usecase.CheckGitHub, true, false, ""), check(usecase.CheckScheduler, true, false, ""),
+		}, http.StatusOK, "degraded", map[string]string{usecase.CheckDatabase: "ok", usecase.CheckGitHub: "ok", usecase.CheckScheduler: "ok"}},
+		{"required scheduler stale", false, []usecase.ReadinessCheck{
+			check(usecase.CheckDatabase, true, true, ""), check(usecase.CheckGitHub, true, false, ""), check(usecase.CheckScheduler, false, true, "no recent success for: sync"),
+		}, http.StatusServiceUnavailable, "unavailable", map[string]string{usecase.CheckDatabase: "ok", usecase.CheckGitHub: "ok", usecase.CheckScheduler: "fail"}},
+		{"required database down", false, []usecase.ReadinessCheck{
+			check(usecase.CheckDatabase, false, true, "database is unreachable"), check(usecase.CheckGitHub, true, false, ""), check(usecase.CheckScheduler, true, false, ""),
+		}, http.StatusServiceUnavailable, "unavailable", map[string]string{usecase.CheckDatabase: "fail", usecase.CheckGitHub: "ok", usecase.CheckScheduler: "ok"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := NewHealthController(&fakeHealthUsecase{readiness: &usecase.ReadinessStatus{
+				Ready:  tt.ready,
+				Health: &usecase.HealthStatus{Version: "test"},
+				GitHub: &usecase.GitHubStatus{},
+				Checks: tt.checks,
+			}})
+			rec := httptest.NewRecorder()
+			ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/readyz", nil), rec)
+			if err := controller.Ready(ctx); err != nil {
+				t.Fatalf("Ready returned an error: %v", err)
+			}
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("status code = %d, want %d", rec.Code, tt.wantCode)
+			}
+			var res HealthResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if res.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", res.Status, tt.wantStatus)
+			}
+			if res.Ready == nil || *res.Ready != tt.ready {
+				t.Errorf("ready = %v, want %v", res.Ready, tt.ready)
+			}
+			for name, want := range tt.wantChecks {
+				if got := res.Checks[name].Status; got != want {
+					t.Errorf("%s status = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/keeee21/commit-town/api/pagination"
+	"github.com/keeee21/commit-town/api/repository"
+	"github.com/keeee21/commit-town/api/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultNeighborWindow = 2
+	maxNeighborWindow     = 10
+)
+
+type LeaderboardController struct {
+	leaderboardUsecase *usecase.LeaderboardUsecase
+	page               pagination.Config
+}
+
+func NewLeaderboardController(leaderboardUsecase *usecase.LeaderboardUsecase, page pagination.Config) *LeaderboardController {
+	return &LeaderboardController{leaderboardUsecase: leaderboardUsecase, page: page}
+}
+
+// GetCommitLeaderboard コミット数ランキングを取得（?since=&until=&limit=&public_only=）
+func (leaderboardController *LeaderboardController) GetCommitLeaderboard(ctx echo.Context) error {
+	since, until, err := parseDateRange(ctx)
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	limit := leaderboardController.page.Limit(ctx)
+
+	publicOnly := false
+	if v := ctx.QueryParam("public_only"); v != "" {
+		publicOnly, err = strconv.ParseBool(v)
+		if err != nil {
+			return httperr.ValidationFailed("public_only must be true or false")
+		}
+	}
+
+	leaderboard, err := leaderboardController.leaderboardUsecase.TopCommitters(ctx.Request().Context(), since, until, limit, publicOnly)
+	if err != nil {
+		return httperr.Internal("Failed to get leaderboard", err)
+	}
+
+	return ctx.JSON(http.StatusOK, leaderboard)
+}
+
+// streakLeaderboardSorts streakランキングの ?sort=（順位は常に長い順のため ?order= は受け付けない）
+var streakLeaderboardSorts = pagination.Sorts{
+	Fields:       []string{dto.StreakSortCurrent, dto.StreakSortLongest},
+	DefaultField: dto.StreakSortCurrent,
+}
+
+// GetStreakLeaderboard streakランキングを取得（?sort=current|longest&limit=&offset=）
+func (leaderboardController *LeaderboardController) GetStreakLeaderboard(ctx echo.Context) error {
+	params, err := leaderboardController.page.ParseListParams(ctx, streakLeaderboardSorts)
+	if err != nil {
+		return err
+	}
+
+	leaderboard, err := leaderboardController.leaderboardUsecase.TopStreaks(ctx.Request().Context(), params.Sort, params.Limit, params.Offset)
+	if err != nil {
+		return httperr.Internal("Failed to get streak leaderboard", err)
+	}
+
+	return ctx.JSON(http.StatusOK, leaderboard)
+}
+
+// GetNeighbors ユーザーの順位と、すぐ上・すぐ下の window 人ずつを取得（?metric=streak|commits&window=&since=&until=）
+// since・until は metric=commits の場合のみ使う
+func (leaderboardController *LeaderboardController) GetNeighbors(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	metric := dto.NeighborMetricStreak
+	if v := ctx.QueryParam("metric"); v != "" {
+		if v != dto.NeighborMetricStreak && v != dto.NeighborMetricCommits {
+			return httperr.ValidationFailed("metric must be streak or commits")
+		}
+		metric = v
+	}
+
+	window := defaultNeighborWindow
+	if v := ctx.QueryParam("window"); v != "" {
+		window, err = strconv.Atoi(v)
+		if err != nil || window < 1 || window > maxNeighborWindow {
+			return httperr.ValidationFailed("window must be an integer between 1 and " + strconv.Itoa(maxNeighborWindow))
+		}
+	}
+
+	since, until, err := parseDateRange(ctx)
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	res, err := leaderboardController.leaderboardUsecase.GetNeighbors(ctx.Request().Context(), userID, metric, since, until, window)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.UserNotFound()
+		}
+		return httperr.Internal("Failed to get leaderboard neighbors", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
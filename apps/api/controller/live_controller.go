@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/keeee21/commit-town/api/repository"
+	"github.com/keeee21/commit-town/api/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+// defaultLiveHeartbeat heartbeat に0以下を指定した場合の間隔
+const defaultLiveHeartbeat = 15 * time.Second
+
+type LiveController struct {
+	liveUsecase *usecase.LiveUsecase
+	heartbeat   time.Duration // 接続を保つためのコメントを送る間隔
+}
+
+func NewLiveController(liveUsecase *usecase.LiveUsecase, heartbeat time.Duration) *LiveController {
+	if heartbeat <= 0 {
+		heartbeat = defaultLiveHeartbeat
+	}
+	return &LiveController{liveUsecase: liveUsecase, heartbeat: heartbeat}
+}
+
+// StreamEvents ユーザーのstreakの変化とリポジトリの同期を Server-Sent Events で送り続ける
+// クライアントが切断する（リクエストのコンテキストが終わる）まで返らない
+func (liveController *LiveController) StreamEvents(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	reqCtx := ctx.Request().Context()
+	stream, err := liveController.liveUsecase.Open(reqCtx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return httperr.UserNotFound()
+		case errors.Is(err, usecase.ErrTooManyStreams):
+			return httperr.TooManyRequests("Too many open event streams for the user")
+		}
+		return httperr.Internal("Failed to open event stream", err)
+	}
+	defer liveController.liveUsecase.Close(stream)
+
+	res := ctx.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no") // リバースプロキシにバッファさせない
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	ticker := time.NewTicker(liveController.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-reqCtx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+		case event := <-stream.Events:
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("Failed to encode live event for user %d: %v", userID, err)
+				continue
+			}
+			if _, err := fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return nil
+			}
+		}
+		res.Flush()
+	}
+}
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/keeee21/commit-town/api/auth"
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/labstack/echo/v4"
+)
+
+// dateLayout クエリパラメータで受け付ける日付形式
+const dateLayout = "2006-01-02"
+
+// parseIDParam パスパラメータを正の整数IDとして取得
+func parseIDParam(ctx echo.Context, name string) (uint64, error) {
+	id, err := strconv.ParseUint(ctx.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("%s must be a positive integer", name)
+	}
+	return id, nil
+}
+
+// requestUserID 操作するユーザーのIDを取得する（APIキーで認証していればそのユーザー、なければ ?user_id=）
+// APIキーと違うユーザーを ?user_id= に指定した場合は403を返す。required でなければ指定が無い場合は0を返す
+func requestUserID(ctx echo.Context, required bool) (uint64, error) {
+	var userID uint64
+	if v := ctx.QueryParam("user_id"); v != "" {
+		var err error
+		userID, err = strconv.ParseUint(v, 10, 64)
+		if err != nil || userID == 0 {
+			return 0, httperr.ValidationFailed("user_id must be a positive integer")
+		}
+	}
+
+	if keyUserID, ok := auth.UserID(ctx); ok {
+		if userID != 0 && userID != keyUserID {
+			return 0, httperr.Forbidden("The API key belongs to another user")
+		}
+		return keyUserID, nil
+	}
+	if userID == 0 && required {
+		return 0, httperr.ValidationFailed("user_id is required")
+	}
+	return userID, nil
+}
+
+// requireKeyUser APIキーで認証したリクエストが、userIDs にキーのユーザー以外を含む場合に403を返す（APIキーが無い場合は何もしない）
+func requireKeyUser(ctx echo.Context, userIDs ...uint64) error {
+	keyUserID, ok := auth.UserID(ctx)
+	if !ok {
+		return nil
+	}
+	for _, userID := range userIDs {
+		if userID != keyUserID {
+			return httperr.Forbidden("The API key belongs to another user")
+		}
+	}
+	return nil
+}
+
+// parseDateRange ?since=&until= (YYYY-MM-DD) を取得
+// since省略時は全期間、until省略時は今日(UTC)とする
+func parseDateRange(ctx echo.Context) (time.Time, time.Time, error) {
+	since := time.Time{}
+	now := time.Now().UTC()
+	until := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	if s := ctx.QueryParam("since"); s != "" {
+		t, err := time.Parse(dateLayout, s)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("since must be in YYYY-MM-DD format")
+		}
+		since = t
+	}
+	if s := ctx.QueryParam("until"); s != "" {
+		t, err := time.Parse(dateLayout, s)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("until must be in YYYY-MM-DD format")
+		}
+		until = t
+	}
+
+	if until.Before(since) {
+		return time.Time{}, time.Time{}, fmt.Errorf("since must be on or before until")
+	}
+	return since, until, nil
+}
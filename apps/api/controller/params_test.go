@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/keeee21/commit-town/api/auth"
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/labstack/echo/v4"
+)
+
+func TestRequestUserID(t *testing.T) {
+	authenticate := func(ctx context.Context, key string) (uint64, error) {
+		if key == "alice-key" {
+			return 1, nil
+		}
+		return 0, auth.ErrInvalidCredentials
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		key      string
+		required bool
+		want     uint64
+		status   int // 0 の場合はエラーにならない
+	}{
+		{"key user", "", "alice-key", true, 1, 0},
+		{"key user with the same user_id", "?user_id=1", "alice-key", true, 1, 0},
+		{"key user with a foreign user_id", "?user_id=2", "alice-key", true, 0, http.StatusForbidden},
+		{"key user with a foreign optional user_id", "?user_id=2", "alice-key", false, 0, http.StatusForbidden},
+		{"user_id without a key", "?user_id=2", "", true, 2, 0},
+		{"missing required user_id", "", "", true, 0, http.StatusBadRequest},
+		{"missing optional user_id", "", "", false, 0, 0},
+		{"invalid user_id", "?user_id=abc", "", false, 0, http.StatusBadRequest},
+		{"zero user_id", "?user_id=0", "alice-key", true, 0, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got uint64
+			var gotErr error
+			handler := auth.Middleware(authenticate)(func(ctx echo.Context) error {
+				got, gotErr = requestUserID(ctx, tt.required)
+				return nil
+			})
+			req := httptest.NewRequest(http.MethodGet, "/api/repositories/1/streak"+tt.query, nil)
+			if tt.key != "" {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.key)
+			}
+			if err := handler(echo.New().NewContext(req, httptest.NewRecorder())); err != nil {
+				t.Fatalf("middleware returned an error: %v", err)
+			}
+
+			if tt.status == 0 {
+				if gotErr != nil || got != tt.want {
+					t.Errorf("requestUserID = (%d, %v), want (%d, nil)", got, gotErr, tt.want)
+				}
+				return
+			}
+			var apiErr *httperr.APIError
+			if !errors.As(gotErr, &apiErr) || apiErr.Status != tt.status {
+				t.Errorf("requestUserID error = %v, want status %d", gotErr, tt.status)
+			}
+		})
+	}
+}
+
+func TestRequireKeyUser(t *testing.T) {
+	authenticate := func(ctx context.Context, key string) (uint64, error) {
+		return 1, nil
+	}
+	tests := []struct {
+		name    string
+		key     string
+		userIDs []uint64
+		status  int // 0 の場合はエラーにならない
+	}{
+		{"own user", "alice-key", []uint64{1}, 0},
+		{"own user twice", "alice-key", []uint64{1, 1}, 0},
+		{"foreign user among them", "alice-key", []uint64{1, 2}, http.StatusForbidden},
+		{"no key", "", []uint64{1, 2}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotErr error
+			handler := auth.Middleware(authenticate)(func(ctx echo.Context) error {
+				gotErr = requireKeyUser(ctx, tt.userIDs...)
+				return nil
+			})
+			req := httptest.NewRequest(http.MethodPost, "/api/users/merge", nil)
+			if tt.key != "" {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.key)
+			}
+			if err := handler(echo.New().NewContext(req, httptest.NewRecorder())); err != nil {
+				t.Fatalf("middleware returned an error: %v", err)
+			}
+
+			if tt.status == 0 {
+				if gotErr != nil {
+					t.Errorf("requireKeyUser = %v, want nil", gotErr)
+				}
+				return
+			}
+			var apiErr *httperr.APIError
+			if !errors.As(gotErr, &apiErr) || apiErr.Status != tt.status {
+				t.Errorf("requireKeyUser = %v, want status %d", gotErr, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,363 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/keeee21/commit-town/api/internal/github"
+	"github.com/keeee21/commit-town/api/limits"
+	"github.com/keeee21/commit-town/api/pagination"
+	"github.com/keeee21/commit-town/api/repository"
+	"github.com/keeee21/commit-town/api/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultRecentCommitsLimit = 5
+	maxRecentCommitsLimit     = 20
+)
+
+type RepositoryController struct {
+	repositoryUsecase *usecase.RepositoryUsecase
+	pipelineUsecase   *usecase.PipelineUsecase
+	limits            limits.Limits
+	staleAfter        time.Duration // これ以上同期されていないリポジトリを stale とする
+	page              pagination.Config
+}
+
+func NewRepositoryController(repositoryUsecase *usecase.RepositoryUsecase, pipelineUsecase *usecase.PipelineUsecase, limits limits.Limits, staleAfter time.Duration, page pagination.Config) *RepositoryController {
+	return &RepositoryController{
+		repositoryUsecase: repositoryUsecase,
+		pipelineUsecase:   pipelineUsecase,
+		limits:            limits,
+		staleAfter:        staleAfter,
+		page:              page,
+	}
+}
+
+// BulkImport ユーザーのリポジトリを一括登録
+func (repositoryController *RepositoryController) BulkImport(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	var req dto.BulkImportRepositoriesRequest
+	if err := ctx.Bind(&req); err != nil {
+		return httperr.InvalidRequest("Invalid request body")
+	}
+	if len(req.Repositories) == 0 {
+		return httperr.ValidationFailed("repositories must not be empty")
+	}
+	if len(req.Repositories) > repositoryController.limits.MaxBulkImportItems {
+		return httperr.ValidationFailed(fmt.Sprintf("repositories must not exceed %d items", repositoryController.limits.MaxBulkImportItems))
+	}
+
+	res, err := repositoryController.repositoryUsecase.BulkImport(ctx.Request().Context(), userID, &req)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.UserNotFound()
+		}
+		return httperr.Internal("Failed to import repositories", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// GetSyncStatus ユーザーの登録リポジトリごとの同期状況を取得（同期が止まったリポジトリの検知用）
+func (repositoryController *RepositoryController) GetSyncStatus(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	res, err := repositoryController.repositoryUsecase.GetSyncStatus(ctx.Request().Context(), userID, repositoryController.staleAfter)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.UserNotFound()
+		}
+		return httperr.Internal("Failed to get repository sync status", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// repositorySearchSorts 登録リポジトリの検索の ?sort=&order=（デフォルトはオーナー名・リポジトリ名の昇順）
+var repositorySearchSorts = pagination.Sorts{
+	Fields:       []string{dto.RepositorySearchSortName, dto.RepositorySearchSortCreatedAt},
+	DefaultField: dto.RepositorySearchSortName,
+	DefaultOrder: pagination.OrderAsc,
+}
+
+// SearchRepositories 登録リポジトリをオーナー名・リポジトリ名の部分一致で検索（?owner=&name=&sort=name|created_at&order=&limit=&offset=）
+// 全件走査にならないよう owner と name のどちらかは必須
+func (repositoryController *RepositoryController) SearchRepositories(ctx echo.Context) error {
+	owner := strings.TrimSpace(ctx.QueryParam("owner"))
+	name := strings.TrimSpace(ctx.QueryParam("name"))
+	if owner == "" && name == "" {
+		return httperr.ValidationFailed("owner or name is required")
+	}
+
+	params, err := repositoryController.page.ParseListParams(ctx, repositorySearchSorts)
+	if err != nil {
+		return err
+	}
+
+	res, err := repositoryController.repositoryUsecase.SearchRepositories(ctx.Request().Context(), owner, name, params)
+	if err != nil {
+		return httperr.Internal("Failed to search repositories", err)
+	}
+
+	pagination.SetHeaders(ctx, res.Limit, res.Offset, res.Total)
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// PatchRepository 登録リポジトリの一部の項目（表示順）を更新
+func (repositoryController *RepositoryController) PatchRepository(ctx echo.Context) error {
+	repoID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	userID, err := requestUserID(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	var req dto.PatchRepositoryRequest
+	if err := ctx.Bind(&req); err != nil {
+		return httperr.InvalidRequest("Invalid request body")
+	}
+	if err := ctx.Validate(&req); err != nil {
+		return err
+	}
+
+	res, err := repositoryController.repositoryUsecase.PatchRepository(ctx.Request().Context(), userID, repoID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return httperr.NotFound("Repository not found")
+		case errors.Is(err, usecase.ErrRepositoryNotOwned):
+			return httperr.Forbidden("Repository does not belong to the user")
+		}
+		return httperr.Internal("Failed to update repository", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// DeactivateRepository 登録リポジトリを無効化（過去の集計は残し、以降の同期対象から外す）
+func (repositoryController *RepositoryController) DeactivateRepository(ctx echo.Context) error {
+	repoID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	userID, err := requestUserID(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	err = repositoryController.repositoryUsecase.DeactivateRepository(ctx.Request().Context(), userID, repoID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return httperr.NotFound("Repository not found")
+		case errors.Is(err, usecase.ErrRepositoryNotOwned):
+			return httperr.Forbidden("Repository does not belong to the user")
+		}
+		return httperr.Internal("Failed to deactivate repository", err)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// BackfillRepository 登録リポジトリの過去のコミットを取り込む（?days=N、デフォルト・上限は MAX_HISTORY_DAYS）
+// 同期的に実行し、時間内に終わらなかった場合は504を返す
+func (repositoryController *RepositoryController) BackfillRepository(ctx echo.Context) error {
+	repoID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	userID, err := requestUserID(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	days := repositoryController.limits.MaxHistoryDays
+	if v := ctx.QueryParam("days"); v != "" {
+		days, err = strconv.Atoi(v)
+		if err != nil || days < 1 {
+			return httperr.ValidationFailed("days must be a positive integer")
+		}
+		if err := repositoryController.limits.CheckDays(days); err != nil {
+			return httperr.ValidationFailed(err.Error())
+		}
+	}
+
+	res, err := repositoryController.pipelineUsecase.BackfillRepository(ctx.Request().Context(), userID, repoID, days)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return httperr.NotFound("Repository not found")
+		case errors.Is(err, usecase.ErrRepositoryNotOwned):
+			return httperr.Forbidden("Repository does not belong to the user")
+		case errors.Is(err, usecase.ErrRepositoryDeactivated):
+			return httperr.Conflict("Repository is deactivated")
+		case errors.Is(err, usecase.ErrRepositoryNoAccess):
+			return httperr.Conflict("Repository is not accessible on GitHub")
+		case errors.Is(err, context.DeadlineExceeded):
+			return httperr.Timeout("Backfill did not finish in time, try a shorter range")
+		}
+		return httperr.Internal("Failed to backfill repository", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// SyncRepository 登録リポジトリ1件をすぐに同期する（?days=N の範囲で前回の同期以降、デフォルト7日・上限は MAX_HISTORY_DAYS）
+// 操作できるのはリポジトリを登録したユーザーのみ（APIキーで認証していればそのユーザー、なければ ?user_id=）
+// GitHubのレート制限に達した場合は429と制限が解除される時刻を返す
+func (repositoryController *RepositoryController) SyncRepository(ctx echo.Context) error {
+	repoID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	userID, err := requestUserID(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	days := defaultSyncDays
+	if v := ctx.QueryParam("days"); v != "" {
+		days, err = strconv.Atoi(v)
+		if err != nil || days < 1 {
+			return httperr.ValidationFailed("days must be a positive integer")
+		}
+		if err := repositoryController.limits.CheckDays(days); err != nil {
+			return httperr.ValidationFailed(err.Error())
+		}
+	}
+
+	res, err := repositoryController.pipelineUsecase.SyncRepository(ctx.Request().Context(), userID, repoID, days)
+	if err != nil {
+		var rateLimitErr *github.RateLimitError
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return httperr.NotFound("Repository not found")
+		case errors.Is(err, usecase.ErrRepositoryNotOwned):
+			return httperr.Forbidden("Repository does not belong to the user")
+		case errors.Is(err, usecase.ErrRepositoryDeactivated):
+			return httperr.Conflict("Repository is deactivated")
+		case errors.Is(err, usecase.ErrRepositoryNoAccess):
+			return httperr.Conflict("Repository is not accessible on GitHub")
+		case errors.As(err, &rateLimitErr):
+			ctx.Response().Header().Set("Retry-After", strconv.Itoa(int(time.Until(rateLimitErr.ResetAt).Seconds())+1))
+			return httperr.New(http.StatusTooManyRequests, httperr.CodeRateLimited, fmt.Sprintf("GitHub rate limit reached, resets at %s", rateLimitErr.ResetAt.UTC().Format(time.RFC3339)))
+		case errors.Is(err, context.DeadlineExceeded):
+			return httperr.Timeout("Sync did not finish in time, try a shorter range")
+		}
+		return httperr.Internal("Failed to sync repository", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// GetRepoStreak 登録リポジトリ単位のstreakを取得
+func (repositoryController *RepositoryController) GetRepoStreak(ctx echo.Context) error {
+	repoID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	res, err := repositoryController.repositoryUsecase.GetRepoStreak(ctx.Request().Context(), repoID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.NotFound("Repository not found")
+		}
+		return httperr.Internal("Failed to get repository streak", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// GetRecentCommits 登録リポジトリの最近のコミットを取得（?limit=N、デフォルト5・上限20）
+// 非公開リポジトリは登録したユーザー（APIキーで認証していればそのユーザー、なければ ?user_id=）の場合のみ返す
+func (repositoryController *RepositoryController) GetRecentCommits(ctx echo.Context) error {
+	repoID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	userID, err := requestUserID(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	limit := defaultRecentCommitsLimit
+	if v := ctx.QueryParam("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxRecentCommitsLimit {
+			return httperr.ValidationFailed("limit must be between 1 and 20")
+		}
+	}
+
+	res, err := repositoryController.repositoryUsecase.GetRecentCommits(ctx.Request().Context(), userID, repoID, limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return httperr.NotFound("Repository not found")
+		case errors.Is(err, usecase.ErrRepositoryNotOwned):
+			return httperr.Forbidden("Repository does not belong to the user")
+		}
+		return httperr.Internal("Failed to get recent commits", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// DeleteRepository 登録リポジトリを物理削除（?cascade=true でコミットログごと削除）
+func (repositoryController *RepositoryController) DeleteRepository(ctx echo.Context) error {
+	repoID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	// 認証導入までは APIキーのユーザーか user_id で他ユーザーのリポジトリ削除を防ぐ
+	userID, err := requestUserID(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	cascade := false
+	if s := ctx.QueryParam("cascade"); s != "" {
+		cascade, err = strconv.ParseBool(s)
+		if err != nil {
+			return httperr.ValidationFailed("cascade must be true or false")
+		}
+	}
+
+	err = repositoryController.repositoryUsecase.DeleteRepository(ctx.Request().Context(), userID, repoID, cascade)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return httperr.NotFound("Repository not found")
+		case errors.Is(err, usecase.ErrRepositoryNotOwned):
+			return httperr.Forbidden("Repository does not belong to the user")
+		case errors.Is(err, usecase.ErrRepositoryHasLogs):
+			return httperr.Conflict("Repository has commit logs; retry with cascade=true to delete them")
+		}
+		return httperr.Internal("Failed to delete repository", err)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/keeee21/commit-town/api/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+type StatsController struct {
+	statsUsecase *usecase.StatsUsecase
+}
+
+func NewStatsController(statsUsecase *usecase.StatsUsecase) *StatsController {
+	return &StatsController{statsUsecase: statsUsecase}
+}
+
+// GetPlatformStats トップページ用のサービス全体の集計を取得
+func (statsController *StatsController) GetPlatformStats(ctx echo.Context) error {
+	res, err := statsController.statsUsecase.GetPlatformStats(ctx.Request().Context())
+	if err != nil {
+		return httperr.Internal("Failed to get platform stats", err)
+	}
+
+	return jsonWithETag(ctx, http.StatusOK, res)
+}
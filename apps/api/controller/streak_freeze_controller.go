@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/keeee21/commit-town/api/limits"
+	"github.com/keeee21/commit-town/api/repository"
+	"github.com/keeee21/commit-town/api/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+type StreakFreezeController struct {
+	freezeUsecase *usecase.StreakFreezeUsecase
+	maxDays       int // 1回の凍結で指定できる最大日数（両端を含む）
+}
+
+func NewStreakFreezeController(freezeUsecase *usecase.StreakFreezeUsecase, maxDays int) *StreakFreezeController {
+	return &StreakFreezeController{freezeUsecase: freezeUsecase, maxDays: maxDays}
+}
+
+// ListFreezes ユーザーのstreakの凍結期間の一覧を取得
+func (freezeController *StreakFreezeController) ListFreezes(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	res, err := freezeController.freezeUsecase.ListFreezes(ctx.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.UserNotFound()
+		}
+		return httperr.Internal("Failed to list streak freezes", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// ScheduleFreeze streakの凍結を予約（明日以降、STREAK_FREEZE_MAX_DAYS 日まで）
+func (freezeController *StreakFreezeController) ScheduleFreeze(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	var req dto.CreateStreakFreezeRequest
+	if err := ctx.Bind(&req); err != nil {
+		return httperr.InvalidRequest("Invalid request body")
+	}
+	if err := ctx.Validate(&req); err != nil {
+		return err
+	}
+	start, err := time.Parse(dateLayout, req.StartDate)
+	if err != nil {
+		return httperr.ValidationFailed("start_date must be in YYYY-MM-DD format")
+	}
+	end, err := time.Parse(dateLayout, req.EndDate)
+	if err != nil {
+		return httperr.ValidationFailed("end_date must be in YYYY-MM-DD format")
+	}
+	if end.Before(start) {
+		return httperr.ValidationFailed("end_date must be on or after start_date")
+	}
+	if limits.RangeDays(start, end) > freezeController.maxDays {
+		return httperr.ValidationFailed(fmt.Sprintf("a freeze must not exceed %d days", freezeController.maxDays))
+	}
+
+	res, err := freezeController.freezeUsecase.ScheduleFreeze(ctx.Request().Context(), userID, start, end, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return httperr.UserNotFound()
+		case errors.Is(err, usecase.ErrStreakFreezeNotInFuture):
+			return httperr.ValidationFailed("start_date must be in the future")
+		case errors.Is(err, usecase.ErrStreakFreezeOverlaps):
+			return httperr.Conflict("The freeze overlaps or directly adjoins an existing one")
+		}
+		return httperr.Internal("Failed to schedule streak freeze", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// CancelFreeze まだ始まっていないstreakの凍結を取り消す
+func (freezeController *StreakFreezeController) CancelFreeze(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+	freezeID, err := parseIDParam(ctx, "freeze_id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	err = freezeController.freezeUsecase.CancelFreeze(ctx.Request().Context(), userID, freezeID, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return httperr.NotFound("Streak freeze not found")
+		case errors.Is(err, usecase.ErrStreakFreezeNotOwned):
+			return httperr.Forbidden("Streak freeze does not belong to the user")
+		case errors.Is(err, usecase.ErrStreakFreezeStarted):
+			return httperr.Conflict("The freeze has already started and cannot be cancelled")
+		}
+		return httperr.Internal("Failed to cancel streak freeze", err)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/keeee21/commit-town/api/validator"
+	"github.com/labstack/echo/v4"
+)
+
+// 日付と日数の検証はユースケースを呼ぶ前に行う（ユースケースは nil のため、届くと panic する）
+func TestStreakFreezeController_ScheduleFreeze_Validation(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = httperr.Handler
+	e.Validator = validator.NewRequestValidator()
+	freezeController := NewStreakFreezeController(nil, 14)
+	e.POST("/api/users/:id/streak/freezes", freezeController.ScheduleFreeze)
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"missing end_date", `{"start_date":"2030-01-01"}`, http.StatusBadRequest},
+		{"invalid date", `{"start_date":"2030/01/01","end_date":"2030-01-02"}`, http.StatusBadRequest},
+		{"end before start", `{"start_date":"2030-01-02","end_date":"2030-01-01"}`, http.StatusBadRequest},
+		{"longer than the maximum", `{"start_date":"2030-01-01","end_date":"2030-01-15"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/users/1/streak/freezes", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/keeee21/commit-town/api/pagination"
+	"github.com/keeee21/commit-town/api/repository"
+	"github.com/keeee21/commit-town/api/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+type SummaryController struct {
+	summaryUsecase *usecase.SummaryUsecase
+	page           pagination.Config
+}
+
+func NewSummaryController(summaryUsecase *usecase.SummaryUsecase, page pagination.Config) *SummaryController {
+	return &SummaryController{summaryUsecase: summaryUsecase, page: page}
+}
+
+// GetSummary ユーザーのダッシュボード用サマリーを取得
+func (summaryController *SummaryController) GetSummary(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	summary, err := summaryController.summaryUsecase.GetSummary(ctx.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.UserNotFound()
+		}
+		return httperr.Internal("Failed to get user summary", err)
+	}
+
+	return jsonWithETag(ctx, http.StatusOK, summary)
+}
+
+// GetSummaries 複数ユーザーのサマリーをまとめて取得（最大100件、存在しないIDは結果から除く）
+// 一部のユーザーの取得に失敗しても200で返し、失敗したユーザーは status: failed にする（原因はリクエストIDと共にログに出す）
+// APIキーで認証したリクエストはキーのユーザー以外のIDを含むと403
+func (summaryController *SummaryController) GetSummaries(ctx echo.Context) error {
+	var req dto.UserSummariesRequest
+	if err := ctx.Bind(&req); err != nil {
+		return httperr.InvalidRequest("Invalid request body")
+	}
+	if err := ctx.Validate(&req); err != nil {
+		return err
+	}
+	if err := requireKeyUser(ctx, req.IDs...); err != nil {
+		return err
+	}
+
+	summaries, failed, err := summaryController.summaryUsecase.GetSummaries(ctx.Request().Context(), req.IDs)
+	if err != nil {
+		return httperr.Internal("Failed to get user summaries", err)
+	}
+	requestID := ctx.Response().Header().Get(echo.HeaderXRequestID)
+	for userID, err := range failed {
+		log.Printf("Failed to get summary of user %d (request %s): %v", userID, requestID, err)
+	}
+
+	return ctx.JSON(http.StatusOK, summaries)
+}
+
+// GetToday 今日のコミット有無とstreak日数だけを返す（メニューバー等の高頻度ポーリング用）
+func (summaryController *SummaryController) GetToday(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	today, err := summaryController.summaryUsecase.GetToday(ctx.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.UserNotFound()
+		}
+		return httperr.Internal("Failed to get today's status", err)
+	}
+
+	return jsonWithETag(ctx, http.StatusOK, today)
+}
+
+// streakHistorySorts streak履歴の ?sort=&order=（デフォルトは開始日の新しい順）
+var streakHistorySorts = pagination.Sorts{
+	Fields:       []string{dto.StreakHistorySortStartDate, dto.StreakHistorySortLength},
+	DefaultField: dto.StreakHistorySortStartDate,
+	DefaultOrder: pagination.OrderDesc,
+}
+
+// GetStreakHistory ユーザーの過去を含む全streakを取得（?sort=start_date|length&order=&limit=&offset=）
+func (summaryController *SummaryController) GetStreakHistory(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	params, err := summaryController.page.ParseListParams(ctx, streakHistorySorts)
+	if err != nil {
+		return err
+	}
+
+	history, err := summaryController.summaryUsecase.GetStreakHistory(ctx.Request().Context(), userID, params)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.UserNotFound()
+		}
+		return httperr.Internal("Failed to get streak history", err)
+	}
+
+	pagination.SetHeaders(ctx, history.Limit, history.Offset, history.Total)
+	return ctx.JSON(http.StatusOK, history)
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/keeee21/commit-town/api/internal/github"
+	"github.com/keeee21/commit-town/api/limits"
+	"github.com/keeee21/commit-town/api/repository"
+	"github.com/keeee21/commit-town/api/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+// defaultSyncDays since省略時に同期する日数（今日を含む）
+const defaultSyncDays = 7
+
+type SyncController struct {
+	pipelineUsecase *usecase.PipelineUsecase
+	limits          limits.Limits
+}
+
+func NewSyncController(pipelineUsecase *usecase.PipelineUsecase, limits limits.Limits) *SyncController {
+	return &SyncController{pipelineUsecase: pipelineUsecase, limits: limits}
+}
+
+// SyncUser ユーザーの全有効リポジトリを同期し、書き込んだ内容の差分を返す
+// ?dry_run=true の場合はGitHubから取得して差分だけを返し、DBには書き込まない
+func (syncController *SyncController) SyncUser(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	since, until, err := parseDateRange(ctx)
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+	if ctx.QueryParam("since") == "" {
+		since = syncController.limits.DefaultSince(until, defaultSyncDays)
+	}
+	if err := syncController.limits.CheckRange(since, until); err != nil {
+		return httperr.ValidationFailed(err.Error())
+	}
+
+	dryRun := false
+	if v := ctx.QueryParam("dry_run"); v != "" {
+		dryRun, err = strconv.ParseBool(v)
+		if err != nil {
+			return httperr.ValidationFailed("dry_run must be true or false")
+		}
+	}
+
+	preview, err := syncController.pipelineUsecase.RunForUser(ctx.Request().Context(), userID, since, until, dryRun)
+	if err != nil {
+		var rateLimitErr *github.RateLimitError
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return httperr.UserNotFound()
+		case errors.As(err, &rateLimitErr):
+			ctx.Response().Header().Set("Retry-After", strconv.Itoa(int(time.Until(rateLimitErr.ResetAt).Seconds())+1))
+			return httperr.RateLimited("GitHub rate limit reached, retry later")
+		}
+		return httperr.Internal("Failed to sync user", err)
+	}
+
+	return ctx.JSON(http.StatusOK, preview)
+}
+
+// RecomputeUser ユーザーの日次集計とstreakを全期間で計算し直す（1ユーザーだけを直すための軽量版）
+func (syncController *SyncController) RecomputeUser(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	res, err := syncController.pipelineUsecase.RecomputeUser(ctx.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.UserNotFound()
+		}
+		return httperr.Internal("Failed to recompute user", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
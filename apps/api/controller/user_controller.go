@@ -1,47 +1,175 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/keeee21/commit-town/api/repository"
 	"github.com/keeee21/commit-town/api/usecase"
 	"github.com/labstack/echo/v4"
 )
 
 type UserController struct {
-	userUsecase *usecase.UserUsecase
+	userUsecase         *usecase.UserUsecase
+	userMergeUsecase    *usecase.UserMergeUsecase
+	userDeletionUsecase *usecase.UserDeletionUsecase
+	webhookUsecase      *usecase.WebhookUsecase
 }
 
-func NewUserController(userUsecase *usecase.UserUsecase) *UserController {
-	return &UserController{userUsecase: userUsecase}
+func NewUserController(userUsecase *usecase.UserUsecase, userMergeUsecase *usecase.UserMergeUsecase, userDeletionUsecase *usecase.UserDeletionUsecase, webhookUsecase *usecase.WebhookUsecase) *UserController {
+	return &UserController{
+		userUsecase:         userUsecase,
+		userMergeUsecase:    userMergeUsecase,
+		userDeletionUsecase: userDeletionUsecase,
+		webhookUsecase:      webhookUsecase,
+	}
 }
 
 // UpsertUser ユーザーを作成または更新
 func (userController *UserController) UpsertUser(ctx echo.Context) error {
 	var req dto.UpsertUserRequest
 	if err := ctx.Bind(&req); err != nil {
-		return ctx.JSON(http.StatusBadRequest, map[string]string{
-			"error": "Invalid request body",
-		})
+		return httperr.InvalidRequest("Invalid request body")
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return err
+	}
+
+	user, err := userController.userUsecase.UpsertUser(ctx.Request().Context(), &req)
+	if err != nil {
+		if errors.Is(err, repository.ErrStaleUpdate) {
+			return httperr.Conflict("User was updated concurrently, please retry")
+		}
+		return httperr.Internal("Failed to upsert user", err)
+	}
+
+	return ctx.JSON(http.StatusOK, user)
+}
+
+// PatchUser ユーザーの一部の項目を更新
+func (userController *UserController) PatchUser(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	var req dto.PatchUserRequest
+	if err := ctx.Bind(&req); err != nil {
+		return httperr.InvalidRequest("Invalid request body")
+	}
+	if err := ctx.Validate(&req); err != nil {
+		return err
+	}
+
+	user, err := userController.userUsecase.PatchUser(ctx.Request().Context(), userID, &req)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.UserNotFound()
+		}
+		return httperr.Internal("Failed to update user", err)
+	}
+
+	return ctx.JSON(http.StatusOK, user)
+}
+
+// DeleteUser ユーザーを削除（?purge=true で関連するデータごと物理削除）
+func (userController *UserController) DeleteUser(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	purge := false
+	if s := ctx.QueryParam("purge"); s != "" {
+		purge, err = strconv.ParseBool(s)
+		if err != nil {
+			return httperr.ValidationFailed("purge must be true or false")
+		}
 	}
 
-	// 簡易バリデーション
-	if req.GitHubUserID == 0 {
-		return ctx.JSON(http.StatusBadRequest, map[string]string{
-			"error": "github_user_id is required",
-		})
+	res, err := userController.userDeletionUsecase.DeleteUser(ctx.Request().Context(), userID, purge)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.UserNotFound()
+		}
+		return httperr.Internal("Failed to delete user", err)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// SetWebhook streakの節目を送るWebhookのURLを設定（署名用のシークレットを発行し直す）
+func (userController *UserController) SetWebhook(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	var req dto.SetWebhookRequest
+	if err := ctx.Bind(&req); err != nil {
+		return httperr.InvalidRequest("Invalid request body")
+	}
+	if err := ctx.Validate(&req); err != nil {
+		return err
+	}
+
+	webhook, err := userController.webhookUsecase.SetWebhook(ctx.Request().Context(), userID, req.URL)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.UserNotFound()
+		}
+		return httperr.Internal("Failed to set webhook", err)
+	}
+
+	return ctx.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook Webhookの設定を削除
+func (userController *UserController) DeleteWebhook(ctx echo.Context) error {
+	userID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return httperr.InvalidRequest(err.Error())
+	}
+
+	if err := userController.webhookUsecase.DeleteWebhook(ctx.Request().Context(), userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return httperr.UserNotFound()
+		}
+		return httperr.Internal("Failed to delete webhook", err)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// MergeUsers 重複して作られたユーザーを統合（管理者向け）
+// APIキーで認証したリクエストは両方のユーザーがキーのユーザーでなければ403（他のユーザーを統合して消せないため）
+func (userController *UserController) MergeUsers(ctx echo.Context) error {
+	var req dto.MergeUsersRequest
+	if err := ctx.Bind(&req); err != nil {
+		return httperr.InvalidRequest("Invalid request body")
+	}
+	if err := ctx.Validate(&req); err != nil {
+		return err
 	}
-	if req.GitHubUsername == "" {
-		return ctx.JSON(http.StatusBadRequest, map[string]string{
-			"error": "github_username is required",
-		})
+	if err := requireKeyUser(ctx, req.KeepID, req.RemoveID); err != nil {
+		return err
 	}
 
-	user, err := userController.userUsecase.UpsertUser(&req)
+	user, err := userController.userMergeUsecase.MergeUsers(ctx.Request().Context(), req.KeepID, req.RemoveID)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to upsert user",
-		})
+		switch {
+		case errors.Is(err, usecase.ErrMergeSameUser):
+			return httperr.ValidationFailed("keep_id and remove_id must be different users")
+		case errors.Is(err, repository.ErrNotFound):
+			return httperr.UserNotFound()
+		case errors.Is(err, repository.ErrStaleUpdate):
+			return httperr.Conflict("User was updated concurrently, please retry")
+		}
+		return httperr.Internal("Failed to merge users", err)
 	}
 
 	return ctx.JSON(http.StatusOK, user)
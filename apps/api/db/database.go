@@ -1,32 +1,88 @@
 package db
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
+	"strconv"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/keeee21/commit-town/api/models"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
 )
 
-// NewDatabase creates a new database connection
-func NewDatabase(dsn string) (*gorm.DB, error) {
+// slowQueryThreshold queries slower than this are logged as warnings at every log level
+const slowQueryThreshold = 200 * time.Millisecond
+
+// NewDatabase creates a new database connection.
+// Connection failures are returned as ErrDSNMissing, ErrDSNInvalid, ErrDBAuth, ErrDBNotFound or ErrDBUnreachable where they can be told apart.
+// statementTimeout is applied to every session as Postgres' statement_timeout so a slow query is
+// cancelled server-side even when the caller's context has no deadline; zero leaves it unset.
+// GORM logs through logger: every SQL statement when debug is enabled, otherwise only slow queries and errors.
+func NewDatabase(dsn string, statementTimeout time.Duration, logger *slog.Logger) (*gorm.DB, error) {
 	if dsn == "" {
-		return nil, fmt.Errorf("DATABASE_URL is not set")
+		return nil, ErrDSNMissing
+	}
+
+	dialector, err := newDialector(dsn, statementTimeout)
+	if err != nil {
+		return nil, err
 	}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: newGormLogger(logger)})
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+		return nil, fmt.Errorf("failed to connect to database: %w", classifyConnectError(err))
 	}
 
-	log.Println("Database connection established")
+	logger.Info("Database connection established")
 	return db, nil
 }
 
-// AutoMigrate runs database migrations
-func AutoMigrate(db *gorm.DB) error {
-	log.Println("Running database migrations...")
+// newGormLogger adapts logger for GORM, reporting SQL only when logger has debug enabled
+func newGormLogger(logger *slog.Logger) gormlogger.Interface {
+	level := gormlogger.Warn
+	if logger.Enabled(context.Background(), slog.LevelDebug) {
+		level = gormlogger.Info
+	}
+	return gormlogger.NewSlogLogger(logger, gormlogger.Config{
+		LogLevel:                  level,
+		SlowThreshold:             slowQueryThreshold,
+		IgnoreRecordNotFoundError: true,
+	})
+}
+
+// newDialector parses dsn and applies the session settings shared by the primary and the replica
+func newDialector(dsn string, statementTimeout time.Duration) (gorm.Dialector, error) {
+	config, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDSNInvalid, err)
+	}
+	if statementTimeout > 0 {
+		config.RuntimeParams["statement_timeout"] = strconv.FormatInt(statementTimeout.Milliseconds(), 10)
+	}
+	return postgres.New(postgres.Config{Conn: stdlib.OpenDB(*config)}), nil
+}
+
+// WithTransaction runs fn inside a transaction, committing when it returns nil and rolling back otherwise
+func WithTransaction(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	return db.Transaction(fn)
+}
+
+// WithReadOnlySnapshot runs fn inside a read-only REPEATABLE READ transaction,
+// so every query in fn sees the same snapshot even while other transactions commit
+func WithReadOnlySnapshot(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	return db.Transaction(fn, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+}
+
+// AutoMigrate creates the schema directly from the models.
+// It is kept for tests only; the application uses the versioned SQL files in the migrations package.
+func AutoMigrate(db *gorm.DB, logger *slog.Logger) error {
+	logger.Info("Running database migrations...")
 
 	// Migrate all models in order
 	err := db.AutoMigrate(
@@ -34,7 +90,14 @@ func AutoMigrate(db *gorm.DB) error {
 		&models.UserRepository{},
 		&models.RepoDailyCommitLog{},
 		&models.UserDailyCommitLog{},
+		&models.UserDailyCommitLogDeletion{},
 		&models.UserStreak{},
+		&models.UserAchievement{},
+		&models.RepoStreak{},
+		&models.SyncJobRun{},
+		&models.UserGoal{},
+		&models.StreakFreeze{},
+		&models.APIKey{},
 	)
 
 	if err != nil {
@@ -46,7 +109,7 @@ func AutoMigrate(db *gorm.DB) error {
 		return fmt.Errorf("failed to add unique constraints: %w", err)
 	}
 
-	log.Println("Database migrations completed successfully")
+	logger.Info("Database migrations completed successfully")
 	return nil
 }
 
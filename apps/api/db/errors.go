@@ -0,0 +1,51 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Errors returned by NewDatabase. Each wraps the underlying driver error, so callers match them with errors.Is
+// and can still reach the driver error (e.g. *pgconn.PgError) with errors.As.
+var (
+	// ErrDSNMissing DATABASE_URL is empty
+	ErrDSNMissing = errors.New("DATABASE_URL is not set")
+	// ErrDSNInvalid DATABASE_URL could not be parsed
+	ErrDSNInvalid = errors.New("invalid DATABASE_URL")
+	// ErrDBAuth the server rejected the user name or password
+	ErrDBAuth = errors.New("database authentication failed")
+	// ErrDBNotFound the server is up but the database named in DATABASE_URL does not exist
+	ErrDBNotFound = errors.New("database does not exist")
+	// ErrDBUnreachable the server could not be reached (wrong host or port, server down, DNS failure, timeout)
+	ErrDBUnreachable = errors.New("database server is unreachable")
+)
+
+// SQLSTATE codes reported while connecting
+const (
+	invalidAuthorizationSpecification = "28000"
+	invalidPassword                   = "28P01"
+	invalidCatalogName                = "3D000"
+)
+
+// classifyConnectError wraps a connection failure in the matching typed error, or returns it unchanged
+func classifyConnectError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case invalidAuthorizationSpecification, invalidPassword:
+			return fmt.Errorf("%w: %w", ErrDBAuth, err)
+		case invalidCatalogName:
+			return fmt.Errorf("%w: %w", ErrDBNotFound, err)
+		}
+		return err
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || pgconn.Timeout(err) {
+		return fmt.Errorf("%w: %w", ErrDBUnreachable, err)
+	}
+	return err
+}
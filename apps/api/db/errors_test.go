@@ -0,0 +1,52 @@
+package db
+
+import (
+	"errors"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestClassifyConnectError(t *testing.T) {
+	other := errors.New("something else")
+	tests := []struct {
+		name string
+		err  error
+		want error // nil の場合は err をそのまま返す
+	}{
+		{"wrong password", &pgconn.PgError{Code: invalidPassword}, ErrDBAuth},
+		{"unknown role", &pgconn.PgError{Code: invalidAuthorizationSpecification}, ErrDBAuth},
+		{"missing database", &pgconn.PgError{Code: invalidCatalogName}, ErrDBNotFound},
+		{"connection refused", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, ErrDBUnreachable},
+		{"dns failure", &net.DNSError{Err: "no such host", Name: "db.invalid", IsNotFound: true}, ErrDBUnreachable},
+		{"other server error", &pgconn.PgError{Code: "53300"}, nil},
+		{"other error", other, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifyConnectError(tt.err)
+			if tt.want == nil {
+				if got != tt.err {
+					t.Errorf("classifyConnectError = %v, want %v unchanged", got, tt.err)
+				}
+				return
+			}
+			if !errors.Is(got, tt.want) {
+				t.Errorf("classifyConnectError = %v, want %v", got, tt.want)
+			}
+			if got.Error() == tt.want.Error() {
+				t.Errorf("classifyConnectError = %q, want the driver message kept", got)
+			}
+			// 元のドライバーのエラーも errors.Is・errors.As で取り出せる
+			if !errors.Is(got, tt.err) {
+				t.Errorf("classifyConnectError = %v, want it to wrap %v", got, tt.err)
+			}
+			var pgErr *pgconn.PgError
+			if _, isPg := tt.err.(*pgconn.PgError); isPg && !errors.As(got, &pgErr) {
+				t.Errorf("classifyConnectError = %v, want errors.As to find the *pgconn.PgError", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// GORM のSQLは debug では全て、info 以上では遅いものとエラーだけを出力する
+func TestNewGormLogger(t *testing.T) {
+	tests := []struct {
+		name    string
+		level   slog.Level
+		elapsed time.Duration
+		err     error
+		wantSQL bool
+	}{
+		{"debug shows fast queries", slog.LevelDebug, 0, nil, true},
+		{"info hides fast queries", slog.LevelInfo, 0, nil, false},
+		{"info shows slow queries", slog.LevelInfo, 2 * slowQueryThreshold, nil, true},
+		{"info shows failed queries", slog.LevelInfo, 0, errors.New("relation does not exist"), true},
+		{"error hides slow queries", slog.LevelError, 2 * slowQueryThreshold, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: tt.level}))
+
+			newGormLogger(logger).Trace(context.Background(), time.Now().Add(-tt.elapsed), func() (string, int64) {
+				return "SELECT 1", 1
+			}, tt.err)
+			if got := strings.Contains(buf.String(), "SELECT 1"); got != tt.wantSQL {
+				t.Errorf("logged SQL = %v, want %v (output %q)", got, tt.wantSQL, buf.String())
+			}
+		})
+	}
+}
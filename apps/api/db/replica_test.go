@@ -0,0 +1,174 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// recordingDriver 実際には接続せず、受け取ったSQLを接続先の名前ごとに記録する database/sql のドライバー
+type recordingDriver struct {
+	mu      sync.Mutex
+	queries map[string][]string
+}
+
+func (d *recordingDriver) Open(name string) (driver.Conn, error) {
+	return &recordingConn{driver: d, name: name}, nil
+}
+
+func (d *recordingDriver) record(name, query string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries[name] = append(d.queries[name], query)
+}
+
+// take name が受け取ったSQLを返して記録を空にする
+func (d *recordingDriver) take(name string) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	queries := d.queries[name]
+	delete(d.queries, name)
+	return queries
+}
+
+type recordingConn struct {
+	driver *recordingDriver
+	name   string
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{conn: c, query: query}, nil
+}
+
+func (c *recordingConn) Close() error { return nil }
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *recordingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.driver.record(c.name, "BEGIN")
+	return c, nil
+}
+
+func (c *recordingConn) Commit() error   { return nil }
+func (c *recordingConn) Rollback() error { return nil }
+
+type recordingStmt struct {
+	conn  *recordingConn
+	query string
+}
+
+func (s *recordingStmt) Close() error  { return nil }
+func (s *recordingStmt) NumInput() int { return -1 }
+
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.conn.driver.record(s.conn.name, s.query)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.conn.driver.record(s.conn.name, s.query)
+	return &oneRow{}, nil
+}
+
+// oneRow 1列1行（値は1）の結果
+type oneRow struct{ done bool }
+
+func (r *oneRow) Columns() []string { return []string{"count"} }
+func (r *oneRow) Close() error      { return nil }
+
+func (r *oneRow) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+// openRecording name に接続したことにする gorm のダイアレクター
+func openRecording(t *testing.T, d *recordingDriver, name string) gorm.Dialector {
+	t.Helper()
+	conn := sql.OpenDB(recordingConnector{driver: d, name: name})
+	t.Cleanup(func() { conn.Close() })
+	return postgres.New(postgres.Config{Conn: conn})
+}
+
+type recordingConnector struct {
+	driver *recordingDriver
+	name   string
+}
+
+func (c recordingConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.name)
+}
+func (c recordingConnector) Driver() driver.Driver { return c.driver }
+
+// レプリカを登録した読み取り用のハンドルは読み取りをレプリカに、書き込みをプライマリに送る
+func TestNewReader_RoutesReadsToReplica(t *testing.T) {
+	d := &recordingDriver{queries: make(map[string][]string)}
+	primary, err := gorm.Open(openRecording(t, d, "primary"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open primary: %v", err)
+	}
+	reader, err := newReader(primary, openRecording(t, d, "replica"), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("newReader returned an error: %v", err)
+	}
+	if got := d.take("replica"); len(got) != 1 {
+		t.Errorf("replica check sent %v, want one query", got)
+	}
+	d.take("primary")
+
+	var count int64
+	tests := []struct {
+		name string
+		run  func() error
+		want string
+	}{
+		{"reader query", func() error { return reader.Table("users").Count(&count).Error }, "replica"},
+		{"reader raw query", func() error { return reader.Raw("SELECT count(*) FROM users").Scan(&count).Error }, "replica"},
+		{"reader snapshot", func() error {
+			return WithReadOnlySnapshot(reader, func(tx *gorm.DB) error { return tx.Table("users").Count(&count).Error })
+		}, "replica"},
+		{"reader write", func() error { return reader.Exec("UPDATE users SET timezone = 'UTC'").Error }, "primary"},
+		{"primary query", func() error { return primary.Table("users").Count(&count).Error }, "primary"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); err != nil {
+				t.Fatalf("query returned an error: %v", err)
+			}
+			other := "primary"
+			if tt.want == "primary" {
+				other = "replica"
+			}
+			if got := d.take(tt.want); len(got) == 0 {
+				t.Errorf("%s received nothing", tt.want)
+			}
+			if got := d.take(other); len(got) != 0 {
+				t.Errorf("%s received %v, want nothing", other, got)
+			}
+		})
+	}
+}
+
+func TestNewReader_WithoutReplicaReturnsPrimary(t *testing.T) {
+	d := &recordingDriver{queries: make(map[string][]string)}
+	primary, err := gorm.Open(openRecording(t, d, "primary"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open primary: %v", err)
+	}
+	reader, err := NewReader(primary, "", 0, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil || reader != primary {
+		t.Errorf("NewReader = %p, %v, want the primary handle %p", reader, err, primary)
+	}
+}
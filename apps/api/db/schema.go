@@ -0,0 +1,16 @@
+package db
+
+import (
+	"github.com/keeee21/commit-town/api/migrations"
+	"gorm.io/gorm"
+)
+
+// IsSchemaCurrent reports whether every embedded migration has been applied,
+// so a deploy that shipped new code without running -migrate shows up in /health.
+func IsSchemaCurrent(db *gorm.DB) (bool, error) {
+	pending, err := migrations.Pending(db)
+	if err != nil {
+		return false, err
+	}
+	return len(pending) == 0, nil
+}
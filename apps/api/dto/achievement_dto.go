@@ -0,0 +1,8 @@
+package dto
+
+// AchievementResponse 獲得済みバッジのレスポンス
+type AchievementResponse struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	EarnedAt string `json:"earned_at"`
+}
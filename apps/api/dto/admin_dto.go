@@ -0,0 +1,109 @@
+package dto
+
+import "time"
+
+// RecomputeFailure 再計算に失敗したユーザー
+type RecomputeFailure struct {
+	UserID uint64 `json:"user_id"`
+	Error  string `json:"error"`
+}
+
+// RecomputeResponse 全ユーザー再計算の結果
+type RecomputeResponse struct {
+	Processed int                `json:"processed"` // 成功したユーザー数
+	Failed    int                `json:"failed"`
+	Failures  []RecomputeFailure `json:"failures"`
+}
+
+// ReconcileResponse 1ユーザーの日次ログの突き合わせの結果
+type ReconcileResponse struct {
+	UserID      uint64 `json:"user_id"`
+	CheckedDays int    `json:"checked_days"`
+	FixedDays   int    `json:"fixed_days"` // 0 の場合は食い違いが無かった
+}
+
+// DeactivateStaleResponse 同期されていない登録リポジトリの一括無効化の結果
+type DeactivateStaleResponse struct {
+	Days        int       `json:"days"`
+	Cutoff      time.Time `json:"cutoff"`      // この日以降のコミットが無いリポジトリを無効化した
+	Deactivated int64     `json:"deactivated"` // 無効化した件数（無効化済みのものは含まない）
+}
+
+// RawDataCleanupResponse 日次ログの RawData の一括削除の結果
+type RawDataCleanupResponse struct {
+	OlderThanDays int       `json:"older_than_days"`
+	Cutoff        time.Time `json:"cutoff"`  // この日より前の日次ログの RawData を削除した
+	Cleared       int64     `json:"cleared"` // RawData を削除した日次ログの件数（削除済みのものは含まない）
+}
+
+// SyncJobRunResponse 同期ジョブの実行記録
+type SyncJobRunResponse struct {
+	ID             uint64     `json:"id"`
+	UserID         *uint64    `json:"user_id"`       // 全ユーザー対象の定期同期では null
+	RepositoryID   *uint64    `json:"repository_id"` // 1リポジトリだけを対象にした場合のみ
+	Status         string     `json:"status"`
+	Error          string     `json:"error,omitempty"`
+	ReposProcessed int        `json:"repos_processed"`
+	StartedAt      time.Time  `json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at"`
+}
+
+// SyncJobRunsResponse 同期ジョブの実行記録一覧
+type SyncJobRunsResponse struct {
+	Runs []SyncJobRunResponse `json:"runs"`
+}
+
+// StreakSummaryResponse 再計算後のユーザーのstreak
+type StreakSummaryResponse struct {
+	UserID        uint64      `json:"user_id"`
+	CurrentStreak int         `json:"current_streak"`
+	LongestStreak int         `json:"longest_streak"`
+	Level         StreakLevel `json:"level"` // 継続中のstreakの長さに応じた称号
+}
+
+// StreakLevel streakの称号
+type StreakLevel struct {
+	Code string `json:"code"` // seedling / sapling / tree / forest
+	Name string `json:"name"`
+}
+
+// MaintenanceRequest メンテナンスモードの切り替え
+type MaintenanceRequest struct {
+	Enabled *bool `json:"enabled" validate:"required"`
+}
+
+// MaintenanceResponse メンテナンスモードの状態
+type MaintenanceResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+// AtRiskUser 今日のうちにコミットしないとstreakが途切れるユーザー
+type AtRiskUser struct {
+	UserID         uint64    `json:"user_id"`
+	GitHubUsername string    `json:"github_username"`
+	Timezone       string    `json:"timezone"`
+	CurrentStreak  int       `json:"current_streak"`
+	LocalDate      string    `json:"local_date"`   // ユーザーのタイムゾーンでの今日（YYYY-MM-DD）
+	DayEndsAt      time.Time `json:"day_ends_at"`  // ユーザーのタイムゾーンでの今日の終わり
+	MinutesLeft    int       `json:"minutes_left"` // 今日の終わりまでの残り時間
+}
+
+// AtRiskUsersResponse streakが途切れそうなユーザー一覧（残り時間の短い順）
+type AtRiskUsersResponse struct {
+	Hours int          `json:"hours"`
+	Users []AtRiskUser `json:"users"`
+}
+
+// CacheStats キャッシュのヒット数・ミス数（stale な値を返した場合もヒットに数える）
+type CacheStats struct {
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+	Entries int    `json:"entries"`
+}
+
+// LeaderboardCacheStatsResponse ランキングのキャッシュの状況
+type LeaderboardCacheStatsResponse struct {
+	TTLSeconds int        `json:"ttl_seconds"`
+	Commits    CacheStats `json:"commits"`
+	Streaks    CacheStats `json:"streaks"`
+}
@@ -0,0 +1,28 @@
+package dto
+
+import "time"
+
+// CreateAPIKeyRequest APIキーを作成するリクエスト
+type CreateAPIKeyRequest struct {
+	Label string `json:"label" validate:"required,max=100"`
+}
+
+// APIKeyResponse APIキー（平文のキーは含まない）
+type APIKeyResponse struct {
+	ID         uint64     `json:"id"`
+	Label      string     `json:"label"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	Revoked    bool       `json:"revoked"`
+}
+
+// CreateAPIKeyResponse 作成したAPIキー。Key は平文のキーで、このレスポンスでしか返さない
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+// APIKeysResponse ユーザーのAPIキーの一覧（作成の古い順）
+type APIKeysResponse struct {
+	APIKeys []APIKeyResponse `json:"api_keys"`
+}
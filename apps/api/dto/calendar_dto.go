@@ -0,0 +1,47 @@
+package dto
+
+import "time"
+
+// CalendarDay カレンダー（ヒートマップ）の1日分
+type CalendarDay struct {
+	Date         string `json:"date"` // YYYY-MM-DD
+	TotalCommits int    `json:"total_commits"`
+}
+
+// CalendarResponse 期間内のコミットがあった日の一覧（コミットが無い日は含めない）
+type CalendarResponse struct {
+	Since        string        `json:"since"`
+	Until        string        `json:"until"`
+	Days         []CalendarDay `json:"days"`
+	LastModified time.Time     `json:"-"` // 期間内の日次ログの最後の更新・削除の日時（Last-Modified ヘッダー用、どちらも無ければゼロ値）
+}
+
+// CalendarDayRepository 1日分のコミット数の登録リポジトリごとの内訳
+type CalendarDayRepository struct {
+	RepositoryID uint64 `json:"repository_id"`
+	Owner        string `json:"owner"`
+	Name         string `json:"name"`
+	CommitCount  int    `json:"commit_count"`
+}
+
+// CalendarDayDetailResponse 1日分の合計コミット数と内訳（コミットが無い日は合計0・空の内訳）
+type CalendarDayDetailResponse struct {
+	Date         string                  `json:"date"`     // YYYY-MM-DD
+	Timezone     string                  `json:"timezone"` // 日付を数えたタイムゾーン（日次集計がUTCのため常に UTC）
+	TotalCommits int                     `json:"total_commits"`
+	Repositories []CalendarDayRepository `json:"repositories"` // コミットが多い順
+}
+
+// WeekdayActivity 曜日ごとの合計コミット数
+type WeekdayActivity struct {
+	Weekday      int `json:"weekday"` // 0=日曜〜6=土曜
+	TotalCommits int `json:"total_commits"`
+}
+
+// WeekdayActivityResponse 期間内の曜日ごとの合計コミット数（コミットが無い曜日も0で含め、常に7件）
+type WeekdayActivityResponse struct {
+	Since    string            `json:"since"`
+	Until    string            `json:"until"`
+	Timezone string            `json:"timezone"` // 曜日を数えたタイムゾーン（日次集計がUTCのため常に UTC）
+	Weekdays []WeekdayActivity `json:"weekdays"`
+}
@@ -0,0 +1,41 @@
+package dto
+
+import "time"
+
+// UserExportSchemaVersion JSONエクスポートの形式のバージョン（項目の意味を変える・削除する場合に上げる）
+const UserExportSchemaVersion = 1
+
+// UserExport ユーザーのデータのJSONエクスポート（daily_logs は件数が多いため、レスポンスでは最後にストリーミングで書き出す）
+type UserExport struct {
+	SchemaVersion int                  `json:"schema_version"`
+	ExportedAt    time.Time            `json:"exported_at"`
+	Since         *string              `json:"since"` // daily_logs の開始日（YYYY-MM-DD、全期間の場合は null）
+	User          UserResponse         `json:"user"`
+	Repositories  []RepositoryResponse `json:"repositories"` // 無効化したものを含む
+	Streaks       []StreakHistoryEntry `json:"streaks"`      // 開始日の新しい順
+}
+
+// UserExportDailyLog JSONエクスポートの日次コミットログ1日分
+type UserExportDailyLog struct {
+	Date         string `json:"date"` // YYYY-MM-DD
+	TotalCommits int    `json:"total_commits"`
+}
+
+// UserChangesResponse 差分同期用の、since より後に更新・削除された日次ログとstreak
+// 次回は Now をそのまま since に渡す（クライアントの時計を使うとずれで更新を取りこぼすため）
+type UserChangesResponse struct {
+	UserID          uint64               `json:"user_id"`
+	Since           *time.Time           `json:"since"` // 指定が無い場合（初回）は null
+	Now             time.Time            `json:"now"`   // サーバーの現在時刻（次回の since）
+	DailyLogs       []UserChangeDailyLog `json:"daily_logs"`
+	DeletedDates    []string             `json:"deleted_dates"`    // 再集計で削除された日（YYYY-MM-DD）。キャッシュからも消す
+	StreaksReplaced bool                 `json:"streaks_replaced"` // true の場合は Streaks（空の場合も）でキャッシュを置き換える
+	Streaks         []StreakHistoryEntry `json:"streaks"`          // StreaksReplaced が true なら全件
+}
+
+// UserChangeDailyLog 更新された日次コミットログ1日分
+type UserChangeDailyLog struct {
+	Date         string    `json:"date"` // YYYY-MM-DD
+	TotalCommits int       `json:"total_commits"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,21 @@
+package dto
+
+// GitHubRepo 登録前のプレビュー用のGitHubリポジトリ
+type GitHubRepo struct {
+	Owner         string `json:"owner"`
+	Name          string `json:"name"`
+	IsPublic      bool   `json:"is_public"`
+	DefaultBranch string `json:"default_branch"`
+	Registered    bool   `json:"registered"` // user_id のユーザーが登録済みか（user_id 省略時は常に false）
+}
+
+// GitHubReposResponse GitHubユーザーがオーナーのリポジトリ一覧
+type GitHubReposResponse struct {
+	Username     string       `json:"username"`
+	Repositories []GitHubRepo `json:"repositories"`
+}
+
+// GitHubWebhookResponse push を受けて始めた同期
+type GitHubWebhookResponse struct {
+	Queued int `json:"queued"` // バックグラウンドで同期を始めた登録リポジトリの数（登録したユーザーごとに数える）
+}
@@ -0,0 +1,18 @@
+package dto
+
+// SetGoalRequest 今の期間の目標を設定するリクエスト
+type SetGoalRequest struct {
+	Period string `json:"period" validate:"required,oneof=month week"`
+	Target int    `json:"target" validate:"required,min=1"`
+}
+
+// GoalResponse 今の期間の目標と進捗
+type GoalResponse struct {
+	Period      string `json:"period"`
+	PeriodStart string `json:"period_start"` // 期間の初日（YYYY-MM-DD、ユーザーのタイムゾーン）
+	PeriodEnd   string `json:"period_end"`   // 期間の最終日（YYYY-MM-DD）
+	Target      int    `json:"target"`
+	Current     int    `json:"current"`
+	Remaining   int    `json:"remaining"` // 目標まで残りのコミット数（達成済みなら0）
+	OnTrack     bool   `json:"on_track"`  // 達成率が期間の経過率以上か
+}
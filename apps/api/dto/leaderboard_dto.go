@@ -0,0 +1,66 @@
+package dto
+
+// LeaderboardEntryResponse ランキングの1行
+type LeaderboardEntryResponse struct {
+	Rank           int    `json:"rank"` // 同数の場合は同順位
+	UserID         uint64 `json:"user_id"`
+	GitHubUsername string `json:"github_username"`
+	TotalCommits   int    `json:"total_commits"`
+}
+
+// CommitLeaderboardResponse コミット数ランキング
+type CommitLeaderboardResponse struct {
+	Since      string                     `json:"since,omitempty"` // 全期間の場合は省略
+	Until      string                     `json:"until"`
+	PublicOnly bool                       `json:"public_only"`
+	Entries    []LeaderboardEntryResponse `json:"entries"`
+}
+
+// streakランキングの並び順
+const (
+	StreakSortCurrent = "current" // 継続中のstreak
+	StreakSortLongest = "longest" // 過去を含めた最長streak
+)
+
+// StreakLeaderboardEntryResponse streakランキングの1行
+type StreakLeaderboardEntryResponse struct {
+	Rank           int    `json:"rank"` // 同じ長さは同順位。offset を含めた通しの順位
+	UserID         uint64 `json:"user_id"`
+	GitHubUsername string `json:"github_username"`
+	Length         int    `json:"length"`
+}
+
+// 前後の順位で比べる値（?metric=）
+const (
+	NeighborMetricStreak  = "streak"  // 継続中のstreak
+	NeighborMetricCommits = "commits" // 期間内の合計コミット数
+)
+
+// NeighborEntryResponse 前後の順位の1行
+type NeighborEntryResponse struct {
+	Rank           int    `json:"rank"` // 同じ値は同順位
+	UserID         uint64 `json:"user_id"`
+	GitHubUsername string `json:"github_username"`
+	Value          int    `json:"value"`
+	Self           bool   `json:"self"` // 指定したユーザー本人
+}
+
+// NeighborsResponse ユーザーの順位と、すぐ上・すぐ下のユーザー
+type NeighborsResponse struct {
+	UserID     uint64                  `json:"user_id"`
+	Metric     string                  `json:"metric"`
+	Since      string                  `json:"since,omitempty"` // commits で期間を指定した場合のみ
+	Until      string                  `json:"until,omitempty"` // commits の場合のみ
+	Rank       int                     `json:"rank"`
+	Value      int                     `json:"value"`
+	TotalUsers int                     `json:"total_users"` // 順位付けしたユーザー数
+	Entries    []NeighborEntryResponse `json:"entries"`     // 順位順。本人を含む
+}
+
+// StreakLeaderboardResponse streakランキング
+type StreakLeaderboardResponse struct {
+	Sort    string                           `json:"sort"`
+	Limit   int                              `json:"limit"`
+	Offset  int                              `json:"offset"`
+	Entries []StreakLeaderboardEntryResponse `json:"entries"`
+}
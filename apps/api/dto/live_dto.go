@@ -0,0 +1,22 @@
+package dto
+
+// LiveEvent ライブ更新（SSE）で送る1件分のメッセージ
+type LiveEvent struct {
+	Type       string          `json:"type"`                 // streak.started / streak.extended / streak.broken / repository.synced
+	Streak     *LiveStreak     `json:"streak,omitempty"`     // streak.* の場合のみ
+	Repository *LiveRepository `json:"repository,omitempty"` // repository.synced の場合のみ
+}
+
+// LiveStreak 変化したstreak
+type LiveStreak struct {
+	StartDate string `json:"start_date"` // YYYY-MM-DD
+	Length    int    `json:"length"`     // streak.broken の場合は途切れる前の日数
+}
+
+// LiveRepository コミットを保存した登録リポジトリ（今日のコミット数が変わった可能性があるため、受け取ったら today を取り直す）
+type LiveRepository struct {
+	ID    uint64 `json:"id"`
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
+	Days  int    `json:"days"` // 保存した日数
+}
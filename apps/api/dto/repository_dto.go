@@ -0,0 +1,148 @@
+package dto
+
+import "time"
+
+// 一括登録の各項目の結果
+const (
+	BulkImportStatusCreated = "created"
+	BulkImportStatusSkipped = "skipped"
+	BulkImportStatusFailed  = "failed"
+)
+
+// RepositoryInput 登録するリポジトリ
+type RepositoryInput struct {
+	Owner        string  `json:"owner"`
+	Name         string  `json:"name"`
+	IsPublic     *bool   `json:"is_public"`     // 省略時は true
+	Branch       *string `json:"branch"`        // 省略時はデフォルトブランチ
+	CountMode    string  `json:"count_mode"`    // all / authored_only / no_merges（省略時は、オーナーが本人なら all、Organization など本人以外なら authored_only）
+	DisplayOrder *int    `json:"display_order"` // 表示順（0以上、省略時は0）
+}
+
+// PatchRepositoryRequest 登録リポジトリの部分更新リクエスト（nil の項目は変更しない）
+type PatchRepositoryRequest struct {
+	DisplayOrder *int `json:"display_order" validate:"omitnil,min=0"`
+}
+
+// RepositoryResponse 登録リポジトリ
+type RepositoryResponse struct {
+	ID           uint64    `json:"id"`
+	UserID       uint64    `json:"user_id"`
+	Owner        string    `json:"owner"`
+	Name         string    `json:"name"`
+	IsPublic     bool      `json:"is_public"`
+	Branch       *string   `json:"branch"` // null はデフォルトブランチ
+	CountMode    string    `json:"count_mode"`
+	DisplayOrder int       `json:"display_order"`
+	Active       bool      `json:"active"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// BulkImportRepositoriesRequest リポジトリ一括登録リクエスト
+type BulkImportRepositoriesRequest struct {
+	Repositories []RepositoryInput `json:"repositories"`
+}
+
+// BulkImportResult 一括登録の項目ごとの結果
+type BulkImportResult struct {
+	Owner  string `json:"owner"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	ID     uint64 `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkImportRepositoriesResponse リポジトリ一括登録レスポンス
+type BulkImportRepositoriesResponse struct {
+	Results []BulkImportResult `json:"results"`
+	Created int                `json:"created"`
+	Skipped int                `json:"skipped"`
+	Failed  int                `json:"failed"`
+}
+
+// BackfillRepositoryResponse 過去のコミット取り込みの結果
+type BackfillRepositoryResponse struct {
+	RepositoryID uint64 `json:"repository_id"`
+	Since        string `json:"since"`       // 取り込んだ期間の開始日（YYYY-MM-DD）
+	Until        string `json:"until"`       // 取り込んだ期間の終了日（YYYY-MM-DD）
+	DaysSynced   int    `json:"days_synced"` // コミットがあり保存した日数（未来の日付として飛ばした日は含まない）
+}
+
+// SyncRepositoryResponse 登録リポジトリ1件の同期の結果
+type SyncRepositoryResponse struct {
+	RepositoryID uint64       `json:"repository_id"`
+	Since        string       `json:"since"`        // GitHubから取得した期間の開始日（YYYY-MM-DD、同期済みの日より前は取り直さない）
+	Until        string       `json:"until"`        // GitHubから取得した期間の終了日（YYYY-MM-DD）
+	DaysWritten  int          `json:"days_written"` // 保存した（コミットがあった）日数
+	DaysUpdated  int          `json:"days_updated"` // コミット数が変わった日数（新しくコミットが見つかった日を含む）
+	Streak       StreakChange `json:"streak"`
+}
+
+// RepoSyncStatus 登録リポジトリごとの同期状況
+type RepoSyncStatus struct {
+	RepositoryID     uint64     `json:"repository_id"`
+	Owner            string     `json:"owner"`
+	Name             string     `json:"name"`
+	Active           bool       `json:"active"`
+	AccessStatus     string     `json:"access_status"`      // GitHubから読み取れるか（ok / no_access）。no_access の間は同期しない
+	LatestCommitDate *string    `json:"latest_commit_date"` // 最新の日次ログの日付（YYYY-MM-DD、無ければnull）
+	LastSyncedAt     *time.Time `json:"last_synced_at"`     // 日次ログが最後に書き込まれた日時（無ければnull）
+	Stale            bool       `json:"stale"`
+}
+
+// RepoSyncStatusResponse ユーザーの登録リポジトリの同期状況一覧
+type RepoSyncStatusResponse struct {
+	StaleAfterHours int              `json:"stale_after_hours"`
+	Repositories    []RepoSyncStatus `json:"repositories"`
+}
+
+// RepoStreakResponse 登録リポジトリ単位のstreak
+type RepoStreakResponse struct {
+	RepositoryID       uint64  `json:"repository_id"`
+	CurrentStreak      int     `json:"current_streak"`
+	LongestStreak      int     `json:"longest_streak"`
+	CurrentStreakStart *string `json:"current_streak_start"` // 継続中のstreakの開始日（YYYY-MM-DD、無ければnull）
+}
+
+// CommitDetail 保存したコミット1件
+type CommitDetail struct {
+	SHA         string    `json:"sha"`
+	Message     string    `json:"message"`
+	AuthorName  string    `json:"author_name"`
+	AuthorLogin *string   `json:"author_login"` // GitHubアカウントに紐づかないコミットは null
+	CommittedAt time.Time `json:"committed_at"`
+}
+
+// RecentCommitsResponse 登録リポジトリの最近のコミット（新しい順）
+type RecentCommitsResponse struct {
+	RepositoryID uint64         `json:"repository_id"`
+	Commits      []CommitDetail `json:"commits"`
+}
+
+// RepositorySearchEntry 検索で見つかった登録リポジトリ
+type RepositorySearchEntry struct {
+	ID             uint64    `json:"id"`
+	UserID         uint64    `json:"user_id"`
+	GitHubUsername string    `json:"github_username"` // 登録したユーザー
+	Owner          string    `json:"owner"`
+	Name           string    `json:"name"`
+	IsPublic       bool      `json:"is_public"`
+	Active         bool      `json:"active"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// 登録リポジトリの検索結果の並び替えの項目
+const (
+	RepositorySearchSortName      = "name"       // オーナー名・リポジトリ名
+	RepositorySearchSortCreatedAt = "created_at" // 登録日時
+)
+
+// RepositorySearchResponse 登録リポジトリの検索結果
+type RepositorySearchResponse struct {
+	Sort         string                  `json:"sort"`
+	Order        string                  `json:"order"`
+	Limit        int                     `json:"limit"`
+	Offset       int                     `json:"offset"`
+	Total        int64                   `json:"total"` // 条件に一致する全件数
+	Repositories []RepositorySearchEntry `json:"repositories"`
+}
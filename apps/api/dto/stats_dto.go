@@ -0,0 +1,9 @@
+package dto
+
+// PlatformStatsResponse トップページ用のサービス全体の集計
+type PlatformStatsResponse struct {
+	TotalUsers           int64 `json:"total_users"`
+	TotalRepositories    int64 `json:"total_repositories"` // 無効化されていない登録リポジトリ数
+	TotalCommits         int64 `json:"total_commits"`      // 無効化されていない登録リポジトリのコミット数の合計
+	LongestCurrentStreak int   `json:"longest_current_streak"`
+}
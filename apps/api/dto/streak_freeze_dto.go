@@ -0,0 +1,19 @@
+package dto
+
+// CreateStreakFreezeRequest streakの凍結を予約するリクエスト（日付は YYYY-MM-DD、両端を含む）
+type CreateStreakFreezeRequest struct {
+	StartDate string `json:"start_date" validate:"required"`
+	EndDate   string `json:"end_date" validate:"required"`
+}
+
+// StreakFreezeResponse streakの凍結期間
+type StreakFreezeResponse struct {
+	ID        uint64 `json:"id"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+// StreakFreezesResponse ユーザーの凍結期間の一覧（開始日の古い順）
+type StreakFreezesResponse struct {
+	Freezes []StreakFreezeResponse `json:"freezes"`
+}
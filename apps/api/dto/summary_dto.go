@@ -0,0 +1,79 @@
+package dto
+
+// UserSummaryResponse プロフィール画面用のユーザーサマリー
+type UserSummaryResponse struct {
+	User               UserResponse       `json:"user"`
+	CurrentStreak      int                `json:"current_streak"`
+	LongestStreak      int                `json:"longest_streak"`
+	TotalCommits       int                `json:"total_commits"`
+	CommitsLast7Days   int                `json:"commits_last_7_days"`
+	ActiveRepositories int                `json:"active_repositories"`
+	FirstCommitDate    *string            `json:"first_commit_date"`  // コミットがあった最初の日（YYYY-MM-DD、無ければnull）
+	LastCommitDate     *string            `json:"last_commit_date"`   // コミットがあった最後の日（YYYY-MM-DD、無ければnull）
+	SuggestedTimezone  *SuggestedTimezone `json:"suggested_timezone"` // コミットから推定したタイムゾーン（未推定は null）
+}
+
+// SuggestedTimezone コミットの作者日時から推定したタイムゾーン（初期設定の候補。user.timezone は変えない）
+type SuggestedTimezone struct {
+	UTCOffset string  `json:"utc_offset"` // 最も多かったUTCオフセット（+09:00 形式）
+	Timezone  *string `json:"timezone"`   // UTCOffset に相当するIANAタイムゾーン名（Etc/GMT-9 など。1時間単位でなければ null）
+}
+
+// UserSummariesRequest 複数ユーザーのサマリー取得リクエスト
+type UserSummariesRequest struct {
+	IDs []uint64 `json:"ids" validate:"required,min=1,max=100"`
+}
+
+// 複数ユーザーのサマリー取得のユーザーごとの結果
+const (
+	SummaryStatusOK     = "ok"
+	SummaryStatusFailed = "failed"
+)
+
+// UserSummaryResult 1ユーザー分の結果。ok の場合は UserSummaryResponse の項目を、failed の場合は error だけを返す
+type UserSummaryResult struct {
+	Status string `json:"status"`
+	*UserSummaryResponse
+	Error string `json:"error,omitempty"`
+}
+
+// UserSummariesResponse ユーザーIDごとのサマリー（存在しないIDは含めない）
+// 一部のユーザーの取得に失敗しても、そのユーザーだけを failed にして残りを返す
+type UserSummariesResponse struct {
+	Summaries map[uint64]UserSummaryResult `json:"summaries"`
+	Failed    int                          `json:"failed"`
+}
+
+// TodayStatusResponse ウィジェット用の今日のコミット状況
+type TodayStatusResponse struct {
+	Date             string `json:"date"`            // ユーザーのタイムゾーンでの今日（YYYY-MM-DD）
+	CommittedToday   bool   `json:"committed_today"` // 今日のコミット数が MinCommitsPerDay 以上か
+	CommitsToday     int    `json:"commits_today"`
+	MinCommitsPerDay int    `json:"min_commits_per_day"` // このユーザーでstreakに数える1日のコミット数の下限
+	StreakLength     int    `json:"streak_length"`       // 継続中のstreak日数（無ければ0）
+}
+
+// StreakHistoryEntry 過去のstreak1件
+type StreakHistoryEntry struct {
+	StartDate string  `json:"start_date"` // YYYY-MM-DD
+	EndDate   *string `json:"end_date"`   // 継続中の場合は null
+	Length    int     `json:"length"`
+	Active    bool    `json:"active"`
+}
+
+// streak履歴の並び替えの項目
+const (
+	StreakHistorySortStartDate = "start_date" // 開始日
+	StreakHistorySortLength    = "length"     // 日数
+)
+
+// StreakHistoryResponse ユーザーのstreak履歴（デフォルトは開始日の新しい順）
+type StreakHistoryResponse struct {
+	UserID  uint64               `json:"user_id"`
+	Sort    string               `json:"sort"`
+	Order   string               `json:"order"`
+	Limit   int                  `json:"limit"`
+	Offset  int                  `json:"offset"`
+	Total   int64                `json:"total"` // streakの全件数
+	Streaks []StreakHistoryEntry `json:"streaks"`
+}
@@ -0,0 +1,34 @@
+package dto
+
+// DailyCountChange 1日分のコミット数の変化
+type DailyCountChange struct {
+	Date   string `json:"date"` // YYYY-MM-DD
+	Before int    `json:"before"`
+	After  int    `json:"after"`
+}
+
+// RepositorySyncPreview リポジトリ別日次ログの変化
+type RepositorySyncPreview struct {
+	RepositoryID uint64             `json:"repository_id"`
+	Owner        string             `json:"owner"`
+	Name         string             `json:"name"`
+	Days         []DailyCountChange `json:"days"` // 変化があった日のみ
+}
+
+// StreakChange streak日数の変化
+type StreakChange struct {
+	CurrentBefore int `json:"current_before"`
+	CurrentAfter  int `json:"current_after"`
+	LongestBefore int `json:"longest_before"`
+	LongestAfter  int `json:"longest_after"`
+}
+
+// SyncPreview 同期で書き込んだ（ドライランの場合は書き込むはずだった）内容の差分
+type SyncPreview struct {
+	DryRun       bool                    `json:"dry_run"`
+	Since        string                  `json:"since"`
+	Until        string                  `json:"until"`
+	Repositories []RepositorySyncPreview `json:"repositories"`
+	DailyTotals  []DailyCountChange      `json:"daily_totals"` // ユーザー単位の日次ログで変化があった日のみ
+	Streak       StreakChange            `json:"streak"`
+}
@@ -1,18 +1,66 @@
 package dto
 
+import "time"
+
 // UpsertUserRequest ユーザー作成/更新リクエスト
 type UpsertUserRequest struct {
 	GitHubUserID   uint64 `json:"github_user_id" validate:"required"`
-	GitHubUsername string `json:"github_username" validate:"required"`
-	Email          string `json:"email"`
+	GitHubUsername string `json:"github_username" validate:"required,max=100"`
+	Email          string `json:"email" validate:"omitempty,email,max=255"`
+}
+
+// PatchUserRequest ユーザーの部分更新リクエスト（nil の項目は変更しない）
+type PatchUserRequest struct {
+	Email                *string `json:"email" validate:"omitnil,max=255,eq=|email"` // 空文字でメールアドレスを削除
+	Timezone             *string `json:"timezone" validate:"omitnil,required,timezone,max=64"`
+	NotificationsEnabled *bool   `json:"notifications_enabled"`
+	IsPublicProfile      *bool   `json:"is_public_profile"`                                     // false でランキングとサービス全体の集計から外す
+	MinCommitsPerDay     *int    `json:"min_commits_per_day" validate:"omitnil,min=0,max=1000"` // 0 でサーバーのデフォルトに戻す
+}
+
+// MergeUsersRequest 重複ユーザーの統合リクエスト
+type MergeUsersRequest struct {
+	KeepID   uint64 `json:"keep_id" validate:"required"`
+	RemoveID uint64 `json:"remove_id" validate:"required"`
+}
+
+// DeletedRowCounts アカウント削除で削除した行数
+type DeletedRowCounts struct {
+	Users               int64 `json:"users"`
+	Repositories        int64 `json:"repositories"`
+	RepoDailyCommitLogs int64 `json:"repo_daily_commit_logs"`
+	RepoStreaks         int64 `json:"repo_streaks"`
+	UserDailyCommitLogs int64 `json:"user_daily_commit_logs"`
+	UserStreaks         int64 `json:"user_streaks"`
+	Achievements        int64 `json:"achievements"`
+	Goals               int64 `json:"goals"`
+	StreakFreezes       int64 `json:"streak_freezes"`
+	APIKeys             int64 `json:"api_keys"`
+}
+
+// DeleteUserResponse アカウント削除の結果
+type DeleteUserResponse struct {
+	UserID  uint64           `json:"user_id"`
+	Purged  bool             `json:"purged"` // false の場合は論理削除のみ
+	Deleted DeletedRowCounts `json:"deleted"`
+}
+
+// RevokeTokensResponse トークン無効化の結果
+type RevokeTokensResponse struct {
+	UserID       uint64 `json:"user_id"`
+	TokenVersion uint   `json:"token_version"` // これより前の版で発行したトークン・APIキーは無効
 }
 
 // UserResponse ユーザーレスポンス
 type UserResponse struct {
-	ID             uint64 `json:"id"`
-	GitHubUserID   uint64 `json:"github_user_id"`
-	GitHubUsername string `json:"github_username"`
-	Email          string `json:"email"`
-	CreatedAt      string `json:"created_at"`
-	UpdatedAt      string `json:"updated_at"`
+	ID                   uint64    `json:"id"`
+	GitHubUserID         uint64    `json:"github_user_id"`
+	GitHubUsername       string    `json:"github_username"`
+	Email                string    `json:"email"`
+	Timezone             string    `json:"timezone"`
+	NotificationsEnabled bool      `json:"notifications_enabled"`
+	IsPublicProfile      bool      `json:"is_public_profile"`
+	MinCommitsPerDay     *int      `json:"min_commits_per_day"` // null はサーバーのデフォルト
+	CreatedAt            time.Time `json:"created_at"`          // ユーザーのタイムゾーンのオフセット付き RFC3339
+	UpdatedAt            time.Time `json:"updated_at"`
 }
@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+// SetWebhookRequest Webhookの送信先の設定リクエスト
+type SetWebhookRequest struct {
+	URL string `json:"url" validate:"required,https_url,max=2048"`
+}
+
+// WebhookResponse 設定したWebhook（Secret は設定した時にだけ返す）
+type WebhookResponse struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret"` // X-CommitTown-Signature の検証に使う
+}
+
+// StreakWebhookPayload streakの節目・途切れたときにWebhookで送る内容
+type StreakWebhookPayload struct {
+	Event          string    `json:"event"` // streak.extended / streak.broken
+	UserID         uint64    `json:"user_id"`
+	GitHubUsername string    `json:"github_username"`
+	StartDate      string    `json:"start_date"` // YYYY-MM-DD
+	Length         int       `json:"length"`     // streak.broken の場合は途切れる前の日数
+	Milestone      int       `json:"milestone,omitempty"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
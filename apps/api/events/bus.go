@@ -0,0 +1,158 @@
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Type イベントの種類
+type Type string
+
+const (
+	StreakStarted    Type = "streak.started"    // 新しいstreakが始まった
+	StreakExtended   Type = "streak.extended"   // 継続中のstreakが伸びた
+	StreakBroken     Type = "streak.broken"     // 継続中だったstreakが途切れた
+	RepositorySynced Type = "repository.synced" // リポジトリのコミットを保存した
+)
+
+// Event 発行されるイベント
+type Event interface {
+	EventType() Type
+}
+
+// StreakEvent streakの変化（StreakStarted / StreakExtended / StreakBroken）
+type StreakEvent struct {
+	Type           Type
+	UserID         uint64
+	StartDate      time.Time
+	Length         int // StreakBroken の場合は途切れる前の日数
+	PreviousLength int // StreakExtended の場合の伸びる前の日数（一度の同期で数日分伸びることがある）
+}
+
+func (e StreakEvent) EventType() Type { return e.Type }
+
+// RepositorySyncedEvent リポジトリのコミットを保存した
+type RepositorySyncedEvent struct {
+	UserID     uint64
+	UserRepoID uint64
+	RepoOwner  string
+	RepoName   string
+	Days       int // 保存した日数
+}
+
+func (e RepositorySyncedEvent) EventType() Type { return RepositorySynced }
+
+// Handler イベントを受け取る処理。エラーはログに出すだけで発行元には返さない
+type Handler func(ctx context.Context, event Event) error
+
+// Bus プロセス内のイベント配信
+// Publish は購読者を登録順に同期的に呼び出す。購読者のエラーやpanicは発行元に影響させない
+// Defer したコンテキストで発行したイベントは Flush まで配信しないため、トランザクション内ではコミット後に届き、
+// ロールバックした場合は購読者に届かない
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[Type][]Handler
+}
+
+// NewBus creates a new event bus
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[Type][]Handler)}
+}
+
+// Subscribe eventType のイベントを受け取る処理を登録する
+func (b *Bus) Subscribe(eventType Type, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+// Publish イベントを購読者に配信する（Defer したコンテキストでは Flush まで溜める）
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	if pending, ok := ctx.Value(pendingKey{}).(*Pending); ok {
+		pending.add(b, event)
+		return
+	}
+	b.deliver(ctx, event)
+}
+
+// deliver 購読者を登録順に呼び出す
+func (b *Bus) deliver(ctx context.Context, event Event) {
+	b.mu.RLock()
+	handlers := b.handlers[event.EventType()]
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		b.call(ctx, handler, event)
+	}
+}
+
+// call 1つの購読者を呼び出す（panicしても他の購読者と発行元は続行する）
+func (b *Bus) call(ctx context.Context, handler Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Event handler panicked on %s: %v", event.EventType(), r)
+		}
+	}()
+	if err := handler(ctx, event); err != nil {
+		log.Printf("Event handler failed on %s: %v", event.EventType(), err)
+	}
+}
+
+// SubscribeLogger 全てのイベントをログに出力する購読者を登録する
+func SubscribeLogger(b *Bus) {
+	logEvent := func(ctx context.Context, event Event) error {
+		log.Printf("Event %s: %+v", event.EventType(), event)
+		return nil
+	}
+	for _, eventType := range []Type{StreakStarted, StreakExtended, StreakBroken, RepositorySynced} {
+		b.Subscribe(eventType, logEvent)
+	}
+}
+
+type pendingKey struct{}
+
+// Pending Defer したコンテキストで発行され、配信を待っているイベント
+type Pending struct {
+	parent *Pending // 外側の Defer（入れ子のトランザクション）
+
+	mu     sync.Mutex
+	events []pendingEvent
+}
+
+type pendingEvent struct {
+	bus   *Bus
+	event Event
+}
+
+// Defer 返したコンテキストで Publish されたイベントを配信せずに溜める
+// Flush するまで購読者には届かず、Flush しなければ破棄される（トランザクションのロールバックなど）
+func Defer(ctx context.Context) (context.Context, *Pending) {
+	parent, _ := ctx.Value(pendingKey{}).(*Pending)
+	pending := &Pending{parent: parent}
+	return context.WithValue(ctx, pendingKey{}, pending), pending
+}
+
+func (p *Pending) add(b *Bus, event Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, pendingEvent{bus: b, event: event})
+}
+
+// Flush 溜めたイベントを発行順に配信する。外側にも Defer がある場合はそちらに引き渡し、外側の Flush で配信する
+// ctx は購読者に渡すコンテキスト（Defer する前のもの）
+func (p *Pending) Flush(ctx context.Context) {
+	p.mu.Lock()
+	queued := p.events
+	p.events = nil
+	p.mu.Unlock()
+
+	for _, e := range queued {
+		if p.parent != nil {
+			p.parent.add(e.bus, e.event)
+			continue
+		}
+		e.bus.deliver(ctx, e.event)
+	}
+}
@@ -0,0 +1,147 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// recorder 受け取ったイベントの種類を記録する購読者
+type recorder struct {
+	got []Type
+}
+
+func (r *recorder) handle(ctx context.Context, event Event) error {
+	r.got = append(r.got, event.EventType())
+	return nil
+}
+
+func newRecordedBus() (*Bus, *recorder) {
+	bus := NewBus()
+	rec := &recorder{}
+	for _, eventType := range []Type{StreakStarted, StreakExtended, StreakBroken, RepositorySynced} {
+		bus.Subscribe(eventType, rec.handle)
+	}
+	return bus, rec
+}
+
+func TestBus_PublishIsolatesFailingHandlers(t *testing.T) {
+	bus := NewBus()
+	var calls []string
+	bus.Subscribe(StreakStarted, func(ctx context.Context, event Event) error {
+		calls = append(calls, "panics")
+		panic("boom")
+	})
+	bus.Subscribe(StreakStarted, func(ctx context.Context, event Event) error {
+		calls = append(calls, "fails")
+		return errors.New("failed")
+	})
+	bus.Subscribe(StreakStarted, func(ctx context.Context, event Event) error {
+		calls = append(calls, "succeeds")
+		return nil
+	})
+	bus.Subscribe(StreakBroken, func(ctx context.Context, event Event) error {
+		calls = append(calls, "other type")
+		return nil
+	})
+
+	bus.Publish(context.Background(), StreakEvent{Type: StreakStarted, UserID: 1})
+
+	if want := []string{"panics", "fails", "succeeds"}; !slices.Equal(calls, want) {
+		t.Errorf("handlers called = %v, want %v", calls, want)
+	}
+}
+
+func TestDefer(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(ctx context.Context, bus *Bus)
+		want []Type
+	}{
+		{
+			name: "without defer",
+			run: func(ctx context.Context, bus *Bus) {
+				bus.Publish(ctx, StreakEvent{Type: StreakStarted})
+			},
+			want: []Type{StreakStarted},
+		},
+		{
+			name: "flushed in publish order",
+			run: func(ctx context.Context, bus *Bus) {
+				txCtx, pending := Defer(ctx)
+				bus.Publish(txCtx, RepositorySyncedEvent{})
+				bus.Publish(txCtx, StreakEvent{Type: StreakExtended})
+				pending.Flush(ctx)
+			},
+			want: []Type{RepositorySynced, StreakExtended},
+		},
+		{
+			name: "discarded without flush",
+			run: func(ctx context.Context, bus *Bus) {
+				txCtx, _ := Defer(ctx)
+				bus.Publish(txCtx, StreakEvent{Type: StreakBroken})
+			},
+			want: nil,
+		},
+		{
+			name: "nested flush waits for the outer flush",
+			run: func(ctx context.Context, bus *Bus) {
+				outerCtx, outer := Defer(ctx)
+				innerCtx, inner := Defer(outerCtx)
+				bus.Publish(innerCtx, StreakEvent{Type: StreakStarted})
+				inner.Flush(outerCtx)
+				bus.Publish(outerCtx, RepositorySyncedEvent{})
+				outer.Flush(ctx)
+			},
+			want: []Type{StreakStarted, RepositorySynced},
+		},
+		{
+			name: "nested flush discarded with the outer",
+			run: func(ctx context.Context, bus *Bus) {
+				outerCtx, _ := Defer(ctx)
+				innerCtx, inner := Defer(outerCtx)
+				bus.Publish(innerCtx, StreakEvent{Type: StreakStarted})
+				inner.Flush(outerCtx)
+			},
+			want: nil,
+		},
+		{
+			name: "flushed once",
+			run: func(ctx context.Context, bus *Bus) {
+				txCtx, pending := Defer(ctx)
+				bus.Publish(txCtx, StreakEvent{Type: StreakStarted})
+				pending.Flush(ctx)
+				pending.Flush(ctx)
+			},
+			want: []Type{StreakStarted},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus, rec := newRecordedBus()
+			tt.run(context.Background(), bus)
+			if !slices.Equal(rec.got, tt.want) {
+				t.Errorf("delivered %v, want %v", rec.got, tt.want)
+			}
+		})
+	}
+}
+
+// Flush 中の購読者が発行したイベントは溜めずにそのまま配信する
+func TestPending_FlushDeliversWithOuterContext(t *testing.T) {
+	bus, rec := newRecordedBus()
+	bus.Subscribe(StreakExtended, func(ctx context.Context, event Event) error {
+		bus.Publish(ctx, StreakEvent{Type: StreakBroken})
+		return nil
+	})
+
+	ctx := context.Background()
+	txCtx, pending := Defer(ctx)
+	bus.Publish(txCtx, StreakEvent{Type: StreakExtended})
+	pending.Flush(ctx)
+
+	if want := []Type{StreakExtended, StreakBroken}; !slices.Equal(rec.got, want) {
+		t.Errorf("delivered %v, want %v", rec.got, want)
+	}
+}
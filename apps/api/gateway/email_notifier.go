@@ -0,0 +1,55 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+type emailNotifier struct {
+	addr     string
+	from     string
+	username string
+	password string
+}
+
+// NewEmailNotifier SMTP経由でメールを送る通知
+func NewEmailNotifier(addr, from, username, password string) Notifier {
+	return &emailNotifier{
+		addr:     addr,
+		from:     from,
+		username: username,
+		password: password,
+	}
+}
+
+func (n *emailNotifier) Notify(ctx context.Context, notification Notification) error {
+	if notification.Email == "" {
+		return fmt.Errorf("user %d has no email address", notification.UserID)
+	}
+
+	var auth smtp.Auth
+	if n.username != "" {
+		host, _, err := net.SplitHostPort(n.addr)
+		if err != nil {
+			return fmt.Errorf("invalid SMTP address %q: %w", n.addr, err)
+		}
+		auth = smtp.PlainAuth("", n.username, n.password, host)
+	}
+
+	msg := strings.Join([]string{
+		"From: " + n.from,
+		"To: " + notification.Email,
+		"Subject: " + notification.Subject,
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		notification.Body,
+	}, "\r\n")
+
+	if err := smtp.SendMail(n.addr, auth, n.from, []string{notification.Email}, []byte(msg)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package gateway
+
+import (
+	"context"
+	"log"
+)
+
+// Notification ユーザーに送る通知内容
+type Notification struct {
+	UserID         uint64
+	GitHubUsername string
+	Email          string
+	Subject        string
+	Body           string
+}
+
+// Notifier 通知の送信先を抽象化するインターフェース
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+type noopNotifier struct{}
+
+// NewNoopNotifier 何も送信しない通知（デフォルト・テスト用）
+func NewNoopNotifier() Notifier {
+	return &noopNotifier{}
+}
+
+func (n *noopNotifier) Notify(ctx context.Context, notification Notification) error {
+	log.Printf("Notification skipped (noop notifier): user=%d subject=%q", notification.UserID, notification.Subject)
+	return nil
+}
@@ -0,0 +1,114 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+const (
+	// HeaderWebhookSignature ボディの HMAC-SHA256（"sha256=<hex>"）。受信側は同じシークレットで計算して比べる
+	HeaderWebhookSignature = "X-CommitTown-Signature"
+	// HeaderWebhookEvent 送信したイベントの種類
+	HeaderWebhookEvent = "X-CommitTown-Event"
+)
+
+// WebhookSender ユーザーが設定したURLへ署名付きのJSONをPOSTする
+type WebhookSender struct {
+	client *http.Client
+}
+
+// errForbiddenAddress 送信先が公開されていないアドレスに解決された
+var errForbiddenAddress = errors.New("webhook address is not publicly routable")
+
+// sharedAddressSpace キャリアグレードNAT（RFC 6598）のアドレス。IsPrivate には含まれない
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// NewWebhookSender 1回の送信は10秒でタイムアウトする
+// ユーザーが指定したURLへ送るため、ループバック・プライベート・リンクローカルのアドレスには接続せず、リダイレクトも追わない
+// 判定は名前解決した後のアドレスに対して接続の直前に行うため、DNSリバインディングでも内部へは届かない
+func NewWebhookSender() *WebhookSender {
+	return newWebhookSender(denyPrivateAddress)
+}
+
+// newWebhookSender 接続の直前に control で接続先を確かめる WebhookSender
+func newWebhookSender(control func(network, address string, c syscall.RawConn) error) *WebhookSender {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: control}
+	return NewWebhookSenderWithClient(&http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	})
+}
+
+// NewWebhookSenderWithClient 送信に client をそのまま使う（送信先の制限は client の設定による。テスト用）
+func NewWebhookSenderWithClient(client *http.Client) *WebhookSender {
+	return &WebhookSender{client: client}
+}
+
+// denyPrivateAddress net.Dialer の Control。接続先が公開アドレスでなければ接続しない
+func denyPrivateAddress(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errForbiddenAddress, address)
+	}
+	if !isPublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", errForbiddenAddress, address)
+	}
+	return nil
+}
+
+// isPublicAddr インターネットから到達できるユニキャストのアドレスかどうか
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() &&
+		!addr.IsPrivate() &&
+		!addr.IsLoopback() &&
+		!addr.IsLinkLocalUnicast() &&
+		!sharedAddressSpace.Contains(addr)
+}
+
+// Send body を url へPOSTする。2xx 以外のレスポンスはエラーとして返す
+func (sender *WebhookSender) Send(ctx context.Context, url, secret, event string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderWebhookEvent, event)
+	req.Header.Set(HeaderWebhookSignature, Sign(secret, body))
+
+	res, err := sender.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+	return nil
+}
+
+// Sign X-CommitTown-Signature ヘッダーの値を計算する
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,217 @@
+package httperr
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// エラーコード（フロントエンドはこの値で分岐する）
+const (
+	CodeInvalidRequest   = "invalid_request"
+	CodeValidationFailed = "validation_failed"
+	CodeNotFound         = "not_found"
+	CodeUserNotFound     = "user_not_found"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeConflict         = "conflict"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodePayloadTooLarge  = "payload_too_large"
+	CodeUnsupportedMedia = "unsupported_media_type"
+	CodeTimeout          = "timeout"
+	CodeRateLimited      = "rate_limited"
+	CodeTooManyRequests  = "too_many_requests"
+	CodeMaintenance      = "maintenance"
+	CodeInternal         = "internal_error"
+)
+
+// APIError HTTPステータスとエラーコードを持つ型付きエラー
+type APIError struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"` // validation_failed の項目ごとの詳細
+	Status  int          `json:"-"`
+	Err     error        `json:"-"` // ログ用の原因エラー（レスポンスには含めない）
+}
+
+// FieldError 入力項目ごとのバリデーションエラー
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// errorEnvelope レスポンスボディ {"error":{"code":...,"message":...}}
+type errorEnvelope struct {
+	Error *APIError `json:"error"`
+}
+
+// New 任意のステータス・コードでAPIErrorを作成
+func New(status int, code, message string) *APIError {
+	return &APIError{Code: code, Message: message, Status: status}
+}
+
+// InvalidRequest リクエストの形式が不正（JSONやパラメータのパース失敗など）
+func InvalidRequest(message string) *APIError {
+	return New(http.StatusBadRequest, CodeInvalidRequest, message)
+}
+
+// ValidationFailed 入力値のバリデーションエラー
+func ValidationFailed(message string) *APIError {
+	return New(http.StatusBadRequest, CodeValidationFailed, message)
+}
+
+// InvalidFields 項目ごとのバリデーションエラー
+func InvalidFields(fields []FieldError) *APIError {
+	apiErr := New(http.StatusBadRequest, CodeValidationFailed, "Validation failed")
+	apiErr.Fields = fields
+	return apiErr
+}
+
+// NotFound リソースが存在しない
+func NotFound(message string) *APIError {
+	return New(http.StatusNotFound, CodeNotFound, message)
+}
+
+// UserNotFound ユーザーが存在しない
+func UserNotFound() *APIError {
+	return New(http.StatusNotFound, CodeUserNotFound, "User not found")
+}
+
+// Unauthorized 認証情報が無効
+func Unauthorized(message string) *APIError {
+	return New(http.StatusUnauthorized, CodeUnauthorized, message)
+}
+
+// Forbidden 操作する権限がない
+func Forbidden(message string) *APIError {
+	return New(http.StatusForbidden, CodeForbidden, message)
+}
+
+// Conflict リソースの状態と競合
+func Conflict(message string) *APIError {
+	return New(http.StatusConflict, CodeConflict, message)
+}
+
+// UnsupportedMediaType リクエストボディの Content-Type に対応していない
+func UnsupportedMediaType(message string) *APIError {
+	return New(http.StatusUnsupportedMediaType, CodeUnsupportedMedia, message)
+}
+
+// Timeout 処理が時間内に終わらなかった
+func Timeout(message string) *APIError {
+	return New(http.StatusGatewayTimeout, CodeTimeout, message)
+}
+
+// RateLimited 外部APIのレート制限中のため処理できない
+func RateLimited(message string) *APIError {
+	return New(http.StatusServiceUnavailable, CodeRateLimited, message)
+}
+
+// TooManyRequests 同時に受け付けられる数を超えた
+func TooManyRequests(message string) *APIError {
+	return New(http.StatusTooManyRequests, CodeTooManyRequests, message)
+}
+
+// Maintenance メンテナンス中のため書き込みを受け付けない
+func Maintenance(message string) *APIError {
+	return New(http.StatusServiceUnavailable, CodeMaintenance, message)
+}
+
+// Internal サーバー内部エラー。原因はログにのみ出力する
+func Internal(message string, err error) *APIError {
+	return &APIError{Code: CodeInternal, Message: message, Status: http.StatusInternalServerError, Err: err}
+}
+
+// Handler Echoの共通エラーハンドラー。全てのエラーを同じ形式で返す
+func Handler(err error, ctx echo.Context) {
+	if ctx.Response().Committed {
+		return
+	}
+
+	apiErr := toAPIError(err)
+	if apiErr.Status >= http.StatusInternalServerError {
+		log.Printf("%s %s: %v", ctx.Request().Method, ctx.Request().URL.Path, apiErr)
+	}
+
+	var writeErr error
+	if ctx.Request().Method == http.MethodHead {
+		writeErr = ctx.NoContent(apiErr.Status)
+	} else {
+		writeErr = ctx.JSON(apiErr.Status, errorEnvelope{Error: apiErr})
+	}
+	if writeErr != nil {
+		log.Printf("Failed to write error response: %v", writeErr)
+	}
+}
+
+// fieldErrorer 項目ごとのエラーを持つエラー（validator.ValidationErrors など）
+type fieldErrorer interface {
+	error
+	FieldErrors() []FieldError
+}
+
+// toAPIError 任意のエラーをAPIErrorに変換
+func toAPIError(err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var fieldErrs fieldErrorer
+	if errors.As(err, &fieldErrs) {
+		return InvalidFields(fieldErrs.FieldErrors())
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		message := http.StatusText(httpErr.Code)
+		if m, ok := httpErr.Message.(string); ok {
+			message = m
+		}
+		return &APIError{Code: codeForStatus(httpErr.Code), Message: message, Status: httpErr.Code, Err: httpErr.Internal}
+	}
+
+	return Internal("Internal server error", err)
+}
+
+// codeForStatus Echo標準のエラー（ルーティング・ミドルウェア由来）をエラーコードに対応付ける
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusUnsupportedMediaType:
+		return CodeUnsupportedMedia
+	case http.StatusTooManyRequests:
+		return CodeTooManyRequests
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeInvalidRequest
+}
@@ -0,0 +1,126 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// HeaderIdempotencyKey クライアントがリクエストごとに付けるキー（再送時は同じ値を付ける）
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed 記録したレスポンスを返した場合に付けるヘッダー
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+)
+
+// Middleware Idempotency-Key ヘッダーが付いたリクエストのレスポンスを ttl の間記録し、
+// 同じキーで再送された場合は処理をやり直さずに記録したレスポンスを返す
+//
+//   - ヘッダーが無いリクエストはそのまま処理する
+//   - 同じキーで別のボディが送られた場合、または最初のリクエストがまだ処理中の場合は409を返す
+//   - 5xx のレスポンスは記録せず、同じキーでの再試行を受け付ける
+//
+// キーはメソッドとルートごとに区別する
+func Middleware(store Store, ttl time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			key := ctx.Request().Header.Get(HeaderIdempotencyKey)
+			if key == "" {
+				return next(ctx)
+			}
+			if len(key) > maxKeyLength {
+				return httperr.InvalidRequest("Idempotency-Key must be at most 255 characters")
+			}
+
+			req := ctx.Request()
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return httperr.InvalidRequest("Invalid request body")
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			storeKey := req.Method + " " + ctx.Path() + " " + key
+			fingerprint := fingerprintOf(req.Method, ctx.Path(), body)
+			existing, err := store.Reserve(req.Context(), storeKey, fingerprint, ttl)
+			if err != nil {
+				return httperr.Internal("Failed to check Idempotency-Key", err)
+			}
+			if existing != nil {
+				switch {
+				case existing.Fingerprint != fingerprint:
+					return httperr.Conflict("Idempotency-Key was already used with a different request")
+				case existing.Response == nil:
+					return httperr.Conflict("A request with this Idempotency-Key is still being processed")
+				}
+				ctx.Response().Header().Set(HeaderIdempotentReplayed, "true")
+				return ctx.Blob(existing.Response.Status, existing.Response.ContentType, existing.Response.Body)
+			}
+
+			// レスポンスを書いた後はリクエストが終わっても記録できるよう、キャンセルされない ctx を使う
+			storeCtx := context.WithoutCancel(req.Context())
+			completed := false
+			// panic などで記録できなかった場合は予約を外し、処理中のまま TTL まで残らないようにする
+			defer func() {
+				if !completed {
+					if err := store.Release(storeCtx, storeKey); err != nil {
+						log.Printf("Failed to release Idempotency-Key: %v", err)
+					}
+				}
+			}()
+
+			res := ctx.Response()
+			recorder := &responseRecorder{ResponseWriter: res.Writer}
+			res.Writer = recorder
+			// エラーもここでレスポンスにして記録する
+			if err := next(ctx); err != nil {
+				ctx.Error(err)
+			}
+			res.Writer = recorder.ResponseWriter
+
+			if res.Status >= http.StatusInternalServerError {
+				return nil
+			}
+			err = store.Complete(storeCtx, storeKey, Response{
+				Status:      res.Status,
+				ContentType: res.Header().Get(echo.HeaderContentType),
+				Body:        recorder.body.Bytes(),
+			})
+			if err != nil {
+				// レスポンスは返し済みのため、記録できなかったことだけをログに残す
+				log.Printf("Failed to store response for Idempotency-Key: %v", err)
+				return nil
+			}
+			completed = true
+			return nil
+		}
+	}
+}
+
+// fingerprintOf リクエストのハッシュ（同じキーで別のリクエストが送られたかの判定用）
+func fingerprintOf(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder 書き込まれたレスポンスボディを記録用に複製する
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
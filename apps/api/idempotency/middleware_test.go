@@ -0,0 +1,157 @@
+package idempotency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/labstack/echo/v4"
+)
+
+// newTestServer POST /api/users を処理した回数を calls に数えるサーバー
+// status が5xxの間はエラーを返す
+func newTestServer(store Store, ttl time.Duration, calls *int, status *int) *echo.Echo {
+	e := echo.New()
+	e.HTTPErrorHandler = httperr.Handler
+	e.POST("/api/users", func(ctx echo.Context) error {
+		*calls++
+		if *status >= http.StatusInternalServerError {
+			return httperr.Internal("Failed to upsert user", nil)
+		}
+		return ctx.JSON(*status, map[string]int{"id": *calls})
+	}, Middleware(store, ttl))
+	return e
+}
+
+func post(e *echo.Echo, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/users", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(HeaderIdempotencyKey, key)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddleware_ReplayReturnsSameResponse(t *testing.T) {
+	calls, status := 0, http.StatusOK
+	e := newTestServer(NewMemoryStore(), time.Hour, &calls, &status)
+	const body = `{"github_user_id":1,"github_username":"alice"}`
+
+	first := post(e, "retry-1", body)
+	replay := post(e, "retry-1", body)
+
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+	if replay.Code != first.Code || replay.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %s, want %d %s", replay.Code, replay.Body.String(), first.Code, first.Body.String())
+	}
+	if got := replay.Header().Get(echo.HeaderContentType); got != first.Header().Get(echo.HeaderContentType) {
+		t.Errorf("replay Content-Type = %q, want %q", got, first.Header().Get(echo.HeaderContentType))
+	}
+	if replay.Header().Get(HeaderIdempotentReplayed) != "true" {
+		t.Error("replay is missing the Idempotent-Replayed header")
+	}
+	if first.Header().Get(HeaderIdempotentReplayed) != "" {
+		t.Error("first response has the Idempotent-Replayed header")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	const body = `{"github_user_id":1}`
+	tests := []struct {
+		name       string
+		run        func(e *echo.Echo, status *int) *httptest.ResponseRecorder
+		wantStatus int
+		wantCalls  int
+	}{
+		{
+			name: "no key runs every time",
+			run: func(e *echo.Echo, status *int) *httptest.ResponseRecorder {
+				post(e, "", body)
+				return post(e, "", body)
+			},
+			wantStatus: http.StatusOK,
+			wantCalls:  2,
+		},
+		{
+			name: "same key with a different body",
+			run: func(e *echo.Echo, status *int) *httptest.ResponseRecorder {
+				post(e, "k", body)
+				return post(e, "k", `{"github_user_id":2}`)
+			},
+			wantStatus: http.StatusConflict,
+			wantCalls:  1,
+		},
+		{
+			name: "different keys run separately",
+			run: func(e *echo.Echo, status *int) *httptest.ResponseRecorder {
+				post(e, "a", body)
+				return post(e, "b", body)
+			},
+			wantStatus: http.StatusOK,
+			wantCalls:  2,
+		},
+		{
+			name: "server error is not recorded",
+			run: func(e *echo.Echo, status *int) *httptest.ResponseRecorder {
+				*status = http.StatusInternalServerError
+				post(e, "k", body)
+				*status = http.StatusOK
+				return post(e, "k", body)
+			},
+			wantStatus: http.StatusOK,
+			wantCalls:  2,
+		},
+		{
+			name: "client error is replayed",
+			run: func(e *echo.Echo, status *int) *httptest.ResponseRecorder {
+				*status = http.StatusUnprocessableEntity
+				post(e, "k", body)
+				*status = http.StatusOK
+				return post(e, "k", body)
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCalls:  1,
+		},
+		{
+			name: "key too long",
+			run: func(e *echo.Echo, status *int) *httptest.ResponseRecorder {
+				return post(e, strings.Repeat("k", maxKeyLength+1), body)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCalls:  0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls, status := 0, http.StatusOK
+			e := newTestServer(NewMemoryStore(), time.Hour, &calls, &status)
+			rec := tt.run(e, &status)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("handler ran %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestMiddleware_ExpiredKeyRunsAgain(t *testing.T) {
+	calls, status := 0, http.StatusOK
+	e := newTestServer(NewMemoryStore(), 10*time.Millisecond, &calls, &status)
+	post(e, "k", `{}`)
+	time.Sleep(20 * time.Millisecond)
+	if rec := post(e, "k", `{}`); rec.Header().Get(HeaderIdempotentReplayed) != "" {
+		t.Error("expired key was replayed")
+	}
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want 2", calls)
+	}
+}
@@ -0,0 +1,85 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Response 記録しておき、同じキーで再送されたときにそのまま返すレスポンス
+type Response struct {
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// Record キーごとの記録。Response が nil の場合は最初のリクエストがまだ処理中
+type Record struct {
+	Fingerprint string // リクエスト（メソッド・パス・ボディ）のハッシュ
+	Response    *Response
+}
+
+// Store 処理済みのキーとレスポンスを TTL の間保存する
+type Store interface {
+	// Reserve 未使用のキーを処理中として予約する。既に予約・記録済みの場合は予約せずにその記録を返す
+	Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, error)
+	// Complete 予約したキーにレスポンスを記録する（TTL は記録した時点から数え直す）
+	Complete(ctx context.Context, key string, response Response) error
+	// Release 記録せずに予約を外す（サーバーエラーなど、同じキーでの再試行を受け付けたい場合）
+	Release(ctx context.Context, key string) error
+}
+
+type memoryEntry struct {
+	record    Record
+	ttl       time.Duration
+	expiresAt time.Time
+}
+
+type memoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+}
+
+// NewMemoryStore プロセス内のメモリに保存する Store（デフォルト。複数台構成ではインスタンス間で共有されない）
+func NewMemoryStore() Store {
+	return &memoryStore{entries: make(map[string]*memoryEntry)}
+}
+
+func (s *memoryStore) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 期限切れのエントリはここで捨て、キーの数だけメモリが増え続けないようにする
+	for k, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, k)
+		}
+	}
+
+	if entry, ok := s.entries[key]; ok {
+		record := entry.record
+		return &record, nil
+	}
+	s.entries[key] = &memoryEntry{record: Record{Fingerprint: fingerprint}, ttl: ttl, expiresAt: now.Add(ttl)}
+	return nil, nil
+}
+
+func (s *memoryStore) Complete(ctx context.Context, key string, response Response) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok {
+		entry.record.Response = &response
+		entry.expiresAt = time.Now().Add(entry.ttl)
+	}
+	return nil
+}
+
+func (s *memoryStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// refreshTimeout 期限切れのエントリをバックグラウンドで取り直すときのタイムアウト
+const refreshTimeout = 30 * time.Second
+
+// Loader キャッシュに無い値を取得する
+type Loader[V any] func(ctx context.Context) (V, error)
+
+// Stats キャッシュのヒット数・ミス数と現在のエントリ数
+type Stats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+// TTL キー単位で値を ttl の間キャッシュする（複数のgoroutineから安全に使える）
+//
+// ttl を過ぎたエントリは stale として扱い、すぐに古い値を返しつつバックグラウンドで1回だけ取り直す
+// （stale-while-refresh）。2×ttl を過ぎたエントリは古すぎるため、取り直しが終わるまで待つ
+// 同じキーの取得は singleflight でまとめ、期限切れの瞬間に同じクエリが並んで走らないようにする
+type TTL[V any] struct {
+	ttl time.Duration
+
+	mu      sync.RWMutex
+	entries map[string]entry[V]
+	group   singleflight.Group
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+type entry[V any] struct {
+	value    V
+	storedAt time.Time
+}
+
+// NewTTL ttl が0以下の場合はキャッシュせず、毎回 Loader を呼ぶ
+func NewTTL[V any](ttl time.Duration) *TTL[V] {
+	return &TTL[V]{ttl: ttl, entries: make(map[string]entry[V])}
+}
+
+// Get キャッシュされた値を返し、無ければ（または古すぎれば）load で取得してキャッシュする
+func (c *TTL[V]) Get(ctx context.Context, key string, load Loader[V]) (V, error) {
+	if c.ttl <= 0 {
+		return load(ctx)
+	}
+
+	now := time.Now()
+	c.mu.RLock()
+	cached, ok := c.entries[key]
+	c.mu.RUnlock()
+
+	if ok {
+		age := now.Sub(cached.storedAt)
+		if age < c.ttl {
+			c.hits.Add(1)
+			return cached.value, nil
+		}
+		if age < 2*c.ttl {
+			c.hits.Add(1)
+			c.refresh(key, load)
+			return cached.value, nil
+		}
+	}
+
+	c.misses.Add(1)
+	value, err, _ := c.group.Do(key, func() (any, error) {
+		return c.load(ctx, key, load)
+	})
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	return value.(V), nil
+}
+
+// refresh stale なエントリをバックグラウンドで取り直す（同じキーの取り直しが走っていれば何もしない）
+// リクエストのキャンセルに巻き込まれないよう、呼び出し元の ctx は使わない
+func (c *TTL[V]) refresh(key string, load Loader[V]) {
+	go func() {
+		_, err, _ := c.group.Do(key, func() (any, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+			defer cancel()
+			return c.load(ctx, key, load)
+		})
+		if err != nil {
+			log.Printf("Failed to refresh cache entry %q: %v", key, err)
+		}
+	}()
+}
+
+// load 値を取得して保存する。古すぎるエントリはここで捨て、エントリ数が増え続けないようにする
+func (c *TTL[V]) load(ctx context.Context, key string, load Loader[V]) (V, error) {
+	value, err := load(ctx)
+	if err != nil {
+		return value, err
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, e := range c.entries {
+		if now.Sub(e.storedAt) >= 2*c.ttl {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = entry[V]{value: value, storedAt: now}
+	return value, nil
+}
+
+// Clear すべてのエントリを捨てる（ヒット数・ミス数はそのまま）
+func (c *TTL[V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]entry[V])
+}
+
+// Stats ヒット数・ミス数と現在のエントリ数を返す
+func (c *TTL[V]) Stats() Stats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return Stats{Hits: c.hits.Load(), Misses: c.misses.Load(), Entries: len(c.entries)}
+}
@@ -0,0 +1,163 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// tokenRefreshMargin 有効期限のどれだけ前にインストールトークンを更新するか
+const tokenRefreshMargin = time.Minute
+
+// tokenSource リクエストに付与するアクセストークンの取得元
+type tokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// staticToken Personal Access Token（空の場合は未認証）
+type staticToken string
+
+func (t staticToken) Token(ctx context.Context) (string, error) {
+	return string(t), nil
+}
+
+// installationTokenSource GitHub Appのインストールアクセストークンを発行・キャッシュする
+type installationTokenSource struct {
+	client         *Client
+	appID          int64
+	installationID int64
+	privateKey     *rsa.PrivateKey
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewAppClient GitHub App として認証するクライアントを作成する
+// privateKey はAppの秘密鍵（PEM形式）。インストールトークンは有効期限前に自動で更新する
+func NewAppClient(appID int64, privateKey []byte, installationID int64, opts ...Option) (*Client, error) {
+	key, err := parsePrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	c := NewClient("", opts...)
+	c.tokens = &installationTokenSource{
+		client:         c,
+		appID:          appID,
+		installationID: installationID,
+		privateKey:     key,
+	}
+	return c, nil
+}
+
+func (s *installationTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Until(s.expiresAt) > tokenRefreshMargin {
+		return s.token, nil
+	}
+
+	token, expiresAt, err := s.exchange(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.token, s.expiresAt = token, expiresAt
+	return token, nil
+}
+
+// exchange App JWT をインストールアクセストークンに交換する
+func (s *installationTokenSource) exchange(ctx context.Context) (string, time.Time, error) {
+	jwt, err := s.appJWT(time.Now())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	url := fmt.Sprintf("%s/app/installations/%d/access_tokens", s.client.baseURL, s.installationID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to build installation token request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	res, err := s.client.httpClient.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to request installation token: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return "", time.Time{}, &APIError{StatusCode: res.StatusCode, Message: string(body)}
+	}
+
+	var payload struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to decode installation token: %w", err)
+	}
+	return payload.Token, payload.ExpiresAt, nil
+}
+
+// appJWT App認証用のJWT（RS256、有効期限10分未満）を生成する
+func (s *installationTokenSource) appJWT(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]int64{
+		// 時計のずれを考慮して発行時刻を少し過去にする
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": s.appID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	encoding := base64.RawURLEncoding
+	signingInput := encoding.EncodeToString(header) + "." + encoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign app JWT: %w", err)
+	}
+	return signingInput + "." + encoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey PEM形式のRSA秘密鍵（PKCS#1 / PKCS#8）を読み込む
+func parsePrivateKey(pemBytes []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, fmt.Errorf("GitHub App private key is not valid PEM")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub App private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("GitHub App private key must be an RSA key")
+	}
+	return key, nil
+}
@@ -0,0 +1,360 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultBaseURL = "https://api.github.com"
+	perPage        = 100
+)
+
+// Client GitHub REST APIのクライアント
+type Client struct {
+	baseURL          string
+	graphqlURL       string
+	tokens           tokenSource
+	httpClient       *http.Client
+	maxRateLimitWait time.Duration
+	requestLimiter   *rate.Limiter // 全ての呼び出し元で共有するリクエスト数の上限（nil は制限しない）
+
+	mu               sync.Mutex
+	rateLimitResetAt time.Time
+}
+
+// Option クライアントの設定
+type Option func(*Client)
+
+// WithMaxRateLimitWait レート制限時に解除まで待機する最大時間（超える場合は RateLimitError を返す）
+func WithMaxRateLimitWait(d time.Duration) Option {
+	return func(c *Client) {
+		c.maxRateLimitWait = d
+	}
+}
+
+// WithRequestRate 1秒あたりのリクエスト数の上限（0以下は制限しない）
+// 並行に同期してもクライアント全体でこの数を超えないため、GitHubのレート制限を使い切る前に速度を抑えられる
+func WithRequestRate(perSecond float64) Option {
+	return func(c *Client) {
+		if perSecond <= 0 {
+			c.requestLimiter = nil
+			return
+		}
+		c.requestLimiter = rate.NewLimiter(rate.Limit(perSecond), 1)
+	}
+}
+
+// WithHTTPClient 使用するHTTPクライアントを差し替える
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithBaseURL APIのベースURLを変更する（GitHub Enterprise Server 用）。ParseBaseURL で検証した値を渡す
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// ParseBaseURL GITHUB_API_URL のようなAPIのベースURLを検証し、末尾のスラッシュを除いて返す（空文字列は api.github.com）
+// GitHub Enterprise Server はホスト名だけを指定した場合に REST API の /api/v3 を補う
+// github.com を指定した場合は api.github.com にする
+func ParseBaseURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return defaultBaseURL, nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid GitHub API URL %q: %w", raw, err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", fmt.Errorf("invalid GitHub API URL %q: must be an absolute http(s) URL", raw)
+	}
+	if u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return "", fmt.Errorf("invalid GitHub API URL %q: must not contain credentials, a query or a fragment", raw)
+	}
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+	switch host := strings.ToLower(u.Hostname()); {
+	case host == "github.com" || host == "api.github.com":
+		if u.Path != "" {
+			return "", fmt.Errorf("invalid GitHub API URL %q: github.com does not take a path", raw)
+		}
+		return defaultBaseURL, nil
+	case u.Path == "":
+		u.Path = "/api/v3"
+	}
+	return u.String(), nil
+}
+
+// NewClient creates a new GitHub client. token が空の場合は未認証でアクセスする
+func NewClient(token string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:          defaultBaseURL,
+		tokens:           staticToken(token),
+		httpClient:       &http.Client{Timeout: 30 * time.Second},
+		maxRateLimitWait: defaultMaxRateLimitWait,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.graphqlURL = graphQLURL(c.baseURL)
+	return c
+}
+
+// Commit GitHub API の commits レスポンスのうち集計に使う項目
+type Commit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name  string    `json:"name"`
+			Email string    `json:"email"`
+			Date  time.Time `json:"date"`
+		} `json:"author"`
+	} `json:"commit"`
+	Author *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	Parents []struct {
+		SHA string `json:"sha"`
+	} `json:"parents"`
+	// AuthorOffset AnnotateAuthorOffsets が RawData に書き足す作者日時のUTCオフセット（分）。REST API のレスポンスには無い
+	AuthorOffset *int `json:"author_utc_offset,omitempty"`
+}
+
+// IsMerge マージコミット（親が2つ以上）か
+func (c *Commit) IsMerge() bool {
+	return len(c.Parents) > 1
+}
+
+// AuthorUTCOffset 作者の日時のUTCからのずれ（分）
+// REST API の commit.author.date は常にUTCで返るため、AnnotateAuthorOffsets でオフセットを付けたコミットだけ ok が true になる
+func (c *Commit) AuthorUTCOffset() (minutes int, ok bool) {
+	if c.AuthorOffset == nil {
+		return 0, false
+	}
+	return *c.AuthorOffset, true
+}
+
+// AuthoredBy GitHubアカウントの login が作者のコミットか（大文字小文字を区別しない）
+// コミットのメールアドレスがGitHubアカウントに紐づいていない場合は author が null になるため false
+func (c *Commit) AuthoredBy(login string) bool {
+	return c.Author != nil && strings.EqualFold(c.Author.Login, login)
+}
+
+// ParseCommits DayCommits.RawData（commits レスポンスのJSON配列）をコミットに戻す
+func ParseCommits(rawData []byte) ([]Commit, error) {
+	var commits []Commit
+	if err := json.Unmarshal(rawData, &commits); err != nil {
+		return nil, fmt.Errorf("failed to decode commits: %w", err)
+	}
+	return commits, nil
+}
+
+// CommitFilter 日ごとの集計に含めるコミットを選ぶ（nil の場合は全て含める）
+type CommitFilter func(commit *Commit) bool
+
+// DayCommits 1日分（UTC）のコミット
+// 作者日時のオフセットが分かっても日付はUTCで決める（増分同期は取得した日を丸ごと書き直すため、
+// 作者のローカル日付でまとめると取得期間の外の日を一部のコミットだけで上書きしてしまう）
+type DayCommits struct {
+	Date    time.Time
+	Count   int
+	RawData json.RawMessage // その日のコミットのAPIレスポンス（JSON配列）
+}
+
+// FetchCommitsByDay ブランチのコミットを期間で取得し、UTCの日付ごとにまとめる（日付昇順）
+// branch が空の場合はデフォルトブランチ。指定したブランチが存在しない場合は ErrBranchNotFound を返す
+// filter が false を返したコミットは数えず、RawData にも含めない
+func (c *Client) FetchCommitsByDay(ctx context.Context, owner, repo, branch string, since, until time.Time, filter CommitFilter) ([]DayCommits, error) {
+	raws, err := c.listCommits(ctx, owner, repo, branch, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[time.Time][]json.RawMessage)
+	for _, raw := range raws {
+		var commit Commit
+		if err := json.Unmarshal(raw, &commit); err != nil {
+			return nil, fmt.Errorf("failed to decode commit: %w", err)
+		}
+		if filter != nil && !filter(&commit) {
+			continue
+		}
+		d := commit.Commit.Author.Date.UTC()
+		date := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+		byDate[date] = append(byDate[date], raw)
+	}
+
+	days := make([]DayCommits, 0, len(byDate))
+	for date, commits := range byDate {
+		rawData, err := json.Marshal(commits)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode commits: %w", err)
+		}
+		days = append(days, DayCommits{Date: date, Count: len(commits), RawData: rawData})
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date.Before(days[j].Date)
+	})
+	return days, nil
+}
+
+// listCommits 期間内のコミットを全ページ取得する
+func (c *Client) listCommits(ctx context.Context, owner, repo, branch string, since, until time.Time) ([]json.RawMessage, error) {
+	var all []json.RawMessage
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("since", since.UTC().Format(time.RFC3339))
+		query.Set("until", until.UTC().Format(time.RFC3339))
+		query.Set("per_page", fmt.Sprint(perPage))
+		query.Set("page", fmt.Sprint(page))
+		if branch != "" {
+			query.Set("sha", branch)
+		}
+
+		path := fmt.Sprintf("/repos/%s/%s/commits?%s", url.PathEscape(owner), url.PathEscape(repo), query.Encode())
+		var commits []json.RawMessage
+		status, err := c.get(ctx, path, &commits)
+		if err != nil {
+			// 空のリポジトリは 409 を返す
+			if status == http.StatusConflict {
+				return nil, nil
+			}
+			// 存在しないブランチを sha に指定すると 404（または 422）になる
+			if branch != "" && (status == http.StatusNotFound || status == http.StatusUnprocessableEntity) {
+				return nil, fmt.Errorf("%w: %s: %v", ErrBranchNotFound, branch, err)
+			}
+			return nil, err
+		}
+
+		all = append(all, commits...)
+		if len(commits) < perPage {
+			return all, nil
+		}
+	}
+}
+
+// get GETリクエストを送り、JSONレスポンスをoutにデコードする
+// レート制限に達した場合は最大待機時間内であれば解除まで待って再試行する
+func (c *Client) get(ctx context.Context, path string, out any) (int, error) {
+	return c.send(ctx, http.MethodGet, c.baseURL+path, nil, out)
+}
+
+// send get と同じく、レート制限を待って再試行しながら endpoint にリクエストを送る（body は再試行のたびに送り直す）
+func (c *Client) send(ctx context.Context, method, endpoint string, body []byte, out any) (int, error) {
+	for attempt := 0; ; attempt++ {
+		if err := c.waitForRateLimit(ctx, c.blockedUntil()); err != nil {
+			return 0, err
+		}
+		if c.requestLimiter != nil {
+			if err := c.requestLimiter.Wait(ctx); err != nil {
+				return 0, err
+			}
+		}
+
+		res, err := c.request(ctx, method, endpoint, body)
+		if err != nil {
+			return 0, err
+		}
+
+		if resetAt, limited := rateLimitResetAt(res, time.Now()); limited {
+			res.Body.Close()
+			if attempt >= maxRateLimitRetries {
+				return res.StatusCode, &RateLimitError{ResetAt: resetAt}
+			}
+			if err := c.waitForRateLimit(ctx, resetAt); err != nil {
+				return res.StatusCode, err
+			}
+			continue
+		}
+
+		status, err := decodeResponse(res, out)
+		if resetAt, ok := exhaustedUntil(res); ok {
+			c.setBlockedUntil(resetAt)
+		}
+		return status, err
+	}
+}
+
+// do 認証ヘッダー付きでGETリクエストを送る
+func (c *Client) do(ctx context.Context, path string) (*http.Response, error) {
+	return c.request(ctx, http.MethodGet, c.baseURL+path, nil)
+}
+
+// request 認証ヘッダー付きで endpoint にリクエストを送る（body が nil でなければJSONとして送る）
+func (c *Client) request(ctx context.Context, method, endpoint string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GitHub access token: %w", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call GitHub API: %w", err)
+	}
+	return res, nil
+}
+
+// decodeResponse レスポンスボディをoutにデコードし、200以外は APIError を返す
+func decodeResponse(res *http.Response, out any) (int, error) {
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return res.StatusCode, &APIError{StatusCode: res.StatusCode, Message: string(body)}
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return res.StatusCode, fmt.Errorf("failed to decode GitHub response: %w", err)
+	}
+	return res.StatusCode, nil
+}
+
+// ErrBranchNotFound 指定したブランチがリポジトリに存在しない
+var ErrBranchNotFound = errors.New("branch not found")
+
+// APIError GitHub APIが200以外を返したときのエラー
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("GitHub API responded with status %d: %s", e.StatusCode, e.Message)
+}
@@ -0,0 +1,151 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// graphQLBatchSize 1回のクエリで作者日時を問い合わせるコミット数
+const graphQLBatchSize = 100
+
+// graphQLURL REST API のベースURLに対応する GraphQL API のエンドポイント
+// api.github.com は /graphql、GitHub Enterprise Server は /api/v3 の代わりに /api/graphql
+func graphQLURL(baseURL string) string {
+	if prefix, ok := strings.CutSuffix(baseURL, "/api/v3"); ok {
+		return prefix + "/api/graphql"
+	}
+	return baseURL + "/graphql"
+}
+
+// AuthorUTCOffsets コミットの作者日時のUTCオフセット（分）を GraphQL API でまとめて取得する（SHA → オフセット）
+// REST API の commit.author.date はUTCに直して返るが、GraphQL の author.date（GitTimestamp）はコミットに記録されたオフセットのまま返る
+// 見つからないコミットは結果に含めない。GraphQL API は認証が必要なため、トークンが無い場合は APIError になる
+func (c *Client) AuthorUTCOffsets(ctx context.Context, owner, repo string, shas []string) (map[string]int, error) {
+	offsets := make(map[string]int, len(shas))
+	for start := 0; start < len(shas); start += graphQLBatchSize {
+		batch := shas[start:min(start+graphQLBatchSize, len(shas))]
+		if err := c.authorUTCOffsets(ctx, owner, repo, batch, offsets); err != nil {
+			return nil, err
+		}
+	}
+	return offsets, nil
+}
+
+// authorUTCOffsets shas の作者日時を1回のクエリで取得し、offsets に書き込む
+func (c *Client) authorUTCOffsets(ctx context.Context, owner, repo string, shas []string, offsets map[string]int) error {
+	var query strings.Builder
+	query.WriteString("query($owner: String!, $name: String!) { repository(owner: $owner, name: $name) {")
+	for i, sha := range shas {
+		// SHA はクエリに埋め込むため、16進数以外のものは問い合わせない
+		if !isHexSHA(sha) {
+			continue
+		}
+		fmt.Fprintf(&query, " c%d: object(oid: %q) { ... on Commit { author { date } } }", i, sha)
+	}
+	query.WriteString(" } }")
+
+	body, err := json.Marshal(map[string]any{
+		"query":     query.String(),
+		"variables": map[string]string{"owner": owner, "name": repo},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode GraphQL query: %w", err)
+	}
+
+	var res struct {
+		Data struct {
+			Repository map[string]*struct {
+				Author *struct {
+					Date time.Time `json:"date"`
+				} `json:"author"`
+			} `json:"repository"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if _, err := c.send(ctx, http.MethodPost, c.graphqlURL, body, &res); err != nil {
+		return err
+	}
+	if res.Data.Repository == nil {
+		if len(res.Errors) > 0 {
+			return fmt.Errorf("GitHub GraphQL API returned an error for %s/%s: %s", owner, repo, res.Errors[0].Message)
+		}
+		return fmt.Errorf("%w: %s/%s", ErrRepoNotFound, owner, repo)
+	}
+
+	for i, sha := range shas {
+		node := res.Data.Repository[fmt.Sprintf("c%d", i)]
+		if node == nil || node.Author == nil {
+			continue
+		}
+		_, offset := node.Author.Date.Zone()
+		offsets[sha] = offset / 60
+	}
+	return nil
+}
+
+// AnnotateAuthorOffsets days の RawData の各コミットに、GraphQL API で取得した作者日時のUTCオフセット（author_utc_offset）を書き足す
+// 取得できなかったコミットには書き足さない。エラーの場合 days は変更しない
+func (c *Client) AnnotateAuthorOffsets(ctx context.Context, owner, repo string, days []DayCommits) error {
+	commitsByDay := make([][]map[string]json.RawMessage, len(days))
+	var shas []string
+	for i, day := range days {
+		if err := json.Unmarshal(day.RawData, &commitsByDay[i]); err != nil {
+			return fmt.Errorf("failed to decode commits: %w", err)
+		}
+		for _, commit := range commitsByDay[i] {
+			var sha string
+			if err := json.Unmarshal(commit["sha"], &sha); err == nil && sha != "" {
+				shas = append(shas, sha)
+			}
+		}
+	}
+	if len(shas) == 0 {
+		return nil
+	}
+
+	offsets, err := c.AuthorUTCOffsets(ctx, owner, repo, shas)
+	if err != nil {
+		return err
+	}
+
+	rawData := make([]json.RawMessage, len(days))
+	for i, commits := range commitsByDay {
+		for _, commit := range commits {
+			var sha string
+			if err := json.Unmarshal(commit["sha"], &sha); err != nil {
+				continue
+			}
+			if offset, ok := offsets[sha]; ok {
+				commit["author_utc_offset"] = json.RawMessage(fmt.Sprint(offset))
+			}
+		}
+		encoded, err := json.Marshal(commits)
+		if err != nil {
+			return fmt.Errorf("failed to encode commits: %w", err)
+		}
+		rawData[i] = encoded
+	}
+	for i := range days {
+		days[i].RawData = rawData[i]
+	}
+	return nil
+}
+
+// isHexSHA コミットのSHA（16進数）として扱える文字列か
+func isHexSHA(sha string) bool {
+	if sha == "" || len(sha) > 64 {
+		return false
+	}
+	for _, r := range sha {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,190 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGraphQLURL(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		want    string
+	}{
+		{defaultBaseURL, "https://api.github.com/graphql"},
+		{"https://ghe.example.com/api/v3", "https://ghe.example.com/api/graphql"},
+		{"http://127.0.0.1:8080", "http://127.0.0.1:8080/graphql"},
+		{"https://ghe.example.com/github", "https://ghe.example.com/github/graphql"},
+	}
+	for _, tt := range tests {
+		if got := graphQLURL(tt.baseURL); got != tt.want {
+			t.Errorf("graphQLURL(%q) = %q, want %q", tt.baseURL, got, tt.want)
+		}
+	}
+}
+
+// queriedObject クライアントが作るクエリのうちコミット1件分（エイリアスとSHA）
+var queriedObject = regexp.MustCompile(`(c\d+): object\(oid: "([0-9a-f]+)"\)`)
+
+// authorDateServer GraphQL API の代わりに、dates（SHA → author.date）を返すサーバー。dates に無いSHAは null を返す
+func authorDateServer(t *testing.T, dates map[string]string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Method != http.MethodPost || r.URL.Path != "/graphql" {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct {
+			Query     string            `json:"query"`
+			Variables map[string]string `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode GraphQL request: %v", err)
+		}
+		if req.Variables["owner"] != "alice" || req.Variables["name"] != "town" {
+			t.Errorf("variables = %v, want alice/town", req.Variables)
+		}
+		objects := make(map[string]any)
+		for _, match := range queriedObject.FindAllStringSubmatch(req.Query, -1) {
+			if date, ok := dates[match[2]]; ok {
+				objects[match[1]] = map[string]any{"author": map[string]string{"date": date}}
+			} else {
+				objects[match[1]] = nil
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"repository": objects}})
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+// REST API ではUTCになる作者日時のオフセットを GraphQL API から読み、RawData のコミットに書き足す
+func TestClient_AnnotateAuthorOffsets(t *testing.T) {
+	tests := []struct {
+		name   string
+		sha    string
+		date   string // GraphQL API の author.date（空の場合はコミットが見つからない）
+		want   int
+		wantOK bool
+	}{
+		{"+09:00", "a1", "2024-05-01T21:04:05+09:00", 540, true},
+		{"-05:00", "b2", "2024-05-01T07:04:05-05:00", -300, true},
+		{"+05:30", "c3", "2024-05-01T17:34:05+05:30", 330, true},
+		{"Z", "d4", "2024-05-01T12:04:05Z", 0, true},
+		{"not found", "e5", "", 0, false},
+	}
+	dates := make(map[string]string)
+	raws := make([]string, 0, len(tests))
+	for _, tt := range tests {
+		if tt.date != "" {
+			dates[tt.sha] = tt.date
+		}
+		raws = append(raws, fmt.Sprintf(`{"sha":%q,"commit":{"author":{"date":"2024-05-01T12:04:05Z"}}}`, tt.sha))
+	}
+	server, _ := authorDateServer(t, dates)
+	client := NewClient("", WithBaseURL(server.URL))
+
+	days := []DayCommits{{Count: len(raws), RawData: json.RawMessage("[" + strings.Join(raws, ",") + "]")}}
+	if err := client.AnnotateAuthorOffsets(context.Background(), "alice", "town", days); err != nil {
+		t.Fatalf("AnnotateAuthorOffsets returned an error: %v", err)
+	}
+	commits, err := ParseCommits(days[0].RawData)
+	if err != nil {
+		t.Fatalf("ParseCommits returned an error: %v", err)
+	}
+	if len(commits) != len(tests) {
+		t.Fatalf("RawData has %d commits, want %d", len(commits), len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if commits[i].SHA != tt.sha {
+				t.Fatalf("commit %d SHA = %q, want %q", i, commits[i].SHA, tt.sha)
+			}
+			got, ok := commits[i].AuthorUTCOffset()
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("AuthorUTCOffset() = (%d, %v), want (%d, %v)", got, ok, tt.want, tt.wantOK)
+			}
+			if !commits[i].Commit.Author.Date.Equal(commits[0].Commit.Author.Date) {
+				t.Errorf("commit.author.date was changed to %v", commits[i].Commit.Author.Date)
+			}
+		})
+	}
+}
+
+// 100件を超えるコミットは100件ずつ問い合わせる
+func TestClient_AuthorUTCOffsets_Batches(t *testing.T) {
+	dates := make(map[string]string)
+	shas := make([]string, 0, 250)
+	for i := 0; i < 250; i++ {
+		sha := fmt.Sprintf("%x", 0x1000+i)
+		shas = append(shas, sha)
+		dates[sha] = "2024-05-01T21:04:05+09:00"
+	}
+	// 16進数でないSHAはクエリに埋め込まない
+	shas = append(shas, `x") { oid } #`)
+	server, calls := authorDateServer(t, dates)
+	client := NewClient("", WithBaseURL(server.URL))
+
+	offsets, err := client.AuthorUTCOffsets(context.Background(), "alice", "town", shas)
+	if err != nil {
+		t.Fatalf("AuthorUTCOffsets returned an error: %v", err)
+	}
+	if len(offsets) != 250 {
+		t.Errorf("got offsets for %d commits, want 250", len(offsets))
+	}
+	if offsets["1000"] != 540 || offsets[fmt.Sprintf("%x", 0x1000+249)] != 540 {
+		t.Errorf("offsets of the first and last commits = %d, %d, want 540", offsets["1000"], offsets[fmt.Sprintf("%x", 0x1000+249)])
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("GraphQL requests = %d, want 3", got)
+	}
+}
+
+// GraphQL API に失敗した場合はエラーを返し、RawData は変えない
+func TestClient_AnnotateAuthorOffsets_LeavesDaysOnError(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr func(error) bool
+	}{
+		{"unauthenticated", http.StatusUnauthorized, `{"message":"This endpoint requires you to be authenticated."}`, func(err error) bool {
+			var apiErr *APIError
+			return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized
+		}},
+		{"repository not found", http.StatusOK, `{"data":{"repository":null},"errors":[{"type":"NOT_FOUND","message":"Could not resolve to a Repository"}]}`, func(err error) bool {
+			return err != nil && strings.Contains(err.Error(), "Could not resolve to a Repository")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			t.Cleanup(server.Close)
+			client := NewClient("", WithBaseURL(server.URL))
+
+			raw := json.RawMessage(`[{"sha":"a1","commit":{"author":{"date":"2024-05-01T12:04:05Z"}}}]`)
+			days := []DayCommits{{Count: 1, RawData: raw}}
+			err := client.AnnotateAuthorOffsets(context.Background(), "alice", "town", days)
+			if !tt.wantErr(err) {
+				t.Errorf("AnnotateAuthorOffsets error = %v", err)
+			}
+			if string(days[0].RawData) != string(raw) {
+				t.Errorf("RawData = %s, want it unchanged", days[0].RawData)
+			}
+		})
+	}
+}
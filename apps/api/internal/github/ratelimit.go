@@ -0,0 +1,138 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultMaxRateLimitWait レート制限時に待機する最大時間のデフォルト
+const defaultMaxRateLimitWait = time.Minute
+
+// maxRateLimitRetries レート制限で待機・再試行する最大回数
+const maxRateLimitRetries = 3
+
+// RateLimitError レート制限に達し、最大待機時間内に解除されない場合のエラー
+// 呼び出し側は ResetAt 以降に再実行する
+type RateLimitError struct {
+	ResetAt time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("GitHub API rate limit exceeded; resets at %s", e.ResetAt.Format(time.RFC3339))
+}
+
+// rateLimitResetAt レスポンスがレート制限によるものなら解除時刻を返す
+// 403/429 の Retry-After（セカンダリレート制限）、または X-RateLimit-Remaining: 0 を判定する
+func rateLimitResetAt(res *http.Response, now time.Time) (time.Time, bool) {
+	if res.StatusCode != http.StatusForbidden && res.StatusCode != http.StatusTooManyRequests {
+		return time.Time{}, false
+	}
+
+	if retryAfter := res.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return now.Add(time.Duration(seconds) * time.Second), true
+		}
+		if t, err := http.ParseTime(retryAfter); err == nil {
+			return t, true
+		}
+	}
+
+	if res.Header.Get("X-RateLimit-Remaining") == "0" {
+		if resetAt, ok := parseResetHeader(res); ok {
+			return resetAt, true
+		}
+		return now.Add(defaultMaxRateLimitWait), true
+	}
+
+	// 429 はヘッダーが無くてもレート制限として扱う
+	if res.StatusCode == http.StatusTooManyRequests {
+		return now.Add(defaultMaxRateLimitWait), true
+	}
+	return time.Time{}, false
+}
+
+// exhaustedUntil 正常レスポンスで残り回数が0になった場合、その解除時刻を返す
+func exhaustedUntil(res *http.Response) (time.Time, bool) {
+	if res.Header.Get("X-RateLimit-Remaining") != "0" {
+		return time.Time{}, false
+	}
+	return parseResetHeader(res)
+}
+
+// parseResetHeader X-RateLimit-Reset（UNIX秒）を解析する
+func parseResetHeader(res *http.Response) (time.Time, bool) {
+	reset, err := strconv.ParseInt(res.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(reset, 0), true
+}
+
+// waitForRateLimit 解除時刻まで待機する。最大待機時間を超える場合は RateLimitError を返す
+func (c *Client) waitForRateLimit(ctx context.Context, resetAt time.Time) error {
+	wait := time.Until(resetAt)
+	if wait <= 0 {
+		return nil
+	}
+	if wait > c.maxRateLimitWait {
+		return &RateLimitError{ResetAt: resetAt}
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// blockedUntil 前回のレスポンスで残り回数が0になっていれば、その解除時刻を返す
+func (c *Client) blockedUntil() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rateLimitResetAt
+}
+
+// setBlockedUntil 残り回数0の解除時刻を記録する
+func (c *Client) setBlockedUntil(resetAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rateLimitResetAt = resetAt
+}
+
+// RateLimitStatus REST API（core）のレート制限の残り
+type RateLimitStatus struct {
+	Limit     int
+	Remaining int
+	ResetAt   time.Time
+}
+
+// RateLimit /rate_limit で現在のレート制限を取得する（このAPI自体は残りを消費しない）
+// ヘルスチェック用のため、レート制限中でも待機・再試行せずにすぐ返す
+func (c *Client) RateLimit(ctx context.Context) (*RateLimitStatus, error) {
+	res, err := c.do(ctx, "/rate_limit")
+	if err != nil {
+		return nil, err
+	}
+
+	var body struct {
+		Resources struct {
+			Core struct {
+				Limit     int   `json:"limit"`
+				Remaining int   `json:"remaining"`
+				Reset     int64 `json:"reset"`
+			} `json:"core"`
+		} `json:"resources"`
+	}
+	if _, err := decodeResponse(res, &body); err != nil {
+		return nil, err
+	}
+
+	core := body.Resources.Core
+	return &RateLimitStatus{Limit: core.Limit, Remaining: core.Remaining, ResetAt: time.Unix(core.Reset, 0).UTC()}, nil
+}
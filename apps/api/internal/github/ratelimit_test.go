@@ -0,0 +1,147 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimitResetAt(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	reset := now.Add(10 * time.Minute)
+
+	tests := []struct {
+		name    string
+		status  int
+		headers map[string]string
+		want    time.Time
+		limited bool
+	}{
+		{"ok response", http.StatusOK, map[string]string{"X-RateLimit-Remaining": "0"}, time.Time{}, false},
+		{"403 with Retry-After seconds", http.StatusForbidden, map[string]string{"Retry-After": "30"}, now.Add(30 * time.Second), true},
+		{"429 with Retry-After date", http.StatusTooManyRequests, map[string]string{"Retry-After": reset.Format(http.TimeFormat)}, reset, true},
+		{"403 with exhausted primary limit", http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": strconv.FormatInt(reset.Unix(), 10)}, reset, true},
+		{"403 exhausted without reset", http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0"}, now.Add(defaultMaxRateLimitWait), true},
+		{"429 without headers", http.StatusTooManyRequests, nil, now.Add(defaultMaxRateLimitWait), true},
+		{"403 permission error", http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "42"}, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			for k, v := range tt.headers {
+				res.Header.Set(k, v)
+			}
+			got, limited := rateLimitResetAt(res, now)
+			if limited != tt.limited || !got.Equal(tt.want) {
+				t.Errorf("rateLimitResetAt = (%v, %v), want (%v, %v)", got, limited, tt.want, tt.limited)
+			}
+		})
+	}
+}
+
+// secondaryLimitServer 最初の limited 回は 403 と Retry-After を返し、その後はリポジトリを返す
+func secondaryLimitServer(t *testing.T, limited int32, retryAfter string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= limited {
+			w.Header().Set("Retry-After", retryAfter)
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message":"You have exceeded a secondary rate limit."}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"town","owner":{"login":"alice"},"private":false,"default_branch":"main"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestClient_RetriesAfterSecondaryRateLimit(t *testing.T) {
+	server, calls := secondaryLimitServer(t, 1, "1")
+	client := NewClient("", WithBaseURL(server.URL), WithMaxRateLimitWait(5*time.Second))
+
+	start := time.Now()
+	repo, err := client.GetRepo(context.Background(), "alice", "town")
+	if err != nil {
+		t.Fatalf("GetRepo returned an error: %v", err)
+	}
+	if repo.Name != "town" {
+		t.Errorf("Name = %q, want town", repo.Name)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+	if waited := time.Since(start); waited < 900*time.Millisecond {
+		t.Errorf("retried after %v, want to wait for Retry-After", waited)
+	}
+}
+
+func TestClient_ReturnsRateLimitErrorBeyondMaxWait(t *testing.T) {
+	server, calls := secondaryLimitServer(t, 1, "120")
+	client := NewClient("", WithBaseURL(server.URL), WithMaxRateLimitWait(time.Second))
+
+	start := time.Now()
+	_, err := client.GetRepo(context.Background(), "alice", "town")
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("GetRepo error = %v, want *RateLimitError", err)
+	}
+	if until := time.Until(rateLimitErr.ResetAt); until < 100*time.Second || until > 120*time.Second {
+		t.Errorf("ResetAt is %v from now, want about 120s", until)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+	if waited := time.Since(start); waited > 500*time.Millisecond {
+		t.Errorf("blocked for %v, want to return without waiting", waited)
+	}
+}
+
+func TestClient_GivesUpAfterMaxRetries(t *testing.T) {
+	server, calls := secondaryLimitServer(t, maxRateLimitRetries+1, "0")
+	client := NewClient("", WithBaseURL(server.URL))
+
+	_, err := client.GetRepo(context.Background(), "alice", "town")
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("GetRepo error = %v, want *RateLimitError", err)
+	}
+	if got, want := calls.Load(), int32(maxRateLimitRetries+1); got != want {
+		t.Errorf("requests = %d, want %d", got, want)
+	}
+}
+
+// WithRequestRate の上限は並行に呼び出してもクライアント全体で共有する
+func TestClient_RequestRateIsSharedAcrossGoroutines(t *testing.T) {
+	server, calls := secondaryLimitServer(t, 0, "")
+	client := NewClient("", WithBaseURL(server.URL), WithRequestRate(20))
+
+	const requests = 5
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetRepo(context.Background(), "alice", "town"); err != nil {
+				t.Errorf("GetRepo returned an error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := calls.Load(); got != requests {
+		t.Errorf("requests = %d, want %d", got, requests)
+	}
+	// バーストは1件なので、残りの4件は50msずつ待つ
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Errorf("%d concurrent requests took %v, want at least 200ms at 20 requests per second", requests, elapsed)
+	}
+}
@@ -0,0 +1,68 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ErrUserNotFound 指定したGitHubユーザーが存在しない
+var ErrUserNotFound = errors.New("GitHub user not found")
+
+// ErrRepoNotFound リポジトリが存在しない、または非公開でアクセスできない（GitHubはどちらも404を返す）
+var ErrRepoNotFound = errors.New("GitHub repository not found or not accessible")
+
+// Repo GitHub API の repos レスポンスのうち登録に使う項目
+type Repo struct {
+	Name  string `json:"name"`
+	Owner struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+	Private       bool   `json:"private"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+// ListUserRepos ユーザーがオーナーの公開リポジトリを全ページ取得する（名前順）
+// ユーザーが存在しない場合は ErrUserNotFound を返す
+func (c *Client) ListUserRepos(ctx context.Context, username string) ([]Repo, error) {
+	var all []Repo
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("type", "owner")
+		query.Set("sort", "full_name")
+		query.Set("per_page", fmt.Sprint(perPage))
+		query.Set("page", fmt.Sprint(page))
+
+		path := fmt.Sprintf("/users/%s/repos?%s", url.PathEscape(username), query.Encode())
+		var repos []Repo
+		status, err := c.get(ctx, path, &repos)
+		if err != nil {
+			if status == http.StatusNotFound {
+				return nil, fmt.Errorf("%w: %s", ErrUserNotFound, username)
+			}
+			return nil, err
+		}
+
+		all = append(all, repos...)
+		if len(repos) < perPage {
+			return all, nil
+		}
+	}
+}
+
+// GetRepo リポジトリの現在の情報（公開設定など）を取得する
+// 存在しない・アクセスできない場合は ErrRepoNotFound を返す
+func (c *Client) GetRepo(ctx context.Context, owner, repo string) (*Repo, error) {
+	var res Repo
+	path := fmt.Sprintf("/repos/%s/%s", url.PathEscape(owner), url.PathEscape(repo))
+	status, err := c.get(ctx, path, &res)
+	if err != nil {
+		if status == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s/%s", ErrRepoNotFound, owner, repo)
+		}
+		return nil, err
+	}
+	return &res, nil
+}
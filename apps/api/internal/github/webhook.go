@@ -0,0 +1,141 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Webhook の X-GitHub-Event ヘッダーの値
+const (
+	EventPing = "ping"
+	EventPush = "push"
+)
+
+const (
+	// HeaderWebhookEvent Webhookのイベントの種類
+	HeaderWebhookEvent = "X-GitHub-Event"
+	// HeaderWebhookSignature ボディの HMAC-SHA256（"sha256=<hex>"）。Webhookに設定したシークレットで計算される
+	HeaderWebhookSignature = "X-Hub-Signature-256"
+)
+
+// ErrInvalidWebhookPayload Webhookのボディが JSON として読めない、または必要な項目が無い（受け口では400にする）
+var ErrInvalidWebhookPayload = errors.New("invalid webhook payload")
+
+// ErrUnsupportedWebhookEvent 扱わない種類のイベント（受け口では無視して2xxを返す）
+var ErrUnsupportedWebhookEvent = errors.New("unsupported webhook event")
+
+// PushEvent push イベントのうち集計に使う項目
+type PushEvent struct {
+	Ref        string `json:"ref"`
+	Repository struct {
+		Name          string `json:"name"`
+		DefaultBranch string `json:"default_branch"`
+		Owner         struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+	Commits []PushCommit `json:"commits"`
+}
+
+// PushCommit push イベントに含まれるコミット
+type PushCommit struct {
+	ID        string    `json:"id"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+	Author    struct {
+		Name     string `json:"name"`
+		Username string `json:"username"`
+	} `json:"author"`
+}
+
+// pushEventFields 必須項目が「無い」のか「空」なのかを区別するためのデコード先
+// GitHubはイベントに項目を追加することがあるため、DisallowUnknownFields は使わずに必要な項目だけを確認する
+type pushEventFields struct {
+	Repository *struct {
+		Name  *string `json:"name"`
+		Owner *struct {
+			Login *string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+	Commits *[]json.RawMessage `json:"commits"`
+}
+
+// ParseWebhook X-GitHub-Event の event とボディを検証して読む
+//
+//   - ping は nil, nil を返す（Webhookの登録確認のため、受け口では何もせずに2xxを返す）
+//   - push は repository.owner.login・repository.name・commits が無い場合に ErrInvalidWebhookPayload を返す
+//   - それ以外のイベントは ErrUnsupportedWebhookEvent を返す
+//
+// 巨大なボディは読む前に受け口のボディサイズ上限（API_BODY_LIMIT）で弾くこと
+func ParseWebhook(event string, body []byte) (*PushEvent, error) {
+	switch event {
+	case EventPing:
+		if !json.Valid(body) {
+			return nil, fmt.Errorf("%w: body is not valid JSON", ErrInvalidWebhookPayload)
+		}
+		return nil, nil
+	case EventPush:
+		return parsePushEvent(body)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedWebhookEvent, event)
+	}
+}
+
+// parsePushEvent push イベントの必須項目を確認してから読む
+func parsePushEvent(body []byte) (*PushEvent, error) {
+	var fields pushEventFields
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhookPayload, err)
+	}
+
+	var missing []string
+	if fields.Repository == nil || fields.Repository.Owner == nil || fields.Repository.Owner.Login == nil || *fields.Repository.Owner.Login == "" {
+		missing = append(missing, "repository.owner.login")
+	}
+	if fields.Repository == nil || fields.Repository.Name == nil || *fields.Repository.Name == "" {
+		missing = append(missing, "repository.name")
+	}
+	if fields.Commits == nil {
+		missing = append(missing, "commits")
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: missing %s", ErrInvalidWebhookPayload, strings.Join(missing, ", "))
+	}
+
+	var push PushEvent
+	if err := json.Unmarshal(body, &push); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhookPayload, err)
+	}
+	return &push, nil
+}
+
+// Branch push されたブランチ名（タグなどブランチ以外の push では空）
+func (push *PushEvent) Branch() string {
+	branch, ok := strings.CutPrefix(push.Ref, "refs/heads/")
+	if !ok {
+		return ""
+	}
+	return branch
+}
+
+// VerifyWebhookSignature X-Hub-Signature-256 の signature が secret で計算したボディの署名と一致するか
+// 比較は時間が一定になるよう hmac.Equal で行う
+func VerifyWebhookSignature(secret string, body []byte, signature string) bool {
+	digest, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok || secret == "" {
+		return false
+	}
+	got, err := hex.DecodeString(digest)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
@@ -0,0 +1,131 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+// readFixture testdata のファイルを読む
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("failed to read fixture %s: %v", name, err)
+	}
+	return body
+}
+
+func TestParseWebhook(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    string
+		body     []byte
+		wantErr  error
+		wantPush bool
+	}{
+		{"valid push", EventPush, readFixture(t, "webhook_push.json"), nil, true},
+		{"ping", EventPing, readFixture(t, "webhook_ping.json"), nil, false},
+		{"malformed push", EventPush, readFixture(t, "webhook_malformed.json"), ErrInvalidWebhookPayload, false},
+		{"malformed ping", EventPing, readFixture(t, "webhook_malformed.json"), ErrInvalidWebhookPayload, false},
+		{"push without commits", EventPush, []byte(`{"repository":{"name":"town","owner":{"login":"alice"}}}`), ErrInvalidWebhookPayload, false},
+		{"push without owner", EventPush, []byte(`{"repository":{"name":"town"},"commits":[]}`), ErrInvalidWebhookPayload, false},
+		{"push with empty name", EventPush, []byte(`{"repository":{"name":"","owner":{"login":"alice"}},"commits":[]}`), ErrInvalidWebhookPayload, false},
+		{"push with null commits", EventPush, []byte(`{"repository":{"name":"town","owner":{"login":"alice"}},"commits":null}`), ErrInvalidWebhookPayload, false},
+		{"push with empty commits", EventPush, []byte(`{"repository":{"name":"town","owner":{"login":"alice"}},"commits":[]}`), nil, true},
+		{"unsupported event", "issues", readFixture(t, "webhook_push.json"), ErrUnsupportedWebhookEvent, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			push, err := ParseWebhook(tt.event, tt.body)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if (push != nil) != tt.wantPush {
+				t.Errorf("push = %+v, want a push event: %v", push, tt.wantPush)
+			}
+		})
+	}
+}
+
+func TestParseWebhook_PushFixture(t *testing.T) {
+	push, err := ParseWebhook(EventPush, readFixture(t, "webhook_push.json"))
+	if err != nil {
+		t.Fatalf("ParseWebhook returned an error: %v", err)
+	}
+	if push.Repository.Owner.Login != "Alice" || push.Repository.Name != "Town" || push.Repository.DefaultBranch != "main" {
+		t.Errorf("repository = %+v, want Alice/Town on main", push.Repository)
+	}
+	if push.Branch() != "main" {
+		t.Errorf("Branch() = %q, want main", push.Branch())
+	}
+	if len(push.Commits) != 1 {
+		t.Fatalf("commits = %d, want 1", len(push.Commits))
+	}
+	commit := push.Commits[0]
+	wantTime := time.Date(2024, 5, 1, 12, 4, 5, 0, time.UTC)
+	if commit.ID != "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c" || commit.Author.Username != "Alice" || !commit.Timestamp.Equal(wantTime) {
+		t.Errorf("commit = %+v, want Alice's commit at %v", commit, wantTime)
+	}
+}
+
+func TestPushEvent_Branch(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"refs/heads/main", "main"},
+		{"refs/heads/feature/town", "feature/town"},
+		{"refs/tags/v1.0.0", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		push := &PushEvent{Ref: tt.ref}
+		if got := push.Branch(); got != tt.want {
+			t.Errorf("Branch() for %q = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := readFixture(t, "webhook_push.json")
+	const secret = "It's a Secret to Everybody"
+	// GitHubのドキュメントの例（シークレットとボディ "Hello, World!" の署名）
+	const documented = "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"
+
+	tests := []struct {
+		name      string
+		secret    string
+		body      []byte
+		signature string
+		want      bool
+	}{
+		{"documented example", secret, []byte("Hello, World!"), documented, true},
+		{"signed fixture", "s3cret", body, sign("s3cret", body), true},
+		{"wrong secret", "other", body, sign("s3cret", body), false},
+		{"tampered body", "s3cret", append([]byte(" "), body...), sign("s3cret", body), false},
+		{"missing prefix", secret, []byte("Hello, World!"), documented[len("sha256="):], false},
+		{"sha1 signature", secret, []byte("Hello, World!"), "sha1=" + documented[len("sha256="):], false},
+		{"not hex", secret, []byte("Hello, World!"), "sha256=zz", false},
+		{"empty signature", secret, []byte("Hello, World!"), "", false},
+		{"empty secret", "", []byte("Hello, World!"), sign("", []byte("Hello, World!")), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyWebhookSignature(tt.secret, tt.body, tt.signature); got != tt.want {
+				t.Errorf("VerifyWebhookSignature = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// sign secret でボディに署名した X-Hub-Signature-256 の値
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,296 @@
+// Package githubtest GitHub REST API（と作者日時を問い合わせる GraphQL API）の代わりに使うテスト用のサーバー
+package githubtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/keeee21/commit-town/api/internal/github"
+)
+
+// Commit サーバーが返すコミット
+type Commit struct {
+	SHA         string
+	Message     string
+	Date        time.Time // commit.author.date（GitHubと同じく REST API ではUTC、GraphQL API では Date のオフセットのまま返す）
+	AuthorLogin string    // 空の場合は author を null で返す（GitHubアカウントに紐づかないメールアドレス）
+	Parents     int       // 2以上はマージコミット
+}
+
+// Repo サーバーが返すリポジトリ
+type Repo struct {
+	Private       bool
+	DefaultBranch string
+	Commits       []Commit
+	IgnoreUntil   bool // until を無視して未来の日付のコミットも返す（時計のずれや不正なレスポンスの再現）
+	FailCommits   bool // commits に500を返す（GitHub側の障害の再現）
+	FailGraphQL   bool // GraphQL API に401を返す（未認証のクライアントの再現）
+}
+
+// Server 登録したリポジトリの repos・commits と、GraphQL API でコミットの作者日時を返すサーバー。登録していないリポジトリは404を返す
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	repos    map[string]*Repo
+	requests map[string]int // パスごとのリクエスト数（クエリは含まない）
+	latency  time.Duration
+	inFlight int
+	peak     int // 同時に処理していたリクエスト数の最大
+}
+
+// NewServer サーバーを起動する（テストの終了時に止める）
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{repos: make(map[string]*Repo), requests: make(map[string]int)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Client このサーバーにアクセスする未認証のクライアント
+func (s *Server) Client(opts ...github.Option) *github.Client {
+	return github.NewClient("", append([]github.Option{github.WithBaseURL(s.URL)}, opts...)...)
+}
+
+// SetRepo リポジトリを登録する（登録済みの場合は置き換える）
+func (s *Server) SetRepo(owner, name string, repo Repo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repos[repoKey(owner, name)] = &repo
+}
+
+// RemoveRepo リポジトリを消す（以降は404を返す）
+func (s *Server) RemoveRepo(owner, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.repos, repoKey(owner, name))
+}
+
+// SetLatency 全てのレスポンスを latency だけ遅らせる（並行数の確認用）
+func (s *Server) SetLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = latency
+}
+
+// MaxInFlight 同時に処理していたリクエスト数の最大
+func (s *Server) MaxInFlight() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.peak
+}
+
+// Requests path へのリクエスト数
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+// CommitRequests owner/name の commits へのリクエスト数
+func (s *Server) CommitRequests(owner, name string) int {
+	return s.Requests("/repos/" + owner + "/" + name + "/commits")
+}
+
+func repoKey(owner, name string) string {
+	return strings.ToLower(owner + "/" + name)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests[r.URL.Path]++
+	s.inFlight++
+	s.peak = max(s.peak, s.inFlight)
+	latency := s.latency
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	}()
+	time.Sleep(latency)
+
+	if r.Method == http.MethodPost && r.URL.Path == "/graphql" {
+		s.handleGraphQL(w, r)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if r.Method != http.MethodGet || len(parts) < 3 || parts[0] != "repos" {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	repo, ok := s.repos[repoKey(parts[1], parts[2])]
+	var copied Repo
+	if ok {
+		copied = *repo
+		copied.Commits = append([]Commit(nil), repo.Commits...)
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return
+	}
+
+	switch {
+	case len(parts) == 3:
+		writeRepo(w, parts[1], parts[2], copied)
+	case len(parts) == 4 && parts[3] == "commits" && copied.FailCommits:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"message": "Server Error"})
+	case len(parts) == 4 && parts[3] == "commits":
+		writeCommits(w, r, copied)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeRepo(w http.ResponseWriter, owner, name string, repo Repo) {
+	branch := repo.DefaultBranch
+	if branch == "" {
+		branch = "main"
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"name":           name,
+		"owner":          map[string]string{"login": owner},
+		"private":        repo.Private,
+		"default_branch": branch,
+	})
+}
+
+// writeCommits since〜until のコミットを新しい順にページ分けして返す
+func writeCommits(w http.ResponseWriter, r *http.Request, repo Repo) {
+	query := r.URL.Query()
+	since, _ := time.Parse(time.RFC3339, query.Get("since"))
+	until, _ := time.Parse(time.RFC3339, query.Get("until"))
+	perPage, _ := strconv.Atoi(query.Get("per_page"))
+	if perPage <= 0 {
+		perPage = 30
+	}
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+
+	matched := make([]int, 0, len(repo.Commits))
+	for i, commit := range repo.Commits {
+		if !since.IsZero() && commit.Date.Before(since) {
+			continue
+		}
+		if !repo.IgnoreUntil && !until.IsZero() && commit.Date.After(until) {
+			continue
+		}
+		matched = append(matched, i)
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return repo.Commits[matched[i]].Date.After(repo.Commits[matched[j]].Date)
+	})
+
+	start := min((page-1)*perPage, len(matched))
+	end := min(start+perPage, len(matched))
+	body := make([]map[string]any, 0, end-start)
+	for _, index := range matched[start:end] {
+		body = append(body, commitJSON(repo.Commits[index], index))
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+// commitSHA commit.SHA が空の場合は、登録した順番（index）から決まったSHAを作る（REST API と GraphQL API で同じになる）
+func commitSHA(commit Commit, index int) string {
+	if commit.SHA != "" {
+		return commit.SHA
+	}
+	return strconv.FormatInt(commit.Date.UnixNano(), 16) + strconv.Itoa(index)
+}
+
+func commitJSON(commit Commit, index int) map[string]any {
+	sha := commitSHA(commit, index)
+	var author any
+	if commit.AuthorLogin != "" {
+		author = map[string]string{"login": commit.AuthorLogin}
+	}
+	parents := make([]map[string]string, 0, commit.Parents)
+	for i := 0; i < commit.Parents; i++ {
+		parents = append(parents, map[string]string{"sha": sha + "-parent" + strconv.Itoa(i)})
+	}
+	return map[string]any{
+		"sha": sha,
+		"commit": map[string]any{
+			"message": commit.Message,
+			"author": map[string]string{
+				"name":  commit.AuthorLogin,
+				"email": commit.AuthorLogin + "@example.com",
+				"date":  commit.Date.UTC().Format(time.RFC3339),
+			},
+		},
+		"author":  author,
+		"parents": parents,
+	}
+}
+
+// graphQLObject クライアントが作るクエリのうち、作者日時を問い合わせる1件分（エイリアスとSHA）
+var graphQLObject = regexp.MustCompile(`(c\d+): object\(oid: "([0-9a-fA-F]+)"\)`)
+
+// handleGraphQL repository(owner, name) の object(oid) ごとに author.date を返す。見つからないSHAは null
+func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Query     string `json:"query"`
+		Variables struct {
+			Owner string `json:"owner"`
+			Name  string `json:"name"`
+		} `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": "Problems parsing JSON"})
+		return
+	}
+
+	s.mu.Lock()
+	repo, ok := s.repos[repoKey(req.Variables.Owner, req.Variables.Name)]
+	dates := make(map[string]time.Time)
+	failed := ok && repo.FailGraphQL
+	if ok {
+		for i, commit := range repo.Commits {
+			dates[commitSHA(commit, i)] = commit.Date
+		}
+	}
+	s.mu.Unlock()
+	if failed {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "This endpoint requires you to be authenticated."})
+		return
+	}
+	if !ok {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"data":   map[string]any{"repository": nil},
+			"errors": []map[string]string{{"type": "NOT_FOUND", "message": "Could not resolve to a Repository"}},
+		})
+		return
+	}
+
+	objects := make(map[string]any)
+	for _, match := range graphQLObject.FindAllStringSubmatch(req.Query, -1) {
+		date, ok := dates[match[2]]
+		if !ok {
+			objects[match[1]] = nil
+			continue
+		}
+		objects[match[1]] = map[string]any{"author": map[string]string{"date": date.Format(time.RFC3339)}}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": map[string]any{"repository": objects}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
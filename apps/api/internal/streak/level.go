@@ -0,0 +1,73 @@
+package streak
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Level 継続中のstreakの長さに応じた称号
+type Level struct {
+	Code string // フロントエンドが分岐に使う値（seedling など）
+	Name string // 表示名
+}
+
+// 称号（短い順）
+var (
+	LevelSeedling = Level{Code: "seedling", Name: "Seedling"}
+	LevelSapling  = Level{Code: "sapling", Name: "Sapling"}
+	LevelTree     = Level{Code: "tree", Name: "Tree"}
+	LevelForest   = Level{Code: "forest", Name: "Forest"}
+)
+
+// Levels 各称号になるstreakの最短日数（Seedling は0日から）
+type Levels struct {
+	Sapling int
+	Tree    int
+	Forest  int
+}
+
+// DefaultLevels Seedling 0〜6日、Sapling 7〜29日、Tree 30〜99日、Forest 100日以上
+var DefaultLevels = Levels{Sapling: 7, Tree: 30, Forest: 100}
+
+// LevelFor DefaultLevels での称号
+func LevelFor(length int) Level {
+	return DefaultLevels.For(length)
+}
+
+// For streakの長さ（日数）に応じた称号を返す
+func (l Levels) For(length int) Level {
+	switch {
+	case length >= l.Forest:
+		return LevelForest
+	case length >= l.Tree:
+		return LevelTree
+	case length >= l.Sapling:
+		return LevelSapling
+	}
+	return LevelSeedling
+}
+
+// ParseLevels "7,30,100" のような Sapling・Tree・Forest の最短日数を読み取る（空文字列は DefaultLevels）
+func ParseLevels(s string) (Levels, error) {
+	if strings.TrimSpace(s) == "" {
+		return DefaultLevels, nil
+	}
+
+	parts := strings.Split(s, ",")
+	if len(parts) != 3 {
+		return Levels{}, fmt.Errorf("streak levels must be three comma-separated day counts, got %q", s)
+	}
+	var days [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n < 1 {
+			return Levels{}, fmt.Errorf("streak level %q must be a positive integer", part)
+		}
+		if i > 0 && n <= days[i-1] {
+			return Levels{}, fmt.Errorf("streak levels must be increasing, got %q", s)
+		}
+		days[i] = n
+	}
+	return Levels{Sapling: days[0], Tree: days[1], Forest: days[2]}, nil
+}
@@ -0,0 +1,70 @@
+package streak
+
+import "testing"
+
+func TestLevelFor(t *testing.T) {
+	tests := []struct {
+		length int
+		want   Level
+	}{
+		{0, LevelSeedling},
+		{1, LevelSeedling},
+		{6, LevelSeedling},
+		{7, LevelSapling},
+		{29, LevelSapling},
+		{30, LevelTree},
+		{99, LevelTree},
+		{100, LevelForest},
+		{1000, LevelForest},
+	}
+	for _, tt := range tests {
+		if got := LevelFor(tt.length); got != tt.want {
+			t.Errorf("LevelFor(%d) = %v, want %v", tt.length, got, tt.want)
+		}
+	}
+}
+
+func TestLevels_For_CustomThresholds(t *testing.T) {
+	levels := Levels{Sapling: 3, Tree: 10, Forest: 20}
+	tests := []struct {
+		length int
+		want   Level
+	}{
+		{2, LevelSeedling},
+		{3, LevelSapling},
+		{9, LevelSapling},
+		{10, LevelTree},
+		{19, LevelTree},
+		{20, LevelForest},
+	}
+	for _, tt := range tests {
+		if got := levels.For(tt.length); got != tt.want {
+			t.Errorf("For(%d) = %v, want %v", tt.length, got, tt.want)
+		}
+	}
+}
+
+func TestParseLevels(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Levels
+		wantErr bool
+	}{
+		{"", DefaultLevels, false},
+		{"  ", DefaultLevels, false},
+		{"3,10,20", Levels{Sapling: 3, Tree: 10, Forest: 20}, false},
+		{" 3 , 10 , 20 ", Levels{Sapling: 3, Tree: 10, Forest: 20}, false},
+		{"3,10", Levels{}, true},
+		{"3,10,20,40", Levels{}, true},
+		{"0,10,20", Levels{}, true},
+		{"3,x,20", Levels{}, true},
+		{"10,10,20", Levels{}, true},
+		{"3,20,10", Levels{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevels(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseLevels(%q) = (%+v, %v), want (%+v, error %t)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
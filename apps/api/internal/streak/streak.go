@@ -0,0 +1,100 @@
+package streak
+
+import "time"
+
+// DayCount 1日分のコミット数（Date はUTCの0時0分）
+type DayCount struct {
+	Date  time.Time
+	Count int
+}
+
+// Run コミットが連続した期間
+type Run struct {
+	Start  time.Time
+	End    time.Time // 最後にコミットした日
+	Length int       // コミットした日数（猶予で許した休みの日・凍結した日は含まない）
+}
+
+// Freeze streakを凍結した期間（両端を含む、UTCの0時0分）
+// 凍結した日はコミットが無くても連続期間を途切れさせず、猶予日数も消費しない
+type Freeze struct {
+	Start time.Time
+	End   time.Time
+}
+
+// covers date が凍結期間に含まれるか
+func (f Freeze) covers(date time.Time) bool {
+	return !date.Before(f.Start) && !date.After(f.End)
+}
+
+// ComputeRuns 日付昇順の日別コミット数から、コミットが1件以上ある日の連続期間を組み立てる
+// graceDays 日以下の休みは連続期間を途切れさせない（0 の場合は1日でも休むと途切れる）
+func ComputeRuns(days []DayCount, graceDays int) []Run {
+	return ComputeRunsWithFreezes(days, graceDays, nil)
+}
+
+// ComputeRunsWithFreezes ComputeRuns と同じだが、freezes に含まれる休みの日は数えない（途切れも伸びもしない）
+func ComputeRunsWithFreezes(days []DayCount, graceDays int, freezes []Freeze) []Run {
+	var runs []Run
+	for _, day := range days {
+		if day.Count <= 0 {
+			continue
+		}
+		date := truncateToDate(day.Date)
+		if n := len(runs); n > 0 {
+			last := &runs[n-1]
+			if date.Equal(last.End) {
+				continue
+			}
+			if missedDays(last.End, date, freezes, graceDays) <= graceDays {
+				last.End = date
+				last.Length++
+				continue
+			}
+		}
+		runs = append(runs, Run{Start: date, End: date, Length: 1})
+	}
+	return runs
+}
+
+// ActiveOn 今日または昨日（猶予がある場合はさらに graceDays 日前）まで続いていれば継続中とみなす
+func (r Run) ActiveOn(today time.Time, graceDays int) bool {
+	return r.ActiveOnWithFreezes(today, graceDays, nil)
+}
+
+// ActiveOnWithFreezes ActiveOn と同じだが、最後のコミットから昨日までの凍結した日は休みに数えない
+func (r Run) ActiveOnWithFreezes(today time.Time, graceDays int, freezes []Freeze) bool {
+	return missedDays(r.End, truncateToDate(today), freezes, graceDays) <= graceDays
+}
+
+// missedDays from と to の間（両端を含まない）の、凍結されていない日数
+// limit を超えた時点で数えるのをやめる（長い空白を1日ずつ数えないため）
+func missedDays(from, to time.Time, freezes []Freeze, limit int) int {
+	if len(freezes) == 0 {
+		gap := int(to.Sub(from).Hours()/24) - 1
+		return max(gap, 0)
+	}
+
+	missed := 0
+	for date := from.AddDate(0, 0, 1); date.Before(to) && missed <= limit; date = date.AddDate(0, 0, 1) {
+		if !frozen(date, freezes) {
+			missed++
+		}
+	}
+	return missed
+}
+
+func frozen(date time.Time, freezes []Freeze) bool {
+	for _, freeze := range freezes {
+		if freeze.covers(date) {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateToDate UTCの日付（0時0分）に丸める
+func truncateToDate(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,87 @@
+package streak
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// day 2024年5月 d 日（UTCの0時0分）
+func day(d int) time.Time {
+	return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC)
+}
+
+func counts(days ...int) []DayCount {
+	result := make([]DayCount, 0, len(days))
+	for _, d := range days {
+		result = append(result, DayCount{Date: day(d), Count: 1})
+	}
+	return result
+}
+
+func TestComputeRunsWithFreezes(t *testing.T) {
+	tests := []struct {
+		name      string
+		days      []DayCount
+		graceDays int
+		freezes   []Freeze
+		want      []Run
+	}{
+		{"no days", nil, 0, nil, nil},
+		{"consecutive days", counts(1, 2, 3), 0, nil, []Run{{day(1), day(3), 3}}},
+		{"gap breaks the run", counts(1, 2, 4), 0, nil, []Run{{day(1), day(2), 2}, {day(4), day(4), 1}}},
+		{"gap within grace", counts(1, 2, 4), 1, nil, []Run{{day(1), day(4), 3}}},
+		{"gap beyond grace", counts(1, 4), 1, nil, []Run{{day(1), day(1), 1}, {day(4), day(4), 1}}},
+		{
+			name: "zero-commit days are rest days",
+			days: []DayCount{{day(1), 2}, {day(2), 0}, {day(3), 1}},
+			want: []Run{{day(1), day(1), 1}, {day(3), day(3), 1}},
+		},
+		{
+			name: "same day twice counts once",
+			days: []DayCount{{day(1), 1}, {day(1).Add(5 * time.Hour), 1}, {day(2), 1}},
+			want: []Run{{day(1), day(2), 2}},
+		},
+		{"frozen gap keeps the run without lengthening it", counts(1, 5), 0, []Freeze{{day(2), day(4)}}, []Run{{day(1), day(5), 2}}},
+		{"partly frozen gap uses grace", counts(1, 5), 1, []Freeze{{day(2), day(3)}}, []Run{{day(1), day(5), 2}}},
+		{"partly frozen gap beyond grace", counts(1, 5), 0, []Freeze{{day(2), day(3)}}, []Run{{day(1), day(1), 1}, {day(5), day(5), 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeRunsWithFreezes(tt.days, tt.graceDays, tt.freezes)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ComputeRunsWithFreezes = %v, want %v", got, tt.want)
+			}
+			if tt.freezes == nil {
+				if got := ComputeRuns(tt.days, tt.graceDays); !slices.Equal(got, tt.want) {
+					t.Errorf("ComputeRuns = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRun_ActiveOnWithFreezes(t *testing.T) {
+	run := Run{Start: day(1), End: day(10), Length: 10}
+	tests := []struct {
+		name      string
+		today     time.Time
+		graceDays int
+		freezes   []Freeze
+		want      bool
+	}{
+		{"committed today", day(10), 0, nil, true},
+		{"committed yesterday", day(11).Add(23 * time.Hour), 0, nil, true},
+		{"missed a day", day(12), 0, nil, false},
+		{"missed a day within grace", day(12), 1, nil, true},
+		{"missed days were frozen", day(14), 0, []Freeze{{day(11), day(13)}}, true},
+		{"missed a day after the freeze", day(15), 0, []Freeze{{day(11), day(13)}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := run.ActiveOnWithFreezes(tt.today, tt.graceDays, tt.freezes); got != tt.want {
+				t.Errorf("ActiveOnWithFreezes(%s) = %v, want %v", tt.today.Format("2006-01-02"), got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Package testdb DBを使うテストのためのヘルパー
+package testdb
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/keeee21/commit-town/api/db"
+	"github.com/keeee21/commit-town/api/migrations"
+	"gorm.io/gorm"
+)
+
+// EnvDatabaseURL テストに使うPostgresの接続文字列を指定する環境変数（本番・開発用のDBは指定しないこと）
+const EnvDatabaseURL = "TEST_DATABASE_URL"
+
+var schemaSeq atomic.Int64
+
+// Open TEST_DATABASE_URL のデータベースにテストごとのスキーマを作ってマイグレーションを適用し、そのスキーマを使う接続を返す
+// TEST_DATABASE_URL が無い場合はテストを飛ばす。スキーマはテストの終了時に削除するため、テスト同士・パッケージ同士で並行に実行してもよい
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv(EnvDatabaseURL)
+	if dsn == "" {
+		t.Skipf("%s is not set", EnvDatabaseURL)
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	admin, err := db.NewDatabase(dsn, 0, logger)
+	if err != nil {
+		t.Fatalf("failed to connect to %s: %v", EnvDatabaseURL, err)
+	}
+	schema := fmt.Sprintf("test_%d_%d_%d", os.Getpid(), time.Now().UnixNano(), schemaSeq.Add(1))
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("failed to create schema %s: %v", schema, err)
+	}
+	t.Cleanup(func() {
+		if err := admin.Exec("DROP SCHEMA " + schema + " CASCADE").Error; err != nil {
+			t.Errorf("failed to drop schema %s: %v", schema, err)
+		}
+		closeDB(admin)
+	})
+
+	database, err := db.NewDatabase(withSearchPath(dsn, schema), 0, logger)
+	if err != nil {
+		t.Fatalf("failed to connect to schema %s: %v", schema, err)
+	}
+	t.Cleanup(func() { closeDB(database) })
+
+	if err := migrations.Up(database); err != nil {
+		t.Fatalf("failed to migrate schema %s: %v", schema, err)
+	}
+	return database
+}
+
+// withSearchPath 全ての接続で schema を使うよう dsn に search_path を加える（URL 形式とキー=値形式のどちらにも対応）
+func withSearchPath(dsn, schema string) string {
+	if u, err := url.Parse(dsn); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		query := u.Query()
+		query.Set("search_path", schema)
+		u.RawQuery = query.Encode()
+		return u.String()
+	}
+	return strings.TrimSpace(dsn) + " search_path=" + schema
+}
+
+func closeDB(database *gorm.DB) {
+	if sqlDB, err := database.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
@@ -0,0 +1,60 @@
+package limits
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultMaxHistoryDays MAX_HISTORY_DAYS 未設定時の日付範囲の上限
+	DefaultMaxHistoryDays = 365
+	// DefaultMaxBulkImportItems BULK_IMPORT_MAX_ITEMS 未設定時の一括登録の上限
+	DefaultMaxBulkImportItems = 500
+)
+
+// Limits 1リクエストで扱える量の上限
+// GitHub APIの使用量やDBの負荷を抑えるため、1人のユーザーが何年分もの履歴を一度に取得できないようにする
+type Limits struct {
+	MaxHistoryDays     int // バックフィル・同期・履歴・集計で指定できる日付範囲の最大日数（両端を含む）
+	MaxBulkImportItems int // 一括登録1回あたりの最大件数
+}
+
+// New 0以下の値はデフォルトの上限にする
+func New(maxHistoryDays, maxBulkImportItems int) Limits {
+	if maxHistoryDays <= 0 {
+		maxHistoryDays = DefaultMaxHistoryDays
+	}
+	if maxBulkImportItems <= 0 {
+		maxBulkImportItems = DefaultMaxBulkImportItems
+	}
+	return Limits{MaxHistoryDays: maxHistoryDays, MaxBulkImportItems: maxBulkImportItems}
+}
+
+// CheckDays 日数が上限以下か確認する（超えた場合は上限を含むメッセージのエラーを返す）
+func (l Limits) CheckDays(days int) error {
+	if days > l.MaxHistoryDays {
+		return fmt.Errorf("days must not exceed %d", l.MaxHistoryDays)
+	}
+	return nil
+}
+
+// CheckRange since〜until（両端を含む、日付のみ）が上限の日数以下か確認する
+func (l Limits) CheckRange(since, until time.Time) error {
+	if RangeDays(since, until) > l.MaxHistoryDays {
+		return fmt.Errorf("date range must not exceed %d days", l.MaxHistoryDays)
+	}
+	return nil
+}
+
+// DefaultSince until までの days 日分（両端を含む）の開始日。days が上限を超える場合は上限の日数にする
+func (l Limits) DefaultSince(until time.Time, days int) time.Time {
+	if days > l.MaxHistoryDays {
+		days = l.MaxHistoryDays
+	}
+	return until.AddDate(0, 0, -(days - 1))
+}
+
+// RangeDays since〜until の日数（両端を含む）
+func RangeDays(since, until time.Time) int {
+	return int(until.Sub(since).Hours()/24) + 1
+}
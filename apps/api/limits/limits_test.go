@@ -0,0 +1,73 @@
+package limits
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNew_Defaults(t *testing.T) {
+	if got := New(0, -1); got.MaxHistoryDays != DefaultMaxHistoryDays || got.MaxBulkImportItems != DefaultMaxBulkImportItems {
+		t.Errorf("New(0, -1) = %+v, want the defaults", got)
+	}
+	if got := New(30, 10); got.MaxHistoryDays != 30 || got.MaxBulkImportItems != 10 {
+		t.Errorf("New(30, 10) = %+v", got)
+	}
+}
+
+func TestLimits_CheckDays(t *testing.T) {
+	l := New(90, 0)
+	tests := []struct {
+		days    int
+		wantErr bool
+	}{
+		{1, false},
+		{90, false},
+		{91, true},
+	}
+	for _, tt := range tests {
+		err := l.CheckDays(tt.days)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckDays(%d) error = %v, want error = %v", tt.days, err, tt.wantErr)
+		}
+		if err != nil && err.Error() != "days must not exceed 90" {
+			t.Errorf("CheckDays(%d) message = %q, want it to state the cap", tt.days, err.Error())
+		}
+	}
+}
+
+func TestLimits_CheckRange(t *testing.T) {
+	l := New(90, 0)
+	until := time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		since   time.Time
+		wantErr bool
+	}{
+		{"single day", until, false},
+		{"at the limit", until.AddDate(0, 0, -89), false},
+		{"one day over", until.AddDate(0, 0, -90), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := l.CheckRange(tt.since, until)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckRange error = %v, want error = %v", err, tt.wantErr)
+			}
+			if err != nil && err.Error() != "date range must not exceed 90 days" {
+				t.Errorf("CheckRange message = %q, want it to state the cap", err.Error())
+			}
+		})
+	}
+}
+
+func TestLimits_DefaultSince(t *testing.T) {
+	l := New(90, 0)
+	until := time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)
+	if got, want := l.DefaultSince(until, 30), until.AddDate(0, 0, -29); !got.Equal(want) {
+		t.Errorf("DefaultSince(30) = %s, want %s", got.Format("2006-01-02"), want.Format("2006-01-02"))
+	}
+	capped := l.DefaultSince(until, 365)
+	if got := RangeDays(capped, until); got != 90 {
+		t.Errorf("DefaultSince(365) covers %d days, want the cap of 90", got)
+	}
+}
@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"log"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// streamedRouteSuffixes レスポンスを少しずつ書き出すルート（SSE・エクスポート）。
+// BodyDump はレスポンス全体をメモリに溜めるため、これらのルートでは使わない
+var streamedRouteSuffixes = []string{"/events", "/export.csv", "/export.json"}
+
+// BodyLogger JSONのリクエスト・レスポンスのボディをログに出すミドルウェア（デバッグ用）
+// 機密項目は redactor で伏せてから出力する。JSON以外（CSV・HTMLなど）のボディは出力せず、
+// レスポンスを少しずつ書き出すルートはボディを溜めずにそのまま通す
+func BodyLogger(redactor *Redactor) echo.MiddlewareFunc {
+	return middleware.BodyDumpWithConfig(middleware.BodyDumpConfig{
+		Skipper: isStreamedRoute,
+		Handler: func(ctx echo.Context, reqBody, resBody []byte) {
+			req := ctx.Request()
+			reqLog, resLog := "", ""
+			if isJSON(req.Header.Get(echo.HeaderContentType)) {
+				reqLog = redactor.JSON(reqBody)
+			}
+			if isJSON(ctx.Response().Header().Get(echo.HeaderContentType)) {
+				resLog = redactor.JSON(resBody)
+			}
+			log.Printf("%s %s %d headers=%v request=%s response=%s",
+				req.Method, req.URL.Path, ctx.Response().Status, redactor.Headers(req.Header), reqLog, resLog)
+		},
+	})
+}
+
+// isStreamedRoute ルートのパターン（ctx.Path()）が streamedRouteSuffixes のどれかで終わるか
+func isStreamedRoute(ctx echo.Context) bool {
+	for _, suffix := range streamedRouteSuffixes {
+		if strings.HasSuffix(ctx.Path(), suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isJSON(contentType string) bool {
+	return strings.HasPrefix(contentType, echo.MIMEApplicationJSON)
+}
@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"", slog.LevelInfo, false},
+		{"debug", slog.LevelDebug, false},
+		{"INFO", slog.LevelInfo, false},
+		{"warn", slog.LevelWarn, false},
+		{"Error", slog.LevelError, false},
+		{"verbose", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLevel(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+// 設定したレベルより低いログは出力しない
+func TestNewLogger_FiltersLowerLevels(t *testing.T) {
+	tests := []struct {
+		level string
+		want  []string // 出力されるメッセージ
+	}{
+		{"debug", []string{"debug message", "info message", "warn message", "error message"}},
+		{"info", []string{"info message", "warn message", "error message"}},
+		{"warn", []string{"warn message", "error message"}},
+		{"error", []string{"error message"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			level, err := ParseLevel(tt.level)
+			if err != nil {
+				t.Fatalf("ParseLevel returned an error: %v", err)
+			}
+			var buf bytes.Buffer
+			logger, err := NewLogger(&buf, level, FormatJSON)
+			if err != nil {
+				t.Fatalf("NewLogger returned an error: %v", err)
+			}
+			logger.Debug("debug message")
+			logger.Info("info message")
+			logger.Warn("warn message")
+			logger.Error("error message")
+
+			var got []string
+			for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+				var record struct {
+					Msg string `json:"msg"`
+				}
+				if err := json.Unmarshal([]byte(line), &record); err != nil {
+					t.Fatalf("line %q is not JSON: %v", line, err)
+				}
+				got = append(got, record.Msg)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("logged %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewLogger_Format(t *testing.T) {
+	tests := []struct {
+		format  string
+		want    string // 出力に含まれる文字列
+		wantErr bool
+	}{
+		{"", `level=INFO msg=hello`, false},
+		{"text", `level=INFO msg=hello`, false},
+		{"JSON", `"msg":"hello"`, false},
+		{"xml", "", true},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		logger, err := NewLogger(&buf, slog.LevelInfo, tt.format)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewLogger(%q) error = %v, want error %v", tt.format, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		logger.Info("hello")
+		if !strings.Contains(buf.String(), tt.want) {
+			t.Errorf("NewLogger(%q) wrote %q, want it to contain %q", tt.format, buf.String(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Redacted 伏せ字にした値
+const Redacted = "[REDACTED]"
+
+// DefaultSensitiveFields ログから伏せるJSONのキー・ヘッダー名の既定値（大文字小文字を区別しない）
+var DefaultSensitiveFields = []string{"email", "authorization", "cookie", "code", "access_token", "refresh_token", "token", "password", "client_secret"}
+
+// Redactor ログに出す前に機密項目の値を伏せる
+type Redactor struct {
+	fields map[string]bool
+}
+
+// NewRedactor fields（JSONのキー名またはヘッダー名）の値を伏せる Redactor を作る
+func NewRedactor(fields []string) *Redactor {
+	set := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); field != "" {
+			set[strings.ToLower(field)] = true
+		}
+	}
+	return &Redactor{fields: set}
+}
+
+// JSON ボディ内の機密項目（入れ子のオブジェクト・配列も含む）を伏せたJSONを返す
+// JSONとして読めないボディは中身を出さずに置き換える
+func (r *Redactor) JSON(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return "[unparseable body omitted]"
+	}
+	out, err := json.Marshal(r.redact(v))
+	if err != nil {
+		return "[unparseable body omitted]"
+	}
+	return string(out)
+}
+
+// Headers 機密ヘッダーの値を伏せたコピーを返す
+func (r *Redactor) Headers(header http.Header) http.Header {
+	out := make(http.Header, len(header))
+	for name, values := range header {
+		if r.fields[strings.ToLower(name)] {
+			out[name] = []string{Redacted}
+			continue
+		}
+		out[name] = values
+	}
+	return out
+}
+
+func (r *Redactor) redact(v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		for key, child := range value {
+			if r.fields[strings.ToLower(key)] {
+				value[key] = Redacted
+				continue
+			}
+			value[key] = r.redact(child)
+		}
+		return value
+	case []any:
+		for i, child := range value {
+			value[i] = r.redact(child)
+		}
+		return value
+	}
+	return v
+}
@@ -0,0 +1,115 @@
+package logging
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestRedactor_JSON(t *testing.T) {
+	redactor := NewRedactor([]string{"email", " Token ", ""})
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"empty body", ``, ``},
+		{"top-level field", `{"email":"alice@example.com","github_username":"alice"}`, `{"email":"[REDACTED]","github_username":"alice"}`},
+		{"case-insensitive key", `{"EMAIL":"alice@example.com"}`, `{"EMAIL":"[REDACTED]"}`},
+		{"nested object and array", `{"users":[{"email":"a@example.com","id":1}],"auth":{"token":{"value":"x"}}}`, `{"auth":{"token":"[REDACTED]"},"users":[{"email":"[REDACTED]","id":1}]}`},
+		{"unlisted field", `{"code":"abc"}`, `{"code":"abc"}`},
+		{"not json", `email=alice@example.com`, `[unparseable body omitted]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactor.JSON([]byte(tt.body)); got != tt.want {
+				t.Errorf("JSON(%s) = %s, want %s", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactor_Headers(t *testing.T) {
+	redactor := NewRedactor(DefaultSensitiveFields)
+	header := http.Header{}
+	header.Set("Authorization", "Bearer ct_secret")
+	header.Set("Content-Type", "application/json")
+
+	got := redactor.Headers(header)
+	if got.Get("Authorization") != Redacted {
+		t.Errorf("Authorization = %q, want %q", got.Get("Authorization"), Redacted)
+	}
+	if got.Get("Content-Type") != "application/json" {
+		t.Errorf("Content-Type = %q, want it unchanged", got.Get("Content-Type"))
+	}
+	if header.Get("Authorization") != "Bearer ct_secret" {
+		t.Error("Headers modified the original header")
+	}
+}
+
+func TestBodyLogger_MasksEmail(t *testing.T) {
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	e := echo.New()
+	e.Use(BodyLogger(NewRedactor(DefaultSensitiveFields)))
+	e.POST("/api/users", func(ctx echo.Context) error {
+		return ctx.JSON(http.StatusOK, map[string]string{"email": "alice@example.com", "github_username": "alice"})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/users", strings.NewReader(`{"email":"alice@example.com","github_user_id":1}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer ct_secret")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	out := logged.String()
+	for _, secret := range []string{"alice@example.com", "ct_secret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("log contains %q: %s", secret, out)
+		}
+	}
+	if !strings.Contains(out, `request={"email":"[REDACTED]","github_user_id":1}`) {
+		t.Errorf("log does not contain the redacted request body: %s", out)
+	}
+	if !strings.Contains(out, `"github_username":"alice"`) {
+		t.Errorf("log does not contain the unredacted response fields: %s", out)
+	}
+}
+
+// SSE・エクスポートのルートはボディを溜めず、レスポンスはそのまま流れる
+func TestBodyLogger_SkipsStreamedRoutes(t *testing.T) {
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	e := echo.New()
+	e.Use(BodyLogger(NewRedactor(DefaultSensitiveFields)))
+	stream := func(ctx echo.Context) error {
+		// BodyDump で包まれていなければ、記録したレスポンスをそのまま Flush できる
+		if _, ok := ctx.Response().Writer.(*httptest.ResponseRecorder); !ok {
+			t.Errorf("%s: response writer is %T, want the unwrapped recorder", ctx.Path(), ctx.Response().Writer)
+		}
+		return ctx.String(http.StatusOK, "data: {}\n\n")
+	}
+	for _, path := range []string{"/events", "/export.csv", "/export.json"} {
+		e.GET("/api/users/:id"+path, stream)
+	}
+
+	for _, path := range []string{"/events", "/export.csv", "/export.json"} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users/1"+path, nil))
+		if rec.Body.String() != "data: {}\n\n" {
+			t.Errorf("%s: body = %q", path, rec.Body.String())
+		}
+	}
+	if logged.Len() != 0 {
+		t.Errorf("streamed routes were logged: %s", logged.String())
+	}
+}
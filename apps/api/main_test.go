@@ -0,0 +1,149 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+func TestCORSConfig(t *testing.T) {
+	e := echo.New()
+	e.Use(middleware.CORSWithConfig(corsConfig([]string{"https://town.example.com"})))
+	e.GET("/api/users/:id/summary", func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusOK)
+	})
+
+	tests := []struct {
+		name      string
+		method    string
+		origin    string
+		wantAllow string
+	}{
+		{"allowed origin", http.MethodGet, "https://town.example.com", "https://town.example.com"},
+		{"disallowed origin", http.MethodGet, "https://evil.example.com", ""},
+		{"allowed preflight", http.MethodOptions, "https://town.example.com", "https://town.example.com"},
+		{"disallowed preflight", http.MethodOptions, "https://evil.example.com", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/users/1/summary", nil)
+			req.Header.Set(echo.HeaderOrigin, tt.origin)
+			if tt.method == http.MethodOptions {
+				req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodGet)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get(echo.HeaderAccessControlAllowOrigin); got != tt.wantAllow {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantAllow)
+			}
+			wantCredentials := ""
+			if tt.wantAllow != "" {
+				wantCredentials = "true"
+			}
+			if got := rec.Header().Get(echo.HeaderAccessControlAllowCredentials); got != wantCredentials {
+				t.Errorf("Access-Control-Allow-Credentials = %q, want %q", got, wantCredentials)
+			}
+		})
+	}
+}
+
+func TestAllowedOrigins(t *testing.T) {
+	tests := []struct {
+		name    string
+		origins string
+		appEnv  string
+		want    []string
+	}{
+		{"listed origins", " https://a.example.com, ,https://b.example.com ", "production", []string{"https://a.example.com", "https://b.example.com"}},
+		{"unset in production", "", "production", nil},
+		{"unset in development", "", "development", []string{"http://localhost:3000", "http://127.0.0.1:3000"}},
+		{"listed origins win in development", "https://a.example.com", "development", []string{"https://a.example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ALLOWED_ORIGINS", tt.origins)
+			t.Setenv("APP_ENV", tt.appEnv)
+			if got := allowedOrigins(); !slices.Equal(got, tt.want) {
+				t.Errorf("allowedOrigins() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGzipConfig(t *testing.T) {
+	e := echo.New()
+	e.Use(middleware.GzipWithConfig(gzipConfig(1024)))
+	days := make([]map[string]any, 365)
+	for i := range days {
+		days[i] = map[string]any{"date": "2024-01-01", "count": i}
+	}
+	calendar := map[string]any{"days": days}
+	e.GET("/api/users/:id/calendar", func(ctx echo.Context) error {
+		return ctx.JSON(http.StatusOK, calendar)
+	})
+	e.GET("/api/users/:id/today", func(ctx echo.Context) error {
+		return ctx.JSON(http.StatusOK, map[string]int{"commits_today": 1})
+	})
+	e.GET("/metrics", func(ctx echo.Context) error {
+		return ctx.String(http.StatusOK, strings.Repeat("commit_sync_runs_total 1\n", 100))
+	})
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		wantGzip       bool
+	}{
+		{"calendar with gzip accepted", "/api/users/1/calendar", "gzip, deflate", true},
+		{"calendar without gzip", "/api/users/1/calendar", "", false},
+		{"small body", "/api/users/1/today", "gzip", false},
+		{"metrics", "/metrics", "gzip", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set(echo.HeaderAcceptEncoding, tt.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			gzipped := rec.Header().Get(echo.HeaderContentEncoding) == "gzip"
+			if gzipped != tt.wantGzip {
+				t.Fatalf("Content-Encoding = %q, want gzip = %v", rec.Header().Get(echo.HeaderContentEncoding), tt.wantGzip)
+			}
+			if !gzipped {
+				return
+			}
+			if got := rec.Header().Get(echo.HeaderVary); !strings.Contains(got, echo.HeaderAcceptEncoding) {
+				t.Errorf("Vary = %q, want Accept-Encoding", got)
+			}
+			reader, err := gzip.NewReader(rec.Body)
+			if err != nil {
+				t.Fatalf("body is not gzip: %v", err)
+			}
+			body, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("failed to decompress body: %v", err)
+			}
+			var got struct {
+				Days []map[string]any `json:"days"`
+			}
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatalf("decompressed body is not JSON: %v", err)
+			}
+			if len(got.Days) != len(days) {
+				t.Errorf("decompressed body has %d days, want %d", len(got.Days), len(days))
+			}
+		})
+	}
+}
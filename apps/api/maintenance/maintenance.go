@@ -0,0 +1,53 @@
+package maintenance
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/labstack/echo/v4"
+)
+
+// Mode メンテナンスモードのオン・オフ（マイグレーション中に書き込みを止めるため、実行中に切り替えられる）
+type Mode struct {
+	enabled atomic.Bool
+}
+
+// New enabled は起動時の状態（MAINTENANCE_MODE）
+func New(enabled bool) *Mode {
+	mode := &Mode{}
+	mode.enabled.Store(enabled)
+	return mode
+}
+
+// Enabled メンテナンス中かどうか
+func (mode *Mode) Enabled() bool {
+	return mode.enabled.Load()
+}
+
+// Set メンテナンスモードを切り替える
+func (mode *Mode) Set(enabled bool) {
+	mode.enabled.Store(enabled)
+}
+
+// Middleware メンテナンス中は読み取り（GET・HEAD・OPTIONS）以外のリクエストを503で拒否する
+// allowPaths のルート（メンテナンスモードを解除するエンドポイントや、POSTで受ける読み取りなど）は止めない
+func Middleware(mode *Mode, allowPaths ...string) echo.MiddlewareFunc {
+	allowed := make(map[string]bool, len(allowPaths))
+	for _, path := range allowPaths {
+		allowed[path] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if !mode.Enabled() || allowed[ctx.Path()] {
+				return next(ctx)
+			}
+			switch ctx.Request().Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return next(ctx)
+			}
+			return httperr.Maintenance("The API is in maintenance mode; changes are temporarily disabled")
+		}
+	}
+}
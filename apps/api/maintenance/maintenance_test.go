@@ -0,0 +1,84 @@
+package maintenance
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/labstack/echo/v4"
+)
+
+// newTestServer router.SetupRoutes と同じく /api にだけミドルウェアを掛けたEcho
+func newTestServer(mode *Mode) *echo.Echo {
+	e := echo.New()
+	e.HTTPErrorHandler = httperr.Handler
+	ok := func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusOK)
+	}
+	e.GET("/health", ok)
+	api := e.Group("/api", Middleware(mode, "/api/admin/maintenance"))
+	api.GET("/users/:id", ok)
+	api.POST("/users", ok)
+	api.DELETE("/users/:id", ok)
+	api.PUT("/admin/maintenance", ok)
+	return e
+}
+
+func serve(e *echo.Echo, method, path string) int {
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	return rec.Code
+}
+
+func TestMiddleware_TogglingRejectsWritesButServesReads(t *testing.T) {
+	mode := New(false)
+	e := newTestServer(mode)
+
+	tests := []struct {
+		method string
+		path   string
+		off    int
+		on     int
+	}{
+		{http.MethodPost, "/api/users", http.StatusOK, http.StatusServiceUnavailable},
+		{http.MethodDelete, "/api/users/1", http.StatusOK, http.StatusServiceUnavailable},
+		{http.MethodGet, "/api/users/1", http.StatusOK, http.StatusOK},
+		{http.MethodGet, "/health", http.StatusOK, http.StatusOK},
+		{http.MethodPut, "/api/admin/maintenance", http.StatusOK, http.StatusOK},
+	}
+	for _, enabled := range []bool{false, true, false} {
+		mode.Set(enabled)
+		for _, tt := range tests {
+			want := tt.off
+			if enabled {
+				want = tt.on
+			}
+			if got := serve(e, tt.method, tt.path); got != want {
+				t.Errorf("maintenance=%v: %s %s = %d, want %d", enabled, tt.method, tt.path, got, want)
+			}
+		}
+	}
+}
+
+func TestMiddleware_ReturnsMaintenanceError(t *testing.T) {
+	e := newTestServer(New(true))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/users", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	var body struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode the error body %q: %v", rec.Body.String(), err)
+	}
+	if body.Error.Code != httperr.CodeMaintenance {
+		t.Errorf("code = %q, want %q", body.Error.Code, httperr.CodeMaintenance)
+	}
+}
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS users (
+    id              BIGSERIAL PRIMARY KEY,
+    github_user_id  BIGINT,
+    github_username VARCHAR(100),
+    email           VARCHAR(255),
+    created_at      TIMESTAMPTZ,
+    updated_at      TIMESTAMPTZ,
+    deleted_at      TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_github_user_id ON users(github_user_id);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at);
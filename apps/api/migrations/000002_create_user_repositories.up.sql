@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS user_repositories (
+    id             BIGSERIAL PRIMARY KEY,
+    user_id        BIGINT,
+    repo_owner     VARCHAR(100),
+    repo_name      VARCHAR(100),
+    is_public      BOOLEAN DEFAULT true,
+    deactivated_at TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ,
+    updated_at     TIMESTAMPTZ,
+    CONSTRAINT fk_users_repositories FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_repositories_user_id ON user_repositories(user_id);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS repo_daily_commit_logs (
+    id           BIGSERIAL PRIMARY KEY,
+    user_repo_id BIGINT,
+    commit_date  TIMESTAMPTZ,
+    commit_count BIGINT,
+    raw_data     JSONB,
+    created_at   TIMESTAMPTZ,
+    updated_at   TIMESTAMPTZ,
+    CONSTRAINT fk_user_repositories_repo_daily_commit_logs FOREIGN KEY (user_repo_id) REFERENCES user_repositories(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_repo_daily_commit_logs_user_repo_id ON repo_daily_commit_logs(user_repo_id);
+CREATE INDEX IF NOT EXISTS idx_repo_daily_commit_logs_commit_date ON repo_daily_commit_logs(commit_date);
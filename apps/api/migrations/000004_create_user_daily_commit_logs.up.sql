@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS user_daily_commit_logs (
+    id            BIGSERIAL PRIMARY KEY,
+    user_id       BIGINT,
+    date          TIMESTAMPTZ,
+    total_commits BIGINT,
+    created_at    TIMESTAMPTZ,
+    updated_at    TIMESTAMPTZ,
+    CONSTRAINT fk_users_daily_commit_logs FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_daily_commit_logs_user_id ON user_daily_commit_logs(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_daily_commit_logs_date ON user_daily_commit_logs(date);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS user_streaks (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT,
+    start_date TIMESTAMPTZ,
+    end_date   TIMESTAMPTZ,
+    length     BIGINT,
+    active     BOOLEAN DEFAULT true,
+    created_at TIMESTAMPTZ,
+    CONSTRAINT fk_users_streaks FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_streaks_user_id ON user_streaks(user_id);
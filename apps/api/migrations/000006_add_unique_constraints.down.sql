@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_user_daily_commit_logs_user_date;
+DROP INDEX IF EXISTS idx_repo_daily_commit_logs_repo_date;
+DROP INDEX IF EXISTS idx_user_repositories_user_repo;
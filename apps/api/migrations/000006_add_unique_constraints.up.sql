@@ -0,0 +1,11 @@
+-- UserRepository: unique constraint on (UserID, RepoOwner, RepoName)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_repositories_user_repo
+ON user_repositories(user_id, repo_owner, repo_name);
+
+-- RepoDailyCommitLog: unique constraint on (UserRepoID, CommitDate)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_repo_daily_commit_logs_repo_date
+ON repo_daily_commit_logs(user_repo_id, commit_date);
+
+-- UserDailyCommitLog: unique constraint on (UserID, Date)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_daily_commit_logs_user_date
+ON user_daily_commit_logs(user_id, date);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS user_achievements (
+    id        BIGSERIAL PRIMARY KEY,
+    user_id   BIGINT,
+    code      VARCHAR(50),
+    earned_at TIMESTAMPTZ,
+    CONSTRAINT fk_user_achievements_user FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_achievements_user_code ON user_achievements(user_id, code);
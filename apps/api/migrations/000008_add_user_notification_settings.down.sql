@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS last_streak_reminder_on;
+ALTER TABLE users DROP COLUMN IF EXISTS notifications_enabled;
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS repo_streaks (
+    id           BIGSERIAL PRIMARY KEY,
+    user_repo_id BIGINT,
+    start_date   TIMESTAMPTZ,
+    end_date     TIMESTAMPTZ,
+    length       BIGINT,
+    active       BOOLEAN DEFAULT false,
+    created_at   TIMESTAMPTZ,
+    CONSTRAINT fk_user_repositories_repo_streaks FOREIGN KEY (user_repo_id) REFERENCES user_repositories(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_repo_streaks_user_repo_id ON repo_streaks(user_repo_id);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS sync_job_runs (
+    id              BIGSERIAL PRIMARY KEY,
+    user_id         BIGINT,
+    user_repo_id    BIGINT,
+    status          VARCHAR(20) NOT NULL,
+    error           TEXT,
+    repos_processed BIGINT NOT NULL DEFAULT 0,
+    started_at      TIMESTAMPTZ NOT NULL,
+    finished_at     TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_job_runs_started_at ON sync_job_runs(started_at);
@@ -0,0 +1 @@
+ALTER TABLE user_repositories ADD COLUMN IF NOT EXISTS branch VARCHAR(255);
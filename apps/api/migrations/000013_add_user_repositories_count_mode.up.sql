@@ -0,0 +1 @@
+ALTER TABLE user_repositories ADD COLUMN IF NOT EXISTS count_mode VARCHAR(20) NOT NULL DEFAULT 'all';
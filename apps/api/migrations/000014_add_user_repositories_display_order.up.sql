@@ -0,0 +1 @@
+ALTER TABLE user_repositories ADD COLUMN IF NOT EXISTS display_order INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- Restores the original casing; duplicates removed by the up migration are not brought back
+UPDATE user_repositories SET repo_owner = display_owner, repo_name = display_name WHERE display_owner <> '';
+ALTER TABLE user_repositories DROP COLUMN IF EXISTS display_name;
+ALTER TABLE user_repositories DROP COLUMN IF EXISTS display_owner;
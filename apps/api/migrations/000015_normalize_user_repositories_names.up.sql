@@ -0,0 +1,32 @@
+-- GitHub treats owner and repository names case-insensitively, so they are stored lowercased
+-- and the casing the user typed is kept in display_owner / display_name
+ALTER TABLE user_repositories ADD COLUMN IF NOT EXISTS display_owner VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE user_repositories ADD COLUMN IF NOT EXISTS display_name VARCHAR(100) NOT NULL DEFAULT '';
+UPDATE user_repositories SET display_owner = repo_owner, display_name = repo_name WHERE display_owner = '';
+
+-- Registrations that only differ in casing collapse into the earliest one; the duplicates' logs and
+-- streaks are dropped because they counted the same commits twice. Run POST /api/admin/recompute afterwards
+-- to rebuild daily totals and streaks without the double counting.
+DELETE FROM repo_daily_commit_logs WHERE user_repo_id IN (
+    SELECT id FROM (
+        SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id, LOWER(repo_owner), LOWER(repo_name) ORDER BY created_at, id) AS n
+        FROM user_repositories
+    ) AS ranked WHERE n > 1
+);
+DELETE FROM repo_streaks WHERE user_repo_id IN (
+    SELECT id FROM (
+        SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id, LOWER(repo_owner), LOWER(repo_name) ORDER BY created_at, id) AS n
+        FROM user_repositories
+    ) AS ranked WHERE n > 1
+);
+DELETE FROM user_repositories WHERE id IN (
+    SELECT id FROM (
+        SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id, LOWER(repo_owner), LOWER(repo_name) ORDER BY created_at, id) AS n
+        FROM user_repositories
+    ) AS ranked WHERE n > 1
+);
+
+DROP INDEX IF EXISTS idx_user_repositories_user_repo;
+UPDATE user_repositories SET repo_owner = LOWER(repo_owner), repo_name = LOWER(repo_name);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_repositories_user_repo
+ON user_repositories(user_id, repo_owner, repo_name);
@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS webhook_url VARCHAR(2048);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS webhook_secret VARCHAR(64);
@@ -0,0 +1 @@
+ALTER TABLE user_repositories DROP COLUMN IF EXISTS last_synced_at;
@@ -0,0 +1 @@
+ALTER TABLE user_repositories ADD COLUMN IF NOT EXISTS last_synced_at TIMESTAMPTZ;
@@ -0,0 +1,2 @@
+-- Tokens carry the version they were issued with; bumping it revokes every token issued before
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS user_goals (
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      BIGINT NOT NULL,
+    period_type  VARCHAR(10) NOT NULL,
+    period_start TIMESTAMPTZ NOT NULL,
+    target       BIGINT NOT NULL CHECK (target > 0),
+    created_at   TIMESTAMPTZ,
+    updated_at   TIMESTAMPTZ,
+    CONSTRAINT fk_user_goals_user FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_goals_user_period ON user_goals(user_id, period_type, period_start);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS streak_freezes (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT NOT NULL,
+    start_date TIMESTAMPTZ NOT NULL,
+    end_date   TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ,
+    CONSTRAINT fk_streak_freezes_user FOREIGN KEY (user_id) REFERENCES users(id),
+    CONSTRAINT chk_streak_freezes_range CHECK (start_date <= end_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_streak_freezes_user_id ON streak_freezes(user_id);
@@ -0,0 +1,2 @@
+-- Whether the sync could still read the repository on GitHub the last time it checked
+ALTER TABLE user_repositories ADD COLUMN IF NOT EXISTS access_status VARCHAR(20) NOT NULL DEFAULT 'ok';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_user_daily_commit_logs_user_updated_at;
+DROP INDEX IF EXISTS idx_user_streaks_user_updated_at;
+ALTER TABLE user_streaks DROP COLUMN IF EXISTS updated_at;
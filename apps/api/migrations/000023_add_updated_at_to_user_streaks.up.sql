@@ -0,0 +1,6 @@
+-- Lets clients fetch only the streaks that changed since their last sync
+ALTER TABLE user_streaks ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ;
+UPDATE user_streaks SET updated_at = created_at WHERE updated_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_user_streaks_user_updated_at ON user_streaks(user_id, updated_at);
+CREATE INDEX IF NOT EXISTS idx_user_daily_commit_logs_user_updated_at ON user_daily_commit_logs(user_id, updated_at);
@@ -0,0 +1,2 @@
+-- Users with a private profile are left out of leaderboards and platform stats
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_public_profile BOOLEAN NOT NULL DEFAULT true;
@@ -0,0 +1,2 @@
+-- Most common UTC offset (in minutes) of the user's commit author dates, offered as a timezone suggestion
+ALTER TABLE users ADD COLUMN IF NOT EXISTS inferred_utc_offset INTEGER;
@@ -0,0 +1 @@
+ALTER TABLE users DROP COLUMN IF EXISTS min_commits_per_day;
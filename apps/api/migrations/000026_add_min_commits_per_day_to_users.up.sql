@@ -0,0 +1,2 @@
+-- Commits a day needs to count toward the user's streak; NULL uses the server's MIN_COMMITS_PER_DAY
+ALTER TABLE users ADD COLUMN IF NOT EXISTS min_commits_per_day INTEGER;
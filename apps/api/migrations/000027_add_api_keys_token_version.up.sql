@@ -0,0 +1,4 @@
+-- The user's token version when the key was issued; keys below the user's current version are rejected.
+-- Existing keys take the user's current version so they stay valid.
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS token_version INTEGER NOT NULL DEFAULT 1;
+UPDATE api_keys SET token_version = users.token_version FROM users WHERE users.id = api_keys.user_id;
@@ -0,0 +1,11 @@
+-- Days removed from user_daily_commit_logs by re-aggregation, so incremental clients can drop them too
+CREATE TABLE IF NOT EXISTS user_daily_commit_log_deletions (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT NOT NULL,
+    date       TIMESTAMPTZ NOT NULL,
+    deleted_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT fk_user_daily_commit_log_deletions_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_daily_commit_log_deletions_user_date ON user_daily_commit_log_deletions(user_id, date);
+CREATE INDEX IF NOT EXISTS idx_user_daily_commit_log_deletions_user_deleted_at ON user_daily_commit_log_deletions(user_id, deleted_at);
@@ -0,0 +1 @@
+ALTER TABLE users DROP COLUMN IF EXISTS streaks_replaced_at;
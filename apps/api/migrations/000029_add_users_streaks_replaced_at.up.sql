@@ -0,0 +1,2 @@
+-- When the user's streaks were last rebuilt, so incremental clients know to replace their cached list even when it is now empty
+ALTER TABLE users ADD COLUMN IF NOT EXISTS streaks_replaced_at TIMESTAMPTZ;
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// APIKey スクリプトなどからユーザー自身のデータにアクセスするための長期間有効なキー
+// 平文のキーは作成時に一度だけ返し、DBには SHA-256 のハッシュのみを保存する
+type APIKey struct {
+	ID           uint64     `gorm:"primaryKey;autoIncrement"`
+	UserID       uint64     `gorm:"index;not null"`
+	Label        string     `gorm:"size:100;not null"`            // 用途を見分けるための名前
+	KeyHash      string     `gorm:"size:64;uniqueIndex;not null"` // 平文のキーの SHA-256（16進数）
+	LastUsedAt   *time.Time // 最後に認証に使われた日時（未使用の場合は nil）
+	Revoked      bool       `gorm:"not null;default:false"` // 無効化したキーは認証に使えない
+	TokenVersion uint       `gorm:"not null;default:1"`     // 作成した時点のユーザーの TokenVersion。ユーザーの版より古いキーは認証に使えない
+	CreatedAt    time.Time  `gorm:"autoCreateTime"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID;references:ID"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// RepoStreak 登録リポジトリ単位の連続コミット期間(streak)の履歴テーブル
+type RepoStreak struct {
+	ID         uint64 `gorm:"primaryKey;autoIncrement"`
+	UserRepoID uint64 `gorm:"index"`
+	StartDate  time.Time
+	EndDate    *time.Time // 継続中の場合は nil
+	Length     int
+	Active     bool
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+
+	// Relations
+	UserRepository UserRepository `gorm:"foreignKey:UserRepoID;references:ID"`
+}
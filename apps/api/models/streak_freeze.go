@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// StreakFreeze ユーザーがstreakを凍結した期間（休暇などでコミットできない日を途切れとして扱わない）
+type StreakFreeze struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement"`
+	UserID    uint64    `gorm:"index;not null"`
+	StartDate time.Time `gorm:"not null"` // 凍結の初日（UTCの0時0分）
+	EndDate   time.Time `gorm:"not null"` // 凍結の最終日（両端を含む）
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID;references:ID"`
+}
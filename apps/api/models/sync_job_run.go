@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// 同期ジョブの状態
+const (
+	SyncJobRunStatusRunning   = "running"
+	SyncJobRunStatusSucceeded = "succeeded"
+	SyncJobRunStatusFailed    = "failed"
+)
+
+// SyncJobRun 同期ジョブ1回分の実行記録（スケジューラー・手動同期・過去分の取り込み）
+type SyncJobRun struct {
+	ID             uint64     `gorm:"primaryKey;autoIncrement"`
+	UserID         *uint64    // 全ユーザー対象の定期同期では nil
+	UserRepoID     *uint64    // 1リポジトリだけを対象にした場合のみ
+	Status         string     `gorm:"size:20;not null"`
+	Error          string     // 失敗時のエラー内容
+	ReposProcessed int        `gorm:"not null;default:0"`
+	StartedAt      time.Time  `gorm:"index;not null"`
+	FinishedAt     *time.Time // 実行中は nil
+}
@@ -8,13 +8,24 @@ import (
 
 // User GitHubアカウントを基にしたアプリユーザー情報
 type User struct {
-	ID             uint64         `gorm:"primaryKey;autoIncrement"`
-	GitHubUserID   uint64         `gorm:"uniqueIndex;column:github_user_id"` // GitHub API の profile.id (変更不可、一意)
-	GitHubUsername string         `gorm:"size:100;column:github_username"`   // GitHub API の profile.login (変更可能)
-	Email          string         `gorm:"size:255"`                           // メールアドレス
-	CreatedAt      time.Time      `gorm:"autoCreateTime"`
-	UpdatedAt      time.Time      `gorm:"autoUpdateTime"`
-	DeletedAt      gorm.DeletedAt `gorm:"index"`
+	ID                   uint64         `gorm:"primaryKey;autoIncrement"`
+	GitHubUserID         uint64         `gorm:"uniqueIndex;column:github_user_id"` // GitHub API の profile.id (変更不可、一意)
+	GitHubUsername       string         `gorm:"size:100;column:github_username"`   // GitHub API の profile.login (変更可能)
+	Email                string         `gorm:"size:255"`                          // メールアドレス
+	Timezone             string         `gorm:"size:64;default:UTC"`               // IANAタイムゾーン名（日付の区切りに使用）
+	NotificationsEnabled bool           // streak通知を受け取るか（オプトイン）
+	LastStreakReminderOn *time.Time     // 最後にstreak通知を送ったローカル日付
+	InferredUTCOffset    *int           // コミットの作者日時から推定したUTCからのずれ（分、未推定は nil）。Timezone の候補としてだけ使う
+	MinCommitsPerDay     *int           // streakに数える1日のコミット数の下限（nil はサーバーの MIN_COMMITS_PER_DAY）
+	StreaksReplacedAt    *time.Time     // streak履歴を最後に作り直した時刻（差分同期でキャッシュを置き換えるかの判定に使う）
+	WebhookURL           *string        `gorm:"size:2048"`             // streakの節目を送るWebhookのURL（nil は送らない）
+	WebhookSecret        *string        `gorm:"size:64"`               // Webhookの署名に使うシークレット
+	TokenVersion         uint           `gorm:"not null;default:1"`    // 発行するトークンに含める版。上げるとそれ以前に発行したトークンは無効になる
+	IsPublicProfile      bool           `gorm:"not null;default:true"` // false のユーザーはランキングとサービス全体の集計に含めない
+	Version              uint           `gorm:"not null;default:1"`    // 楽観的ロック用。Update のたびに1増える
+	CreatedAt            time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt            time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt            gorm.DeletedAt `gorm:"index"`
 
 	// Relations
 	Repositories    []UserRepository     `gorm:"foreignKey:UserID"`
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// UserAchievement ユーザーが獲得したバッジ（user_id + code で一意）
+type UserAchievement struct {
+	ID       uint64 `gorm:"primaryKey;autoIncrement"`
+	UserID   uint64 `gorm:"uniqueIndex:idx_user_achievements_user_code"`
+	Code     string `gorm:"size:50;uniqueIndex:idx_user_achievements_user_code"`
+	EarnedAt time.Time
+
+	// Relations
+	User User `gorm:"foreignKey:UserID;references:ID"`
+}
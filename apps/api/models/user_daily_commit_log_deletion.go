@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// UserDailyCommitLogDeletion 再集計で削除した日次ログの日付（差分同期のクライアントにも削除を伝える）
+type UserDailyCommitLogDeletion struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement"`
+	UserID    uint64    `gorm:"not null;uniqueIndex:idx_user_daily_commit_log_deletions_user_date"`
+	Date      time.Time `gorm:"not null;uniqueIndex:idx_user_daily_commit_log_deletions_user_date"` // 削除した日（UTCの0時0分）
+	DeletedAt time.Time `gorm:"not null"`                                                           // 最後に削除した時刻（同じ日を再び削除すると進む）
+
+	// Relations
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// 目標の期間の種類
+const (
+	// GoalPeriodMonth 月ごとの目標（1日から月末まで）
+	GoalPeriodMonth = "month"
+	// GoalPeriodWeek 週ごとの目標（月曜から日曜まで）
+	GoalPeriodWeek = "week"
+)
+
+// UserGoal ユーザーが期間ごとに設定したコミット数の目標（user_id + period_type + period_start で一意）
+type UserGoal struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement"`
+	UserID      uint64    `gorm:"not null;uniqueIndex:idx_user_goals_user_period"`
+	PeriodType  string    `gorm:"size:10;not null;uniqueIndex:idx_user_goals_user_period"` // GoalPeriodMonth など
+	PeriodStart time.Time `gorm:"not null;uniqueIndex:idx_user_goals_user_period"`         // 期間の初日（ユーザーのタイムゾーンでの日付）
+	Target      int       `gorm:"not null"`                                                // 期間内の目標コミット数（1以上）
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID;references:ID"`
+}
@@ -4,14 +4,39 @@ import (
 	"time"
 )
 
+// 登録リポジトリのコミットの数え方
+const (
+	// CountModeAll 取得した全てのコミットを数える（従来の動作）
+	CountModeAll = "all"
+	// CountModeAuthoredOnly 作者（author.login）がユーザー本人のコミットだけを数える（共同作業者のコミットを除く）
+	CountModeAuthoredOnly = "authored_only"
+	// CountModeNoMerges マージコミット（親が2つ以上）を除いて数える
+	CountModeNoMerges = "no_merges"
+)
+
+// 登録リポジトリにGitHubからアクセスできるか
+const (
+	// AccessStatusOK 前回の同期でリポジトリを読み取れた
+	AccessStatusOK = "ok"
+	// AccessStatusNoAccess リポジトリが非公開になった・削除された等で読み取れない（アクセスできるようになるまで同期しない）
+	AccessStatusNoAccess = "no_access"
+)
+
 // UserRepository ユーザーがGUIで登録したGitHubリポジトリ情報
 type UserRepository struct {
 	ID            uint64     `gorm:"primaryKey;autoIncrement"`
 	UserID        uint64     `gorm:"index"`
-	RepoOwner     string     `gorm:"size:100"`
-	RepoName      string     `gorm:"size:100"`
+	RepoOwner     string     `gorm:"size:100"`                     // 小文字にそろえたオーナー名（一意性の判定・GitHubの問い合わせに使う）
+	RepoName      string     `gorm:"size:100"`                     // 小文字にそろえたリポジトリ名
+	DisplayOwner  string     `gorm:"size:100;not null;default:''"` // 登録時に入力された表記のオーナー名（表示用）
+	DisplayName   string     `gorm:"size:100;not null;default:''"` // 登録時に入力された表記のリポジトリ名（表示用）
 	IsPublic      bool       `gorm:"default:true"`
+	Branch        *string    `gorm:"size:255"` // コミットを取得するブランチ（nil はデフォルトブランチ）
+	CountMode     string     `gorm:"size:20;not null;default:all"` // 数えるコミットの種類（CountModeAll など）
+	DisplayOrder  int        `gorm:"not null;default:0"`           // 表示順（小さいほど先。同じ場合は登録順）
 	DeactivatedAt *time.Time
+	LastSyncedAt  *time.Time // 同期済みの時点（nil は未同期）。次回の同期はこの日から取得する
+	AccessStatus  string     `gorm:"size:20;not null;default:ok"` // GitHubから読み取れるか（AccessStatusOK など）
 	CreatedAt     time.Time  `gorm:"autoCreateTime"`
 	UpdatedAt     time.Time  `gorm:"autoUpdateTime"`
 
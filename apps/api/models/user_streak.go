@@ -13,6 +13,7 @@ type UserStreak struct {
 	Length    int
 	Active    bool      `gorm:"default:true"`
 	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
 
 	// Relations
 	User User `gorm:"foreignKey:UserID;references:ID"`
@@ -0,0 +1,14 @@
+package openapi
+
+import _ "embed"
+
+// openapi.yaml は packages/openapi/schema.yaml のコピー。スキーマを変更したら go generate で同期する
+//go:generate cp ../../../packages/openapi/schema.yaml openapi.yaml
+
+//go:embed openapi.yaml
+var spec []byte
+
+// Spec 埋め込まれたOpenAPI 3仕様（YAML）を返す
+func Spec() []byte {
+	return spec
+}
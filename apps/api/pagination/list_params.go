@@ -0,0 +1,78 @@
+package pagination
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// OrderAsc 昇順
+	OrderAsc = "asc"
+	// OrderDesc 降順
+	OrderDesc = "desc"
+)
+
+// ListParams 一覧のクエリパラメータ（?limit=&offset=&sort=&order=）を検証・正規化したもの
+type ListParams struct {
+	Limit  int
+	Offset int
+	Sort   string // Sorts.Fields のいずれか（並び替えできない一覧では空）
+	Order  string // OrderAsc か OrderDesc（並び順を選べない一覧では空）
+}
+
+// Desc 降順か
+func (p ListParams) Desc() bool {
+	return p.Order == OrderDesc
+}
+
+// Sorts 一覧ごとに指定できる ?sort= の値と、省略した場合の並び
+// Fields が空の一覧は ?sort= を、DefaultOrder が空の一覧は ?order= を受け付けない
+type Sorts struct {
+	Fields       []string
+	DefaultField string
+	DefaultOrder string
+}
+
+// ParseListParams limit・offset・sort・order を取得する
+// limit は Limit と同じく丸め、offset・sort・order が不正な場合は400（validation_failed）を返す
+func (c Config) ParseListParams(ctx echo.Context, sorts Sorts) (ListParams, error) {
+	params := ListParams{
+		Limit: c.Limit(ctx),
+		Sort:  sorts.DefaultField,
+		Order: sorts.DefaultOrder,
+	}
+
+	if v := ctx.QueryParam("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return ListParams{}, httperr.ValidationFailed("offset must be a non-negative integer")
+		}
+		params.Offset = offset
+	}
+
+	if v := ctx.QueryParam("sort"); v != "" {
+		if len(sorts.Fields) == 0 {
+			return ListParams{}, httperr.ValidationFailed("sort is not supported")
+		}
+		if !slices.Contains(sorts.Fields, v) {
+			return ListParams{}, httperr.ValidationFailed(fmt.Sprintf("sort must be one of %s", strings.Join(sorts.Fields, ", ")))
+		}
+		params.Sort = v
+	}
+
+	if v := strings.ToLower(ctx.QueryParam("order")); v != "" {
+		if sorts.DefaultOrder == "" {
+			return ListParams{}, httperr.ValidationFailed("order is not supported")
+		}
+		if v != OrderAsc && v != OrderDesc {
+			return ListParams{}, httperr.ValidationFailed("order must be asc or desc")
+		}
+		params.Order = v
+	}
+	return params, nil
+}
@@ -0,0 +1,66 @@
+package pagination
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/labstack/echo/v4"
+)
+
+func TestConfig_ParseListParams(t *testing.T) {
+	config := NewConfig(20, 50)
+	sortable := Sorts{Fields: []string{"date", "length"}, DefaultField: "date", DefaultOrder: OrderDesc}
+	fixed := Sorts{}
+
+	tests := []struct {
+		name    string
+		sorts   Sorts
+		query   string
+		want    ListParams
+		wantErr bool
+	}{
+		{"defaults", sortable, "", ListParams{Limit: 20, Offset: 0, Sort: "date", Order: OrderDesc}, false},
+		{"limit clamped to max", sortable, "limit=500", ListParams{Limit: 50, Sort: "date", Order: OrderDesc}, false},
+		{"zero limit uses default", sortable, "limit=0", ListParams{Limit: 20, Sort: "date", Order: OrderDesc}, false},
+		{"offset", sortable, "offset=40", ListParams{Limit: 20, Offset: 40, Sort: "date", Order: OrderDesc}, false},
+		{"sort and order", sortable, "sort=length&order=asc", ListParams{Limit: 20, Sort: "length", Order: OrderAsc}, false},
+		{"order is case insensitive", sortable, "order=ASC", ListParams{Limit: 20, Sort: "date", Order: OrderAsc}, false},
+		{"list without sorting", fixed, "limit=5&offset=5", ListParams{Limit: 5, Offset: 5}, false},
+		{"unknown sort field", sortable, "sort=name", ListParams{}, true},
+		{"sort field is case sensitive", sortable, "sort=Date", ListParams{}, true},
+		{"invalid order", sortable, "order=random", ListParams{}, true},
+		{"negative offset", sortable, "offset=-1", ListParams{}, true},
+		{"non-numeric offset", sortable, "offset=abc", ListParams{}, true},
+		{"sort on a list without sorting", fixed, "sort=date", ListParams{}, true},
+		{"order on a list without sorting", fixed, "order=asc", ListParams{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/api/leaderboard/streaks?"+tt.query, nil)
+			got, err := config.ParseListParams(e.NewContext(req, httptest.NewRecorder()), tt.sorts)
+			if tt.wantErr {
+				var apiErr *httperr.APIError
+				if !errors.As(err, &apiErr) || apiErr.Code != httperr.CodeValidationFailed {
+					t.Errorf("ParseListParams(%q) error = %v, want %s", tt.query, err, httperr.CodeValidationFailed)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseListParams(%q) = (%+v, %v), want (%+v, nil)", tt.query, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestListParams_Desc(t *testing.T) {
+	if !(ListParams{Order: OrderDesc}).Desc() {
+		t.Error("Desc() = false for desc")
+	}
+	if (ListParams{Order: OrderAsc}).Desc() || (ListParams{}).Desc() {
+		t.Error("Desc() = true for asc or no order")
+	}
+}
@@ -0,0 +1,94 @@
+package pagination
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// DefaultPageSize PAGE_SIZE_DEFAULT 未設定時の1ページの件数
+	DefaultPageSize = 10
+	// DefaultMaxPageSize PAGE_SIZE_MAX 未設定時の1ページの件数の上限
+	DefaultMaxPageSize = 100
+)
+
+const (
+	// HeaderTotalCount 条件に一致する全件数
+	HeaderTotalCount = "X-Total-Count"
+	// HeaderLink 前後・最初・最後のページのURL（RFC 5988）
+	HeaderLink = "Link"
+)
+
+// Config 一覧のページサイズ（環境ごとに調整できるよう設定から渡す）
+type Config struct {
+	DefaultPageSize int // ?limit= を省略した・不正な値を指定した場合の件数
+	MaxPageSize     int // ?limit= の上限（超えた場合は上限にする）
+}
+
+// NewConfig 0以下の値はデフォルトにし、DefaultPageSize は MaxPageSize を超えないようにする
+func NewConfig(defaultPageSize, maxPageSize int) Config {
+	if maxPageSize <= 0 {
+		maxPageSize = DefaultMaxPageSize
+	}
+	if defaultPageSize <= 0 {
+		defaultPageSize = DefaultPageSize
+	}
+	return Config{DefaultPageSize: min(defaultPageSize, maxPageSize), MaxPageSize: maxPageSize}
+}
+
+// Limit ?limit= を取得する。省略・0以下・数値でない場合は DefaultPageSize、MaxPageSize を超える場合は MaxPageSize にする
+func (c Config) Limit(ctx echo.Context) int {
+	limit, err := strconv.Atoi(ctx.QueryParam("limit"))
+	if err != nil || limit <= 0 {
+		return c.DefaultPageSize
+	}
+	return min(limit, c.MaxPageSize)
+}
+
+// SetHeaders limit/offset で区切った一覧のレスポンスに X-Total-Count と Link ヘッダーを付ける
+// レスポンスボディの形を知らない汎用のAPIクライアントでもページをたどれるようにする
+//
+// Link のURLはリクエストのURLの limit・offset だけを置き換えたもの。
+// prev は先頭のページでは、next は最後のページでは付けない
+func SetHeaders(ctx echo.Context, limit, offset int, total int64) {
+	res := ctx.Response().Header()
+	res.Set(HeaderTotalCount, strconv.FormatInt(total, 10))
+	if limit <= 0 {
+		return
+	}
+
+	last := 0
+	if total > 0 {
+		last = int((total-1)/int64(limit)) * limit
+	}
+
+	links := []string{link(ctx, 0, limit, "first")}
+	if offset > 0 {
+		links = append(links, link(ctx, max(offset-limit, 0), limit, "prev"))
+	}
+	if int64(offset+limit) < total {
+		links = append(links, link(ctx, offset+limit, limit, "next"))
+	}
+	links = append(links, link(ctx, last, limit, "last"))
+	res.Set(HeaderLink, strings.Join(links, ", "))
+}
+
+// link offset のページを指す Link ヘッダーの1要素
+func link(ctx echo.Context, offset, limit int, rel string) string {
+	req := ctx.Request()
+	query := req.URL.Query()
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("offset", strconv.Itoa(offset))
+
+	pageURL := url.URL{
+		Scheme:   ctx.Scheme(),
+		Host:     req.Host,
+		Path:     req.URL.Path,
+		RawQuery: query.Encode(),
+	}
+	return fmt.Sprintf(`<%s>; rel="%s"`, pageURL.String(), rel)
+}
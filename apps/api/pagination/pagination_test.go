@@ -0,0 +1,159 @@
+package pagination
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// setHeaders target へのリクエストに SetHeaders を適用したレスポンスのヘッダー
+func setHeaders(target string, limit, offset int, total int64) http.Header {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	rec := httptest.NewRecorder()
+	SetHeaders(e.NewContext(req, rec), limit, offset, total)
+	return rec.Header()
+}
+
+func TestSetHeaders(t *testing.T) {
+	const base = "http://example.com/api/users/1/repositories"
+	tests := []struct {
+		name   string
+		target string
+		limit  int
+		offset int
+		total  int64
+		want   string
+	}{
+		{
+			name:   "first page with more pages",
+			target: "/api/users/1/repositories?limit=10",
+			limit:  10, offset: 0, total: 25,
+			want: `<` + base + `?limit=10&offset=0>; rel="first", <` + base + `?limit=10&offset=10>; rel="next", <` + base + `?limit=10&offset=20>; rel="last"`,
+		},
+		{
+			name:   "middle page",
+			target: "/api/users/1/repositories?limit=10&offset=10",
+			limit:  10, offset: 10, total: 25,
+			want: `<` + base + `?limit=10&offset=0>; rel="first", <` + base + `?limit=10&offset=0>; rel="prev", <` + base + `?limit=10&offset=20>; rel="next", <` + base + `?limit=10&offset=20>; rel="last"`,
+		},
+		{
+			name:   "last page",
+			target: "/api/users/1/repositories?limit=10&offset=20",
+			limit:  10, offset: 20, total: 25,
+			want: `<` + base + `?limit=10&offset=0>; rel="first", <` + base + `?limit=10&offset=10>; rel="prev", <` + base + `?limit=10&offset=20>; rel="last"`,
+		},
+		{
+			name:   "exactly one page",
+			target: "/api/users/1/repositories?limit=10",
+			limit:  10, offset: 0, total: 10,
+			want: `<` + base + `?limit=10&offset=0>; rel="first", <` + base + `?limit=10&offset=0>; rel="last"`,
+		},
+		{
+			name:   "empty list",
+			target: "/api/users/1/repositories",
+			limit:  10, offset: 0, total: 0,
+			want: `<` + base + `?limit=10&offset=0>; rel="first", <` + base + `?limit=10&offset=0>; rel="last"`,
+		},
+		{
+			name:   "keeps other query parameters",
+			target: "/api/users/1/repositories?sort=name&limit=1",
+			limit:  1, offset: 0, total: 2,
+			want: `<` + base + `?limit=1&offset=0&sort=name>; rel="first", <` + base + `?limit=1&offset=1&sort=name>; rel="next", <` + base + `?limit=1&offset=1&sort=name>; rel="last"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := setHeaders(tt.target, tt.limit, tt.offset, tt.total)
+			if got := header.Get(HeaderLink); got != tt.want {
+				t.Errorf("Link =\n  %s\nwant\n  %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// 続きのページがある場合だけ rel="next" を付ける
+func TestSetHeaders_NextOnlyWhenMorePagesExist(t *testing.T) {
+	tests := []struct {
+		offset   int
+		total    int64
+		wantNext bool
+	}{
+		{0, 21, true},
+		{10, 21, true},
+		{20, 21, false},
+		{0, 10, false},
+	}
+	for _, tt := range tests {
+		header := setHeaders("/api/users?limit=10", 10, tt.offset, tt.total)
+		links := parseLinks(t, header.Get(HeaderLink))
+		if _, ok := links["next"]; ok != tt.wantNext {
+			t.Errorf("offset=%d total=%d: rel=next present = %v, want %v", tt.offset, tt.total, ok, tt.wantNext)
+		}
+		if got := header.Get(HeaderTotalCount); got != strconv.FormatInt(tt.total, 10) {
+			t.Errorf("offset=%d total=%d: X-Total-Count = %q", tt.offset, tt.total, got)
+		}
+	}
+}
+
+// parseLinks Link ヘッダーを rel → URL にする
+func parseLinks(t *testing.T, header string) map[string]string {
+	t.Helper()
+	links := map[string]string{}
+	for _, part := range strings.Split(header, ", ") {
+		target, rel, ok := strings.Cut(part, `>; rel="`)
+		if !ok || !strings.HasPrefix(target, "<") || !strings.HasSuffix(rel, `"`) {
+			t.Fatalf("malformed Link element %q", part)
+		}
+		links[strings.TrimSuffix(rel, `"`)] = strings.TrimPrefix(target, "<")
+	}
+	return links
+}
+
+func TestConfig_Limit(t *testing.T) {
+	config := NewConfig(20, 50)
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", 20},
+		{"limit=0", 20},
+		{"limit=-5", 20},
+		{"limit=abc", 20},
+		{"limit=30", 30},
+		{"limit=50", 50},
+		{"limit=51", 50},
+		{"limit=1000", 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/api/users/1/repositories?"+tt.query, nil)
+			if got := config.Limit(e.NewContext(req, httptest.NewRecorder())); got != tt.want {
+				t.Errorf("Limit(%q) = %d, want %d", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewConfig(t *testing.T) {
+	tests := []struct {
+		defaultPageSize, maxPageSize int
+		want                         Config
+	}{
+		{0, 0, Config{DefaultPageSize: DefaultPageSize, MaxPageSize: DefaultMaxPageSize}},
+		{-1, -1, Config{DefaultPageSize: DefaultPageSize, MaxPageSize: DefaultMaxPageSize}},
+		{25, 200, Config{DefaultPageSize: 25, MaxPageSize: 200}},
+		{50, 30, Config{DefaultPageSize: 30, MaxPageSize: 30}},
+		{0, 5, Config{DefaultPageSize: 5, MaxPageSize: 5}},
+	}
+	for _, tt := range tests {
+		if got := NewConfig(tt.defaultPageSize, tt.maxPageSize); got != tt.want {
+			t.Errorf("NewConfig(%d, %d) = %+v, want %+v", tt.defaultPageSize, tt.maxPageSize, got, tt.want)
+		}
+	}
+}
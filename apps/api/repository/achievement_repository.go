@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"github.com/keeee21/commit-town/api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AchievementRepository struct {
+	db *gorm.DB
+}
+
+func NewAchievementRepository(db *gorm.DB) *AchievementRepository {
+	return &AchievementRepository{db: db}
+}
+
+// WithTx トランザクション内で操作するリポジトリを返す
+func (achievementRepo *AchievementRepository) WithTx(tx *gorm.DB) *AchievementRepository {
+	return &AchievementRepository{db: tx}
+}
+
+// ListByUserID ユーザーの獲得済みバッジを獲得日時順に取得
+func (achievementRepo *AchievementRepository) ListByUserID(ctx context.Context, userID uint64) ([]models.UserAchievement, error) {
+	var achievements []models.UserAchievement
+	err := achievementRepo.db.WithContext(ctx).Where("user_id = ?", userID).Order("earned_at, id").Find(&achievements).Error
+	if err != nil {
+		return nil, err
+	}
+	return achievements, nil
+}
+
+// CreateIfNotExists バッジを付与（獲得済みの場合は何もせず、earned_atも変更しない）
+func (achievementRepo *AchievementRepository) CreateIfNotExists(ctx context.Context, achievement *models.UserAchievement) error {
+	return achievementRepo.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "code"}},
+		DoNothing: true,
+	}).Create(achievement).Error
+}
+
+// DeleteByUserID ユーザーのバッジを全て削除し、削除した件数を返す
+func (achievementRepo *AchievementRepository) DeleteByUserID(ctx context.Context, userID uint64) (int64, error) {
+	result := achievementRepo.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.UserAchievement{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/keeee21/commit-town/api/models"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// WithTx トランザクション内で操作するリポジトリを返す
+func (apiKeyRepo *APIKeyRepository) WithTx(tx *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: tx}
+}
+
+// FindByID APIキーを取得
+func (apiKeyRepo *APIKeyRepository) FindByID(ctx context.Context, id uint64) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	if err := apiKeyRepo.db.WithContext(ctx).First(&apiKey, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &apiKey, nil
+}
+
+// FindByHash キーのハッシュからAPIキーを取得（無効化したものも返す）
+func (apiKeyRepo *APIKeyRepository) FindByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	if err := apiKeyRepo.db.WithContext(ctx).Where("key_hash = ?", keyHash).First(&apiKey).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &apiKey, nil
+}
+
+// ListByUserID ユーザーのAPIキーを作成の古い順に取得（無効化したものを含む）
+func (apiKeyRepo *APIKeyRepository) ListByUserID(ctx context.Context, userID uint64) ([]models.APIKey, error) {
+	var apiKeys []models.APIKey
+	err := apiKeyRepo.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&apiKeys).Error
+	if err != nil {
+		return nil, err
+	}
+	return apiKeys, nil
+}
+
+// Create APIキーを作成（ユーザーが存在しない場合は ErrNotFound を返す）
+func (apiKeyRepo *APIKeyRepository) Create(ctx context.Context, apiKey *models.APIKey) error {
+	return translateError(apiKeyRepo.db.WithContext(ctx).Create(apiKey).Error)
+}
+
+// Revoke APIキーを無効化
+func (apiKeyRepo *APIKeyRepository) Revoke(ctx context.Context, id uint64) error {
+	return apiKeyRepo.db.WithContext(ctx).Model(&models.APIKey{}).Where("id = ?", id).Update("revoked", true).Error
+}
+
+// TouchLastUsed 最後に使われた日時を更新
+func (apiKeyRepo *APIKeyRepository) TouchLastUsed(ctx context.Context, id uint64, usedAt time.Time) error {
+	return apiKeyRepo.db.WithContext(ctx).Model(&models.APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+}
+
+// DeleteByUserID ユーザーのAPIキーを全て削除し、削除した件数を返す
+func (apiKeyRepo *APIKeyRepository) DeleteByUserID(ctx context.Context, userID uint64) (int64, error) {
+	result := apiKeyRepo.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.APIKey{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// ErrNotFound 対象のレコードが存在しない
+// 呼び出し側が gorm に依存しないよう、各リポジトリは gorm.ErrRecordNotFound をこのエラーに変換して返す
+// 作成時に参照先（ユーザーなど）が存在せず外部キー制約に違反した場合もこのエラーにする
+var ErrNotFound = errors.New("record not found")
+
+// foreignKeyViolation PostgreSQL の外部キー制約違反（foreign_key_violation）の SQLSTATE
+const foreignKeyViolation = "23503"
+
+// translateError GORM・PostgreSQL 固有のエラーをリポジトリ層のエラーに変換する
+func translateError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) || isForeignKeyViolation(err) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// isForeignKeyViolation 参照先の行が存在せず INSERT・UPDATE が失敗したか
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/keeee21/commit-town/api/internal/testdb"
+	"github.com/keeee21/commit-town/api/models"
+	"gorm.io/gorm"
+)
+
+func TestTranslateError(t *testing.T) {
+	other := errors.New("connection reset")
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"record not found", gorm.ErrRecordNotFound, ErrNotFound},
+		{"wrapped record not found", fmt.Errorf("find: %w", gorm.ErrRecordNotFound), ErrNotFound},
+		{"foreign key violation", &pgconn.PgError{Code: foreignKeyViolation}, ErrNotFound},
+		{"wrapped foreign key violation", fmt.Errorf("insert: %w", &pgconn.PgError{Code: foreignKeyViolation}), ErrNotFound},
+		{"unique violation", &pgconn.PgError{Code: uniqueViolation}, nil},
+		{"other error", other, other},
+		{"nil", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := translateError(tt.err)
+			if tt.want == nil && tt.err != nil {
+				if got != tt.err {
+					t.Errorf("translateError = %v, want %v unchanged", got, tt.err)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("translateError = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// 存在しないユーザーの行を作ると、外部キー制約の違反を ErrNotFound として返す
+func TestCreate_MissingUserReturnsNotFound(t *testing.T) {
+	ctx := context.Background()
+	database := testdb.Open(t)
+	const missingUserID = 999999999
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	tests := []struct {
+		name   string
+		create func() error
+	}{
+		{"repository", func() error {
+			return NewRepoRepository(database).Create(ctx, &models.UserRepository{UserID: missingUserID, RepoOwner: "alice", RepoName: "town", CountMode: models.CountModeAll})
+		}},
+		{"repository if not exists", func() error {
+			_, err := NewRepoRepository(database).CreateIfNotExists(ctx, &models.UserRepository{UserID: missingUserID, RepoOwner: "alice", RepoName: "city", CountMode: models.CountModeAll})
+			return err
+		}},
+		{"api key", func() error {
+			return NewAPIKeyRepository(database).Create(ctx, &models.APIKey{UserID: missingUserID, Label: "laptop", KeyHash: "missing-user-key"})
+		}},
+		{"goal", func() error {
+			return NewGoalRepository(database).Upsert(ctx, &models.UserGoal{UserID: missingUserID, PeriodType: models.GoalPeriodMonth, PeriodStart: today, Target: 10})
+		}},
+		{"streak freeze", func() error {
+			return NewStreakFreezeRepository(database).Create(ctx, &models.StreakFreeze{UserID: missingUserID, StartDate: today, EndDate: today})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.create(); !errors.Is(err, ErrNotFound) {
+				t.Errorf("create error = %v, want %v", err, ErrNotFound)
+			}
+		})
+	}
+}
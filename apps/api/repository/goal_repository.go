@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/keeee21/commit-town/api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GoalRepository struct {
+	db *gorm.DB
+}
+
+func NewGoalRepository(db *gorm.DB) *GoalRepository {
+	return &GoalRepository{db: db}
+}
+
+// WithTx トランザクション内で操作するリポジトリを返す
+func (goalRepo *GoalRepository) WithTx(tx *gorm.DB) *GoalRepository {
+	return &GoalRepository{db: tx}
+}
+
+// FindByPeriod ユーザーの指定した期間の目標を取得
+func (goalRepo *GoalRepository) FindByPeriod(ctx context.Context, userID uint64, periodType string, periodStart time.Time) (*models.UserGoal, error) {
+	var goal models.UserGoal
+	err := goalRepo.db.WithContext(ctx).
+		Where("user_id = ? AND period_type = ? AND period_start = ?", userID, periodType, periodStart).
+		First(&goal).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &goal, nil
+}
+
+// Upsert 目標を作成または更新（ユーザー・期間で判定し、既にあれば目標数だけを変更する）
+// ユーザーが存在しない場合は ErrNotFound を返す
+func (goalRepo *GoalRepository) Upsert(ctx context.Context, goal *models.UserGoal) error {
+	err := goalRepo.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "period_type"}, {Name: "period_start"}},
+		DoUpdates: clause.AssignmentColumns([]string{"target", "updated_at"}),
+	}).Create(goal).Error
+	return translateError(err)
+}
+
+// DeleteByUserID ユーザーの目標を全て削除し、削除した件数を返す
+func (goalRepo *GoalRepository) DeleteByUserID(ctx context.Context, userID uint64) (int64, error) {
+	result := goalRepo.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.UserGoal{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// RankNeighbor 指定ユーザーの前後の順位の1行
+type RankNeighbor struct {
+	Rank           int // 同じ値は同順位
+	Position       int // 同順位をユーザーID順に並べた通し番号
+	UserID         uint64
+	GitHubUsername string
+	Value          int // 順位付けに使った値（活動が無ければ0）
+	Total          int // 順位付けしたユーザー数
+}
+
+// rankNeighbors values（user_id と value の列を持つサブクエリ）の降順で全ユーザーを順位付けし、
+// userID の前後 window 人ずつを通し番号順に返す
+// 論理削除されたユーザーとプロフィールを非公開にしたユーザー（userID 本人を除く）は含めない。
+// values に行が無いユーザーは0として最下位に並ぶ。userID が順位付けの対象でなければ空を返す
+func rankNeighbors(ctx context.Context, db *gorm.DB, values *gorm.DB, userID uint64, window int) ([]RankNeighbor, error) {
+	var neighbors []RankNeighbor
+	err := db.WithContext(ctx).Raw(`
+		WITH ranked AS (
+			SELECT u.id AS user_id, u.github_username, COALESCE(v.value, 0) AS value,
+				RANK() OVER (ORDER BY COALESCE(v.value, 0) DESC) AS rank,
+				ROW_NUMBER() OVER (ORDER BY COALESCE(v.value, 0) DESC, u.id) AS position,
+				COUNT(*) OVER () AS total
+			FROM users AS u
+			LEFT JOIN (?) AS v ON v.user_id = u.id
+			WHERE u.deleted_at IS NULL AND (u.is_public_profile OR u.id = ?)
+		)
+		SELECT ranked.* FROM ranked
+		JOIN ranked AS target ON target.user_id = ?
+		WHERE ranked.position BETWEEN target.position - ? AND target.position + ?
+		ORDER BY ranked.position
+	`, values, userID, userID, window, window).Scan(&neighbors).Error
+	if err != nil {
+		return nil, err
+	}
+	return neighbors, nil
+}
@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/keeee21/commit-town/api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DailyCommitTotal 日付ごとのコミット数の集計結果
+type DailyCommitTotal struct {
+	Date         time.Time
+	TotalCommits int
+}
+
+type RepoDailyCommitLogRepository struct {
+	db *gorm.DB
+}
+
+func NewRepoDailyCommitLogRepository(db *gorm.DB) *RepoDailyCommitLogRepository {
+	return &RepoDailyCommitLogRepository{db: db}
+}
+
+// WithTx トランザクション内で操作するリポジトリを返す
+func (logRepo *RepoDailyCommitLogRepository) WithTx(tx *gorm.DB) *RepoDailyCommitLogRepository {
+	return &RepoDailyCommitLogRepository{db: tx}
+}
+
+// Upsert リポジトリ×日付のコミット集計を作成または更新
+func (logRepo *RepoDailyCommitLogRepository) Upsert(ctx context.Context, log *models.RepoDailyCommitLog) error {
+	return logRepo.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_repo_id"}, {Name: "commit_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"commit_count", "raw_data", "updated_at"}),
+	}).Create(log).Error
+}
+
+// ListByUserRepoID 登録リポジトリの日次ログを期間で取得（日付昇順）
+func (logRepo *RepoDailyCommitLogRepository) ListByUserRepoID(ctx context.Context, userRepoID uint64, since, until time.Time) ([]models.RepoDailyCommitLog, error) {
+	var logs []models.RepoDailyCommitLog
+	err := logRepo.db.WithContext(ctx).
+		Where("user_repo_id = ? AND commit_date BETWEEN ? AND ?", userRepoID, since, until).
+		Order("commit_date").
+		Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// ListActiveDaysByUserRepoID コミットがあった日の日次ログを全期間で取得（日付昇順）
+func (logRepo *RepoDailyCommitLogRepository) ListActiveDaysByUserRepoID(ctx context.Context, userRepoID uint64) ([]models.RepoDailyCommitLog, error) {
+	var logs []models.RepoDailyCommitLog
+	err := logRepo.db.WithContext(ctx).
+		Where("user_repo_id = ? AND commit_count > 0", userRepoID).
+		Order("commit_date").
+		Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// ListLatestActiveDaysByUserRepoID コミットがあった日の日次ログを新しい順に limit 日分取得
+func (logRepo *RepoDailyCommitLogRepository) ListLatestActiveDaysByUserRepoID(ctx context.Context, userRepoID uint64, limit int) ([]models.RepoDailyCommitLog, error) {
+	var logs []models.RepoDailyCommitLog
+	err := logRepo.db.WithContext(ctx).
+		Where("user_repo_id = ? AND commit_count > 0", userRepoID).
+		Order("commit_date DESC").
+		Limit(limit).
+		Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// DateRange 日次ログの最古・最新の日付
+type DateRange struct {
+	First *time.Time
+	Last  *time.Time
+}
+
+// DateRangeByUserRepoID 登録リポジトリの日次ログの日付範囲を取得（ログが無ければ First/Last は nil）
+func (logRepo *RepoDailyCommitLogRepository) DateRangeByUserRepoID(ctx context.Context, userRepoID uint64) (DateRange, error) {
+	var dateRange DateRange
+	err := logRepo.db.WithContext(ctx).Model(&models.RepoDailyCommitLog{}).
+		Select("MIN(commit_date) AS first, MAX(commit_date) AS last").
+		Where("user_repo_id = ?", userRepoID).
+		Scan(&dateRange).Error
+	if err != nil {
+		return DateRange{}, err
+	}
+	return dateRange, nil
+}
+
+// DeleteByUserRepoID 登録リポジトリの日次ログを全て削除
+func (logRepo *RepoDailyCommitLogRepository) DeleteByUserRepoID(ctx context.Context, userRepoID uint64) error {
+	return logRepo.db.WithContext(ctx).Where("user_repo_id = ?", userRepoID).Delete(&models.RepoDailyCommitLog{}).Error
+}
+
+// DeleteByUserID ユーザーの全登録リポジトリの日次ログを削除し、削除した件数を返す
+func (logRepo *RepoDailyCommitLogRepository) DeleteByUserID(ctx context.Context, userID uint64) (int64, error) {
+	result := logRepo.db.WithContext(ctx).
+		Where("user_repo_id IN (SELECT id FROM user_repositories WHERE user_id = ?)", userID).
+		Delete(&models.RepoDailyCommitLog{})
+	return result.RowsAffected, result.Error
+}
+
+// ClearRawDataBefore cutoff より前の日次ログの RawData を batchSize 件ずつ NULL にし、NULL にした件数を返す（CommitCount は残す）
+// ロックを長く持たないよう1回の UPDATE は batchSize 件まで。NULL にしたものは対象外のため、途中で失敗しても再実行すれば続きから進む
+// コミット数は変わらないため updated_at は変えない
+func (logRepo *RepoDailyCommitLogRepository) ClearRawDataBefore(ctx context.Context, cutoff time.Time, batchSize int) (int64, error) {
+	var cleared int64
+	for {
+		batch := logRepo.db.WithContext(ctx).Model(&models.RepoDailyCommitLog{}).
+			Select("id").
+			Where("commit_date < ? AND raw_data IS NOT NULL", cutoff).
+			Order("id").
+			Limit(batchSize)
+		result := logRepo.db.WithContext(ctx).Model(&models.RepoDailyCommitLog{}).
+			Where("id IN (?)", batch).
+			UpdateColumn("raw_data", gorm.Expr("NULL"))
+		if result.Error != nil {
+			return cleared, result.Error
+		}
+		cleared += result.RowsAffected
+		if result.RowsAffected < int64(batchSize) {
+			return cleared, nil
+		}
+	}
+}
+
+// SumByUserID ユーザーの全登録リポジトリのコミット数を日付ごとに合算（日付昇順）
+// 無効化されたリポジトリは無効化した時点より前の日付のみ合算する
+func (logRepo *RepoDailyCommitLogRepository) SumByUserID(ctx context.Context, userID uint64, since, until time.Time) ([]DailyCommitTotal, error) {
+	var totals []DailyCommitTotal
+	err := logRepo.db.WithContext(ctx).
+		Table("repo_daily_commit_logs AS l").
+		Select("l.commit_date AS date, SUM(l.commit_count) AS total_commits").
+		Joins("JOIN user_repositories AS r ON r.id = l.user_repo_id").
+		Where("r.user_id = ? AND l.commit_date BETWEEN ? AND ?", userID, since, until).
+		Where("r.deactivated_at IS NULL OR l.commit_date < r.deactivated_at").
+		Group("l.commit_date").
+		Order("l.commit_date").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
+
+// RepoDayCount ある1日の登録リポジトリごとのコミット数
+type RepoDayCount struct {
+	UserRepoID   uint64
+	DisplayOwner string
+	DisplayName  string
+	CommitCount  int
+}
+
+// ListByUserIDAndDate ユーザーの登録リポジトリごとの date のコミット数を取得（コミットが多い順、0件のリポジトリは含まない）
+// 無効化されたリポジトリの扱いは SumByUserID と同じ（無効化した時点より前の日付のみ含める）
+func (logRepo *RepoDailyCommitLogRepository) ListByUserIDAndDate(ctx context.Context, userID uint64, date time.Time) ([]RepoDayCount, error) {
+	var counts []RepoDayCount
+	err := logRepo.db.WithContext(ctx).
+		Table("repo_daily_commit_logs AS l").
+		Select("r.id AS user_repo_id, r.display_owner, r.display_name, l.commit_count").
+		Joins("JOIN user_repositories AS r ON r.id = l.user_repo_id").
+		Where("r.user_id = ? AND l.commit_date = ? AND l.commit_count > 0", userID, date).
+		Where("r.deactivated_at IS NULL OR l.commit_date < r.deactivated_at").
+		Order("l.commit_count DESC, r.display_order, r.id").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// SumActive 論理削除されていない、プロフィールを公開しているユーザーの、無効化されていない登録リポジトリのコミット数を全期間で合算
+func (logRepo *RepoDailyCommitLogRepository) SumActive(ctx context.Context) (int64, error) {
+	var total int64
+	err := logRepo.db.WithContext(ctx).
+		Table("repo_daily_commit_logs AS l").
+		Select("COALESCE(SUM(l.commit_count), 0)").
+		Joins("JOIN user_repositories AS r ON r.id = l.user_repo_id AND r.deactivated_at IS NULL").
+		Joins("JOIN users AS u ON u.id = r.user_id AND u.deleted_at IS NULL AND u.is_public_profile").
+		Scan(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// TopByVisibility 公開設定が isPublic の登録リポジトリだけを対象に、期間内の合計コミット数が多いユーザーを limit 件取得
+// TopByTotalCommits と同じく、プロフィールを非公開にしたユーザーは含めない
+// 集計済みの UserDailyCommitLog ではなくリポジトリ別日次ログから合算する。
+// 無効化されたリポジトリの扱いは SumByUserID と同じ（無効化した時点より前の日付のみ合算）
+func (logRepo *RepoDailyCommitLogRepository) TopByVisibility(ctx context.Context, isPublic bool, since, until time.Time, limit int) ([]LeaderboardEntry, error) {
+	var entries []LeaderboardEntry
+	err := logRepo.db.WithContext(ctx).
+		Table("repo_daily_commit_logs AS l").
+		Select("u.id AS user_id, u.github_username, SUM(l.commit_count) AS total_commits").
+		Joins("JOIN user_repositories AS r ON r.id = l.user_repo_id").
+		Joins("JOIN users AS u ON u.id = r.user_id AND u.deleted_at IS NULL AND u.is_public_profile").
+		Where("r.is_public = ? AND l.commit_date BETWEEN ? AND ?", isPublic, since, until).
+		Where("r.deactivated_at IS NULL OR l.commit_date < r.deactivated_at").
+		Group("u.id, u.github_username").
+		Having("SUM(l.commit_count) > 0").
+		Order("total_commits DESC, u.id").
+		Limit(limit).
+		Scan(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
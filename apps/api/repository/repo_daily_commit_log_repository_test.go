@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/keeee21/commit-town/api/internal/testdb"
+	"github.com/keeee21/commit-town/api/models"
+	"gorm.io/datatypes"
+)
+
+// cutoff より前の RawData だけを batchSize 件ずつ NULL にし、コミット数・更新日時・新しい RawData は残す。再実行しても何もしない
+func TestRepoDailyCommitLogRepository_ClearRawDataBefore(t *testing.T) {
+	ctx := context.Background()
+	database := testdb.Open(t)
+	userRepo := NewUserRepository(database)
+	repoRepo := NewRepoRepository(database)
+	logRepo := NewRepoDailyCommitLogRepository(database)
+	user := createTestUser(t, userRepo, 1, "alice")
+
+	cutoff := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	var logs []*models.RepoDailyCommitLog
+	for _, name := range []string{"town", "dotfiles"} {
+		repo := &models.UserRepository{UserID: user.ID, RepoOwner: "alice", RepoName: name, IsPublic: true, CountMode: models.CountModeAll}
+		if err := repoRepo.Create(ctx, repo); err != nil {
+			t.Fatalf("failed to create repository %s: %v", name, err)
+		}
+		// cutoff の3日前から2日後まで（cutoff 当日は残す）
+		for d := -3; d <= 2; d++ {
+			commitLog := &models.RepoDailyCommitLog{UserRepoID: repo.ID, CommitDate: cutoff.AddDate(0, 0, d), CommitCount: d + 10, RawData: datatypes.JSON(`[{"sha":"abc"}]`)}
+			if err := logRepo.Upsert(ctx, commitLog); err != nil {
+				t.Fatalf("failed to store daily log: %v", err)
+			}
+			logs = append(logs, commitLog)
+		}
+	}
+	var before []models.RepoDailyCommitLog
+	if err := database.Order("id").Find(&before).Error; err != nil {
+		t.Fatalf("failed to read daily logs: %v", err)
+	}
+
+	cleared, err := logRepo.ClearRawDataBefore(ctx, cutoff, 2)
+	if err != nil {
+		t.Fatalf("ClearRawDataBefore returned an error: %v", err)
+	}
+	if cleared != 6 {
+		t.Errorf("cleared = %d, want 6 (3 old days in 2 repositories)", cleared)
+	}
+
+	var after []models.RepoDailyCommitLog
+	if err := database.Order("id").Find(&after).Error; err != nil {
+		t.Fatalf("failed to read daily logs: %v", err)
+	}
+	if len(after) != len(logs) {
+		t.Fatalf("got %d daily logs, want %d", len(after), len(logs))
+	}
+	for i, commitLog := range after {
+		old := commitLog.CommitDate.Before(cutoff)
+		if old != (len(commitLog.RawData) == 0) {
+			t.Errorf("%s: RawData = %s, want cleared only before %s", commitLog.CommitDate.Format("2006-01-02"), commitLog.RawData, cutoff.Format("2006-01-02"))
+		}
+		if commitLog.CommitCount != before[i].CommitCount {
+			t.Errorf("%s: CommitCount = %d, want %d", commitLog.CommitDate.Format("2006-01-02"), commitLog.CommitCount, before[i].CommitCount)
+		}
+		if !commitLog.UpdatedAt.Equal(before[i].UpdatedAt) {
+			t.Errorf("%s: UpdatedAt changed from %v to %v", commitLog.CommitDate.Format("2006-01-02"), before[i].UpdatedAt, commitLog.UpdatedAt)
+		}
+	}
+
+	again, err := logRepo.ClearRawDataBefore(ctx, cutoff, 2)
+	if err != nil {
+		t.Fatalf("second ClearRawDataBefore returned an error: %v", err)
+	}
+	if again != 0 {
+		t.Errorf("second run cleared %d, want 0", again)
+	}
+}
@@ -0,0 +1,385 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/keeee21/commit-town/api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RepoRepository struct {
+	db *gorm.DB
+}
+
+func NewRepoRepository(db *gorm.DB) *RepoRepository {
+	return &RepoRepository{db: db}
+}
+
+// WithTx トランザクション内で操作するリポジトリを返す
+func (repoRepo *RepoRepository) WithTx(tx *gorm.DB) *RepoRepository {
+	return &RepoRepository{db: tx}
+}
+
+// FindByID IDで登録リポジトリを検索
+func (repoRepo *RepoRepository) FindByID(ctx context.Context, id uint64) (*models.UserRepository, error) {
+	var repo models.UserRepository
+	err := repoRepo.db.WithContext(ctx).First(&repo, id).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &repo, nil
+}
+
+// FindByUserAndName ユーザーID・オーナー・リポジトリ名（大文字小文字を区別しない）で登録リポジトリを検索
+func (repoRepo *RepoRepository) FindByUserAndName(ctx context.Context, userID uint64, owner, name string) (*models.UserRepository, error) {
+	var repo models.UserRepository
+	err := repoRepo.db.WithContext(ctx).
+		Where("user_id = ? AND repo_owner = ? AND repo_name = ?", userID, strings.ToLower(owner), strings.ToLower(name)).
+		First(&repo).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &repo, nil
+}
+
+// ListByUserID ユーザーの登録リポジトリ一覧を表示順で取得（表示順が同じ場合は登録順）
+func (repoRepo *RepoRepository) ListByUserID(ctx context.Context, userID uint64) ([]models.UserRepository, error) {
+	var repos []models.UserRepository
+	err := repoRepo.db.WithContext(ctx).Where("user_id = ?", userID).Order("display_order, created_at, id").Find(&repos).Error
+	if err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+// ListActiveByUserID ユーザーの無効化されていない登録リポジトリ一覧を表示順で取得
+func (repoRepo *RepoRepository) ListActiveByUserID(ctx context.Context, userID uint64) ([]models.UserRepository, error) {
+	var repos []models.UserRepository
+	err := repoRepo.db.WithContext(ctx).Where("user_id = ? AND deactivated_at IS NULL", userID).Order("display_order, created_at, id").Find(&repos).Error
+	if err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+// ListActiveByName オーナー・リポジトリ名（大文字小文字を区別しない）が一致する無効化されていない登録リポジトリを全ユーザー分取得
+func (repoRepo *RepoRepository) ListActiveByName(ctx context.Context, owner, name string) ([]models.UserRepository, error) {
+	var repos []models.UserRepository
+	err := repoRepo.db.WithContext(ctx).
+		Where("repo_owner = ? AND repo_name = ? AND deactivated_at IS NULL", strings.ToLower(owner), strings.ToLower(name)).
+		Order("id").
+		Find(&repos).Error
+	if err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+// RepoSyncStatus 登録リポジトリと最新の日次ログ
+type RepoSyncStatus struct {
+	ID               uint64
+	DisplayOwner     string
+	DisplayName      string
+	DeactivatedAt    *time.Time
+	CreatedAt        time.Time
+	AccessStatus     string
+	LatestCommitDate *time.Time // ログの最新の日付（ログが無ければnil）
+	LastSyncedAt     *time.Time // ログが最後に書き込まれた日時（ログが無ければnil）
+}
+
+// ListSyncStatusByUserID ユーザーの登録リポジトリごとに最新の日次ログを取得（ログが無いリポジトリも含む）
+func (repoRepo *RepoRepository) ListSyncStatusByUserID(ctx context.Context, userID uint64) ([]RepoSyncStatus, error) {
+	var statuses []RepoSyncStatus
+	err := repoRepo.db.WithContext(ctx).
+		Table("user_repositories AS r").
+		Select("r.id, r.display_owner, r.display_name, r.deactivated_at, r.created_at, r.access_status, l.latest_commit_date, l.last_synced_at").
+		Joins(`LEFT JOIN (
+			SELECT user_repo_id, MAX(commit_date) AS latest_commit_date, MAX(updated_at) AS last_synced_at
+			FROM repo_daily_commit_logs
+			GROUP BY user_repo_id
+		) AS l ON l.user_repo_id = r.id`).
+		Where("r.user_id = ?", userID).
+		Order("r.display_order, r.created_at, r.id").
+		Scan(&statuses).Error
+	if err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+// RepoSearchEntry 検索結果の登録リポジトリと登録したユーザー
+type RepoSearchEntry struct {
+	ID             uint64
+	UserID         uint64
+	GitHubUsername string
+	DisplayOwner   string
+	DisplayName    string
+	IsPublic       bool
+	DeactivatedAt  *time.Time
+	CreatedAt      time.Time
+}
+
+// RepoSearchOrder Search の並び順（ByCreatedAt が false の場合はオーナー名・リポジトリ名順、同じ値はID順）
+type RepoSearchOrder struct {
+	ByCreatedAt bool
+	Desc        bool
+}
+
+// Search オーナー名・リポジトリ名の部分一致（大文字小文字を区別しない）で登録リポジトリを検索
+// 空の条件は絞り込みに使わない。論理削除されたユーザーの登録は含めない
+func (repoRepo *RepoRepository) Search(ctx context.Context, owner, name string, order RepoSearchOrder, limit, offset int) ([]RepoSearchEntry, error) {
+	dir := "ASC"
+	if order.Desc {
+		dir = "DESC"
+	}
+	orderBy := fmt.Sprintf("r.repo_owner %s, r.repo_name %s, r.id %s", dir, dir, dir)
+	if order.ByCreatedAt {
+		orderBy = fmt.Sprintf("r.created_at %s, r.id %s", dir, dir)
+	}
+
+	var entries []RepoSearchEntry
+	err := repoRepo.searchQuery(ctx, owner, name).
+		Select("r.id, r.user_id, u.github_username, r.display_owner, r.display_name, r.is_public, r.deactivated_at, r.created_at").
+		Order(orderBy).
+		Limit(limit).Offset(offset).
+		Scan(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// CountSearch Search と同じ条件に一致する登録リポジトリの件数を取得
+func (repoRepo *RepoRepository) CountSearch(ctx context.Context, owner, name string) (int64, error) {
+	var count int64
+	if err := repoRepo.searchQuery(ctx, owner, name).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// searchQuery Search・CountSearch の絞り込み条件
+func (repoRepo *RepoRepository) searchQuery(ctx context.Context, owner, name string) *gorm.DB {
+	query := repoRepo.db.WithContext(ctx).
+		Table("user_repositories AS r").
+		Joins("JOIN users AS u ON u.id = r.user_id AND u.deleted_at IS NULL")
+	if owner != "" {
+		query = query.Where(`r.repo_owner ILIKE ? ESCAPE '\'`, "%"+escapeLike(owner)+"%")
+	}
+	if name != "" {
+		query = query.Where(`r.repo_name ILIKE ? ESCAPE '\'`, "%"+escapeLike(name)+"%")
+	}
+	return query
+}
+
+// escapeLike LIKE のワイルドカード（% _）とエスケープ文字をそのままの文字として扱う
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// CountActiveByUserID ユーザーの無効化されていない登録リポジトリ数を取得
+func (repoRepo *RepoRepository) CountActiveByUserID(ctx context.Context, userID uint64) (int, error) {
+	var count int64
+	err := repoRepo.db.WithContext(ctx).Model(&models.UserRepository{}).
+		Where("user_id = ? AND deactivated_at IS NULL", userID).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// CountActive 論理削除されていない、プロフィールを公開しているユーザーの、無効化されていない登録リポジトリ数を取得
+func (repoRepo *RepoRepository) CountActive(ctx context.Context) (int64, error) {
+	var count int64
+	err := repoRepo.db.WithContext(ctx).
+		Table("user_repositories AS r").
+		Joins("JOIN users AS u ON u.id = r.user_id AND u.deleted_at IS NULL AND u.is_public_profile").
+		Where("r.deactivated_at IS NULL").
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// CountActiveByUserIDs 複数ユーザーの無効化されていない登録リポジトリ数を1クエリで取得（0件のユーザーは含まない）
+func (repoRepo *RepoRepository) CountActiveByUserIDs(ctx context.Context, userIDs []uint64) (map[uint64]int, error) {
+	var rows []struct {
+		UserID uint64
+		Count  int
+	}
+	err := repoRepo.db.WithContext(ctx).Model(&models.UserRepository{}).
+		Select("user_id, COUNT(*) AS count").
+		Where("user_id IN ? AND deactivated_at IS NULL", userIDs).
+		Group("user_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint64]int, len(rows))
+	for _, row := range rows {
+		counts[row.UserID] = row.Count
+	}
+	return counts, nil
+}
+
+// normalizeNames オーナー・リポジトリ名を小文字にそろえ、入力された表記は DisplayOwner・DisplayName に残す
+// GitHubは大文字小文字を区別しないため、表記違いの `Owner/Repo` と `owner/repo` を同じリポジトリとして扱う
+func normalizeNames(repo *models.UserRepository) {
+	if repo.DisplayOwner == "" {
+		repo.DisplayOwner = repo.RepoOwner
+	}
+	if repo.DisplayName == "" {
+		repo.DisplayName = repo.RepoName
+	}
+	repo.RepoOwner = strings.ToLower(repo.RepoOwner)
+	repo.RepoName = strings.ToLower(repo.RepoName)
+}
+
+// Create 登録リポジトリを作成（ユーザーが存在しない場合は ErrNotFound を返す）
+func (repoRepo *RepoRepository) Create(ctx context.Context, repo *models.UserRepository) error {
+	normalizeNames(repo)
+	isPublic := repo.IsPublic
+	if err := createInSavepoint(ctx, repoRepo.db, repo); err != nil {
+		return translateError(err)
+	}
+	return repoRepo.restorePrivate(ctx, repo, isPublic)
+}
+
+// Deactivate 登録リポジトリを無効化（無効化済みの場合は日時を変更しない）
+func (repoRepo *RepoRepository) Deactivate(ctx context.Context, id uint64, at time.Time) error {
+	return repoRepo.db.WithContext(ctx).Model(&models.UserRepository{}).
+		Where("id = ? AND deactivated_at IS NULL", id).
+		Update("deactivated_at", at).Error
+}
+
+// DeactivateStale cutoff より前に登録され、cutoff 以降の日次ログが無い有効な登録リポジトリをまとめて無効化し、無効化した件数を返す
+// 1つの UPDATE 文で行うため、途中で失敗しても一部だけが無効化されることはない。無効化済みのものは対象外（何度実行しても同じ結果になる）
+func (repoRepo *RepoRepository) DeactivateStale(ctx context.Context, cutoff, at time.Time) (int64, error) {
+	result := repoRepo.db.WithContext(ctx).Model(&models.UserRepository{}).
+		Where("deactivated_at IS NULL AND created_at < ?", cutoff).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM repo_daily_commit_logs AS l
+			WHERE l.user_repo_id = user_repositories.id AND l.commit_date >= ?
+		)`, cutoff).
+		Update("deactivated_at", at)
+	return result.RowsAffected, result.Error
+}
+
+// AdvanceLastSyncedAt 同期済みの時点を進める（既に at 以降まで同期済みの場合は変更しない）
+// 同期のたびに更新されるため updated_at は変えない
+func (repoRepo *RepoRepository) AdvanceLastSyncedAt(ctx context.Context, id uint64, at time.Time) error {
+	return repoRepo.db.WithContext(ctx).Model(&models.UserRepository{}).
+		Where("id = ? AND (last_synced_at IS NULL OR last_synced_at < ?)", id, at).
+		UpdateColumn("last_synced_at", at).Error
+}
+
+// UpdateFields 指定したカラムだけを更新する（キーはカラム名）
+// 登録リポジトリが存在しない場合は ErrNotFound を返す
+func (repoRepo *RepoRepository) UpdateFields(ctx context.Context, id uint64, fields map[string]any) error {
+	result := repoRepo.db.WithContext(ctx).Model(&models.UserRepository{}).Where("id = ?", id).Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete 登録リポジトリを物理削除
+func (repoRepo *RepoRepository) Delete(ctx context.Context, id uint64) error {
+	return repoRepo.db.WithContext(ctx).Delete(&models.UserRepository{}, id).Error
+}
+
+// DeleteByUserID ユーザーの登録リポジトリを全て物理削除し、削除した件数を返す
+// 日次ログ・リポジトリ単位のstreakが外部キーで参照しているため、先にそれらを削除しておくこと
+func (repoRepo *RepoRepository) DeleteByUserID(ctx context.Context, userID uint64) (int64, error) {
+	result := repoRepo.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.UserRepository{})
+	return result.RowsAffected, result.Error
+}
+
+// ReassignUser fromUserID の登録リポジトリを全て toUserID に付け替える
+func (repoRepo *RepoRepository) ReassignUser(ctx context.Context, fromUserID, toUserID uint64) error {
+	return repoRepo.db.WithContext(ctx).Model(&models.UserRepository{}).
+		Where("user_id = ?", fromUserID).
+		Update("user_id", toUserID).Error
+}
+
+// RenameOwner ユーザーの登録リポジトリのうち、オーナーが oldOwner（大文字小文字を区別しない）のものを newOwner に変更する
+// 組織など他のアカウントがオーナーのリポジトリは対象外。変更後と同じリポジトリが既に登録されている場合はそのままにする
+func (repoRepo *RepoRepository) RenameOwner(ctx context.Context, userID uint64, oldOwner, newOwner string) (int64, error) {
+	result := repoRepo.db.WithContext(ctx).Model(&models.UserRepository{}).
+		Where("user_id = ? AND repo_owner = ?", userID, strings.ToLower(oldOwner)).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM user_repositories AS dup
+			WHERE dup.user_id = user_repositories.user_id AND dup.repo_owner = ? AND dup.repo_name = user_repositories.repo_name
+		)`, strings.ToLower(newOwner)).
+		Updates(map[string]any{"repo_owner": strings.ToLower(newOwner), "display_owner": newOwner})
+	return result.RowsAffected, result.Error
+}
+
+// CreateIfNotExists 登録リポジトリを作成（ユーザーID・オーナー・リポジトリ名の一意インデックスで重複時は何もしない）
+// オーナー・リポジトリ名は小文字にそろえてから比べるため、表記だけが違う登録も重複として扱う。新規作成した場合は true を返す
+// ユーザーが存在しない場合は ErrNotFound を返す
+func (repoRepo *RepoRepository) CreateIfNotExists(ctx context.Context, repo *models.UserRepository) (bool, error) {
+	normalizeNames(repo)
+	isPublic := repo.IsPublic
+	result := repoRepo.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "repo_owner"}, {Name: "repo_name"}},
+		DoNothing: true,
+	}).Create(repo)
+	if result.Error != nil {
+		return false, translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	return true, repoRepo.restorePrivate(ctx, repo, isPublic)
+}
+
+// restorePrivate IsPublic は default:true のため Create では false が保存されない。非公開の場合は明示的に更新する
+func (repoRepo *RepoRepository) restorePrivate(ctx context.Context, repo *models.UserRepository, isPublic bool) error {
+	if isPublic {
+		return nil
+	}
+	repo.IsPublic = false
+	return repoRepo.db.WithContext(ctx).Model(repo).Update("is_public", false).Error
+}
+
+// Update 登録リポジトリを更新
+func (repoRepo *RepoRepository) Update(ctx context.Context, repo *models.UserRepository) error {
+	return repoRepo.db.WithContext(ctx).Save(repo).Error
+}
+
+// Upsert 登録リポジトリを作成または更新（ユーザーID・オーナー・リポジトリ名で判定、大文字小文字は区別しない）
+// 同時に作成されて一意インデックスに違反した場合は、読み直して更新する
+func (repoRepo *RepoRepository) Upsert(ctx context.Context, repo *models.UserRepository) error {
+	normalizeNames(repo)
+	return retryUpsert(func() error {
+		return repoRepo.upsert(ctx, repo)
+	})
+}
+
+func (repoRepo *RepoRepository) upsert(ctx context.Context, repo *models.UserRepository) error {
+	existing, err := repoRepo.FindByUserAndName(ctx, repo.UserID, repo.RepoOwner, repo.RepoName)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return repoRepo.Create(ctx, repo)
+		}
+		return err
+	}
+
+	repo.ID = existing.ID
+	repo.CreatedAt = existing.CreatedAt
+	repo.LastSyncedAt = existing.LastSyncedAt
+	repo.AccessStatus = existing.AccessStatus
+	return repoRepo.Update(ctx, repo)
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keeee21/commit-town/api/internal/testdb"
+	"github.com/keeee21/commit-town/api/models"
+)
+
+func TestNormalizeNames(t *testing.T) {
+	tests := []struct {
+		name                              string
+		repo                              models.UserRepository
+		wantOwner, wantName               string
+		wantDisplayOwner, wantDisplayName string
+	}{
+		{"mixed case", models.UserRepository{RepoOwner: "Alice", RepoName: "Town"}, "alice", "town", "Alice", "Town"},
+		{"already lowercase", models.UserRepository{RepoOwner: "alice", RepoName: "town"}, "alice", "town", "alice", "town"},
+		{"keeps existing display", models.UserRepository{RepoOwner: "alice", RepoName: "town", DisplayOwner: "Alice", DisplayName: "Town"}, "alice", "town", "Alice", "Town"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := tt.repo
+			normalizeNames(&repo)
+			if repo.RepoOwner != tt.wantOwner || repo.RepoName != tt.wantName {
+				t.Errorf("name = %s/%s, want %s/%s", repo.RepoOwner, repo.RepoName, tt.wantOwner, tt.wantName)
+			}
+			if repo.DisplayOwner != tt.wantDisplayOwner || repo.DisplayName != tt.wantDisplayName {
+				t.Errorf("display = %s/%s, want %s/%s", repo.DisplayOwner, repo.DisplayName, tt.wantDisplayOwner, tt.wantDisplayName)
+			}
+		})
+	}
+}
+
+// 表記違いの `Owner/Repo` と `owner/repo` は同じ1行になる
+func TestRepoRepository_CreateIfNotExists_CollapsesCasings(t *testing.T) {
+	ctx := context.Background()
+	database := testdb.Open(t)
+	userRepo := NewUserRepository(database)
+	repoRepo := NewRepoRepository(database)
+	user := createTestUser(t, userRepo, 1, "alice")
+
+	for i, name := range [][2]string{{"Owner", "Repo"}, {"owner", "repo"}} {
+		repo := &models.UserRepository{UserID: user.ID, RepoOwner: name[0], RepoName: name[1], IsPublic: true, CountMode: models.CountModeAll}
+		created, err := repoRepo.CreateIfNotExists(ctx, repo)
+		if err != nil {
+			t.Fatalf("CreateIfNotExists(%s/%s) returned an error: %v", name[0], name[1], err)
+		}
+		if want := i == 0; created != want {
+			t.Errorf("CreateIfNotExists(%s/%s) created = %v, want %v", name[0], name[1], created, want)
+		}
+	}
+
+	var count int64
+	if err := database.Model(&models.UserRepository{}).Where("user_id = ?", user.ID).Count(&count).Error; err != nil {
+		t.Fatalf("failed to count repositories: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("user has %d repositories, want 1", count)
+	}
+	if _, err := repoRepo.FindByUserAndName(ctx, user.ID, "OWNER", "REPO"); err != nil {
+		t.Errorf("FindByUserAndName with another casing returned an error: %v", err)
+	}
+}
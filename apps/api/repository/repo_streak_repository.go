@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/keeee21/commit-town/api/models"
+	"gorm.io/gorm"
+)
+
+type RepoStreakRepository struct {
+	db *gorm.DB
+}
+
+func NewRepoStreakRepository(db *gorm.DB) *RepoStreakRepository {
+	return &RepoStreakRepository{db: db}
+}
+
+// WithTx トランザクション内で操作するリポジトリを返す
+func (repoStreakRepo *RepoStreakRepository) WithTx(tx *gorm.DB) *RepoStreakRepository {
+	return &RepoStreakRepository{db: tx}
+}
+
+// FindActiveByUserRepoID 登録リポジトリの継続中のstreakを取得
+func (repoStreakRepo *RepoStreakRepository) FindActiveByUserRepoID(ctx context.Context, userRepoID uint64) (*models.RepoStreak, error) {
+	var streak models.RepoStreak
+	err := repoStreakRepo.db.WithContext(ctx).Where("user_repo_id = ? AND active = ?", userRepoID, true).First(&streak).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &streak, nil
+}
+
+// LengthsByUserRepoID 継続中・過去最長のstreak日数を1クエリで取得（streakが無ければ0）
+func (repoStreakRepo *RepoStreakRepository) LengthsByUserRepoID(ctx context.Context, userRepoID uint64) (StreakLengths, error) {
+	var lengths StreakLengths
+	err := repoStreakRepo.db.WithContext(ctx).Model(&models.RepoStreak{}).
+		Select("COALESCE(MAX(length) FILTER (WHERE active), 0) AS current, COALESCE(MAX(length), 0) AS longest").
+		Where("user_repo_id = ?", userRepoID).
+		Scan(&lengths).Error
+	if err != nil {
+		return StreakLengths{}, err
+	}
+	return lengths, nil
+}
+
+// ReplaceByUserRepoID 登録リポジトリのstreak履歴を丸ごと置き換え（streaks が空なら削除のみ）
+func (repoStreakRepo *RepoStreakRepository) ReplaceByUserRepoID(ctx context.Context, userRepoID uint64, streaks []models.RepoStreak) error {
+	return repoStreakRepo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_repo_id = ?", userRepoID).Delete(&models.RepoStreak{}).Error; err != nil {
+			return err
+		}
+		if len(streaks) == 0 {
+			return nil
+		}
+		return tx.Create(&streaks).Error
+	})
+}
+
+// DeleteByUserID ユーザーの全登録リポジトリのstreakを削除し、削除した件数を返す
+func (repoStreakRepo *RepoStreakRepository) DeleteByUserID(ctx context.Context, userID uint64) (int64, error) {
+	result := repoStreakRepo.db.WithContext(ctx).
+		Where("user_repo_id IN (SELECT id FROM user_repositories WHERE user_id = ?)", userID).
+		Delete(&models.RepoStreak{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/keeee21/commit-town/api/models"
+	"gorm.io/gorm"
+)
+
+type StreakFreezeRepository struct {
+	db *gorm.DB
+}
+
+func NewStreakFreezeRepository(db *gorm.DB) *StreakFreezeRepository {
+	return &StreakFreezeRepository{db: db}
+}
+
+// WithTx トランザクション内で操作するリポジトリを返す
+func (freezeRepo *StreakFreezeRepository) WithTx(tx *gorm.DB) *StreakFreezeRepository {
+	return &StreakFreezeRepository{db: tx}
+}
+
+// FindByID 凍結期間を取得
+func (freezeRepo *StreakFreezeRepository) FindByID(ctx context.Context, id uint64) (*models.StreakFreeze, error) {
+	var freeze models.StreakFreeze
+	if err := freezeRepo.db.WithContext(ctx).First(&freeze, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &freeze, nil
+}
+
+// ListByUserID ユーザーの凍結期間を開始日の古い順に取得
+func (freezeRepo *StreakFreezeRepository) ListByUserID(ctx context.Context, userID uint64) ([]models.StreakFreeze, error) {
+	var freezes []models.StreakFreeze
+	err := freezeRepo.db.WithContext(ctx).Where("user_id = ?", userID).Order("start_date, id").Find(&freezes).Error
+	if err != nil {
+		return nil, err
+	}
+	return freezes, nil
+}
+
+// ExistsOverlappingOrAdjacent start〜end（両端を含む）と重なる、または間を空けずに隣り合う凍結期間があるか
+// 隣り合う凍結を続けて予約すると、日数の上限を超える期間を凍結できてしまうため
+func (freezeRepo *StreakFreezeRepository) ExistsOverlappingOrAdjacent(ctx context.Context, userID uint64, start, end time.Time) (bool, error) {
+	var count int64
+	err := freezeRepo.db.WithContext(ctx).Model(&models.StreakFreeze{}).
+		Where("user_id = ? AND start_date <= ? AND end_date >= ?", userID, end.AddDate(0, 0, 1), start.AddDate(0, 0, -1)).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Create 凍結期間を作成（ユーザーが存在しない場合は ErrNotFound を返す）
+func (freezeRepo *StreakFreezeRepository) Create(ctx context.Context, freeze *models.StreakFreeze) error {
+	return translateError(freezeRepo.db.WithContext(ctx).Create(freeze).Error)
+}
+
+// Delete 凍結期間を削除
+func (freezeRepo *StreakFreezeRepository) Delete(ctx context.Context, id uint64) error {
+	return freezeRepo.db.WithContext(ctx).Delete(&models.StreakFreeze{}, id).Error
+}
+
+// DeleteByUserID ユーザーの凍結期間を全て削除し、削除した件数を返す
+func (freezeRepo *StreakFreezeRepository) DeleteByUserID(ctx context.Context, userID uint64) (int64, error) {
+	result := freezeRepo.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.StreakFreeze{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/keeee21/commit-town/api/models"
+	"gorm.io/gorm"
+)
+
+type StreakRepository struct {
+	db *gorm.DB
+}
+
+func NewStreakRepository(db *gorm.DB) *StreakRepository {
+	return &StreakRepository{db: db}
+}
+
+// WithTx トランザクション内で操作するリポジトリを返す
+func (streakRepo *StreakRepository) WithTx(tx *gorm.DB) *StreakRepository {
+	return &StreakRepository{db: tx}
+}
+
+// FindActiveByUserID ユーザーの継続中のstreakを取得
+func (streakRepo *StreakRepository) FindActiveByUserID(ctx context.Context, userID uint64) (*models.UserStreak, error) {
+	var streak models.UserStreak
+	err := streakRepo.db.WithContext(ctx).Where("user_id = ? AND active = ?", userID, true).First(&streak).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &streak, nil
+}
+
+// StreakOrder ListByUserID の並び順（ByLength が false の場合は開始日順、同じ値は開始日・ID順）
+type StreakOrder struct {
+	ByLength bool
+	Desc     bool
+}
+
+// ListByUserID ユーザーの全streakを order の順に取得
+func (streakRepo *StreakRepository) ListByUserID(ctx context.Context, userID uint64, order StreakOrder, limit, offset int) ([]models.UserStreak, error) {
+	dir := "ASC"
+	if order.Desc {
+		dir = "DESC"
+	}
+	orderBy := fmt.Sprintf("start_date %s, id %s", dir, dir)
+	if order.ByLength {
+		orderBy = fmt.Sprintf("length %s, %s", dir, orderBy)
+	}
+
+	var streaks []models.UserStreak
+	err := streakRepo.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order(orderBy).
+		Limit(limit).Offset(offset).
+		Find(&streaks).Error
+	if err != nil {
+		return nil, err
+	}
+	return streaks, nil
+}
+
+// CountByUserID ユーザーのstreakの件数を取得（ListByUserID の全件数）
+func (streakRepo *StreakRepository) CountByUserID(ctx context.Context, userID uint64) (int64, error) {
+	var count int64
+	err := streakRepo.db.WithContext(ctx).Model(&models.UserStreak{}).Where("user_id = ?", userID).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// StreakLengths 継続中のstreakと過去最長のstreakの日数
+type StreakLengths struct {
+	Current int
+	Longest int
+}
+
+// LengthsByUserID 継続中・過去最長のstreak日数を1クエリで取得（streakが無ければ0）
+func (streakRepo *StreakRepository) LengthsByUserID(ctx context.Context, userID uint64) (StreakLengths, error) {
+	var lengths StreakLengths
+	err := streakRepo.db.WithContext(ctx).Model(&models.UserStreak{}).
+		Select("COALESCE(MAX(length) FILTER (WHERE active), 0) AS current, COALESCE(MAX(length), 0) AS longest").
+		Where("user_id = ?", userID).
+		Scan(&lengths).Error
+	if err != nil {
+		return StreakLengths{}, err
+	}
+	return lengths, nil
+}
+
+// LengthsByUserIDs 複数ユーザーの継続中・過去最長のstreak日数を1クエリで取得（streakが無いユーザーは含まない）
+func (streakRepo *StreakRepository) LengthsByUserIDs(ctx context.Context, userIDs []uint64) (map[uint64]StreakLengths, error) {
+	var rows []struct {
+		UserID uint64
+		StreakLengths
+	}
+	err := streakRepo.db.WithContext(ctx).Model(&models.UserStreak{}).
+		Select("user_id, COALESCE(MAX(length) FILTER (WHERE active), 0) AS current, COALESCE(MAX(length), 0) AS longest").
+		Where("user_id IN ?", userIDs).
+		Group("user_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	lengths := make(map[uint64]StreakLengths, len(rows))
+	for _, row := range rows {
+		lengths[row.UserID] = row.StreakLengths
+	}
+	return lengths, nil
+}
+
+// StreakRankEntry streakランキングの1行
+type StreakRankEntry struct {
+	Rank           int
+	UserID         uint64
+	GitHubUsername string
+	Length         int
+}
+
+// AtRiskEntry 継続中のstreakがあり、ローカル日付の今日まだコミットしていないユーザー
+type AtRiskEntry struct {
+	UserID         uint64
+	GitHubUsername string
+	Timezone       string
+	Length         int // 継続中のstreakの日数
+}
+
+// ListAtRisk 継続中のstreakがあり、now のユーザーのローカル日付（タイムゾーン未設定はUTC）の日次ログにコミットが無いユーザーを取得
+// 日次ログの date はローカル日付のUTC0時のため、now をユーザーのタイムゾーンで日付に切り捨ててからUTCの0時に戻して比べる
+func (streakRepo *StreakRepository) ListAtRisk(ctx context.Context, now time.Time) ([]AtRiskEntry, error) {
+	var entries []AtRiskEntry
+	err := streakRepo.db.WithContext(ctx).
+		Table("user_streaks AS s").
+		Select("u.id AS user_id, u.github_username, u.timezone, s.length").
+		Joins("JOIN users AS u ON u.id = s.user_id AND u.deleted_at IS NULL").
+		Where("s.active = ?", true).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM user_daily_commit_logs AS l
+			WHERE l.user_id = u.id AND l.total_commits > 0
+			AND l.date = date_trunc('day', ?::timestamptz AT TIME ZONE COALESCE(NULLIF(u.timezone, ''), 'UTC')) AT TIME ZONE 'UTC'
+		)`, now).
+		Order("s.length DESC, u.id").
+		Scan(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// TopCurrent 継続中のstreakが長いユーザーを offset 件目から limit 件取得
+func (streakRepo *StreakRepository) TopCurrent(ctx context.Context, limit, offset int) ([]StreakRankEntry, error) {
+	return streakRepo.top(ctx, true, limit, offset)
+}
+
+// TopLongest 過去を含めた最長streakが長いユーザーを offset 件目から limit 件取得（継続中かどうかは問わない）
+func (streakRepo *StreakRepository) TopLongest(ctx context.Context, limit, offset int) ([]StreakRankEntry, error) {
+	return streakRepo.top(ctx, false, limit, offset)
+}
+
+// top ユーザーごとの最長streakで順位付けする。同じ長さは同順位で、並びはユーザーID順
+// 論理削除されたユーザーとプロフィールを非公開にしたユーザーは含めない
+// 順位はページングの前に全体で計算するため、offset を指定しても通しの順位になる
+func (streakRepo *StreakRepository) top(ctx context.Context, activeOnly bool, limit, offset int) ([]StreakRankEntry, error) {
+	lengths := streakRepo.db.WithContext(ctx).
+		Table("user_streaks AS s").
+		Select("s.user_id, u.github_username, MAX(s.length) AS length").
+		Joins("JOIN users AS u ON u.id = s.user_id AND u.deleted_at IS NULL AND u.is_public_profile").
+		Group("s.user_id, u.github_username")
+	if activeOnly {
+		lengths = lengths.Where("s.active = ?", true)
+	}
+
+	var entries []StreakRankEntry
+	err := streakRepo.db.WithContext(ctx).
+		Table("(?) AS t", lengths).
+		Select("RANK() OVER (ORDER BY t.length DESC) AS rank, t.user_id, t.github_username, t.length").
+		Order("t.length DESC, t.user_id").
+		Limit(limit).
+		Offset(offset).
+		Scan(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// NeighborsByCurrent 継続中のstreakの長さで順位付けし、userID の前後 window 人ずつを取得（継続中のstreakが無いユーザーは0）
+func (streakRepo *StreakRepository) NeighborsByCurrent(ctx context.Context, userID uint64, window int) ([]RankNeighbor, error) {
+	values := streakRepo.db.
+		Table("user_streaks").
+		Select("user_id, MAX(length) AS value").
+		Where("active = ?", true).
+		Group("user_id")
+	return rankNeighbors(ctx, streakRepo.db, values, userID, window)
+}
+
+// MaxActiveLength 論理削除されていない、プロフィールを公開しているユーザーの継続中のstreakのうち最長の日数を取得（無ければ0）
+func (streakRepo *StreakRepository) MaxActiveLength(ctx context.Context) (int, error) {
+	var length int
+	err := streakRepo.db.WithContext(ctx).
+		Table("user_streaks AS s").
+		Select("COALESCE(MAX(s.length), 0)").
+		Joins("JOIN users AS u ON u.id = s.user_id AND u.deleted_at IS NULL AND u.is_public_profile").
+		Where("s.active = ?", true).
+		Scan(&length).Error
+	if err != nil {
+		return 0, err
+	}
+	return length, nil
+}
+
+// MaxLengthByUserID ユーザーの過去を含めた最長streakの日数を取得（streakが無ければ0）
+func (streakRepo *StreakRepository) MaxLengthByUserID(ctx context.Context, userID uint64) (int, error) {
+	var length int
+	err := streakRepo.db.WithContext(ctx).Model(&models.UserStreak{}).
+		Select("COALESCE(MAX(length), 0)").
+		Where("user_id = ?", userID).
+		Scan(&length).Error
+	if err != nil {
+		return 0, err
+	}
+	return length, nil
+}
+
+// ReplaceByUserID ユーザーのstreak履歴を丸ごと置き換え、users.streaks_replaced_at を現在時刻にする
+// streak が0件になった場合も差分同期のクライアントが置き換えに気づけるよう、ユーザーの Version・UpdatedAt は変えずに記録する
+func (streakRepo *StreakRepository) ReplaceByUserID(ctx context.Context, userID uint64, streaks []models.UserStreak) error {
+	return streakRepo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserStreak{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE users SET streaks_replaced_at = NOW() WHERE id = ?", userID).Error; err != nil {
+			return err
+		}
+		if len(streaks) == 0 {
+			return nil
+		}
+		if err := tx.Create(&streaks).Error; err != nil {
+			return err
+		}
+		// Active は default:true のため Create では false が保存されない。終了済みのstreakを明示的に更新する
+		return tx.Model(&models.UserStreak{}).
+			Where("user_id = ? AND end_date IS NOT NULL", userID).
+			Update("active", false).Error
+	})
+}
+
+// DeleteByUserID ユーザーのstreakを全て削除し、削除した件数を返す
+func (streakRepo *StreakRepository) DeleteByUserID(ctx context.Context, userID uint64) (int64, error) {
+	result := streakRepo.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.UserStreak{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/keeee21/commit-town/api/models"
+	"gorm.io/gorm"
+)
+
+type SyncJobRunRepository struct {
+	db *gorm.DB
+}
+
+func NewSyncJobRunRepository(db *gorm.DB) *SyncJobRunRepository {
+	return &SyncJobRunRepository{db: db}
+}
+
+// Create 実行記録を作成
+func (runRepo *SyncJobRunRepository) Create(ctx context.Context, run *models.SyncJobRun) error {
+	return runRepo.db.WithContext(ctx).Create(run).Error
+}
+
+// Update 実行記録を更新（終了時の状態・件数・エラーを書き込む）
+func (runRepo *SyncJobRunRepository) Update(ctx context.Context, run *models.SyncJobRun) error {
+	return runRepo.db.WithContext(ctx).Save(run).Error
+}
+
+// ListRecent 新しい順に実行記録を取得
+func (runRepo *SyncJobRunRepository) ListRecent(ctx context.Context, limit int) ([]models.SyncJobRun, error) {
+	var runs []models.SyncJobRun
+	err := runRepo.db.WithContext(ctx).Order("started_at DESC, id DESC").Limit(limit).Find(&runs).Error
+	if err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// maxUpsertAttempts Upsert の読んでから書くまでの間に同じキーの行が作られた場合に、読み直してやり直す回数の上限
+const maxUpsertAttempts = 3
+
+// uniqueViolation PostgreSQL の一意制約違反（unique_violation）の SQLSTATE
+const uniqueViolation = "23505"
+
+// isUniqueViolation 一意インデックスに違反して INSERT が失敗したか
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+// retryUpsert 同時に Upsert されて両方が「存在しない」と判断し、片方の作成が一意インデックスに違反した場合に
+// 読み直しから upsert をやり直す（2回目以降は既存の行の更新になる）
+func retryUpsert(upsert func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := upsert()
+		if err == nil || !isUniqueViolation(err) || attempt == maxUpsertAttempts {
+			return err
+		}
+	}
+}
+
+// createInSavepoint value を作成する。呼び出し元がトランザクション内の場合はセーブポイントを使い、
+// 一意インデックス違反で失敗してもトランザクションを続けて読み直せるようにする
+func createInSavepoint(ctx context.Context, db *gorm.DB, value any) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Create(value).Error
+	})
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/keeee21/commit-town/api/internal/testdb"
+	"github.com/keeee21/commit-town/api/models"
+	"gorm.io/gorm"
+)
+
+func TestRetryUpsert(t *testing.T) {
+	duplicate := fmt.Errorf("failed to create: %w", &pgconn.PgError{Code: uniqueViolation})
+	other := errors.New("connection reset")
+
+	tests := []struct {
+		name         string
+		errs         []error // 各回の upsert の結果（足りない分は成功）
+		wantErr      error
+		wantAttempts int
+	}{
+		{"succeeds first time", nil, nil, 1},
+		{"retries after a duplicate", []error{duplicate}, nil, 2},
+		{"gives up after max attempts", []error{duplicate, duplicate, duplicate}, duplicate, maxUpsertAttempts},
+		{"does not retry other errors", []error{other}, other, 1},
+		{"stops at another error", []error{duplicate, other}, other, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := retryUpsert(func() error {
+				attempts++
+				if attempts <= len(tt.errs) {
+					return tt.errs[attempts-1]
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+// 存在確認と作成の間に同じGitHubアカウントの行が作られても、既存の行の更新として成功する
+func TestUserRepository_Upsert_RecoversFromCreateRace(t *testing.T) {
+	ctx := context.Background()
+	database := testdb.Open(t)
+	userRepo := NewUserRepository(database)
+
+	// 最初の存在確認の直後に、別のリクエストが先に作成したことにする
+	var raced atomic.Bool
+	var competitor models.User
+	err := database.Callback().Query().After("gorm:query").Register("test:race_users", func(tx *gorm.DB) {
+		if tx.Statement.Table != "users" || !raced.CompareAndSwap(false, true) {
+			return
+		}
+		competitor = models.User{GitHubUserID: 42, GitHubUsername: "alice", Email: "alice@example.com", Timezone: "UTC"}
+		if err := database.Session(&gorm.Session{NewDB: true}).Create(&competitor).Error; err != nil {
+			t.Errorf("failed to create the competing user: %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to register callback: %v", err)
+	}
+
+	user := &models.User{GitHubUserID: 42, GitHubUsername: "alice-renamed", Email: "renamed@example.com", Timezone: "UTC"}
+	if err := userRepo.Upsert(ctx, user); err != nil {
+		t.Fatalf("Upsert returned an error: %v", err)
+	}
+	if !raced.Load() {
+		t.Fatal("the race was not simulated")
+	}
+	if user.ID != competitor.ID {
+		t.Errorf("ID = %d, want the competing row %d", user.ID, competitor.ID)
+	}
+	reloaded, err := userRepo.FindByID(ctx, competitor.ID)
+	if err != nil {
+		t.Fatalf("failed to reload user: %v", err)
+	}
+	if reloaded.GitHubUsername != "alice-renamed" {
+		t.Errorf("GitHubUsername = %q, want the upserted value", reloaded.GitHubUsername)
+	}
+}
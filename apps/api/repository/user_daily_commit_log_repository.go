@@ -0,0 +1,357 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/keeee21/commit-town/api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UserDailyCommitLogRepository struct {
+	db *gorm.DB
+}
+
+func NewUserDailyCommitLogRepository(db *gorm.DB) *UserDailyCommitLogRepository {
+	return &UserDailyCommitLogRepository{db: db}
+}
+
+// WithTx トランザクション内で操作するリポジトリを返す
+func (logRepo *UserDailyCommitLogRepository) WithTx(tx *gorm.DB) *UserDailyCommitLogRepository {
+	return &UserDailyCommitLogRepository{db: tx}
+}
+
+// Upsert ユーザー×日付のコミット集計を作成または更新
+func (logRepo *UserDailyCommitLogRepository) Upsert(ctx context.Context, log *models.UserDailyCommitLog) error {
+	return logRepo.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"total_commits", "updated_at"}),
+	}).Create(log).Error
+}
+
+// FindByUserIDAndDate ユーザーの指定日の日次ログを取得
+func (logRepo *UserDailyCommitLogRepository) FindByUserIDAndDate(ctx context.Context, userID uint64, date time.Time) (*models.UserDailyCommitLog, error) {
+	var log models.UserDailyCommitLog
+	err := logRepo.db.WithContext(ctx).Where("user_id = ? AND date = ?", userID, date).First(&log).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &log, nil
+}
+
+// ListByUserID ユーザーの日次ログを期間で取得（日付昇順）
+func (logRepo *UserDailyCommitLogRepository) ListByUserID(ctx context.Context, userID uint64, since, until time.Time) ([]models.UserDailyCommitLog, error) {
+	var logs []models.UserDailyCommitLog
+	err := logRepo.db.WithContext(ctx).
+		Where("user_id = ? AND date BETWEEN ? AND ?", userID, since, until).
+		Order("date").
+		Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// ListUpdatedSince ユーザーの日次ログのうち updated_at が since より後のものを取得（日付昇順）
+func (logRepo *UserDailyCommitLogRepository) ListUpdatedSince(ctx context.Context, userID uint64, since time.Time) ([]models.UserDailyCommitLog, error) {
+	var logs []models.UserDailyCommitLog
+	err := logRepo.db.WithContext(ctx).
+		Where("user_id = ? AND updated_at > ?", userID, since).
+		Order("date").
+		Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// StreamByUserID ユーザーの日次ログを期間で1行ずつ読み出し、fnに渡す（日付昇順）
+// 全件をメモリに載せないため、大量の履歴でも使用できる
+func (logRepo *UserDailyCommitLogRepository) StreamByUserID(ctx context.Context, userID uint64, since, until time.Time, fn func(log *models.UserDailyCommitLog) error) error {
+	rows, err := logRepo.db.WithContext(ctx).Model(&models.UserDailyCommitLog{}).
+		Where("user_id = ? AND date BETWEEN ? AND ?", userID, since, until).
+		Order("date").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var log models.UserDailyCommitLog
+		if err := logRepo.db.WithContext(ctx).ScanRows(rows, &log); err != nil {
+			return err
+		}
+		if err := fn(&log); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ListActiveDays コミットがあった日の日次ログを全期間で取得（日付昇順）
+func (logRepo *UserDailyCommitLogRepository) ListActiveDays(ctx context.Context, userID uint64) ([]models.UserDailyCommitLog, error) {
+	var logs []models.UserDailyCommitLog
+	err := logRepo.db.WithContext(ctx).
+		Where("user_id = ? AND total_commits > 0", userID).
+		Order("date").
+		Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// SumTotalByUserID ユーザーの全期間の合計コミット数を取得
+func (logRepo *UserDailyCommitLogRepository) SumTotalByUserID(ctx context.Context, userID uint64) (int, error) {
+	var total int
+	err := logRepo.db.WithContext(ctx).Model(&models.UserDailyCommitLog{}).
+		Select("COALESCE(SUM(total_commits), 0)").
+		Where("user_id = ?", userID).
+		Scan(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// SumInRange ユーザーの期間内（since〜until、両端を含む）の合計コミット数を取得
+func (logRepo *UserDailyCommitLogRepository) SumInRange(ctx context.Context, userID uint64, since, until time.Time) (int, error) {
+	var total int
+	err := logRepo.db.WithContext(ctx).Model(&models.UserDailyCommitLog{}).
+		Select("COALESCE(SUM(total_commits), 0)").
+		Where("user_id = ? AND date BETWEEN ? AND ?", userID, since, until).
+		Scan(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// ActivityBounds コミットがあった最初の日と最後の日を取得
+// コミットが無いユーザーや論理削除されたユーザーの場合は first/last とも nil
+func (logRepo *UserDailyCommitLogRepository) ActivityBounds(ctx context.Context, userID uint64) (first, last *time.Time, err error) {
+	var bounds DateRange
+	err = logRepo.db.WithContext(ctx).
+		Table("user_daily_commit_logs AS l").
+		Select("MIN(l.date) AS first, MAX(l.date) AS last").
+		Joins("JOIN users AS u ON u.id = l.user_id AND u.deleted_at IS NULL").
+		Where("l.user_id = ? AND l.total_commits > 0", userID).
+		Scan(&bounds).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	return bounds.First, bounds.Last, nil
+}
+
+// WeekdayTotal 曜日（0=日曜〜6=土曜）ごとの合計コミット数
+type WeekdayTotal struct {
+	Weekday      int
+	TotalCommits int
+}
+
+// SumByWeekday ユーザーの期間内の合計コミット数を曜日ごとに集計（コミットが無い曜日は含まない）
+// date は UTC の日付のため、曜日も UTC で数える
+func (logRepo *UserDailyCommitLogRepository) SumByWeekday(ctx context.Context, userID uint64, since, until time.Time) ([]WeekdayTotal, error) {
+	var totals []WeekdayTotal
+	err := logRepo.db.WithContext(ctx).Model(&models.UserDailyCommitLog{}).
+		Select("EXTRACT(DOW FROM date)::int AS weekday, SUM(total_commits) AS total_commits").
+		Where("user_id = ? AND date BETWEEN ? AND ?", userID, since, until).
+		Group("weekday").
+		Order("weekday").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
+
+// LeaderboardEntry ランキングの1行（ユーザーごとの期間内合計コミット数）
+type LeaderboardEntry struct {
+	UserID         uint64
+	GitHubUsername string
+	TotalCommits   int
+}
+
+// TopByTotalCommits 期間内の合計コミット数が多いユーザーを limit 件取得（同数の場合はユーザーID順）
+// 論理削除されたユーザーとプロフィールを非公開にしたユーザーは含めない
+func (logRepo *UserDailyCommitLogRepository) TopByTotalCommits(ctx context.Context, since, until time.Time, limit int) ([]LeaderboardEntry, error) {
+	var entries []LeaderboardEntry
+	err := logRepo.db.WithContext(ctx).
+		Table("user_daily_commit_logs AS l").
+		Select("u.id AS user_id, u.github_username, SUM(l.total_commits) AS total_commits").
+		Joins("JOIN users AS u ON u.id = l.user_id AND u.deleted_at IS NULL AND u.is_public_profile").
+		Where("l.date BETWEEN ? AND ?", since, until).
+		Group("u.id, u.github_username").
+		Having("SUM(l.total_commits) > 0").
+		Order("total_commits DESC, u.id").
+		Limit(limit).
+		Scan(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// NeighborsByTotalCommits 期間内の合計コミット数で順位付けし、userID の前後 window 人ずつを取得
+func (logRepo *UserDailyCommitLogRepository) NeighborsByTotalCommits(ctx context.Context, userID uint64, since, until time.Time, window int) ([]RankNeighbor, error) {
+	values := logRepo.db.
+		Table("user_daily_commit_logs").
+		Select("user_id, SUM(total_commits) AS value").
+		Where("date BETWEEN ? AND ?", since, until).
+		Group("user_id")
+	return rankNeighbors(ctx, logRepo.db, values, userID, window)
+}
+
+// CommitTotals 全期間と直近期間の合計コミット数
+type CommitTotals struct {
+	All    int
+	Recent int
+}
+
+// TotalsByUserID 全期間の合計と recentSince 以降の合計を1クエリで取得
+func (logRepo *UserDailyCommitLogRepository) TotalsByUserID(ctx context.Context, userID uint64, recentSince time.Time) (CommitTotals, error) {
+	var totals CommitTotals
+	err := logRepo.db.WithContext(ctx).Model(&models.UserDailyCommitLog{}).
+		Select("COALESCE(SUM(total_commits), 0) AS \"all\", COALESCE(SUM(total_commits) FILTER (WHERE date >= ?), 0) AS recent", recentSince).
+		Where("user_id = ?", userID).
+		Scan(&totals).Error
+	if err != nil {
+		return CommitTotals{}, err
+	}
+	return totals, nil
+}
+
+// UserActivity ユーザーの合計コミット数とコミットがあった最初・最後の日（コミットが無ければ nil）
+type UserActivity struct {
+	CommitTotals
+	First *time.Time
+	Last  *time.Time
+}
+
+// ActivityByUserIDs 複数ユーザーの TotalsByUserID と ActivityBounds に相当する値を1クエリで取得（日次ログが無いユーザーは含まない）
+func (logRepo *UserDailyCommitLogRepository) ActivityByUserIDs(ctx context.Context, userIDs []uint64, recentSince time.Time) (map[uint64]UserActivity, error) {
+	var rows []struct {
+		UserID uint64
+		UserActivity
+	}
+	err := logRepo.db.WithContext(ctx).Model(&models.UserDailyCommitLog{}).
+		Select(`user_id,
+			COALESCE(SUM(total_commits), 0) AS "all",
+			COALESCE(SUM(total_commits) FILTER (WHERE date >= ?), 0) AS recent,
+			MIN(date) FILTER (WHERE total_commits > 0) AS first,
+			MAX(date) FILTER (WHERE total_commits > 0) AS last`, recentSince).
+		Where("user_id IN ?", userIDs).
+		Group("user_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	activity := make(map[uint64]UserActivity, len(rows))
+	for _, row := range rows {
+		activity[row.UserID] = row.UserActivity
+	}
+	return activity, nil
+}
+
+// MergeInto fromUserID の日次ログを toUserID に移す（同じ日付のログがある場合はコミット数を合算する）
+func (logRepo *UserDailyCommitLogRepository) MergeInto(ctx context.Context, fromUserID, toUserID uint64) error {
+	err := logRepo.db.WithContext(ctx).Exec(`
+		INSERT INTO user_daily_commit_logs (user_id, date, total_commits, created_at, updated_at)
+		SELECT ?, date, total_commits, NOW(), NOW() FROM user_daily_commit_logs WHERE user_id = ?
+		ON CONFLICT (user_id, date) DO UPDATE
+		SET total_commits = user_daily_commit_logs.total_commits + EXCLUDED.total_commits, updated_at = NOW()
+	`, toUserID, fromUserID).Error
+	if err != nil {
+		return err
+	}
+	return logRepo.db.WithContext(ctx).Where("user_id = ?", fromUserID).Delete(&models.UserDailyCommitLog{}).Error
+}
+
+// recordDeletionsSQL 直前の CTE deleted（削除した日次ログの user_id・date）を user_daily_commit_log_deletions に記録する
+const recordDeletionsSQL = `
+	INSERT INTO user_daily_commit_log_deletions (user_id, date, deleted_at)
+	SELECT user_id, date, NOW() FROM deleted
+	ON CONFLICT (user_id, date) DO UPDATE SET deleted_at = EXCLUDED.deleted_at
+`
+
+// DeleteInRangeExcept 期間内で指定日付以外の日次ログを削除し、削除した日付を記録する（ListDeletedSince で返す）
+func (logRepo *UserDailyCommitLogRepository) DeleteInRangeExcept(ctx context.Context, userID uint64, since, until time.Time, keep []time.Time) error {
+	args := []any{userID, since, until}
+	keepClause := ""
+	if len(keep) > 0 {
+		keepClause = "AND date NOT IN ?"
+		args = append(args, keep)
+	}
+	return logRepo.db.WithContext(ctx).Exec(`
+		WITH deleted AS (
+			DELETE FROM user_daily_commit_logs
+			WHERE user_id = ? AND date BETWEEN ? AND ? `+keepClause+`
+			RETURNING user_id, date
+		)`+recordDeletionsSQL, args...).Error
+}
+
+// FillZeroDays コミットがあった最初の日から最後の日までの間で日次ログが無い日に、コミット数0の日次ログを作成する
+// 活動期間の外に残ったコミット数0の日次ログは削除し、削除した日付を記録する。既にある日は書き換えないため、何度実行しても結果は同じ
+// date はUTCの0時0分のため、セッションのタイムゾーンによらずUTCで1日ずつ進める
+func (logRepo *UserDailyCommitLogRepository) FillZeroDays(ctx context.Context, userID uint64) error {
+	err := logRepo.db.WithContext(ctx).Exec(`
+		INSERT INTO user_daily_commit_logs (user_id, date, total_commits, created_at, updated_at)
+		SELECT ?, d AT TIME ZONE 'UTC', 0, NOW(), NOW()
+		FROM (
+			SELECT MIN(date) AS first, MAX(date) AS last FROM user_daily_commit_logs
+			WHERE user_id = ? AND total_commits > 0
+		) AS bounds, generate_series(bounds.first AT TIME ZONE 'UTC', bounds.last AT TIME ZONE 'UTC', INTERVAL '1 day') AS d
+		ON CONFLICT (user_id, date) DO NOTHING
+	`, userID, userID).Error
+	if err != nil {
+		return err
+	}
+	return logRepo.db.WithContext(ctx).Exec(`
+		WITH deleted AS (
+			DELETE FROM user_daily_commit_logs AS l
+			USING (
+				SELECT MIN(date) AS first, MAX(date) AS last FROM user_daily_commit_logs
+				WHERE user_id = ? AND total_commits > 0
+			) AS bounds
+			WHERE l.user_id = ? AND l.total_commits = 0
+			AND (bounds.first IS NULL OR l.date NOT BETWEEN bounds.first AND bounds.last)
+			RETURNING l.user_id, l.date
+		)`+recordDeletionsSQL, userID, userID).Error
+}
+
+// ListDeletedSince since より後に削除され、今も日次ログが無い日付を取得（日付昇順）
+// 削除した後に作り直した日は ListUpdatedSince が返すため含めない
+func (logRepo *UserDailyCommitLogRepository) ListDeletedSince(ctx context.Context, userID uint64, since time.Time) ([]time.Time, error) {
+	var dates []time.Time
+	err := logRepo.db.WithContext(ctx).Model(&models.UserDailyCommitLogDeletion{}).
+		Where("user_id = ? AND deleted_at > ?", userID, since).
+		Where("NOT EXISTS (SELECT 1 FROM user_daily_commit_logs AS l WHERE l.user_id = user_daily_commit_log_deletions.user_id AND l.date = user_daily_commit_log_deletions.date)").
+		Order("date").
+		Pluck("date", &dates).Error
+	if err != nil {
+		return nil, err
+	}
+	return dates, nil
+}
+
+// LastDeletedInRange 期間内の日次ログを最後に削除した時刻（削除が無ければゼロ値）
+func (logRepo *UserDailyCommitLogRepository) LastDeletedInRange(ctx context.Context, userID uint64, since, until time.Time) (time.Time, error) {
+	var last *time.Time
+	err := logRepo.db.WithContext(ctx).Model(&models.UserDailyCommitLogDeletion{}).
+		Select("MAX(deleted_at)").
+		Where("user_id = ? AND date BETWEEN ? AND ?", userID, since, until).
+		Scan(&last).Error
+	if err != nil || last == nil {
+		return time.Time{}, err
+	}
+	return *last, nil
+}
+
+// DeleteByUserID ユーザー単位の日次ログを全て削除し、削除した件数を返す
+func (logRepo *UserDailyCommitLogRepository) DeleteByUserID(ctx context.Context, userID uint64) (int64, error) {
+	result := logRepo.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.UserDailyCommitLog{})
+	return result.RowsAffected, result.Error
+}
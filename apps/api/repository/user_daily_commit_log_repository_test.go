@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/keeee21/commit-town/api/internal/testdb"
+	"github.com/keeee21/commit-town/api/models"
+)
+
+// DeleteInRangeExcept と FillZeroDays で削除した日を記録し、since より後に削除されて今も無い日だけを返す
+func TestUserDailyCommitLogRepository_ListDeletedSince(t *testing.T) {
+	ctx := context.Background()
+	database := testdb.Open(t)
+	userRepo := NewUserRepository(database)
+	logRepo := NewUserDailyCommitLogRepository(database)
+	user := createTestUser(t, userRepo, 1, "alice")
+
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC) }
+	upsert := func(d, commits int) {
+		t.Helper()
+		if err := logRepo.Upsert(ctx, &models.UserDailyCommitLog{UserID: user.ID, Date: day(d), TotalCommits: commits}); err != nil {
+			t.Fatalf("failed to store daily log: %v", err)
+		}
+	}
+	for d := 1; d <= 5; d++ {
+		upsert(d, d)
+	}
+	// 活動期間の外のコミット数0の日（FillZeroDays が削除する）
+	upsert(10, 0)
+
+	// 比較に使う時刻はDBの時計で取る（削除の記録は NOW() のため）
+	var since time.Time
+	if err := database.Raw("SELECT NOW()").Scan(&since).Error; err != nil {
+		t.Fatalf("failed to read the database clock: %v", err)
+	}
+
+	if err := logRepo.DeleteInRangeExcept(ctx, user.ID, day(1), day(5), []time.Time{day(1), day(3), day(5)}); err != nil {
+		t.Fatalf("DeleteInRangeExcept returned an error: %v", err)
+	}
+	if err := logRepo.FillZeroDays(ctx, user.ID); err != nil {
+		t.Fatalf("FillZeroDays returned an error: %v", err)
+	}
+	// FillZeroDays が 2日・4日をコミット数0で作り直すため、削除した日に残るのは10日だけ
+	deleted, err := logRepo.ListDeletedSince(ctx, user.ID, since)
+	if err != nil {
+		t.Fatalf("ListDeletedSince returned an error: %v", err)
+	}
+	if len(deleted) != 1 || !deleted[0].Equal(day(10)) {
+		t.Errorf("deleted = %v, want [%s]", deleted, day(10).Format("2006-01-02"))
+	}
+
+	// 作り直した日をもう一度削除すると、削除の時刻が進んで再び返る
+	if err := logRepo.DeleteInRangeExcept(ctx, user.ID, day(1), day(5), []time.Time{day(1), day(3), day(5)}); err != nil {
+		t.Fatalf("DeleteInRangeExcept returned an error: %v", err)
+	}
+	deleted, err = logRepo.ListDeletedSince(ctx, user.ID, since)
+	if err != nil {
+		t.Fatalf("ListDeletedSince returned an error: %v", err)
+	}
+	want := []time.Time{day(2), day(4), day(10)}
+	if len(deleted) != len(want) {
+		t.Fatalf("deleted = %v, want %v", deleted, want)
+	}
+	for i := range want {
+		if !deleted[i].Equal(want[i]) {
+			t.Errorf("deleted[%d] = %v, want %v", i, deleted[i], want[i])
+		}
+	}
+
+	// 削除より後の since では返さない
+	deleted, err = logRepo.ListDeletedSince(ctx, user.ID, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("ListDeletedSince returned an error: %v", err)
+	}
+	if len(deleted) != 0 {
+		t.Errorf("deleted after a later since = %v, want none", deleted)
+	}
+}
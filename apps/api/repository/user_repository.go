@@ -1,10 +1,17 @@
 package repository
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"github.com/keeee21/commit-town/api/models"
 	"gorm.io/gorm"
 )
 
+// ErrStaleUpdate 読み込んだ後に他の更新が入ったため、更新が適用されなかった
+var ErrStaleUpdate = errors.New("user was modified by another update")
+
 type UserRepository struct {
 	db *gorm.DB
 }
@@ -13,39 +20,209 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
+// WithTx トランザクション内で操作するリポジトリを返す
+func (userRepo *UserRepository) WithTx(tx *gorm.DB) *UserRepository {
+	return &UserRepository{db: tx}
+}
+
 // FindByGitHubUserID GitHub User IDでユーザーを検索
-func (userRepo *UserRepository) FindByGitHubUserID(githubUserID uint64) (*models.User, error) {
+func (userRepo *UserRepository) FindByGitHubUserID(ctx context.Context, githubUserID uint64) (*models.User, error) {
 	var user models.User
-	err := userRepo.db.Where("github_user_id = ?", githubUserID).First(&user).Error
+	err := userRepo.db.WithContext(ctx).Where("github_user_id = ?", githubUserID).First(&user).Error
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 	return &user, nil
 }
 
 // Create 新規ユーザーを作成
-func (userRepo *UserRepository) Create(user *models.User) error {
-	return userRepo.db.Create(user).Error
+func (userRepo *UserRepository) Create(ctx context.Context, user *models.User) error {
+	return createInSavepoint(ctx, userRepo.db, user)
+}
+
+// Update ユーザー情報を更新（楽観的ロック）
+// 読み込んだ時点の Version と一致する場合のみ更新して Version を1増やし、一致しなければ ErrStaleUpdate を返す
+func (userRepo *UserRepository) Update(ctx context.Context, user *models.User) error {
+	current := user.Version
+	user.Version = current + 1
+	result := userRepo.db.WithContext(ctx).Model(user).
+		Where("version = ?", current).
+		Select("*").Omit("id", "created_at", "token_version").
+		Updates(user)
+	if result.Error != nil {
+		user.Version = current
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		user.Version = current
+		return ErrStaleUpdate
+	}
+	return nil
+}
+
+// BumpTokenVersion トークンの版を1上げ、上げた後の版を返す（Update では変更しない）
+// ユーザーが存在しない場合は ErrNotFound を返す
+func (userRepo *UserRepository) BumpTokenVersion(ctx context.Context, id uint64) (uint, error) {
+	var version uint
+	result := userRepo.db.WithContext(ctx).
+		Raw("UPDATE users SET token_version = token_version + 1 WHERE id = ? AND deleted_at IS NULL RETURNING token_version", id).
+		Scan(&version)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, ErrNotFound
+	}
+	return version, nil
 }
 
-// Update ユーザー情報を更新
-func (userRepo *UserRepository) Update(user *models.User) error {
-	return userRepo.db.Save(user).Error
+// UpdateFields 指定したカラムだけを更新する（キーはカラム名）。Version は1増やす
+// ユーザーが存在しない場合は ErrNotFound を返す
+func (userRepo *UserRepository) UpdateFields(ctx context.Context, id uint64, fields map[string]any) error {
+	updates := make(map[string]any, len(fields)+1)
+	for column, value := range fields {
+		updates[column] = value
+	}
+	updates["version"] = gorm.Expr("version + 1")
+
+	result := userRepo.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
 }
 
 // Upsert ユーザーを作成または更新（GitHub User IDで判定）
-func (userRepo *UserRepository) Upsert(user *models.User) error {
-	existing, err := userRepo.FindByGitHubUserID(user.GitHubUserID)
+// github_user_id の一意インデックスは論理削除された行も対象のため、論理削除済みの行しかない場合は
+// 新規作成せずにその行を復元して更新する（同じGitHubアカウントで再登録すると元のIDと履歴が戻る）
+// 同時に作成されて一意インデックスに違反した場合は、読み直して更新する
+func (userRepo *UserRepository) Upsert(ctx context.Context, user *models.User) error {
+	return retryUpsert(func() error {
+		return userRepo.upsert(ctx, user)
+	})
+}
+
+func (userRepo *UserRepository) upsert(ctx context.Context, user *models.User) error {
+	var existing models.User
+	err := userRepo.db.WithContext(ctx).Unscoped().Where("github_user_id = ?", user.GitHubUserID).First(&existing).Error
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
 			// 新規作成
-			return userRepo.Create(user)
+			return userRepo.Create(ctx, user)
+		}
+		return err
+	}
+
+	if existing.DeletedAt.Valid {
+		if err := userRepo.restore(ctx, &existing); err != nil {
+			return err
 		}
+	}
+
+	// 既存レコードを更新（GitHub由来の項目のみ。通知設定などユーザーが設定した項目は保持する）
+	existing.GitHubUsername = user.GitHubUsername
+	existing.Email = user.Email
+	if err := userRepo.Update(ctx, &existing); err != nil {
 		return err
 	}
+	*user = existing
+	return nil
+}
+
+// restore 論理削除されたユーザーを復元（読み込んだ時点の Version と一致しなければ ErrStaleUpdate）
+func (userRepo *UserRepository) restore(ctx context.Context, user *models.User) error {
+	result := userRepo.db.WithContext(ctx).Unscoped().Model(user).
+		Where("version = ?", user.Version).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStaleUpdate
+	}
+	user.DeletedAt = gorm.DeletedAt{}
+	return nil
+}
+
+// FindByID IDでユーザーを検索
+func (userRepo *UserRepository) FindByID(ctx context.Context, id uint64) (*models.User, error) {
+	var user models.User
+	err := userRepo.db.WithContext(ctx).First(&user, id).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &user, nil
+}
+
+// FindByIDs IDでユーザーをまとめて検索（存在しないIDは結果に含まれない）
+func (userRepo *UserRepository) FindByIDs(ctx context.Context, ids []uint64) ([]models.User, error) {
+	var users []models.User
+	err := userRepo.db.WithContext(ctx).Where("id IN ?", ids).Order("id").Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// Delete ユーザーを論理削除
+func (userRepo *UserRepository) Delete(ctx context.Context, id uint64) error {
+	return userRepo.db.WithContext(ctx).Delete(&models.User{}, id).Error
+}
+
+// FindByIDUnscoped 論理削除されたユーザーも含めてIDで検索
+func (userRepo *UserRepository) FindByIDUnscoped(ctx context.Context, id uint64) (*models.User, error) {
+	var user models.User
+	err := userRepo.db.WithContext(ctx).Unscoped().First(&user, id).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &user, nil
+}
+
+// Purge ユーザーを物理削除（論理削除済みでも削除する）
+// 関連するテーブルが外部キーで参照しているため、先にそれらを削除しておくこと
+func (userRepo *UserRepository) Purge(ctx context.Context, id uint64) error {
+	return userRepo.db.WithContext(ctx).Unscoped().Delete(&models.User{}, id).Error
+}
+
+// CountPublic 論理削除されていない、プロフィールを公開しているユーザー数を取得
+func (userRepo *UserRepository) CountPublic(ctx context.Context) (int64, error) {
+	var count int64
+	if err := userRepo.db.WithContext(ctx).Model(&models.User{}).Where("is_public_profile = ?", true).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ListIDs 全ユーザーのIDを取得
+func (userRepo *UserRepository) ListIDs(ctx context.Context) ([]uint64, error) {
+	var ids []uint64
+	err := userRepo.db.WithContext(ctx).Model(&models.User{}).Order("id").Pluck("id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ListStreakReminderCandidates 通知を有効にしていて継続中のstreakを持つユーザーを取得
+func (userRepo *UserRepository) ListStreakReminderCandidates(ctx context.Context) ([]models.User, error) {
+	var users []models.User
+	err := userRepo.db.WithContext(ctx).
+		Where("notifications_enabled = ?", true).
+		Where("EXISTS (SELECT 1 FROM user_streaks s WHERE s.user_id = users.id AND s.active = ?)", true).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
 
-	// 既存レコードを更新
-	user.ID = existing.ID
-	user.CreatedAt = existing.CreatedAt
-	return userRepo.Update(user)
+// MarkStreakReminderSent streak通知を送ったローカル日付を記録
+func (userRepo *UserRepository) MarkStreakReminderSent(ctx context.Context, userID uint64, date time.Time) error {
+	return userRepo.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ?", userID).
+		Update("last_streak_reminder_on", date).Error
 }
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/keeee21/commit-town/api/internal/testdb"
+	"github.com/keeee21/commit-town/api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func createTestUser(t *testing.T, userRepo *UserRepository, githubUserID uint64, login string) *models.User {
+	t.Helper()
+	user := &models.User{GitHubUserID: githubUserID, GitHubUsername: login, Email: login + "@example.com", Timezone: "UTC"}
+	if err := userRepo.Create(context.Background(), user); err != nil {
+		t.Fatalf("failed to create user %s: %v", login, err)
+	}
+	return user
+}
+
+func TestUserRepository_CancelledContext(t *testing.T) {
+	userRepo := NewUserRepository(testdb.Open(t))
+	user := createTestUser(t, userRepo, 1, "alice")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	_, err := userRepo.FindByID(ctx, user.ID)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("FindByID error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("FindByID returned after %v, want promptly", elapsed)
+	}
+}
+
+// 行ロックで待たされている更新も、context の期限で打ち切られる
+func TestUserRepository_DeadlineCancelsBlockedQuery(t *testing.T) {
+	database := testdb.Open(t)
+	userRepo := NewUserRepository(database)
+	user := createTestUser(t, userRepo, 1, "alice")
+
+	locked := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- database.Transaction(func(tx *gorm.DB) error {
+			var row models.User
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&row, user.ID).Error; err != nil {
+				close(locked)
+				return err
+			}
+			close(locked)
+			<-release
+			return nil
+		})
+	}()
+	<-locked
+	defer func() {
+		close(release)
+		if err := <-done; err != nil {
+			t.Errorf("locking transaction failed: %v", err)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	user.Email = "changed@example.com"
+	err := userRepo.Update(ctx, user)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Update error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Update returned after %v, want shortly after the deadline", elapsed)
+	}
+}
+
+// 同じ版を読み込んだ2つの更新のうち、後から書き込んだ方は ErrStaleUpdate になり、先の更新を上書きしない
+func TestUserRepository_Update_RejectsStaleWrite(t *testing.T) {
+	ctx := context.Background()
+	userRepo := NewUserRepository(testdb.Open(t))
+	created := createTestUser(t, userRepo, 1, "alice")
+
+	first, err := userRepo.FindByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("failed to load user: %v", err)
+	}
+	second, err := userRepo.FindByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("failed to load user: %v", err)
+	}
+
+	first.Email = "first@example.com"
+	if err := userRepo.Update(ctx, first); err != nil {
+		t.Fatalf("first Update returned an error: %v", err)
+	}
+	second.Email = "second@example.com"
+	if err := userRepo.Update(ctx, second); !errors.Is(err, ErrStaleUpdate) {
+		t.Fatalf("second Update error = %v, want ErrStaleUpdate", err)
+	}
+	if second.Version != created.Version {
+		t.Errorf("rejected update left Version = %d, want %d", second.Version, created.Version)
+	}
+
+	stored, err := userRepo.FindByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("failed to reload user: %v", err)
+	}
+	if stored.Email != "first@example.com" {
+		t.Errorf("Email = %q, want the first update to be kept", stored.Email)
+	}
+	if stored.Version != created.Version+1 {
+		t.Errorf("Version = %d, want %d", stored.Version, created.Version+1)
+	}
+}
+
+// 論理削除されたユーザーと同じGitHubアカウントで Upsert すると、一意インデックスに違反せず元の行を復元して更新する
+func TestUserRepository_Upsert_RevivesSoftDeletedUser(t *testing.T) {
+	ctx := context.Background()
+	database := testdb.Open(t)
+	userRepo := NewUserRepository(database)
+	original := createTestUser(t, userRepo, 42, "alice")
+	if err := userRepo.Delete(ctx, original.ID); err != nil {
+		t.Fatalf("failed to delete user: %v", err)
+	}
+
+	revived := &models.User{GitHubUserID: 42, GitHubUsername: "alice-renamed", Email: "renamed@example.com", Timezone: "UTC"}
+	if err := userRepo.Upsert(ctx, revived); err != nil {
+		t.Fatalf("Upsert returned an error: %v", err)
+	}
+	if revived.ID != original.ID {
+		t.Errorf("Upsert created user %d, want the original user %d to be revived", revived.ID, original.ID)
+	}
+
+	stored, err := userRepo.FindByID(ctx, original.ID)
+	if err != nil {
+		t.Fatalf("revived user is not found: %v", err)
+	}
+	if stored.GitHubUsername != "alice-renamed" || stored.Email != "renamed@example.com" {
+		t.Errorf("revived user = %s <%s>, want the upserted fields", stored.GitHubUsername, stored.Email)
+	}
+	var count int64
+	if err := database.Unscoped().Model(&models.User{}).Where("github_user_id = ?", 42).Count(&count).Error; err != nil {
+		t.Fatalf("failed to count users: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("found %d rows for the GitHub account, want 1", count)
+	}
+}
@@ -1,16 +1,162 @@
 package router
 
 import (
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/keeee21/commit-town/api/auth"
 	"github.com/keeee21/commit-town/api/controller"
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/keeee21/commit-town/api/maintenance"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"golang.org/x/time/rate"
 )
 
-// SetupRoutes sets up all API routes
-func SetupRoutes(e *echo.Echo, healthController *controller.HealthController, userController *controller.UserController) {
+// recomputeInterval ユーザー単位の再計算を受け付ける間隔（ユーザーごと、連続2回まで）
+const recomputeInterval = time.Minute
+
+// SetupRoutes sets up all API routes; bodyLimit caps request bodies under /api (e.g. "1M")
+// apiKeyAuth authenticates requests under /api that carry an API key; such requests may only act on the key's own user.
+// While maintenanceMode is enabled, writes under /api are rejected with 503.
+// Writes under /api must send a JSON body; see requireJSON for the routes that take none
+func SetupRoutes(e *echo.Echo, bodyLimit string, idempotent echo.MiddlewareFunc, apiKeyAuth echo.MiddlewareFunc, maintenanceMode *maintenance.Mode, healthController *controller.HealthController, userController *controller.UserController, exportController *controller.ExportController, achievementController *controller.AchievementController, docsController *controller.DocsController, summaryController *controller.SummaryController, repositoryController *controller.RepositoryController, leaderboardController *controller.LeaderboardController, calendarController *controller.CalendarController, syncController *controller.SyncController, githubController *controller.GitHubController, adminController *controller.AdminController, goalController *controller.GoalController, liveController *controller.LiveController, streakFreezeController *controller.StreakFreezeController, statsController *controller.StatsController, apiKeyController *controller.APIKeyController, githubWebhookController *controller.GitHubWebhookController) {
 	// Health check
 	e.GET("/health", healthController.Check)
+	e.GET("/readyz", healthController.Ready)
+
+	// API documentation
+	e.GET("/openapi.yaml", docsController.Spec)
+	e.GET("/docs", docsController.UI)
 
 	// User routes
-	api := e.Group("/api")
-	api.POST("/users", userController.UpsertUser)
+	// メンテナンスモードの解除と、POSTで受ける読み取りはメンテナンス中も受け付ける
+	api := e.Group("/api", middleware.BodyLimit(bodyLimit), apiKeyAuth, maintenance.Middleware(maintenanceMode, "/api/admin/maintenance", "/api/users/summaries"),
+		requireJSON(
+			"/api/users/:id/sync",
+			"/api/users/:id/recompute",
+			"/api/repositories/:id/deactivate",
+			"/api/repositories/:id/backfill",
+			"/api/repositories/:id/sync",
+			"/api/admin/recompute",
+			"/api/admin/reconcile/:id",
+			"/api/admin/repositories/deactivate-stale",
+			"/api/admin/cleanup/rawdata",
+			"/api/admin/users/:id/revoke-tokens",
+		))
+	api.POST("/users", userController.UpsertUser, idempotent)
+	api.POST("/users/merge", userController.MergeUsers)
+	api.POST("/users/summaries", summaryController.GetSummaries)
+	// APIキーで認証したリクエストは、キーのユーザー自身の :id だけを操作できる
+	user := api.Group("/users/:id", auth.RequireUser("id"))
+	user.PATCH("", userController.PatchUser)
+	user.DELETE("", userController.DeleteUser)
+	user.PUT("/webhook", userController.SetWebhook)
+	user.DELETE("/webhook", userController.DeleteWebhook)
+	user.GET("/api-keys", apiKeyController.ListKeys)
+	user.POST("/api-keys", apiKeyController.CreateKey)
+	user.DELETE("/api-keys/:key_id", apiKeyController.RevokeKey)
+	user.GET("/export.csv", exportController.ExportCSV)
+	user.GET("/export.json", exportController.ExportJSON)
+	user.GET("/changes", exportController.GetChanges)
+	user.GET("/achievements", achievementController.ListAchievements)
+	user.GET("/goal", goalController.GetGoal)
+	user.PUT("/goal", goalController.SetGoal)
+	user.GET("/summary", summaryController.GetSummary)
+	user.GET("/today", summaryController.GetToday)
+	user.GET("/events", liveController.StreamEvents)
+	user.GET("/streak/history", summaryController.GetStreakHistory)
+	user.GET("/neighbors", leaderboardController.GetNeighbors)
+	user.GET("/streak/freezes", streakFreezeController.ListFreezes)
+	user.POST("/streak/freezes", streakFreezeController.ScheduleFreeze)
+	user.DELETE("/streak/freezes/:freeze_id", streakFreezeController.CancelFreeze)
+	user.GET("/calendar", calendarController.GetCalendar)
+	user.GET("/day/:date", calendarController.GetDay)
+	user.GET("/activity/weekday", calendarController.GetWeekdayActivity)
+	user.POST("/sync", syncController.SyncUser)
+	user.POST("/recompute", syncController.RecomputeUser, perUserRateLimiter(rate.Every(recomputeInterval), 2))
+
+	// Repository routes
+	user.POST("/repositories/bulk", repositoryController.BulkImport)
+	user.GET("/repositories/sync-status", repositoryController.GetSyncStatus)
+	api.GET("/repositories/search", repositoryController.SearchRepositories)
+	api.PATCH("/repositories/:id", repositoryController.PatchRepository)
+	api.DELETE("/repositories/:id", repositoryController.DeleteRepository)
+	api.GET("/repositories/:id/streak", repositoryController.GetRepoStreak)
+	api.GET("/repositories/:id/recent-commits", repositoryController.GetRecentCommits)
+	api.POST("/repositories/:id/deactivate", repositoryController.DeactivateRepository)
+	api.POST("/repositories/:id/backfill", repositoryController.BackfillRepository)
+	api.POST("/repositories/:id/sync", repositoryController.SyncRepository)
+
+	// GitHub routes
+	api.GET("/github/:username/repos", githubController.ListUserRepos)
+
+	// Webhook routes (GitHub authenticates with the X-Hub-Signature-256 HMAC instead of an API key)
+	api.POST("/webhooks/github", githubWebhookController.ReceiveWebhook)
+
+	// Leaderboard routes
+	api.GET("/leaderboard/commits", leaderboardController.GetCommitLeaderboard)
+	api.GET("/leaderboard/streaks", leaderboardController.GetStreakLeaderboard)
+
+	// Stats routes
+	api.GET("/stats", statsController.GetPlatformStats)
+
+	// Admin routes (to be protected by auth with an admin check once roles exist)
+	admin := api.Group("/admin")
+	admin.POST("/recompute", adminController.Recompute)
+	admin.POST("/reconcile/:id", adminController.ReconcileUser)
+	admin.POST("/repositories/deactivate-stale", adminController.DeactivateStaleRepositories)
+	admin.POST("/cleanup/rawdata", adminController.CleanupRawData)
+	admin.GET("/sync-runs", adminController.ListSyncRuns)
+	admin.GET("/at-risk", adminController.ListAtRiskUsers)
+	admin.GET("/cache/leaderboard", adminController.GetLeaderboardCacheStats)
+	admin.DELETE("/cache/leaderboard", adminController.ClearLeaderboardCache)
+	admin.GET("/maintenance", adminController.GetMaintenance)
+	admin.PUT("/maintenance", adminController.SetMaintenance)
+	admin.POST("/users/:id/revoke-tokens", adminController.RevokeTokens)
+}
+
+// perUserRateLimiter パスの :id（ユーザー）ごとにリクエストを制限する。超えた場合は429を返す
+func perUserRateLimiter(limit rate.Limit, burst int) echo.MiddlewareFunc {
+	return middleware.RateLimiterWithConfig(middleware.RateLimiterConfig{
+		Store: middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+			Rate:      limit,
+			Burst:     burst,
+			ExpiresIn: 10 * time.Minute,
+		}),
+		IdentifierExtractor: func(ctx echo.Context) (string, error) {
+			return ctx.Param("id"), nil
+		},
+	})
+}
+
+// requireJSON POST・PUT・PATCH のリクエストの Content-Type が application/json でなければ415で拒否する
+// （フォーム形式などを送ると Bind のエラーが分かりにくいため、ハンドラーの前で弾く）
+// bodylessPaths のルート（ボディを受け取らないもの）は空のボディなら Content-Type を問わない
+func requireJSON(bodylessPaths ...string) echo.MiddlewareFunc {
+	bodyless := make(map[string]bool, len(bodylessPaths))
+	for _, path := range bodylessPaths {
+		bodyless[path] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			switch req.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch:
+			default:
+				return next(ctx)
+			}
+			if bodyless[ctx.Path()] && req.ContentLength == 0 {
+				return next(ctx)
+			}
+
+			mediaType, _, err := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+			if err != nil || mediaType != echo.MIMEApplicationJSON {
+				return httperr.UnsupportedMediaType("Content-Type must be application/json")
+			}
+			return next(ctx)
+		}
+	}
 }
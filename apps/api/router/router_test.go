@@ -0,0 +1,190 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/keeee21/commit-town/api/auth"
+	"github.com/keeee21/commit-town/api/controller"
+	"github.com/keeee21/commit-town/api/httperr"
+	"github.com/keeee21/commit-town/api/limits"
+	"github.com/keeee21/commit-town/api/maintenance"
+	"github.com/keeee21/commit-town/api/pagination"
+	"github.com/keeee21/commit-town/api/validator"
+	"github.com/labstack/echo/v4"
+)
+
+// passThrough 何もしないミドルウェア（冪等キー・API認証の代わり）
+func passThrough(next echo.HandlerFunc) echo.HandlerFunc {
+	return next
+}
+
+// newTestRouter ルーティングとミドルウェアだけを確かめるためのEcho
+// repositoryController 以外のコントローラーは nil のため、ハンドラーまで届いたリクエストは panic する
+func newTestRouter(bodyLimit string, repositoryController *controller.RepositoryController) *echo.Echo {
+	return newTestRouterWithAuth(bodyLimit, passThrough, repositoryController)
+}
+
+// newTestRouterWithAuth apiKeyAuth でAPIキーを認証する newTestRouter
+func newTestRouterWithAuth(bodyLimit string, apiKeyAuth echo.MiddlewareFunc, repositoryController *controller.RepositoryController) *echo.Echo {
+	e := echo.New()
+	e.HTTPErrorHandler = httperr.Handler
+	e.Validator = validator.NewRequestValidator()
+	SetupRoutes(e, bodyLimit, passThrough, apiKeyAuth, maintenance.New(false),
+		nil, nil, nil, nil, nil, nil, repositoryController, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	return e
+}
+
+func postJSON(e *echo.Echo, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+// bulkBody count 件のリポジトリを登録する一括登録のリクエストボディ
+func bulkBody(count int) string {
+	items := make([]string, count)
+	for i := range items {
+		items[i] = `{"owner":"alice","name":"repository-with-a-long-name","is_public":true}`
+	}
+	return `{"repositories":[` + strings.Join(items, ",") + `]}`
+}
+
+func TestSetupRoutes_RejectsOversizedBody(t *testing.T) {
+	// ハンドラーまで届くと nil のコントローラーで panic するため、413 が返ればハンドラーの前で拒否している
+	e := newTestRouter("1K", nil)
+	body := bulkBody(50)
+	if len(body) <= 1024 {
+		t.Fatalf("test body is only %d bytes", len(body))
+	}
+
+	rec := postJSON(e, "/api/users/1/repositories/bulk", body)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestSetupRoutes_RejectsTooManyBulkItems(t *testing.T) {
+	repositoryController := controller.NewRepositoryController(nil, nil, limits.New(0, 3), 0, pagination.NewConfig(20, 100))
+	e := newTestRouter("1M", repositoryController)
+
+	rec := postJSON(e, "/api/users/1/repositories/bulk", bulkBody(4))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if !strings.Contains(rec.Body.String(), "must not exceed 3 items") {
+		t.Errorf("body = %s, want the item limit", rec.Body.String())
+	}
+}
+
+func TestSetupRoutes_RejectsBackfillOverHistoryLimit(t *testing.T) {
+	repositoryController := controller.NewRepositoryController(nil, nil, limits.New(90, 0), 0, pagination.NewConfig(20, 100))
+	e := newTestRouter("1M", repositoryController)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/repositories/1/backfill?user_id=1&days=91", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if !strings.Contains(rec.Body.String(), "days must not exceed 90") {
+		t.Errorf("body = %s, want the cap in the message", rec.Body.String())
+	}
+}
+
+func TestSetupRoutes_RequiresJSONOnWrites(t *testing.T) {
+	// 415 の行はハンドラーまで届くと nil のコントローラーで panic するため、ハンドラーの前で拒否している
+	repositoryController := controller.NewRepositoryController(nil, nil, limits.New(90, 3), 0, pagination.NewConfig(20, 100))
+	e := newTestRouter("1M", repositoryController)
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+		want        int
+	}{
+		{"text/plain post", http.MethodPost, "/api/users", "text/plain", `{"github_user_id":1}`, http.StatusUnsupportedMediaType},
+		{"form post", http.MethodPost, "/api/users", echo.MIMEApplicationForm, "github_user_id=1", http.StatusUnsupportedMediaType},
+		{"missing content type", http.MethodPost, "/api/users", "", `{"github_user_id":1}`, http.StatusUnsupportedMediaType},
+		{"text/plain patch", http.MethodPatch, "/api/users/1", "text/plain", `{"timezone":"UTC"}`, http.StatusUnsupportedMediaType},
+		{"text/plain put", http.MethodPut, "/api/users/1/goal", "text/plain", `{"daily_target":1}`, http.StatusUnsupportedMediaType},
+		{"body on a bodyless route", http.MethodPost, "/api/repositories/1/backfill?user_id=1&days=91", "text/plain", "days=91", http.StatusUnsupportedMediaType},
+		{"json with charset", http.MethodPost, "/api/users/1/repositories/bulk", "application/json; charset=utf-8", bulkBody(4), http.StatusBadRequest},
+		{"empty body on a bodyless route", http.MethodPost, "/api/repositories/1/backfill?user_id=1&days=91", "", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set(echo.HeaderContentType, tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body.String())
+			}
+			if tt.want == http.StatusUnsupportedMediaType && !strings.Contains(rec.Body.String(), httperr.CodeUnsupportedMedia) {
+				t.Errorf("body = %s, want the %s code", rec.Body.String(), httperr.CodeUnsupportedMedia)
+			}
+		})
+	}
+}
+
+func TestSetupRoutes_APIKeyActsOnlyOnItsOwnUser(t *testing.T) {
+	// 403・401 の行はハンドラーまで届くと nil のコントローラーで panic するため、ハンドラーの前で拒否している
+	apiKeyAuth := auth.Middleware(func(ctx context.Context, key string) (uint64, error) {
+		if key == "alice-key" {
+			return 1, nil
+		}
+		return 0, auth.ErrInvalidCredentials
+	})
+	repositoryController := controller.NewRepositoryController(nil, nil, limits.New(90, 3), 0, pagination.NewConfig(20, 100))
+	e := newTestRouterWithAuth("1M", apiKeyAuth, repositoryController)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		key    string
+		body   string
+		want   int
+	}{
+		{"foreign summary", http.MethodGet, "/api/users/2/summary", "alice-key", "", http.StatusForbidden},
+		{"foreign patch", http.MethodPatch, "/api/users/2", "alice-key", `{"timezone":"UTC"}`, http.StatusForbidden},
+		{"foreign delete", http.MethodDelete, "/api/users/2", "alice-key", "", http.StatusForbidden},
+		{"foreign api keys", http.MethodPost, "/api/users/2/api-keys", "alice-key", `{"label":"laptop"}`, http.StatusForbidden},
+		{"foreign sync", http.MethodPost, "/api/users/2/sync", "alice-key", "", http.StatusForbidden},
+		{"foreign bulk import", http.MethodPost, "/api/users/2/repositories/bulk", "alice-key", bulkBody(1), http.StatusForbidden},
+		{"merging away a foreign user", http.MethodPost, "/api/users/merge", "alice-key", `{"keep_id":1,"remove_id":2}`, http.StatusForbidden},
+		{"foreign summaries", http.MethodPost, "/api/users/summaries", "alice-key", `{"ids":[1,2]}`, http.StatusForbidden},
+		{"foreign user_id on a repository", http.MethodPost, "/api/repositories/1/backfill?user_id=2&days=91", "alice-key", "", http.StatusForbidden},
+		{"revoked key", http.MethodGet, "/api/users/1/summary", "revoked-key", "", http.StatusUnauthorized},
+		{"revoked key on a foreign id", http.MethodGet, "/api/users/2/summary", "revoked-key", "", http.StatusUnauthorized},
+		{"own bulk import", http.MethodPost, "/api/users/1/repositories/bulk", "alice-key", bulkBody(4), http.StatusBadRequest},
+		{"own repository without user_id", http.MethodPost, "/api/repositories/1/backfill?days=91", "alice-key", "", http.StatusBadRequest},
+		{"no key", http.MethodPost, "/api/users/2/repositories/bulk", "", bulkBody(4), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			}
+			if tt.key != "" {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.key)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
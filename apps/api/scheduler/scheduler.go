@@ -0,0 +1,102 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job 一定間隔で実行する処理
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// JobStatus ジョブの最後の成功時刻（readiness の確認用）
+type JobStatus struct {
+	Name        string
+	Interval    time.Duration
+	LastSuccess time.Time // まだ成功していない場合はゼロ値
+}
+
+// Scheduler 登録されたジョブを間隔ごとに実行する
+type Scheduler struct {
+	jobs []Job
+	wg   sync.WaitGroup
+
+	mu          sync.Mutex
+	startedAt   time.Time
+	lastSuccess map[string]time.Time
+}
+
+// NewScheduler creates a new scheduler
+func NewScheduler() *Scheduler {
+	return &Scheduler{lastSuccess: make(map[string]time.Time)}
+}
+
+// Add ジョブを登録する（Start前に呼び出す）
+func (s *Scheduler) Add(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// Start 各ジョブをゴルーチンで開始する。ctxがキャンセルされると停止する
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.startedAt = time.Now()
+	s.mu.Unlock()
+
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go func(job Job) {
+			defer s.wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+}
+
+// StartedAt Start を呼んだ時刻（呼ぶ前はゼロ値）
+func (s *Scheduler) StartedAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.startedAt
+}
+
+// Statuses 登録順に各ジョブの最後の成功時刻を返す
+func (s *Scheduler) Statuses() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		statuses = append(statuses, JobStatus{Name: job.Name, Interval: job.Interval, LastSuccess: s.lastSuccess[job.Name]})
+	}
+	return statuses
+}
+
+// Wait 全ジョブの停止を待つ
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	log.Printf("Scheduler: job %q started (interval %s)", job.Name, job.Interval)
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("Scheduler: job %q stopped", job.Name)
+			return
+		case <-ticker.C:
+			if err := job.Run(ctx); err != nil {
+				log.Printf("Scheduler: job %q failed: %v", job.Name, err)
+				continue
+			}
+			s.mu.Lock()
+			s.lastSuccess[job.Name] = time.Now()
+			s.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/models"
+	"github.com/keeee21/commit-town/api/repository"
+	"gorm.io/gorm"
+)
+
+// achievementStats バッジ判定に使うユーザーの実績値
+type achievementStats struct {
+	LongestStreak int
+	TotalCommits  int
+}
+
+// achievementRule バッジの獲得条件
+type achievementRule struct {
+	Code      string
+	Name      string
+	Qualifies func(stats achievementStats) bool
+}
+
+// achievementRules バッジ定義。新しいバッジはここに追加する
+var achievementRules = []achievementRule{
+	{Code: "streak_7", Name: "7-day streak", Qualifies: func(s achievementStats) bool { return s.LongestStreak >= 7 }},
+	{Code: "streak_30", Name: "30-day streak", Qualifies: func(s achievementStats) bool { return s.LongestStreak >= 30 }},
+	{Code: "commits_100", Name: "100 commits", Qualifies: func(s achievementStats) bool { return s.TotalCommits >= 100 }},
+}
+
+type AchievementUsecase struct {
+	userRepo        *repository.UserRepository
+	achievementRepo *repository.AchievementRepository
+	streakRepo      *repository.StreakRepository
+	userLogRepo     *repository.UserDailyCommitLogRepository
+}
+
+func NewAchievementUsecase(userRepo *repository.UserRepository, achievementRepo *repository.AchievementRepository, streakRepo *repository.StreakRepository, userLogRepo *repository.UserDailyCommitLogRepository) *AchievementUsecase {
+	return &AchievementUsecase{
+		userRepo:        userRepo,
+		achievementRepo: achievementRepo,
+		streakRepo:      streakRepo,
+		userLogRepo:     userLogRepo,
+	}
+}
+
+// WithTx トランザクション内で動作するユースケースを返す
+func (achievementUsecase *AchievementUsecase) WithTx(tx *gorm.DB) *AchievementUsecase {
+	return &AchievementUsecase{
+		userRepo:        achievementUsecase.userRepo.WithTx(tx),
+		achievementRepo: achievementUsecase.achievementRepo.WithTx(tx),
+		streakRepo:      achievementUsecase.streakRepo.WithTx(tx),
+		userLogRepo:     achievementUsecase.userLogRepo.WithTx(tx),
+	}
+}
+
+// Evaluate 条件を満たした未獲得バッジを付与する（streak再計算の後に呼び出す）
+// 獲得済みのバッジは再付与せず、earned_atも更新しない
+func (achievementUsecase *AchievementUsecase) Evaluate(ctx context.Context, userID uint64) error {
+	longest, err := achievementUsecase.streakRepo.MaxLengthByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	total, err := achievementUsecase.userLogRepo.SumTotalByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	stats := achievementStats{LongestStreak: longest, TotalCommits: total}
+
+	now := time.Now()
+	for _, rule := range achievementRules {
+		if !rule.Qualifies(stats) {
+			continue
+		}
+		achievement := &models.UserAchievement{
+			UserID:   userID,
+			Code:     rule.Code,
+			EarnedAt: now,
+		}
+		if err := achievementUsecase.achievementRepo.CreateIfNotExists(ctx, achievement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListAchievements ユーザーの獲得済みバッジ一覧を取得
+func (achievementUsecase *AchievementUsecase) ListAchievements(ctx context.Context, userID uint64) ([]dto.AchievementResponse, error) {
+	if _, err := achievementUsecase.userRepo.FindByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	achievements, err := achievementUsecase.achievementRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.AchievementResponse, 0, len(achievements))
+	for _, achievement := range achievements {
+		responses = append(responses, dto.AchievementResponse{
+			Code:     achievement.Code,
+			Name:     achievementName(achievement.Code),
+			EarnedAt: achievement.EarnedAt.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+	return responses, nil
+}
+
+// achievementName バッジコードから表示名を取得（定義から外れたコードはそのまま返す）
+func achievementName(code string) string {
+	for _, rule := range achievementRules {
+		if rule.Code == code {
+			return rule.Name
+		}
+	}
+	return code
+}
@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/keeee21/commit-town/api/models"
+	"github.com/keeee21/commit-town/api/repository"
+	"gorm.io/gorm"
+)
+
+type AggregationUsecase struct {
+	repoLogRepo  *repository.RepoDailyCommitLogRepository
+	userLogRepo  *repository.UserDailyCommitLogRepository
+	fillZeroDays bool // 活動期間内のコミットが無い日もコミット数0の日次ログとして残すか
+}
+
+func NewAggregationUsecase(repoLogRepo *repository.RepoDailyCommitLogRepository, userLogRepo *repository.UserDailyCommitLogRepository, fillZeroDays bool) *AggregationUsecase {
+	return &AggregationUsecase{repoLogRepo: repoLogRepo, userLogRepo: userLogRepo, fillZeroDays: fillZeroDays}
+}
+
+// WithTx トランザクション内で動作するユースケースを返す
+func (aggregationUsecase *AggregationUsecase) WithTx(tx *gorm.DB) *AggregationUsecase {
+	return &AggregationUsecase{
+		repoLogRepo:  aggregationUsecase.repoLogRepo.WithTx(tx),
+		userLogRepo:  aggregationUsecase.userLogRepo.WithTx(tx),
+		fillZeroDays: aggregationUsecase.fillZeroDays,
+	}
+}
+
+// RebuildRange 期間内のリポジトリ別日次ログを合算し、ユーザー単位の日次ログを作り直す
+// コミットが無くなった日の日次ログは削除する
+// fillZeroDays の場合は、続けて最初と最後のコミットの間の日をコミット数0の日次ログで埋める（期間の外も含む）
+func (aggregationUsecase *AggregationUsecase) RebuildRange(ctx context.Context, userID uint64, since, until time.Time) error {
+	since, until = truncateToDate(since), truncateToDate(until)
+
+	totals, err := aggregationUsecase.repoLogRepo.SumByUserID(ctx, userID, since, until)
+	if err != nil {
+		return err
+	}
+
+	dates := make([]time.Time, 0, len(totals))
+	for _, total := range totals {
+		log := &models.UserDailyCommitLog{
+			UserID:       userID,
+			Date:         total.Date,
+			TotalCommits: total.TotalCommits,
+		}
+		if err := aggregationUsecase.userLogRepo.Upsert(ctx, log); err != nil {
+			return err
+		}
+		dates = append(dates, total.Date)
+	}
+
+	if err := aggregationUsecase.userLogRepo.DeleteInRangeExcept(ctx, userID, since, until, dates); err != nil {
+		return err
+	}
+	if aggregationUsecase.fillZeroDays {
+		return aggregationUsecase.userLogRepo.FillZeroDays(ctx, userID)
+	}
+	return nil
+}
+
+// ReconcileResult 日次ログの突き合わせの結果
+type ReconcileResult struct {
+	CheckedDays int // 突き合わせた日数（リポジトリ別・ユーザー単位のどちらかに記録がある日）
+	FixedDays   int // 合計が食い違っていたため直した日数（作成・更新・削除）
+}
+
+// Reconcile 全期間のユーザー単位の日次ログを、リポジトリ別日次ログの合計と突き合わせ、食い違う日だけを直す
+// RebuildRange と同じ合計にそろえるが、一致している日は書き込まない（途中で失敗した同期のずれの検出用）
+func (aggregationUsecase *AggregationUsecase) Reconcile(ctx context.Context, userID uint64, now time.Time) (ReconcileResult, error) {
+	var res ReconcileResult
+	until := truncateToDate(now).AddDate(0, 0, 1)
+
+	totals, err := aggregationUsecase.repoLogRepo.SumByUserID(ctx, userID, time.Time{}, until)
+	if err != nil {
+		return res, err
+	}
+	logs, err := aggregationUsecase.userLogRepo.ListByUserID(ctx, userID, time.Time{}, until)
+	if err != nil {
+		return res, err
+	}
+
+	stored := make(map[time.Time]int, len(logs))
+	var zeroDays []time.Time
+	for _, commitLog := range logs {
+		date := truncateToDate(commitLog.Date)
+		// 埋めたコミット数0の日次ログは食い違いとして扱わない（活動期間の外のものは FillZeroDays が消す）
+		if aggregationUsecase.fillZeroDays && commitLog.TotalCommits == 0 {
+			zeroDays = append(zeroDays, date)
+			continue
+		}
+		stored[date] = commitLog.TotalCommits
+	}
+
+	expected := make([]time.Time, 0, len(totals)+len(zeroDays))
+	for _, total := range totals {
+		date := truncateToDate(total.Date)
+		expected = append(expected, date)
+		current, ok := stored[date]
+		delete(stored, date)
+		if ok && current == total.TotalCommits {
+			res.CheckedDays++
+			continue
+		}
+
+		log := &models.UserDailyCommitLog{UserID: userID, Date: date, TotalCommits: total.TotalCommits}
+		if err := aggregationUsecase.userLogRepo.Upsert(ctx, log); err != nil {
+			return res, err
+		}
+		res.CheckedDays++
+		res.FixedDays++
+	}
+
+	// リポジトリ別日次ログに無い日のユーザー単位の日次ログは削除する
+	if len(stored) > 0 {
+		if err := aggregationUsecase.userLogRepo.DeleteInRangeExcept(ctx, userID, time.Time{}, until, append(expected, zeroDays...)); err != nil {
+			return res, err
+		}
+		res.CheckedDays += len(stored)
+		res.FixedDays += len(stored)
+	}
+	if aggregationUsecase.fillZeroDays {
+		if err := aggregationUsecase.userLogRepo.FillZeroDays(ctx, userID); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// truncateToDate UTCの日付（0時0分）に丸める
+func truncateToDate(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
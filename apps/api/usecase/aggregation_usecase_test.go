@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/keeee21/commit-town/api/internal/githubtest"
+	"github.com/keeee21/commit-town/api/models"
+)
+
+// FILL_ZERO_DAYS が有効な場合、活動期間内のコミットが無い日をコミット数0の日次ログとして作り、作り直しても増やさない
+func TestAggregationUsecase_FillZeroDays(t *testing.T) {
+	tests := []struct {
+		name         string
+		fillZeroDays bool
+		want         map[string]int
+	}{
+		{"disabled", false, map[string]int{dateOf(5): 1, dateOf(2): 1}},
+		{"enabled", true, map[string]int{dateOf(5): 1, dateOf(4): 0, dateOf(3): 0, dateOf(2): 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv(t, testEnvConfig{fillZeroDays: tt.fillZeroDays})
+			user := env.createUser(t, "alice")
+			env.createRepo(t, user, "alice", "town", commitsOn("alice", 5, 2)...)
+
+			if _, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(7), time.Now(), false); err != nil {
+				t.Fatalf("RunForUser returned an error: %v", err)
+			}
+			if _, err := env.pipeline.RecomputeUser(ctx, user.ID); err != nil {
+				t.Fatalf("RecomputeUser returned an error: %v", err)
+			}
+
+			totals := env.userTotals(t, user)
+			if len(totals) != len(tt.want) {
+				t.Errorf("totals = %v, want %v", totals, tt.want)
+			}
+			for date, total := range tt.want {
+				if got, ok := totals[date]; !ok || got != total {
+					t.Errorf("total on %s = %d (stored %t), want %d", date, got, ok, total)
+				}
+			}
+			if got := env.countRows(t, &models.UserDailyCommitLog{}); got != int64(len(tt.want)) {
+				t.Errorf("user_daily_commit_logs has %d rows after rebuilding, want %d", got, len(tt.want))
+			}
+		})
+	}
+}
+
+// 活動期間が延びると、新しく期間に入った日もコミット数0の日次ログで埋める
+func TestAggregationUsecase_FillZeroDays_FollowsActiveSpan(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{fillZeroDays: true})
+	user := env.createUser(t, "alice")
+	env.createRepo(t, user, "alice", "town", commitsOn("alice", 5, 3)...)
+
+	if _, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(7), time.Now(), false); err != nil {
+		t.Fatalf("first RunForUser returned an error: %v", err)
+	}
+	env.github.SetRepo("alice", "town", githubtest.Repo{Commits: commitsOn("alice", 5, 3, 0)})
+	if _, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(7), time.Now(), false); err != nil {
+		t.Fatalf("second RunForUser returned an error: %v", err)
+	}
+
+	want := map[string]int{dateOf(5): 1, dateOf(4): 0, dateOf(3): 1, dateOf(2): 0, dateOf(1): 0, dateOf(0): 1}
+	totals := env.userTotals(t, user)
+	if len(totals) != len(want) {
+		t.Errorf("totals = %v, want %v", totals, want)
+	}
+	for date, total := range want {
+		if got, ok := totals[date]; !ok || got != total {
+			t.Errorf("total on %s = %d (stored %t), want %d", date, got, ok, total)
+		}
+	}
+}
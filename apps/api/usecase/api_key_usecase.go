@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/keeee21/commit-town/api/auth"
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/models"
+	"github.com/keeee21/commit-town/api/repository"
+)
+
+// apiKeyPrefix 平文のAPIキーの接頭辞（ログやコードに紛れ込んだキーを見つけやすくするため）
+const apiKeyPrefix = "ct_"
+
+var (
+	// ErrAPIKeyInvalid APIキーが存在しない、または無効化されている（auth.Middleware が401にする）
+	ErrAPIKeyInvalid = fmt.Errorf("api key is invalid or revoked: %w", auth.ErrInvalidCredentials)
+	// ErrAPIKeyNotOwned APIキーが指定ユーザーのものではない
+	ErrAPIKeyNotOwned = errors.New("api key does not belong to the user")
+)
+
+type APIKeyUsecase struct {
+	userRepo   *repository.UserRepository
+	apiKeyRepo *repository.APIKeyRepository
+}
+
+func NewAPIKeyUsecase(userRepo *repository.UserRepository, apiKeyRepo *repository.APIKeyRepository) *APIKeyUsecase {
+	return &APIKeyUsecase{userRepo: userRepo, apiKeyRepo: apiKeyRepo}
+}
+
+// CreateKey APIキーを作成し、平文のキーを返す（平文はここでしか返さず、DBにはハッシュのみ保存する）
+// ユーザーが存在しない場合は repository.ErrNotFound を返す
+func (apiKeyUsecase *APIKeyUsecase) CreateKey(ctx context.Context, userID uint64, label string) (*dto.CreateAPIKeyResponse, error) {
+	user, err := apiKeyUsecase.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := generateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	apiKey := &models.APIKey{UserID: userID, Label: strings.TrimSpace(label), KeyHash: hashAPIKey(key), TokenVersion: user.TokenVersion}
+	if err := apiKeyUsecase.apiKeyRepo.Create(ctx, apiKey); err != nil {
+		return nil, err
+	}
+	return &dto.CreateAPIKeyResponse{APIKeyResponse: toAPIKeyResponse(apiKey, user), Key: key}, nil
+}
+
+// ListKeys ユーザーのAPIキーを作成の古い順に取得（無効化したもの、RevokeTokens で無効になったものを含む）
+// ユーザーが存在しない場合は repository.ErrNotFound を返す
+func (apiKeyUsecase *APIKeyUsecase) ListKeys(ctx context.Context, userID uint64) (*dto.APIKeysResponse, error) {
+	user, err := apiKeyUsecase.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeys, err := apiKeyUsecase.apiKeyRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.APIKeysResponse{APIKeys: make([]dto.APIKeyResponse, 0, len(apiKeys))}
+	for i := range apiKeys {
+		res.APIKeys = append(res.APIKeys, toAPIKeyResponse(&apiKeys[i], user))
+	}
+	return res, nil
+}
+
+// RevokeKey APIキーを無効化する（無効化済みの場合は何もしない）
+// キーが存在しない場合は repository.ErrNotFound、userID のものでない場合は ErrAPIKeyNotOwned を返す
+func (apiKeyUsecase *APIKeyUsecase) RevokeKey(ctx context.Context, userID, keyID uint64) error {
+	apiKey, err := apiKeyUsecase.apiKeyRepo.FindByID(ctx, keyID)
+	if err != nil {
+		return err
+	}
+	if apiKey.UserID != userID {
+		return ErrAPIKeyNotOwned
+	}
+	if apiKey.Revoked {
+		return nil
+	}
+	if err := apiKeyUsecase.apiKeyRepo.Revoke(ctx, keyID); err != nil {
+		return err
+	}
+	log.Printf("Revoked API key %d of user %d", keyID, userID)
+	return nil
+}
+
+// AuthenticateAPIKey 平文のキーからユーザーIDを返し、最後に使われた日時を更新する
+// キーが存在しない・無効化されている・ユーザーが削除されている場合と、
+// キーの作成後にユーザーのトークンの版が上がった（UserUsecase.RevokeTokens）場合は ErrAPIKeyInvalid を返す
+func (apiKeyUsecase *APIKeyUsecase) AuthenticateAPIKey(ctx context.Context, key string) (uint64, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return 0, ErrAPIKeyInvalid
+	}
+
+	apiKey, err := apiKeyUsecase.apiKeyRepo.FindByHash(ctx, hashAPIKey(key))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return 0, ErrAPIKeyInvalid
+		}
+		return 0, err
+	}
+	user, err := apiKeyUsecase.userRepo.FindByID(ctx, apiKey.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return 0, ErrAPIKeyInvalid
+		}
+		return 0, err
+	}
+	if !apiKeyUsable(apiKey, user) {
+		return 0, ErrAPIKeyInvalid
+	}
+
+	// 日時の更新に失敗しても認証は通す
+	if err := apiKeyUsecase.apiKeyRepo.TouchLastUsed(ctx, apiKey.ID, time.Now().UTC()); err != nil {
+		log.Printf("Failed to update last_used_at of API key %d: %v", apiKey.ID, err)
+	}
+	return apiKey.UserID, nil
+}
+
+// generateAPIKey 推測できない平文のAPIキーを作る（接頭辞 + 32バイトの乱数）
+func generateAPIKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashAPIKey 保存・照合用のハッシュ（キーは十分な乱数を含むため、ソルトやストレッチングは不要）
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// apiKeyUsable 無効化されておらず、ユーザーの現在のトークンの版以降に作成したキーか
+func apiKeyUsable(apiKey *models.APIKey, user *models.User) bool {
+	return !apiKey.Revoked && apiKey.TokenVersion >= user.TokenVersion
+}
+
+func toAPIKeyResponse(apiKey *models.APIKey, user *models.User) dto.APIKeyResponse {
+	return dto.APIKeyResponse{
+		ID:         apiKey.ID,
+		Label:      apiKey.Label,
+		CreatedAt:  apiKey.CreatedAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		Revoked:    !apiKeyUsable(apiKey, user),
+	}
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/keeee21/commit-town/api/auth"
+	"github.com/keeee21/commit-town/api/models"
+)
+
+func TestAPIKeyUsable(t *testing.T) {
+	tests := []struct {
+		name        string
+		revoked     bool
+		keyVersion  uint
+		userVersion uint
+		want        bool
+	}{
+		{"current version", false, 1, 1, true},
+		{"issued before revoking tokens", false, 1, 2, false},
+		{"issued after revoking tokens", false, 3, 3, true},
+		{"revoked key", true, 2, 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiKey := &models.APIKey{Revoked: tt.revoked, TokenVersion: tt.keyVersion}
+			user := &models.User{TokenVersion: tt.userVersion}
+			if got := apiKeyUsable(apiKey, user); got != tt.want {
+				t.Errorf("apiKeyUsable = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// RevokeTokens の前に作成したキーは使えなくなり、後に作成したキーは使える
+func TestAPIKeyUsecase_AuthenticateAPIKey_RejectsKeysIssuedBeforeRevokeTokens(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	apiKeyUsecase := NewAPIKeyUsecase(env.userRepo, env.apiKeyRepo)
+	user := env.createUser(t, "alice")
+
+	old, err := apiKeyUsecase.CreateKey(ctx, user.ID, "laptop")
+	if err != nil {
+		t.Fatalf("CreateKey returned an error: %v", err)
+	}
+	if got, err := apiKeyUsecase.AuthenticateAPIKey(ctx, old.Key); err != nil || got != user.ID {
+		t.Fatalf("AuthenticateAPIKey before revoking = (%d, %v), want (%d, nil)", got, err, user.ID)
+	}
+
+	if _, err := env.user.RevokeTokens(ctx, user.ID); err != nil {
+		t.Fatalf("RevokeTokens returned an error: %v", err)
+	}
+	if _, err := apiKeyUsecase.AuthenticateAPIKey(ctx, old.Key); !errors.Is(err, ErrAPIKeyInvalid) {
+		t.Errorf("AuthenticateAPIKey with the old key = %v, want %v", err, ErrAPIKeyInvalid)
+	}
+
+	fresh, err := apiKeyUsecase.CreateKey(ctx, user.ID, "new laptop")
+	if err != nil {
+		t.Fatalf("CreateKey after revoking returned an error: %v", err)
+	}
+	if got, err := apiKeyUsecase.AuthenticateAPIKey(ctx, fresh.Key); err != nil || got != user.ID {
+		t.Errorf("AuthenticateAPIKey with the new key = (%d, %v), want (%d, nil)", got, err, user.ID)
+	}
+
+	keys, err := apiKeyUsecase.ListKeys(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ListKeys returned an error: %v", err)
+	}
+	revoked := map[uint64]bool{}
+	for _, key := range keys.APIKeys {
+		revoked[key.ID] = key.Revoked
+	}
+	if !revoked[old.ID] || revoked[fresh.ID] {
+		t.Errorf("revoked = %v, want only the old key %d", revoked, old.ID)
+	}
+}
+
+// 無効化したキーは認証に使えず、他のユーザーのキーは無効化できない
+func TestAPIKeyUsecase_RevokeKey(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	apiKeyUsecase := NewAPIKeyUsecase(env.userRepo, env.apiKeyRepo)
+	alice := env.createUser(t, "alice")
+	bob := env.createUser(t, "bob")
+
+	key, err := apiKeyUsecase.CreateKey(ctx, alice.ID, "laptop")
+	if err != nil {
+		t.Fatalf("CreateKey returned an error: %v", err)
+	}
+	if err := apiKeyUsecase.RevokeKey(ctx, bob.ID, key.ID); !errors.Is(err, ErrAPIKeyNotOwned) {
+		t.Errorf("RevokeKey by another user = %v, want %v", err, ErrAPIKeyNotOwned)
+	}
+	if got, err := apiKeyUsecase.AuthenticateAPIKey(ctx, key.Key); err != nil || got != alice.ID {
+		t.Fatalf("AuthenticateAPIKey before revoking = (%d, %v), want (%d, nil)", got, err, alice.ID)
+	}
+
+	if err := apiKeyUsecase.RevokeKey(ctx, alice.ID, key.ID); err != nil {
+		t.Fatalf("RevokeKey returned an error: %v", err)
+	}
+	if _, err := apiKeyUsecase.AuthenticateAPIKey(ctx, key.Key); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("AuthenticateAPIKey with the revoked key = %v, want %v", err, auth.ErrInvalidCredentials)
+	}
+	if err := apiKeyUsecase.RevokeKey(ctx, alice.ID, key.ID); err != nil {
+		t.Errorf("revoking twice returned an error: %v", err)
+	}
+}
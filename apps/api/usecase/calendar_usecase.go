@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/repository"
+)
+
+// ErrDateInFuture ユーザーのタイムゾーンでまだ来ていない日付は指定できない
+var ErrDateInFuture = errors.New("date is in the future")
+
+type CalendarUsecase struct {
+	userRepo    *repository.UserRepository
+	userLogRepo *repository.UserDailyCommitLogRepository
+	repoRepo    *repository.RepoRepository
+	repoLogRepo *repository.RepoDailyCommitLogRepository
+}
+
+func NewCalendarUsecase(userRepo *repository.UserRepository, userLogRepo *repository.UserDailyCommitLogRepository, repoRepo *repository.RepoRepository, repoLogRepo *repository.RepoDailyCommitLogRepository) *CalendarUsecase {
+	return &CalendarUsecase{userRepo: userRepo, userLogRepo: userLogRepo, repoRepo: repoRepo, repoLogRepo: repoLogRepo}
+}
+
+// GetCalendar 期間内の日ごとのコミット数を取得（コミットが無い日は含めない）
+// LastModified は期間内の日次ログの最後の更新と最後の削除のうち新しい方
+// ユーザーが存在しない場合は repository.ErrNotFound を返す
+func (calendarUsecase *CalendarUsecase) GetCalendar(ctx context.Context, userID uint64, since, until time.Time) (*dto.CalendarResponse, error) {
+	if _, err := calendarUsecase.userRepo.FindByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	logs, err := calendarUsecase.userLogRepo.ListByUserID(ctx, userID, since, until)
+	if err != nil {
+		return nil, err
+	}
+	// 再集計で日次ログが削除された場合も Last-Modified が進むよう、期間内の最後の削除も含める
+	lastDeleted, err := calendarUsecase.userLogRepo.LastDeletedInRange(ctx, userID, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.CalendarResponse{
+		Since:        since.Format("2006-01-02"),
+		Until:        until.Format("2006-01-02"),
+		Days:         make([]dto.CalendarDay, 0, len(logs)),
+		LastModified: lastDeleted,
+	}
+	for _, log := range logs {
+		if log.UpdatedAt.After(res.LastModified) {
+			res.LastModified = log.UpdatedAt
+		}
+		if log.TotalCommits == 0 {
+			continue
+		}
+		res.Days = append(res.Days, dto.CalendarDay{
+			Date:         log.Date.Format("2006-01-02"),
+			TotalCommits: log.TotalCommits,
+		})
+	}
+	return res, nil
+}
+
+// GetRepoCalendar 登録リポジトリ1つ分の期間内の日ごとのコミット数を取得（形式は GetCalendar と同じ）
+// 登録リポジトリが存在しない場合は repository.ErrNotFound、userID のものでない場合は ErrRepositoryNotOwned を返す
+func (calendarUsecase *CalendarUsecase) GetRepoCalendar(ctx context.Context, userID, repoID uint64, since, until time.Time) (*dto.CalendarResponse, error) {
+	repo, err := calendarUsecase.repoRepo.FindByID(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	if repo.UserID != userID {
+		return nil, ErrRepositoryNotOwned
+	}
+
+	logs, err := calendarUsecase.repoLogRepo.ListByUserRepoID(ctx, repoID, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.CalendarResponse{
+		Since: since.Format("2006-01-02"),
+		Until: until.Format("2006-01-02"),
+		Days:  make([]dto.CalendarDay, 0, len(logs)),
+	}
+	for _, log := range logs {
+		if log.UpdatedAt.After(res.LastModified) {
+			res.LastModified = log.UpdatedAt
+		}
+		if log.CommitCount == 0 {
+			continue
+		}
+		res.Days = append(res.Days, dto.CalendarDay{
+			Date:         log.CommitDate.UTC().Format("2006-01-02"),
+			TotalCommits: log.CommitCount,
+		})
+	}
+	return res, nil
+}
+
+// GetDay 1日分の合計コミット数と登録リポジトリごとの内訳を取得（カレンダーの日付の詳細表示用）
+// 日次集計はUTCの日付で持っているため date はUTCの日付として扱う。ユーザーのタイムゾーンで今日より後の日付は ErrDateInFuture を返す
+// コミットが無い日は合計0・空の内訳を返す。ユーザーが存在しない場合は repository.ErrNotFound を返す
+func (calendarUsecase *CalendarUsecase) GetDay(ctx context.Context, userID uint64, date time.Time) (*dto.CalendarDayDetailResponse, error) {
+	user, err := calendarUsecase.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	date = truncateToDate(date)
+	if date.After(localDate(user, time.Now())) {
+		return nil, ErrDateInFuture
+	}
+
+	res := &dto.CalendarDayDetailResponse{
+		Date:         date.Format("2006-01-02"),
+		Timezone:     "UTC",
+		Repositories: make([]dto.CalendarDayRepository, 0),
+	}
+	commitLog, err := calendarUsecase.userLogRepo.FindByUserIDAndDate(ctx, userID, date)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+	case err != nil:
+		return nil, err
+	default:
+		res.TotalCommits = commitLog.TotalCommits
+	}
+
+	counts, err := calendarUsecase.repoLogRepo.ListByUserIDAndDate(ctx, userID, date)
+	if err != nil {
+		return nil, err
+	}
+	for _, count := range counts {
+		res.Repositories = append(res.Repositories, dto.CalendarDayRepository{
+			RepositoryID: count.UserRepoID,
+			Owner:        count.DisplayOwner,
+			Name:         count.DisplayName,
+			CommitCount:  count.CommitCount,
+		})
+	}
+	return res, nil
+}
+
+// GetWeekdayActivity 期間内の合計コミット数を曜日ごとに取得（「いつよくコミットしているか」の表示用）
+// 日次集計はUTCの日付で持っているため、ユーザーのタイムゾーンに関わらず曜日はUTCで数える
+// ユーザーが存在しない場合は repository.ErrNotFound を返す
+func (calendarUsecase *CalendarUsecase) GetWeekdayActivity(ctx context.Context, userID uint64, since, until time.Time) (*dto.WeekdayActivityResponse, error) {
+	if _, err := calendarUsecase.userRepo.FindByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	totals, err := calendarUsecase.userLogRepo.SumByWeekday(ctx, userID, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.WeekdayActivityResponse{
+		Since:    since.Format("2006-01-02"),
+		Until:    until.Format("2006-01-02"),
+		Timezone: "UTC",
+		Weekdays: make([]dto.WeekdayActivity, 7),
+	}
+	for weekday := range res.Weekdays {
+		res.Weekdays[weekday].Weekday = weekday
+	}
+	for _, total := range totals {
+		res.Weekdays[total.Weekday].TotalCommits = total.TotalCommits
+	}
+	return res, nil
+}
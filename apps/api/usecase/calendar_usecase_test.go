@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/keeee21/commit-town/api/models"
+)
+
+// 再集計で日次ログを削除した場合も LastModified が進む
+func TestCalendarUsecase_GetCalendar_LastModifiedMovesOnDelete(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	user := env.createUser(t, "alice")
+	calendarUsecase := NewCalendarUsecase(env.userRepo, env.userLogRepo, env.repoRepo, env.repoLogRepo)
+
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC) }
+	for d := 1; d <= 2; d++ {
+		if err := env.userLogRepo.Upsert(ctx, &models.UserDailyCommitLog{UserID: user.ID, Date: day(d), TotalCommits: 1}); err != nil {
+			t.Fatalf("failed to store daily log: %v", err)
+		}
+	}
+
+	before, err := calendarUsecase.GetCalendar(ctx, user.ID, day(1), day(31))
+	if err != nil {
+		t.Fatalf("GetCalendar returned an error: %v", err)
+	}
+	if before.LastModified.IsZero() {
+		t.Fatal("LastModified is zero, want the latest update")
+	}
+
+	// Last-Modified は秒単位のため、削除の時刻が確実に後になるよう待つ
+	time.Sleep(1100 * time.Millisecond)
+	if err := env.userLogRepo.DeleteInRangeExcept(ctx, user.ID, day(1), day(31), []time.Time{day(1)}); err != nil {
+		t.Fatalf("DeleteInRangeExcept returned an error: %v", err)
+	}
+
+	after, err := calendarUsecase.GetCalendar(ctx, user.ID, day(1), day(31))
+	if err != nil {
+		t.Fatalf("GetCalendar returned an error: %v", err)
+	}
+	if len(after.Days) != 1 {
+		t.Errorf("got %d days, want 1", len(after.Days))
+	}
+	if !after.LastModified.Truncate(time.Second).After(before.LastModified.Truncate(time.Second)) {
+		t.Errorf("LastModified = %v, want later than %v", after.LastModified, before.LastModified)
+	}
+
+	// 期間外の削除では進まない
+	other, err := calendarUsecase.GetCalendar(ctx, user.ID, day(1), day(1))
+	if err != nil {
+		t.Fatalf("GetCalendar returned an error: %v", err)
+	}
+	if other.LastModified.After(before.LastModified) {
+		t.Errorf("LastModified for 2024-05-01 only = %v, want no later than %v", other.LastModified, before.LastModified)
+	}
+}
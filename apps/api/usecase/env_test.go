@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/keeee21/commit-town/api/events"
+	"github.com/keeee21/commit-town/api/internal/githubtest"
+	"github.com/keeee21/commit-town/api/internal/streak"
+	"github.com/keeee21/commit-town/api/internal/testdb"
+	"github.com/keeee21/commit-town/api/models"
+	"github.com/keeee21/commit-town/api/repository"
+	"github.com/keeee21/commit-town/api/validator"
+	"gorm.io/gorm"
+)
+
+// testEnv DBと偽のGitHubを使うテスト用に、main.go と同じ組み合わせでユースケースを作ったもの
+type testEnv struct {
+	db     *gorm.DB
+	github *githubtest.Server
+	bus    *events.Bus
+
+	userRepo       *repository.UserRepository
+	repoRepo       *repository.RepoRepository
+	repoLogRepo    *repository.RepoDailyCommitLogRepository
+	userLogRepo    *repository.UserDailyCommitLogRepository
+	streakRepo     *repository.StreakRepository
+	repoStreakRepo *repository.RepoStreakRepository
+	freezeRepo     *repository.StreakFreezeRepository
+	apiKeyRepo     *repository.APIKeyRepository
+	syncRunRepo    *repository.SyncJobRunRepository
+
+	aggregation *AggregationUsecase
+	streak      *StreakUsecase
+	achievement *AchievementUsecase
+	sync        *SyncUsecase
+	pipeline    *PipelineUsecase
+	repository  *RepositoryUsecase
+	user        *UserUsecase
+	leaderboard *LeaderboardUsecase
+}
+
+// testEnvConfig 環境変数で変えられる設定（ゼロ値は main.go のデフォルトと同じ）
+type testEnvConfig struct {
+	graceDays        int
+	minCommitsPerDay int
+	fillZeroDays     bool
+	initialSyncDays  int
+	inferTimezone    bool
+}
+
+// newTestEnv TEST_DATABASE_URL が無い場合はテストを飛ばす
+func newTestEnv(t *testing.T, config testEnvConfig) *testEnv {
+	t.Helper()
+	database := testdb.Open(t)
+	if config.minCommitsPerDay == 0 {
+		config.minCommitsPerDay = 1
+	}
+	if config.initialSyncDays == 0 {
+		config.initialSyncDays = 30
+	}
+
+	env := &testEnv{
+		db:             database,
+		github:         githubtest.NewServer(t),
+		bus:            events.NewBus(),
+		userRepo:       repository.NewUserRepository(database),
+		repoRepo:       repository.NewRepoRepository(database),
+		repoLogRepo:    repository.NewRepoDailyCommitLogRepository(database),
+		userLogRepo:    repository.NewUserDailyCommitLogRepository(database),
+		streakRepo:     repository.NewStreakRepository(database),
+		repoStreakRepo: repository.NewRepoStreakRepository(database),
+		freezeRepo:     repository.NewStreakFreezeRepository(database),
+		apiKeyRepo:     repository.NewAPIKeyRepository(database),
+		syncRunRepo:    repository.NewSyncJobRunRepository(database),
+	}
+	achievementRepo := repository.NewAchievementRepository(database)
+
+	env.aggregation = NewAggregationUsecase(env.repoLogRepo, env.userLogRepo, config.fillZeroDays)
+	env.streak = NewStreakUsecase(env.userRepo, env.userLogRepo, env.streakRepo, env.repoLogRepo, env.repoStreakRepo, env.freezeRepo, env.bus, config.graceDays, config.minCommitsPerDay)
+	env.achievement = NewAchievementUsecase(env.userRepo, achievementRepo, env.streakRepo, env.userLogRepo)
+	env.sync = NewSyncUsecase(env.github.Client(), env.userRepo, env.repoRepo, env.repoLogRepo, env.bus, config.initialSyncDays, config.inferTimezone)
+	env.pipeline = NewPipelineUsecase(database, env.userRepo, env.repoRepo, env.repoLogRepo, env.userLogRepo, env.streakRepo, env.syncRunRepo, env.sync, env.aggregation, env.streak, env.achievement, 2, streak.DefaultLevels)
+	// キャッシュを捨てるべき場面で捨てているか確かめられるよう、ランキングはテスト中ずっとキャッシュする
+	env.leaderboard = NewLeaderboardUsecase(env.repoLogRepo, env.userLogRepo, env.streakRepo, time.Hour)
+	env.user = NewUserUsecase(database, env.userRepo, env.repoRepo, env.streak, env.leaderboard)
+	env.repository = NewRepositoryUsecase(database, env.userRepo, env.repoRepo, env.repoLogRepo, env.repoStreakRepo, validator.NewRepoValidator(), env.aggregation, env.streak)
+	return env
+}
+
+// createUser GitHubアカウント login のユーザーを作る
+func (env *testEnv) createUser(t *testing.T, login string) *models.User {
+	t.Helper()
+	user := &models.User{GitHubUserID: uint64(time.Now().UnixNano()), GitHubUsername: login, Email: login + "@example.com", Timezone: "UTC"}
+	if err := env.userRepo.Create(context.Background(), user); err != nil {
+		t.Fatalf("failed to create user %s: %v", login, err)
+	}
+	return user
+}
+
+// createRepo user の登録リポジトリを作り、偽のGitHubにも同じリポジトリを commits 付きで用意する
+func (env *testEnv) createRepo(t *testing.T, user *models.User, owner, name string, commits ...githubtest.Commit) *models.UserRepository {
+	t.Helper()
+	repo := &models.UserRepository{UserID: user.ID, RepoOwner: owner, RepoName: name, IsPublic: true, CountMode: models.CountModeAll, AccessStatus: models.AccessStatusOK}
+	if err := env.repoRepo.Create(context.Background(), repo); err != nil {
+		t.Fatalf("failed to create repository %s/%s: %v", owner, name, err)
+	}
+	env.github.SetRepo(owner, name, githubtest.Repo{Commits: commits})
+	return repo
+}
+
+// countRows table の行数
+func (env *testEnv) countRows(t *testing.T, model any) int64 {
+	t.Helper()
+	var count int64
+	if err := env.db.Model(model).Count(&count).Error; err != nil {
+		t.Fatalf("failed to count rows: %v", err)
+	}
+	return count
+}
+
+// recordEvents env.bus に配信された全てのイベントの種類を記録する
+func (env *testEnv) recordEvents() *[]events.Type {
+	var got []events.Type
+	var mu sync.Mutex
+	record := func(ctx context.Context, event events.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, event.EventType())
+		return nil
+	}
+	for _, eventType := range []events.Type{events.StreakStarted, events.StreakExtended, events.StreakBroken, events.RepositorySynced} {
+		env.bus.Subscribe(eventType, record)
+	}
+	return &got
+}
+
+// daysAgo UTCの今日から days 日前の日中の時刻（今日の場合は今より前）
+func daysAgo(days int) time.Time {
+	now := time.Now()
+	today := truncateToDate(now)
+	if days == 0 {
+		return today.Add(now.Sub(today) / 2)
+	}
+	return today.AddDate(0, 0, -days).Add(12 * time.Hour)
+}
+
+// userTotals user の日次ログ（日付 → TotalCommits）
+func (env *testEnv) userTotals(t *testing.T, user *models.User) map[string]int {
+	t.Helper()
+	logs, err := env.userLogRepo.ListByUserID(context.Background(), user.ID, daysAgo(400), time.Now())
+	if err != nil {
+		t.Fatalf("failed to list daily logs: %v", err)
+	}
+	totals := make(map[string]int, len(logs))
+	for _, log := range logs {
+		totals[log.Date.Format("2006-01-02")] = log.TotalCommits
+	}
+	return totals
+}
+
+// dateOf days 日前の日付（userTotals のキー）
+func dateOf(days int) string {
+	return daysAgo(days).Format("2006-01-02")
+}
+
+// commitsOn days 日前のそれぞれに1件ずつ author のコミットを作る
+func commitsOn(author string, days ...int) []githubtest.Commit {
+	commits := make([]githubtest.Commit, 0, len(days))
+	for _, d := range days {
+		commits = append(commits, githubtest.Commit{Date: daysAgo(d), AuthorLogin: author, Parents: 1})
+	}
+	return commits
+}
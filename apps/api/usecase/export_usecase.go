@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/models"
+	"github.com/keeee21/commit-town/api/repository"
+)
+
+type ExportUsecase struct {
+	userRepo    *repository.UserRepository
+	userLogRepo *repository.UserDailyCommitLogRepository
+	repoRepo    *repository.RepoRepository
+	streakRepo  *repository.StreakRepository
+}
+
+func NewExportUsecase(userRepo *repository.UserRepository, userLogRepo *repository.UserDailyCommitLogRepository, repoRepo *repository.RepoRepository, streakRepo *repository.StreakRepository) *ExportUsecase {
+	return &ExportUsecase{userRepo: userRepo, userLogRepo: userLogRepo, repoRepo: repoRepo, streakRepo: streakRepo}
+}
+
+// GetUserExport JSONエクスポートのうち日次ログ以外（プロフィール・登録リポジトリ・streak）を取得
+// 日次ログは件数が多いため StreamDailyLogs で別に読み出す。ユーザーが存在しない場合は repository.ErrNotFound を返す
+func (exportUsecase *ExportUsecase) GetUserExport(ctx context.Context, userID uint64, since time.Time) (*dto.UserExport, error) {
+	user, err := exportUsecase.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	repos, err := exportUsecase.repoRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// -1 は件数の制限なし
+	streaks, err := exportUsecase.streakRepo.ListByUserID(ctx, userID, repository.StreakOrder{Desc: true}, -1, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.UserExport{
+		SchemaVersion: dto.UserExportSchemaVersion,
+		ExportedAt:    time.Now().UTC(),
+		User:          *toUserResponse(user),
+		Repositories:  make([]dto.RepositoryResponse, 0, len(repos)),
+		Streaks:       make([]dto.StreakHistoryEntry, 0, len(streaks)),
+	}
+	if !since.IsZero() {
+		res.Since = formatDatePtr(&since)
+	}
+	for i := range repos {
+		res.Repositories = append(res.Repositories, *toRepositoryResponse(&repos[i]))
+	}
+	for _, streak := range streaks {
+		res.Streaks = append(res.Streaks, dto.StreakHistoryEntry{
+			StartDate: streak.StartDate.UTC().Format("2006-01-02"),
+			EndDate:   formatDatePtr(streak.EndDate),
+			Length:    streak.Length,
+			Active:    streak.Active,
+		})
+	}
+	return res, nil
+}
+
+// StreamDailyLogs ユーザーの日次コミットログを期間で日付昇順に1行ずつfnへ渡す
+// ユーザーが存在しない場合は repository.ErrNotFound を返す
+func (exportUsecase *ExportUsecase) StreamDailyLogs(ctx context.Context, userID uint64, since, until time.Time, fn func(log *models.UserDailyCommitLog) error) error {
+	if _, err := exportUsecase.userRepo.FindByID(ctx, userID); err != nil {
+		return err
+	}
+	return exportUsecase.userLogRepo.StreamByUserID(ctx, userID, since, until, fn)
+}
+
+// GetChanges 差分同期用に、since より後に更新・削除された日次ログとstreakを返す（since がゼロ値の場合は全件）
+// streakは再計算で全件を作り直すため、since より後に作り直していれば StreaksReplaced を true にして全件を返す
+// 次回の since に使う Now は読み出しの前に決め、読み出し中の更新を取りこぼさないようにする
+// ユーザーが存在しない場合は repository.ErrNotFound を返す
+func (exportUsecase *ExportUsecase) GetChanges(ctx context.Context, userID uint64, since time.Time) (*dto.UserChangesResponse, error) {
+	res := &dto.UserChangesResponse{UserID: userID, Now: time.Now().UTC(), DeletedDates: []string{}}
+	user, err := exportUsecase.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !since.IsZero() {
+		res.Since = &since
+	}
+
+	logs, err := exportUsecase.userLogRepo.ListUpdatedSince(ctx, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	// 初回はキャッシュが無いため、削除した日を返す必要はない
+	if !since.IsZero() {
+		deleted, err := exportUsecase.userLogRepo.ListDeletedSince(ctx, userID, since)
+		if err != nil {
+			return nil, err
+		}
+		for _, date := range deleted {
+			res.DeletedDates = append(res.DeletedDates, date.UTC().Format("2006-01-02"))
+		}
+	}
+
+	res.StreaksReplaced = since.IsZero() || (user.StreaksReplacedAt != nil && user.StreaksReplacedAt.After(since))
+	var streaks []models.UserStreak
+	if res.StreaksReplaced {
+		// -1 は件数の制限なし
+		streaks, err = exportUsecase.streakRepo.ListByUserID(ctx, userID, repository.StreakOrder{Desc: true}, -1, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+	res.DailyLogs = make([]dto.UserChangeDailyLog, 0, len(logs))
+	for _, commitLog := range logs {
+		res.DailyLogs = append(res.DailyLogs, dto.UserChangeDailyLog{
+			Date:         commitLog.Date.UTC().Format("2006-01-02"),
+			TotalCommits: commitLog.TotalCommits,
+			UpdatedAt:    commitLog.UpdatedAt.UTC(),
+		})
+	}
+	res.Streaks = make([]dto.StreakHistoryEntry, 0, len(streaks))
+	for _, streak := range streaks {
+		res.Streaks = append(res.Streaks, dto.StreakHistoryEntry{
+			StartDate: streak.StartDate.UTC().Format("2006-01-02"),
+			EndDate:   formatDatePtr(streak.EndDate),
+			Length:    streak.Length,
+			Active:    streak.Active,
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/keeee21/commit-town/api/models"
+)
+
+// 再集計で削除した日を deleted_dates で返し、streakを作り直した場合は0件になっても streaks_replaced を立てる
+func TestExportUsecase_GetChanges_ReportsDeletions(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	user := env.createUser(t, "alice")
+	exportUsecase := NewExportUsecase(env.userRepo, env.userLogRepo, env.repoRepo, env.streakRepo)
+
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC) }
+	for d := 1; d <= 3; d++ {
+		if err := env.userLogRepo.Upsert(ctx, &models.UserDailyCommitLog{UserID: user.ID, Date: day(d), TotalCommits: 1}); err != nil {
+			t.Fatalf("failed to store daily log: %v", err)
+		}
+	}
+	if err := env.streakRepo.ReplaceByUserID(ctx, user.ID, []models.UserStreak{{UserID: user.ID, StartDate: day(1), Length: 3, Active: true}}); err != nil {
+		t.Fatalf("failed to store streaks: %v", err)
+	}
+
+	first, err := exportUsecase.GetChanges(ctx, user.ID, time.Time{})
+	if err != nil {
+		t.Fatalf("GetChanges returned an error: %v", err)
+	}
+	if len(first.DailyLogs) != 3 || len(first.DeletedDates) != 0 || !first.StreaksReplaced || len(first.Streaks) != 1 {
+		t.Fatalf("first call = %d logs, deleted %v, streaks_replaced %v, %d streaks; want 3, none, true, 1",
+			len(first.DailyLogs), first.DeletedDates, first.StreaksReplaced, len(first.Streaks))
+	}
+
+	// 削除の記録は NOW() のため、since はDBの時計で取る
+	var since time.Time
+	if err := env.db.Raw("SELECT NOW()").Scan(&since).Error; err != nil {
+		t.Fatalf("failed to read the database clock: %v", err)
+	}
+	unchanged, err := exportUsecase.GetChanges(ctx, user.ID, since)
+	if err != nil {
+		t.Fatalf("GetChanges returned an error: %v", err)
+	}
+	if len(unchanged.DailyLogs) != 0 || len(unchanged.DeletedDates) != 0 || unchanged.StreaksReplaced || len(unchanged.Streaks) != 0 {
+		t.Fatalf("unchanged = %+v, want no changes", unchanged)
+	}
+
+	if err := env.userLogRepo.DeleteInRangeExcept(ctx, user.ID, day(1), day(3), []time.Time{day(1)}); err != nil {
+		t.Fatalf("DeleteInRangeExcept returned an error: %v", err)
+	}
+	if err := env.streakRepo.ReplaceByUserID(ctx, user.ID, nil); err != nil {
+		t.Fatalf("ReplaceByUserID returned an error: %v", err)
+	}
+
+	changes, err := exportUsecase.GetChanges(ctx, user.ID, since)
+	if err != nil {
+		t.Fatalf("GetChanges returned an error: %v", err)
+	}
+	if len(changes.DailyLogs) != 0 {
+		t.Errorf("daily_logs = %+v, want none", changes.DailyLogs)
+	}
+	if len(changes.DeletedDates) != 2 || changes.DeletedDates[0] != "2024-05-02" || changes.DeletedDates[1] != "2024-05-03" {
+		t.Errorf("deleted_dates = %v, want [2024-05-02 2024-05-03]", changes.DeletedDates)
+	}
+	if !changes.StreaksReplaced || len(changes.Streaks) != 0 {
+		t.Errorf("streaks_replaced = %v with %d streaks, want true with none", changes.StreaksReplaced, len(changes.Streaks))
+	}
+}
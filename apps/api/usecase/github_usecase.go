@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/internal/github"
+	"github.com/keeee21/commit-town/api/repository"
+	"github.com/keeee21/commit-town/api/validator"
+)
+
+// ErrInvalidGitHubUsername GitHubのユーザー名として使えない文字列
+var ErrInvalidGitHubUsername = errors.New("invalid GitHub username")
+
+type GitHubUsecase struct {
+	githubClient  *github.Client
+	userRepo      *repository.UserRepository
+	repoRepo      *repository.RepoRepository
+	repoValidator *validator.RepoValidator
+	cacheTTL      time.Duration // GitHubのリポジトリ一覧をキャッシュする時間（0 はキャッシュしない）
+
+	mu    sync.Mutex
+	cache map[string]cachedGitHubRepos
+}
+
+type cachedGitHubRepos struct {
+	repos     []github.Repo
+	expiresAt time.Time
+}
+
+func NewGitHubUsecase(githubClient *github.Client, userRepo *repository.UserRepository, repoRepo *repository.RepoRepository, repoValidator *validator.RepoValidator, cacheTTL time.Duration) *GitHubUsecase {
+	return &GitHubUsecase{
+		githubClient:  githubClient,
+		userRepo:      userRepo,
+		repoRepo:      repoRepo,
+		repoValidator: repoValidator,
+		cacheTTL:      cacheTTL,
+		cache:         make(map[string]cachedGitHubRepos),
+	}
+}
+
+// ListUserRepos 登録前のプレビュー用に、GitHubユーザーがオーナーの公開リポジトリを取得する
+// userID が0でなければ、そのユーザーが登録済みのリポジトリに Registered を付ける
+// GitHubユーザーが存在しない場合は github.ErrUserNotFound、userID のユーザーが存在しない場合は repository.ErrNotFound を返す
+func (githubUsecase *GitHubUsecase) ListUserRepos(ctx context.Context, username string, userID uint64) (*dto.GitHubReposResponse, error) {
+	if err := githubUsecase.repoValidator.ValidateOwner(username); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGitHubUsername, err)
+	}
+
+	registered := make(map[string]bool)
+	if userID != 0 {
+		if _, err := githubUsecase.userRepo.FindByID(ctx, userID); err != nil {
+			return nil, err
+		}
+		repos, err := githubUsecase.repoRepo.ListByUserID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, repo := range repos {
+			registered[repoKey(repo.RepoOwner, repo.RepoName)] = true
+		}
+	}
+
+	repos, err := githubUsecase.fetchUserRepos(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.GitHubReposResponse{Username: username, Repositories: make([]dto.GitHubRepo, 0, len(repos))}
+	for _, repo := range repos {
+		res.Repositories = append(res.Repositories, dto.GitHubRepo{
+			Owner:         repo.Owner.Login,
+			Name:          repo.Name,
+			IsPublic:      !repo.Private,
+			DefaultBranch: repo.DefaultBranch,
+			Registered:    registered[repoKey(repo.Owner.Login, repo.Name)],
+		})
+	}
+	return res, nil
+}
+
+// fetchUserRepos キャッシュが有効ならそれを返し、なければGitHubから取得してキャッシュする
+// GitHubのユーザー名は大文字小文字を区別しないため、キーは小文字にそろえる
+func (githubUsecase *GitHubUsecase) fetchUserRepos(ctx context.Context, username string) ([]github.Repo, error) {
+	key := strings.ToLower(username)
+	now := time.Now()
+
+	githubUsecase.mu.Lock()
+	cached, ok := githubUsecase.cache[key]
+	githubUsecase.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.repos, nil
+	}
+
+	repos, err := githubUsecase.githubClient.ListUserRepos(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if githubUsecase.cacheTTL <= 0 {
+		return repos, nil
+	}
+
+	githubUsecase.mu.Lock()
+	defer githubUsecase.mu.Unlock()
+	// 期限切れのエントリはここで捨て、問い合わせたユーザー名の分だけキャッシュが増え続けないようにする
+	for k, entry := range githubUsecase.cache {
+		if !now.Before(entry.expiresAt) {
+			delete(githubUsecase.cache, k)
+		}
+	}
+	githubUsecase.cache[key] = cachedGitHubRepos{repos: repos, expiresAt: now.Add(githubUsecase.cacheTTL)}
+	return repos, nil
+}
+
+// repoKey オーナー・リポジトリ名を大文字小文字を区別せずに比べるためのキー
+func repoKey(owner, name string) string {
+	return strings.ToLower(owner) + "/" + strings.ToLower(name)
+}
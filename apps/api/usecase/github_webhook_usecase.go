@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/keeee21/commit-town/api/internal/github"
+	"github.com/keeee21/commit-town/api/models"
+	"github.com/keeee21/commit-town/api/repository"
+)
+
+// pushSyncDays push を受けて同期する範囲の日数（前回の同期以降だけを取得するため、通常はそれより短い）
+const pushSyncDays = 7
+
+// pushSyncState push を受けた登録リポジトリの同期の状態
+type pushSyncState int
+
+const (
+	pushSyncWaiting pushSyncState = iota // 空きを待っている（次の push はこの同期にまとめる）
+	pushSyncRunning                      // 同期中（次の push は終わった後にもう一度同期する）
+	pushSyncAgain                        // 同期中に push があった
+)
+
+type GitHubWebhookUsecase struct {
+	repoRepo        *repository.RepoRepository
+	pipelineUsecase *PipelineUsecase
+	syncRepo        func(repo models.UserRepository) // 1件の同期（テストで差し替える）
+	slots           chan struct{}                    // 同時に実行する同期の数（SYNC_CONCURRENCY）を制限する
+	mu              sync.Mutex
+	pending         map[uint64]pushSyncState // 登録リポジトリIDごとの待機中・実行中の同期
+	syncs           sync.WaitGroup           // バックグラウンドで実行中の同期
+}
+
+func NewGitHubWebhookUsecase(repoRepo *repository.RepoRepository, pipelineUsecase *PipelineUsecase, syncConcurrency int) *GitHubWebhookUsecase {
+	if syncConcurrency < 1 {
+		syncConcurrency = 1
+	}
+	webhookUsecase := &GitHubWebhookUsecase{
+		repoRepo:        repoRepo,
+		pipelineUsecase: pipelineUsecase,
+		slots:           make(chan struct{}, syncConcurrency),
+		pending:         make(map[uint64]pushSyncState),
+	}
+	webhookUsecase.syncRepo = webhookUsecase.sync
+	return webhookUsecase
+}
+
+// HandlePush push されたリポジトリを登録している全ユーザーについて、集計するブランチへの push なら同期を始める
+// GitHubはWebhookの応答を10秒で打ち切るため、同期は待たずにバックグラウンドで行い、受け付けた同期の数を返す
+// 同時に実行する同期は SYNC_CONCURRENCY 件までとし、同じリポジトリの同期が待機中・実行中なら1回にまとめる
+func (webhookUsecase *GitHubWebhookUsecase) HandlePush(ctx context.Context, push *github.PushEvent) (int, error) {
+	if len(push.Commits) == 0 {
+		return 0, nil
+	}
+
+	repos, err := webhookUsecase.repoRepo.ListActiveByName(ctx, push.Repository.Owner.Login, push.Repository.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	for i := range repos {
+		if !tracksBranch(&repos[i], push) {
+			continue
+		}
+		queued++
+		webhookUsecase.enqueue(repos[i])
+	}
+	return queued, nil
+}
+
+// enqueue repo の同期をバックグラウンドで始める。既に待機中ならその同期にまとめ、
+// 実行中なら push より前に取得を始めた可能性があるため、終わった後にもう一度だけ同期する
+func (webhookUsecase *GitHubWebhookUsecase) enqueue(repo models.UserRepository) {
+	webhookUsecase.mu.Lock()
+	defer webhookUsecase.mu.Unlock()
+	if state, ok := webhookUsecase.pending[repo.ID]; ok {
+		if state == pushSyncRunning {
+			webhookUsecase.pending[repo.ID] = pushSyncAgain
+		}
+		return
+	}
+	webhookUsecase.pending[repo.ID] = pushSyncWaiting
+	webhookUsecase.syncs.Add(1)
+	go webhookUsecase.run(repo)
+}
+
+// run 空きを待って repo を同期し、同期中に push があればもう一度同期する
+func (webhookUsecase *GitHubWebhookUsecase) run(repo models.UserRepository) {
+	defer webhookUsecase.syncs.Done()
+	for {
+		webhookUsecase.slots <- struct{}{}
+		webhookUsecase.setState(repo.ID, pushSyncRunning)
+		webhookUsecase.syncRepo(repo)
+		<-webhookUsecase.slots
+
+		webhookUsecase.mu.Lock()
+		again := webhookUsecase.pending[repo.ID] == pushSyncAgain
+		if again {
+			webhookUsecase.pending[repo.ID] = pushSyncWaiting
+		} else {
+			delete(webhookUsecase.pending, repo.ID)
+		}
+		webhookUsecase.mu.Unlock()
+		if !again {
+			return
+		}
+	}
+}
+
+// setState repoID の同期の状態を変える
+func (webhookUsecase *GitHubWebhookUsecase) setState(repoID uint64, state pushSyncState) {
+	webhookUsecase.mu.Lock()
+	defer webhookUsecase.mu.Unlock()
+	webhookUsecase.pending[repoID] = state
+}
+
+// tracksBranch repo が集計しているブランチへの push か
+func tracksBranch(repo *models.UserRepository, push *github.PushEvent) bool {
+	branch := push.Repository.DefaultBranch
+	if repo.Branch != nil {
+		branch = *repo.Branch
+	}
+	return branch != "" && push.Branch() == branch
+}
+
+// sync 呼び出し元のリクエストが終わっても続けるため、コンテキストは新しく作る
+func (webhookUsecase *GitHubWebhookUsecase) sync(repo models.UserRepository) {
+	if _, err := webhookUsecase.pipelineUsecase.SyncRepository(context.Background(), repo.UserID, repo.ID, pushSyncDays); err != nil {
+		log.Printf("Failed to sync %s/%s for user %d after a push: %v", repo.RepoOwner, repo.RepoName, repo.UserID, err)
+	}
+}
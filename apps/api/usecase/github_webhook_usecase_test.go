@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/keeee21/commit-town/api/internal/github"
+	"github.com/keeee21/commit-town/api/models"
+)
+
+// 集計しているブランチに push されたリポジトリを、登録した全ユーザーについて同期する
+func TestGitHubWebhookUsecase_HandlePush_SyncsRegisteredRepositories(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	body, err := os.ReadFile("../internal/github/testdata/webhook_push.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	push, err := github.ParseWebhook(github.EventPush, body)
+	if err != nil {
+		t.Fatalf("ParseWebhook returned an error: %v", err)
+	}
+
+	// push されたのは Alice/Town の main
+	alice := env.createUser(t, "alice")
+	bob := env.createUser(t, "bob")
+	carol := env.createUser(t, "carol")
+	dave := env.createUser(t, "dave")
+	aliceRepo := env.createRepo(t, alice, "alice", "town", commitsOn("alice", 0, 1)...)
+	bobRepo := env.createRepo(t, bob, "alice", "town", commitsOn("alice", 0, 1)...)
+	carolRepo := env.createRepo(t, carol, "alice", "town", commitsOn("alice", 0, 1)...)
+	daveRepo := env.createRepo(t, dave, "alice", "town", commitsOn("alice", 0, 1)...)
+	env.createRepo(t, alice, "alice", "village", commitsOn("alice", 0)...)
+
+	// carol は別のブランチを集計し、dave は無効化している
+	if err := env.db.Model(carolRepo).Update("branch", "develop").Error; err != nil {
+		t.Fatalf("failed to set branch: %v", err)
+	}
+	if err := env.repository.DeactivateRepository(ctx, dave.ID, daveRepo.ID); err != nil {
+		t.Fatalf("DeactivateRepository returned an error: %v", err)
+	}
+
+	webhookUsecase := NewGitHubWebhookUsecase(env.repoRepo, env.pipeline, 2)
+	queued, err := webhookUsecase.HandlePush(ctx, push)
+	if err != nil {
+		t.Fatalf("HandlePush returned an error: %v", err)
+	}
+	webhookUsecase.syncs.Wait()
+
+	if queued != 2 {
+		t.Errorf("queued = %d, want 2", queued)
+	}
+	for _, repo := range []*models.UserRepository{aliceRepo, bobRepo, carolRepo, daveRepo} {
+		var count int64
+		if err := env.db.Model(&models.RepoDailyCommitLog{}).Where("user_repo_id = ?", repo.ID).Count(&count).Error; err != nil {
+			t.Fatalf("failed to count logs: %v", err)
+		}
+		want := int64(0)
+		if repo.ID == aliceRepo.ID || repo.ID == bobRepo.ID {
+			want = 2
+		}
+		if count != want {
+			t.Errorf("repository %d of user %d has %d daily logs, want %d", repo.ID, repo.UserID, count, want)
+		}
+	}
+	if got := env.github.CommitRequests("alice", "village"); got != 0 {
+		t.Errorf("a repository that was not pushed was fetched %d times", got)
+	}
+}
+
+// blockingSyncs 差し替えた同期の呼び出しを記録し、release されるまで戻らない
+type blockingSyncs struct {
+	mu       sync.Mutex
+	calls    map[uint64]int
+	inFlight int
+	maxIn    int
+	started  chan uint64
+	release  chan struct{}
+}
+
+func newBlockingSyncs() *blockingSyncs {
+	return &blockingSyncs{calls: make(map[uint64]int), started: make(chan uint64, 16), release: make(chan struct{})}
+}
+
+func (syncs *blockingSyncs) sync(repo models.UserRepository) {
+	syncs.mu.Lock()
+	syncs.calls[repo.ID]++
+	syncs.inFlight++
+	syncs.maxIn = max(syncs.maxIn, syncs.inFlight)
+	syncs.mu.Unlock()
+	syncs.started <- repo.ID
+	<-syncs.release
+	syncs.mu.Lock()
+	syncs.inFlight--
+	syncs.mu.Unlock()
+}
+
+// awaitStart id の同期が始まるまで待つ
+func (syncs *blockingSyncs) awaitStart(t *testing.T, id uint64) {
+	t.Helper()
+	select {
+	case got := <-syncs.started:
+		if got != id {
+			t.Fatalf("sync of repository %d started, want %d", got, id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("sync of repository %d did not start", id)
+	}
+}
+
+// 同時に実行する同期は SYNC_CONCURRENCY 件まで
+func TestGitHubWebhookUsecase_Enqueue_BoundsConcurrency(t *testing.T) {
+	webhookUsecase := NewGitHubWebhookUsecase(nil, nil, 2)
+	syncs := newBlockingSyncs()
+	webhookUsecase.syncRepo = syncs.sync
+
+	for id := uint64(1); id <= 5; id++ {
+		webhookUsecase.enqueue(models.UserRepository{ID: id})
+	}
+	for range 2 {
+		<-syncs.started
+	}
+	select {
+	case id := <-syncs.started:
+		t.Fatalf("sync of repository %d started while 2 were running", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+	go func() {
+		for range 3 {
+			<-syncs.started
+		}
+	}()
+	close(syncs.release)
+	webhookUsecase.syncs.Wait()
+
+	if syncs.maxIn != 2 {
+		t.Errorf("max in flight = %d, want 2", syncs.maxIn)
+	}
+	for id := uint64(1); id <= 5; id++ {
+		if syncs.calls[id] != 1 {
+			t.Errorf("repository %d synced %d times, want 1", id, syncs.calls[id])
+		}
+	}
+}
+
+// 待機中の同期には push をまとめ、同期中に push があれば終わった後に1回だけ同期し直す
+func TestGitHubWebhookUsecase_Enqueue_CoalescesPerRepository(t *testing.T) {
+	webhookUsecase := NewGitHubWebhookUsecase(nil, nil, 1)
+	syncs := newBlockingSyncs()
+	webhookUsecase.syncRepo = syncs.sync
+	running := models.UserRepository{ID: 1}
+	waiting := models.UserRepository{ID: 2}
+
+	webhookUsecase.enqueue(running)
+	syncs.awaitStart(t, running.ID)
+	for range 3 {
+		webhookUsecase.enqueue(running)
+		webhookUsecase.enqueue(waiting)
+	}
+
+	// 同期を1件ずつ終わらせる（空きは1つのため、次に始まるのは 2 か 1 の同期し直し）
+	syncs.release <- struct{}{}
+	first := <-syncs.started
+	syncs.release <- struct{}{}
+	second := <-syncs.started
+	syncs.release <- struct{}{}
+	webhookUsecase.syncs.Wait()
+
+	if first == second {
+		t.Errorf("repository %d synced back to back, want 1 and 2 once each after the first sync", first)
+	}
+	if syncs.calls[running.ID] != 2 {
+		t.Errorf("running repository synced %d times, want 2 (the sync and one rerun)", syncs.calls[running.ID])
+	}
+	if syncs.calls[waiting.ID] != 1 {
+		t.Errorf("waiting repository synced %d times, want 1", syncs.calls[waiting.ID])
+	}
+	if len(webhookUsecase.pending) != 0 {
+		t.Errorf("pending = %v, want empty", webhookUsecase.pending)
+	}
+}
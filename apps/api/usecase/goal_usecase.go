@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/models"
+	"github.com/keeee21/commit-town/api/repository"
+)
+
+// ErrGoalNotSet 今の期間の目標が設定されていない
+var ErrGoalNotSet = errors.New("goal is not set")
+
+type GoalUsecase struct {
+	userRepo    *repository.UserRepository
+	goalRepo    *repository.GoalRepository
+	userLogRepo *repository.UserDailyCommitLogRepository
+}
+
+func NewGoalUsecase(userRepo *repository.UserRepository, goalRepo *repository.GoalRepository, userLogRepo *repository.UserDailyCommitLogRepository) *GoalUsecase {
+	return &GoalUsecase{userRepo: userRepo, goalRepo: goalRepo, userLogRepo: userLogRepo}
+}
+
+// SetGoal 今の期間（ユーザーのタイムゾーンでの今月・今週）の目標を設定し、進捗を返す（設定済みなら目標数を変更する）
+// ユーザーが存在しない場合は repository.ErrNotFound を返す
+func (goalUsecase *GoalUsecase) SetGoal(ctx context.Context, userID uint64, req *dto.SetGoalRequest, now time.Time) (*dto.GoalResponse, error) {
+	user, err := goalUsecase.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	start, _ := goalPeriod(req.Period, localDate(user, now))
+	goal := &models.UserGoal{
+		UserID:      userID,
+		PeriodType:  req.Period,
+		PeriodStart: start,
+		Target:      req.Target,
+	}
+	if err := goalUsecase.goalRepo.Upsert(ctx, goal); err != nil {
+		return nil, err
+	}
+	return goalUsecase.progress(ctx, user, goal, now)
+}
+
+// GetGoal 今の期間の目標と進捗を取得
+// ユーザーが存在しない場合は repository.ErrNotFound、目標が設定されていない場合は ErrGoalNotSet を返す
+func (goalUsecase *GoalUsecase) GetGoal(ctx context.Context, userID uint64, periodType string, now time.Time) (*dto.GoalResponse, error) {
+	user, err := goalUsecase.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	start, _ := goalPeriod(periodType, localDate(user, now))
+	goal, err := goalUsecase.goalRepo.FindByPeriod(ctx, userID, periodType, start)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrGoalNotSet
+		}
+		return nil, err
+	}
+	return goalUsecase.progress(ctx, user, goal, now)
+}
+
+// progress 期間内のコミット数から進捗を計算する
+// on_track は達成率（current / target）が期間の経過率（ユーザーのタイムゾーンでの経過時間の割合）以上かで判定する
+func (goalUsecase *GoalUsecase) progress(ctx context.Context, user *models.User, goal *models.UserGoal, now time.Time) (*dto.GoalResponse, error) {
+	start, end := goalPeriod(goal.PeriodType, goal.PeriodStart)
+	last := end.AddDate(0, 0, -1)
+	current, err := goalUsecase.userLogRepo.SumInRange(ctx, user.ID, start, last)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := userLocation(user)
+	startAt := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	endAt := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, loc)
+	elapsed := float64(now.Sub(startAt)) / float64(endAt.Sub(startAt))
+	elapsed = min(max(elapsed, 0), 1)
+
+	return &dto.GoalResponse{
+		Period:      goal.PeriodType,
+		PeriodStart: start.Format("2006-01-02"),
+		PeriodEnd:   last.Format("2006-01-02"),
+		Target:      goal.Target,
+		Current:     current,
+		Remaining:   max(goal.Target-current, 0),
+		OnTrack:     float64(current) >= float64(goal.Target)*elapsed,
+	}, nil
+}
+
+// goalPeriod date を含む期間の初日と、次の期間の初日を返す（週は月曜始まり）
+func goalPeriod(periodType string, date time.Time) (start, end time.Time) {
+	if periodType == models.GoalPeriodWeek {
+		start = date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+		return start, start.AddDate(0, 0, 7)
+	}
+	start = time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
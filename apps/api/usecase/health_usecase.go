@@ -1,19 +1,257 @@
 package usecase
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/keeee21/commit-town/api/db"
+	"github.com/keeee21/commit-town/api/internal/github"
+	"github.com/keeee21/commit-town/api/scheduler"
+	"gorm.io/gorm"
+)
+
+const (
+	// databaseCheckTimeout bounds the database readiness check
+	databaseCheckTimeout = 2 * time.Second
+	// githubCheckTimeout bounds the GitHub readiness check so a slow GitHub cannot stall probes
+	githubCheckTimeout = 3 * time.Second
+)
+
+// Names of the readiness checks, as used in READYZ_REQUIRED_CHECKS and the /readyz response
+const (
+	CheckDatabase  = "database"
+	CheckGitHub    = "github"
+	CheckScheduler = "scheduler"
+)
+
+// ReadinessCheckNames lists every readiness check in the order they are reported
+var ReadinessCheckNames = []string{CheckDatabase, CheckGitHub, CheckScheduler}
+
+// DefaultRequiredChecks fail readiness when unhealthy if READYZ_REQUIRED_CHECKS is not set;
+// GitHub and scheduler problems are reported as warnings only
+var DefaultRequiredChecks = []string{CheckDatabase}
+
+// HealthStatus is the result of a health check
+type HealthStatus struct {
+	Version       string
+	DBOK          bool
+	SchemaCurrent bool
+}
+
+// GitHubStatus is the result of checking the GitHub API
+type GitHubStatus struct {
+	Reachable bool
+	RateLimit *github.RateLimitStatus // nil when GitHub could not be reached
+}
+
+// SchedulerJobStatus is one background job's last success, as seen by the readiness check
+type SchedulerJobStatus struct {
+	Name        string
+	Interval    time.Duration
+	LastSuccess *time.Time // nil when the job has not succeeded since startup
+	Stale       bool       // no success for more than two intervals
+}
+
+// ReadinessCheck is the outcome of one readiness check
+type ReadinessCheck struct {
+	Name     string
+	OK       bool
+	Required bool   // a failing required check makes the service not ready
+	Detail   string // why the check failed or what to look at; empty when healthy
+	Duration time.Duration
+}
+
+// ReadinessStatus is the result of every readiness check
+type ReadinessStatus struct {
+	Ready     bool // every required check passed
+	Health    *HealthStatus
+	GitHub    *GitHubStatus
+	Scheduler []SchedulerJobStatus
+	Checks    []ReadinessCheck // in ReadinessCheckNames order
+}
 
 // HealthUsecase defines the interface for health check business logic
 type HealthUsecase interface {
-	Check(ctx context.Context) (string, error)
+	Check(ctx context.Context) (*HealthStatus, error)
+	CheckGitHub(ctx context.Context) *GitHubStatus
+	CheckReadiness(ctx context.Context) (*ReadinessStatus, error)
+}
+
+type healthUsecase struct {
+	database       *gorm.DB
+	version        string
+	githubClient   *github.Client
+	jobs           *scheduler.Scheduler
+	requiredChecks []string
+}
+
+// NewHealthUsecase creates a new health usecase; version is the build version reported by /health.
+// requiredChecks names the readiness checks that make /readyz fail; the others only warn
+func NewHealthUsecase(database *gorm.DB, version string, githubClient *github.Client, jobs *scheduler.Scheduler, requiredChecks []string) HealthUsecase {
+	return &healthUsecase{database: database, version: version, githubClient: githubClient, jobs: jobs, requiredChecks: requiredChecks}
+}
+
+// ParseRequiredChecks reads a comma-separated list of readiness check names (READYZ_REQUIRED_CHECKS).
+// An empty value means DefaultRequiredChecks; "none" makes every check a warning
+func ParseRequiredChecks(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return DefaultRequiredChecks, nil
+	}
+	if strings.TrimSpace(s) == "none" {
+		return nil, nil
+	}
+	var checks []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if !slices.Contains(ReadinessCheckNames, name) {
+			return nil, fmt.Errorf("unknown readiness check %q (want %s or none)", name, strings.Join(ReadinessCheckNames, ", "))
+		}
+		checks = append(checks, name)
+	}
+	return checks, nil
+}
+
+// Check pings the database and compares applied migrations with the embedded ones.
+// An unreachable database is reported in the status rather than returned as an error.
+func (u *healthUsecase) Check(ctx context.Context) (*HealthStatus, error) {
+	status := &HealthStatus{Version: u.version}
+
+	sqlDB, err := u.database.DB()
+	if err != nil {
+		return nil, err
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		log.Printf("Health check: database ping failed: %v", err)
+		return status, nil
+	}
+	status.DBOK = true
+
+	current, err := db.IsSchemaCurrent(u.database.WithContext(ctx))
+	if err != nil {
+		log.Printf("Health check: failed to read migration status: %v", err)
+		return status, nil
+	}
+	status.SchemaCurrent = current
+
+	return status, nil
+}
+
+// CheckGitHub makes an authenticated call to /rate_limit, which does not consume quota, and reports the remaining quota.
+// Failures are reported in the status; the call is cut off after githubCheckTimeout.
+func (u *healthUsecase) CheckGitHub(ctx context.Context) *GitHubStatus {
+	ctx, cancel := context.WithTimeout(ctx, githubCheckTimeout)
+	defer cancel()
+
+	rateLimit, err := u.githubClient.RateLimit(ctx)
+	if err != nil {
+		log.Printf("Health check: GitHub API check failed: %v", err)
+		return &GitHubStatus{}
+	}
+	return &GitHubStatus{Reachable: true, RateLimit: rateLimit}
 }
 
-type healthUsecase struct{}
+// CheckReadiness runs the database, GitHub and scheduler checks concurrently, each under its own timeout.
+// The database check fails when it cannot be pinged; pending migrations only add a detail.
+// The scheduler check fails when any job has gone more than two intervals without a success
+func (u *healthUsecase) CheckReadiness(ctx context.Context) (*ReadinessStatus, error) {
+	status := &ReadinessStatus{}
+	var database, gitHub ReadinessCheck
+	var healthErr error
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		database, status.Health, healthErr = u.checkDatabase(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		gitHub, status.GitHub = u.checkGitHubReadiness(ctx)
+	}()
+	schedulerCheck, jobs := u.checkScheduler(time.Now())
+	wg.Wait()
+	if healthErr != nil {
+		return nil, healthErr
+	}
 
-// NewHealthUsecase creates a new health usecase
-func NewHealthUsecase() HealthUsecase {
-	return &healthUsecase{}
+	status.Scheduler = jobs
+	status.Checks = []ReadinessCheck{database, gitHub, schedulerCheck}
+	status.Ready = true
+	for i := range status.Checks {
+		check := &status.Checks[i]
+		check.Required = slices.Contains(u.requiredChecks, check.Name)
+		if check.Required && !check.OK {
+			status.Ready = false
+		}
+	}
+	return status, nil
 }
 
-func (u *healthUsecase) Check(ctx context.Context) (string, error) {
-	return "ok", nil
+func (u *healthUsecase) checkDatabase(ctx context.Context) (ReadinessCheck, *HealthStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, databaseCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	health, err := u.Check(ctx)
+	check := ReadinessCheck{Name: CheckDatabase, Duration: time.Since(start)}
+	if err != nil {
+		return check, nil, err
+	}
+	switch {
+	case !health.DBOK:
+		check.Detail = "database is unreachable"
+	case !health.SchemaCurrent:
+		check.OK = true
+		check.Detail = "migrations are pending"
+	default:
+		check.OK = true
+	}
+	return check, health, nil
+}
+
+func (u *healthUsecase) checkGitHubReadiness(ctx context.Context) (ReadinessCheck, *GitHubStatus) {
+	start := time.Now()
+	gitHub := u.CheckGitHub(ctx)
+	check := ReadinessCheck{Name: CheckGitHub, OK: gitHub.Reachable, Duration: time.Since(start)}
+	if !gitHub.Reachable {
+		check.Detail = "GitHub API is unreachable"
+	}
+	return check, gitHub
+}
+
+// checkScheduler reads the in-memory job statuses, so it needs no timeout.
+// A job that has never succeeded is stale once two intervals have passed since the scheduler started
+func (u *healthUsecase) checkScheduler(now time.Time) (ReadinessCheck, []SchedulerJobStatus) {
+	check := ReadinessCheck{Name: CheckScheduler, OK: true}
+	if u.jobs == nil {
+		return check, nil
+	}
+
+	startedAt := u.jobs.StartedAt()
+	var stale []string
+	var jobs []SchedulerJobStatus
+	for _, job := range u.jobs.Statuses() {
+		status := SchedulerJobStatus{Name: job.Name, Interval: job.Interval}
+		since := startedAt
+		if !job.LastSuccess.IsZero() {
+			lastSuccess := job.LastSuccess
+			status.LastSuccess = &lastSuccess
+			since = lastSuccess
+		}
+		status.Stale = !since.IsZero() && now.Sub(since) > 2*job.Interval
+		if status.Stale {
+			stale = append(stale, job.Name)
+		}
+		jobs = append(jobs, status)
+	}
+	if len(stale) > 0 {
+		check.OK = false
+		check.Detail = "no recent success for: " + strings.Join(stale, ", ")
+	}
+	return check, jobs
 }
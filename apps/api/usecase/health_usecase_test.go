@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/keeee21/commit-town/api/internal/github"
+	"github.com/keeee21/commit-town/api/internal/testdb"
+	"github.com/keeee21/commit-town/api/scheduler"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// unreachableDatabase 接続できないDB（gorm.Open では接続しない）
+func unreachableDatabase(t *testing.T) *gorm.DB {
+	t.Helper()
+	database, err := gorm.Open(postgres.Open("host=127.0.0.1 port=1 user=town dbname=town sslmode=disable connect_timeout=1"), &gorm.Config{DisableAutomaticPing: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to open the database: %v", err)
+	}
+	return database
+}
+
+// githubHealthServer reachable の場合は /rate_limit に残りを返し、そうでなければ500を返すクライアント
+func githubHealthServer(t *testing.T, reachable bool) *github.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !reachable || r.URL.Path != "/rate_limit" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"resources":{"core":{"limit":5000,"remaining":4999,"reset":1714564800}}}`))
+	}))
+	t.Cleanup(server.Close)
+	return github.NewClient("", github.WithBaseURL(server.URL))
+}
+
+// startedScheduler interval ごとのジョブが1つあり、起動から成功していないスケジューラー（stale の場合は間隔の2倍を過ぎている）
+func startedScheduler(t *testing.T, stale bool) *scheduler.Scheduler {
+	t.Helper()
+	interval := time.Hour
+	if stale {
+		interval = 10 * time.Millisecond
+	}
+	jobs := scheduler.NewScheduler()
+	jobs.Add(scheduler.Job{Name: "sync", Interval: interval, Run: func(ctx context.Context) error { return nil }})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // ジョブは実行せず、起動した時刻だけを記録する
+	jobs.Start(ctx)
+	jobs.Wait()
+	if stale {
+		time.Sleep(3 * interval)
+	}
+	return jobs
+}
+
+// 依存先ごとに健全さを切り替え、失敗したチェックが必須の場合だけ ready でなくなる（DBは接続できない）
+func TestHealthUsecase_CheckReadiness(t *testing.T) {
+	tests := []struct {
+		name           string
+		githubUp       bool
+		schedulerStale bool
+		required       []string
+		wantOK         map[string]bool
+		wantReady      bool
+	}{
+		{"database required", true, false, []string{CheckDatabase}, map[string]bool{CheckDatabase: false, CheckGitHub: true, CheckScheduler: true}, false},
+		{"database only warns", true, false, nil, map[string]bool{CheckDatabase: false, CheckGitHub: true, CheckScheduler: true}, true},
+		{"github down and required", false, false, []string{CheckGitHub}, map[string]bool{CheckDatabase: false, CheckGitHub: false, CheckScheduler: true}, false},
+		{"github down only warns", false, false, []string{CheckScheduler}, map[string]bool{CheckDatabase: false, CheckGitHub: false, CheckScheduler: true}, true},
+		{"scheduler stale and required", true, true, []string{CheckScheduler}, map[string]bool{CheckDatabase: false, CheckGitHub: true, CheckScheduler: false}, false},
+		{"scheduler stale only warns", true, true, []string{CheckGitHub}, map[string]bool{CheckDatabase: false, CheckGitHub: true, CheckScheduler: false}, true},
+	}
+	database := unreachableDatabase(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health := NewHealthUsecase(database, "test", githubHealthServer(t, tt.githubUp), startedScheduler(t, tt.schedulerStale), tt.required)
+
+			status, err := health.CheckReadiness(context.Background())
+			if err != nil {
+				t.Fatalf("CheckReadiness returned an error: %v", err)
+			}
+			if status.Ready != tt.wantReady {
+				t.Errorf("Ready = %v, want %v", status.Ready, tt.wantReady)
+			}
+			if len(status.Checks) != len(ReadinessCheckNames) {
+				t.Fatalf("got %d checks, want %d", len(status.Checks), len(ReadinessCheckNames))
+			}
+			for i, check := range status.Checks {
+				if check.Name != ReadinessCheckNames[i] {
+					t.Errorf("check %d = %q, want %q", i, check.Name, ReadinessCheckNames[i])
+				}
+				if check.OK != tt.wantOK[check.Name] {
+					t.Errorf("%s OK = %v, want %v (detail %q)", check.Name, check.OK, tt.wantOK[check.Name], check.Detail)
+				}
+				if !check.OK && check.Detail == "" {
+					t.Errorf("failing %s check has no detail", check.Name)
+				}
+			}
+			if status.GitHub.Reachable != tt.githubUp {
+				t.Errorf("GitHub.Reachable = %v, want %v", status.GitHub.Reachable, tt.githubUp)
+			}
+			if len(status.Scheduler) != 1 || status.Scheduler[0].Stale != tt.schedulerStale {
+				t.Errorf("Scheduler = %+v, want one job with Stale %v", status.Scheduler, tt.schedulerStale)
+			}
+		})
+	}
+}
+
+// 接続できるDBは database のチェックを通す
+func TestHealthUsecase_CheckReadiness_DatabaseUp(t *testing.T) {
+	health := NewHealthUsecase(testdb.Open(t), "test", githubHealthServer(t, true), startedScheduler(t, false), []string{CheckDatabase})
+
+	status, err := health.CheckReadiness(context.Background())
+	if err != nil {
+		t.Fatalf("CheckReadiness returned an error: %v", err)
+	}
+	if !status.Ready || !status.Checks[0].OK {
+		t.Errorf("Ready = %v, database check = %+v, want both ok", status.Ready, status.Checks[0])
+	}
+	if !status.Health.DBOK || !status.Health.SchemaCurrent {
+		t.Errorf("Health = %+v, want a reachable, migrated database", status.Health)
+	}
+}
+
+func TestParseRequiredChecks(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{"", DefaultRequiredChecks, false},
+		{"none", nil, false},
+		{"database, github", []string{CheckDatabase, CheckGitHub}, false},
+		{"scheduler", []string{CheckScheduler}, false},
+		{"database,redis", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRequiredChecks(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRequiredChecks(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("ParseRequiredChecks(%q) = %v, want %v", tt.value, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("ParseRequiredChecks(%q) = %v, want %v", tt.value, got, tt.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/internal/cache"
+	"github.com/keeee21/commit-town/api/repository"
+)
+
+type LeaderboardUsecase struct {
+	repoLogRepo  *repository.RepoDailyCommitLogRepository
+	userLogRepo  *repository.UserDailyCommitLogRepository
+	streakRepo   *repository.StreakRepository
+	cacheTTL     time.Duration
+	commitsCache *cache.TTL[*dto.CommitLeaderboardResponse]
+	streaksCache *cache.TTL[*dto.StreakLeaderboardResponse]
+}
+
+// NewLeaderboardUsecase ランキングはクエリパラメーターごとに cacheTTL の間キャッシュする（0 はキャッシュしない）
+// cacheTTL を過ぎた結果は古い値を返しつつバックグラウンドで取り直すため、最大で 2×cacheTTL 前の集計が返ることがある
+func NewLeaderboardUsecase(repoLogRepo *repository.RepoDailyCommitLogRepository, userLogRepo *repository.UserDailyCommitLogRepository, streakRepo *repository.StreakRepository, cacheTTL time.Duration) *LeaderboardUsecase {
+	return &LeaderboardUsecase{
+		repoLogRepo:  repoLogRepo,
+		userLogRepo:  userLogRepo,
+		streakRepo:   streakRepo,
+		cacheTTL:     cacheTTL,
+		commitsCache: cache.NewTTL[*dto.CommitLeaderboardResponse](cacheTTL),
+		streaksCache: cache.NewTTL[*dto.StreakLeaderboardResponse](cacheTTL),
+	}
+}
+
+// TopCommitters 期間内のコミット数ランキングを取得（キャッシュあり）
+// publicOnly が true の場合は公開リポジトリのコミットだけを合算する。非公開リポジトリの分を含まないため、
+// 同じユーザーでも全リポジトリ対象（デフォルト）の合計より少なくなることがある
+func (leaderboardUsecase *LeaderboardUsecase) TopCommitters(ctx context.Context, since, until time.Time, limit int, publicOnly bool) (*dto.CommitLeaderboardResponse, error) {
+	key := fmt.Sprintf("%s:%s:%d:%t", since.Format("2006-01-02"), until.Format("2006-01-02"), limit, publicOnly)
+	return leaderboardUsecase.commitsCache.Get(ctx, key, func(ctx context.Context) (*dto.CommitLeaderboardResponse, error) {
+		return leaderboardUsecase.topCommitters(ctx, since, until, limit, publicOnly)
+	})
+}
+
+// topCommitters キャッシュを通さずにコミット数ランキングを集計する
+func (leaderboardUsecase *LeaderboardUsecase) topCommitters(ctx context.Context, since, until time.Time, limit int, publicOnly bool) (*dto.CommitLeaderboardResponse, error) {
+	var entries []repository.LeaderboardEntry
+	var err error
+	if publicOnly {
+		entries, err = leaderboardUsecase.repoLogRepo.TopByVisibility(ctx, true, since, until, limit)
+	} else {
+		entries, err = leaderboardUsecase.userLogRepo.TopByTotalCommits(ctx, since, until, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.CommitLeaderboardResponse{
+		Until:      until.Format("2006-01-02"),
+		PublicOnly: publicOnly,
+		Entries:    make([]dto.LeaderboardEntryResponse, 0, len(entries)),
+	}
+	if !since.IsZero() {
+		res.Since = since.Format("2006-01-02")
+	}
+
+	rank := 0
+	for i, entry := range entries {
+		if i == 0 || entry.TotalCommits != entries[i-1].TotalCommits {
+			rank = i + 1
+		}
+		res.Entries = append(res.Entries, dto.LeaderboardEntryResponse{
+			Rank:           rank,
+			UserID:         entry.UserID,
+			GitHubUsername: entry.GitHubUsername,
+			TotalCommits:   entry.TotalCommits,
+		})
+	}
+	return res, nil
+}
+
+// TopStreaks streakランキングを取得（キャッシュあり）
+// sort が dto.StreakSortLongest の場合は過去を含めた最長streak、それ以外は継続中のstreakで並べる
+func (leaderboardUsecase *LeaderboardUsecase) TopStreaks(ctx context.Context, sort string, limit, offset int) (*dto.StreakLeaderboardResponse, error) {
+	key := fmt.Sprintf("%s:%d:%d", sort, limit, offset)
+	return leaderboardUsecase.streaksCache.Get(ctx, key, func(ctx context.Context) (*dto.StreakLeaderboardResponse, error) {
+		return leaderboardUsecase.topStreaks(ctx, sort, limit, offset)
+	})
+}
+
+// topStreaks キャッシュを通さずにstreakランキングを集計する
+func (leaderboardUsecase *LeaderboardUsecase) topStreaks(ctx context.Context, sort string, limit, offset int) (*dto.StreakLeaderboardResponse, error) {
+	var entries []repository.StreakRankEntry
+	var err error
+	if sort == dto.StreakSortLongest {
+		entries, err = leaderboardUsecase.streakRepo.TopLongest(ctx, limit, offset)
+	} else {
+		sort = dto.StreakSortCurrent
+		entries, err = leaderboardUsecase.streakRepo.TopCurrent(ctx, limit, offset)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.StreakLeaderboardResponse{
+		Sort:    sort,
+		Limit:   limit,
+		Offset:  offset,
+		Entries: make([]dto.StreakLeaderboardEntryResponse, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		res.Entries = append(res.Entries, dto.StreakLeaderboardEntryResponse{
+			Rank:           entry.Rank,
+			UserID:         entry.UserID,
+			GitHubUsername: entry.GitHubUsername,
+			Length:         entry.Length,
+		})
+	}
+	return res, nil
+}
+
+// GetNeighbors metric で全ユーザーを順位付けし、userID の順位と前後 window 人ずつを返す（キャッシュなし）
+// 活動が無いユーザーは0として最下位に並ぶ。プロフィールを非公開にしていても本人の順位は返す
+// ユーザーが存在しない場合は repository.ErrNotFound を返す
+func (leaderboardUsecase *LeaderboardUsecase) GetNeighbors(ctx context.Context, userID uint64, metric string, since, until time.Time, window int) (*dto.NeighborsResponse, error) {
+	res := &dto.NeighborsResponse{UserID: userID, Metric: metric}
+
+	var neighbors []repository.RankNeighbor
+	var err error
+	if metric == dto.NeighborMetricCommits {
+		neighbors, err = leaderboardUsecase.userLogRepo.NeighborsByTotalCommits(ctx, userID, since, until, window)
+		res.Until = until.Format("2006-01-02")
+		if !since.IsZero() {
+			res.Since = since.Format("2006-01-02")
+		}
+	} else {
+		res.Metric = dto.NeighborMetricStreak
+		neighbors, err = leaderboardUsecase.streakRepo.NeighborsByCurrent(ctx, userID, window)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(neighbors) == 0 {
+		return nil, repository.ErrNotFound
+	}
+
+	res.TotalUsers = neighbors[0].Total
+	res.Entries = make([]dto.NeighborEntryResponse, 0, len(neighbors))
+	for _, neighbor := range neighbors {
+		self := neighbor.UserID == userID
+		if self {
+			res.Rank = neighbor.Rank
+			res.Value = neighbor.Value
+		}
+		res.Entries = append(res.Entries, dto.NeighborEntryResponse{
+			Rank:           neighbor.Rank,
+			UserID:         neighbor.UserID,
+			GitHubUsername: neighbor.GitHubUsername,
+			Value:          neighbor.Value,
+			Self:           self,
+		})
+	}
+	return res, nil
+}
+
+// CacheStats ランキングのキャッシュのヒット数・ミス数を取得
+func (leaderboardUsecase *LeaderboardUsecase) CacheStats() *dto.LeaderboardCacheStatsResponse {
+	return &dto.LeaderboardCacheStatsResponse{
+		TTLSeconds: int(leaderboardUsecase.cacheTTL.Seconds()),
+		Commits:    toCacheStats(leaderboardUsecase.commitsCache.Stats()),
+		Streaks:    toCacheStats(leaderboardUsecase.streaksCache.Stats()),
+	}
+}
+
+// ClearCache ランキングのキャッシュを捨てる（次のリクエストから集計し直す）
+func (leaderboardUsecase *LeaderboardUsecase) ClearCache() {
+	leaderboardUsecase.commitsCache.Clear()
+	leaderboardUsecase.streaksCache.Clear()
+}
+
+func toCacheStats(stats cache.Stats) dto.CacheStats {
+	return dto.CacheStats{Hits: stats.Hits, Misses: stats.Misses, Entries: stats.Entries}
+}
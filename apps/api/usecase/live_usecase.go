@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/events"
+	"github.com/keeee21/commit-town/api/repository"
+)
+
+// liveStreamBuffer 1接続あたりに溜めておけるメッセージ数（読み出しが追いつかない接続の分は捨てる）
+const liveStreamBuffer = 16
+
+// ErrTooManyStreams ユーザーあたりの同時接続数の上限に達した
+var ErrTooManyStreams = errors.New("too many live streams for the user")
+
+// LiveStream 1接続分のライブ更新。使い終わったら LiveUsecase.Close で閉じる
+type LiveStream struct {
+	userID uint64
+	Events chan dto.LiveEvent
+}
+
+type LiveUsecase struct {
+	userRepo          *repository.UserRepository
+	maxStreamsPerUser int
+
+	mu      sync.Mutex
+	streams map[uint64]map[*LiveStream]struct{}
+}
+
+func NewLiveUsecase(userRepo *repository.UserRepository, maxStreamsPerUser int) *LiveUsecase {
+	if maxStreamsPerUser < 1 {
+		maxStreamsPerUser = 1
+	}
+	return &LiveUsecase{
+		userRepo:          userRepo,
+		maxStreamsPerUser: maxStreamsPerUser,
+		streams:           make(map[uint64]map[*LiveStream]struct{}),
+	}
+}
+
+// Subscribe streakの変化とリポジトリの同期を接続中のユーザーに配信する購読者を登録する
+func (liveUsecase *LiveUsecase) Subscribe(bus *events.Bus) {
+	for _, eventType := range []events.Type{events.StreakStarted, events.StreakExtended, events.StreakBroken, events.RepositorySynced} {
+		bus.Subscribe(eventType, liveUsecase.handleEvent)
+	}
+}
+
+// Open ユーザーのライブ更新の接続を開く
+// ユーザーが存在しない場合は repository.ErrNotFound、同時接続数の上限に達している場合は ErrTooManyStreams を返す
+func (liveUsecase *LiveUsecase) Open(ctx context.Context, userID uint64) (*LiveStream, error) {
+	if _, err := liveUsecase.userRepo.FindByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	liveUsecase.mu.Lock()
+	defer liveUsecase.mu.Unlock()
+	if len(liveUsecase.streams[userID]) >= liveUsecase.maxStreamsPerUser {
+		return nil, ErrTooManyStreams
+	}
+	stream := &LiveStream{userID: userID, Events: make(chan dto.LiveEvent, liveStreamBuffer)}
+	if liveUsecase.streams[userID] == nil {
+		liveUsecase.streams[userID] = make(map[*LiveStream]struct{})
+	}
+	liveUsecase.streams[userID][stream] = struct{}{}
+	return stream, nil
+}
+
+// Close 接続を閉じて配信対象から外す（何度呼んでもよい）
+func (liveUsecase *LiveUsecase) Close(stream *LiveStream) {
+	liveUsecase.mu.Lock()
+	defer liveUsecase.mu.Unlock()
+	userStreams := liveUsecase.streams[stream.userID]
+	if _, ok := userStreams[stream]; !ok {
+		return
+	}
+	delete(userStreams, stream)
+	if len(userStreams) == 0 {
+		delete(liveUsecase.streams, stream.userID)
+	}
+}
+
+// handleEvent Publish は同期処理の中で呼ばれるため、接続への送信は待たずに、溢れた分は捨てる
+func (liveUsecase *LiveUsecase) handleEvent(ctx context.Context, event events.Event) error {
+	var userID uint64
+	message := dto.LiveEvent{Type: string(event.EventType())}
+	switch e := event.(type) {
+	case events.StreakEvent:
+		userID = e.UserID
+		message.Streak = &dto.LiveStreak{StartDate: e.StartDate.Format("2006-01-02"), Length: e.Length}
+	case events.RepositorySyncedEvent:
+		userID = e.UserID
+		message.Repository = &dto.LiveRepository{ID: e.UserRepoID, Owner: e.RepoOwner, Name: e.RepoName, Days: e.Days}
+	default:
+		return nil
+	}
+
+	liveUsecase.mu.Lock()
+	defer liveUsecase.mu.Unlock()
+	for stream := range liveUsecase.streams[userID] {
+		select {
+		case stream.Events <- message:
+		default:
+		}
+	}
+	return nil
+}
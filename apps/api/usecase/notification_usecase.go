@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/gateway"
+	"github.com/keeee21/commit-town/api/models"
+	"github.com/keeee21/commit-town/api/repository"
+)
+
+type NotificationUsecase struct {
+	userRepo     *repository.UserRepository
+	userLogRepo  *repository.UserDailyCommitLogRepository
+	streakRepo   *repository.StreakRepository
+	notifier     gateway.Notifier
+	reminderHour int
+	minCommits   int
+}
+
+// NewNotificationUsecase reminderHour はローカル時刻で何時以降に通知するか（0-23）
+// minCommitsPerDay はユーザーが設定していない場合に、今日streakに数えるだけコミットしたとみなす件数
+func NewNotificationUsecase(userRepo *repository.UserRepository, userLogRepo *repository.UserDailyCommitLogRepository, streakRepo *repository.StreakRepository, notifier gateway.Notifier, reminderHour int, minCommitsPerDay int) *NotificationUsecase {
+	return &NotificationUsecase{
+		userRepo:     userRepo,
+		userLogRepo:  userLogRepo,
+		streakRepo:   streakRepo,
+		notifier:     notifier,
+		reminderHour: reminderHour,
+		minCommits:   max(minCommitsPerDay, 1),
+	}
+}
+
+// NotifyStreaksEndingTonight 継続中のstreakがあり、ローカル日付の今日まだコミットしていないユーザーに通知する
+// ローカル時刻が reminderHour を過ぎたユーザーのみ対象とし、同じユーザーには1日1回までしか送らない
+func (notificationUsecase *NotificationUsecase) NotifyStreaksEndingTonight(ctx context.Context, now time.Time) error {
+	users, err := notificationUsecase.userRepo.ListStreakReminderCandidates(ctx)
+	if err != nil {
+		return err
+	}
+
+	sent := 0
+	for _, user := range users {
+		localNow := now.In(userLocation(&user))
+		if localNow.Hour() < notificationUsecase.reminderHour {
+			continue
+		}
+
+		today := localDate(&user, now)
+		if user.LastStreakReminderOn != nil && truncateToDate(*user.LastStreakReminderOn).Equal(today) {
+			continue
+		}
+
+		committed, err := notificationUsecase.committedOn(ctx, &user, today)
+		if err != nil {
+			return err
+		}
+		if committed {
+			continue
+		}
+
+		notification := gateway.Notification{
+			UserID:         user.ID,
+			GitHubUsername: user.GitHubUsername,
+			Email:          user.Email,
+			Subject:        "Your streak ends tonight",
+			Body:           fmt.Sprintf("Hi %s, you haven't committed today yet. Push a commit before midnight to keep your streak alive!", user.GitHubUsername),
+		}
+		if err := notificationUsecase.notifier.Notify(ctx, notification); err != nil {
+			// 1人の失敗で他のユーザーへの通知を止めない
+			log.Printf("Failed to send streak reminder to user %d: %v", user.ID, err)
+			continue
+		}
+
+		if err := notificationUsecase.userRepo.MarkStreakReminderSent(ctx, user.ID, today); err != nil {
+			return err
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		log.Printf("Sent %d streak reminders", sent)
+	}
+	return nil
+}
+
+// ListAtRiskUsers 継続中のstreakがあり、ローカル日付の今日まだコミットしておらず、ローカルの1日が within 以内に終わるユーザーを取得
+// NotifyStreaksEndingTonight の通知対象を確認するための読み取り用。通知のオプトインや送信済みかどうかは問わない
+// 残り時間の短い順（同じ場合はstreakの長い順）に返す
+func (notificationUsecase *NotificationUsecase) ListAtRiskUsers(ctx context.Context, now time.Time, within time.Duration) (*dto.AtRiskUsersResponse, error) {
+	entries, err := notificationUsecase.streakRepo.ListAtRisk(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.AtRiskUsersResponse{Hours: int(within.Hours()), Users: make([]dto.AtRiskUser, 0, len(entries))}
+	for _, entry := range entries {
+		loc := userLocation(&models.User{Timezone: entry.Timezone})
+		localNow := now.In(loc)
+		dayEndsAt := time.Date(localNow.Year(), localNow.Month(), localNow.Day()+1, 0, 0, 0, 0, loc)
+		left := dayEndsAt.Sub(now)
+		if left > within {
+			continue
+		}
+		res.Users = append(res.Users, dto.AtRiskUser{
+			UserID:         entry.UserID,
+			GitHubUsername: entry.GitHubUsername,
+			Timezone:       loc.String(),
+			CurrentStreak:  entry.Length,
+			LocalDate:      localNow.Format("2006-01-02"),
+			DayEndsAt:      dayEndsAt,
+			MinutesLeft:    int(left.Minutes()),
+		})
+	}
+	sort.SliceStable(res.Users, func(i, j int) bool {
+		return res.Users[i].MinutesLeft < res.Users[j].MinutesLeft
+	})
+	return res, nil
+}
+
+// committedOn 指定日にstreakに数えるだけ（ユーザーの MinCommitsPerDay 以上）コミットがあるか
+func (notificationUsecase *NotificationUsecase) committedOn(ctx context.Context, user *models.User, date time.Time) (bool, error) {
+	commitLog, err := notificationUsecase.userLogRepo.FindByUserIDAndDate(ctx, user.ID, date)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return commitLog.TotalCommits >= minCommitsPerDay(user, notificationUsecase.minCommits), nil
+}
+
+// userLocation ユーザーのタイムゾーンを取得（未設定・不正な場合はUTC）
+func userLocation(user *models.User) *time.Location {
+	if user.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// localDate ユーザーのタイムゾーンでの now の日付（UTCの0時として返す）
+func localDate(user *models.User, now time.Time) time.Time {
+	local := now.In(userLocation(user))
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
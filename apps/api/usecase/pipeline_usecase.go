@@ -0,0 +1,758 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/internal/github"
+	"github.com/keeee21/commit-town/api/internal/streak"
+	"github.com/keeee21/commit-town/api/models"
+	"github.com/keeee21/commit-town/api/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	// backfillTimeout 取り込み1回あたりの処理時間の上限
+	backfillTimeout = 2 * time.Minute
+)
+
+// ErrRepositoryDeactivated 無効化されたリポジトリは取り込み対象外
+var ErrRepositoryDeactivated = errors.New("repository is deactivated")
+
+type PipelineUsecase struct {
+	database           *gorm.DB
+	userRepo           *repository.UserRepository
+	repoRepo           *repository.RepoRepository
+	repoLogRepo        *repository.RepoDailyCommitLogRepository
+	userLogRepo        *repository.UserDailyCommitLogRepository
+	streakRepo         *repository.StreakRepository
+	syncRunRepo        *repository.SyncJobRunRepository
+	syncUsecase        *SyncUsecase
+	aggregationUsecase *AggregationUsecase
+	streakUsecase      *StreakUsecase
+	achievementUsecase *AchievementUsecase
+	syncConcurrency    int           // 定期同期で並行に処理するユーザー数
+	levels             streak.Levels // streakの称号の日数
+}
+
+func NewPipelineUsecase(database *gorm.DB, userRepo *repository.UserRepository, repoRepo *repository.RepoRepository, repoLogRepo *repository.RepoDailyCommitLogRepository, userLogRepo *repository.UserDailyCommitLogRepository, streakRepo *repository.StreakRepository, syncRunRepo *repository.SyncJobRunRepository, syncUsecase *SyncUsecase, aggregationUsecase *AggregationUsecase, streakUsecase *StreakUsecase, achievementUsecase *AchievementUsecase, syncConcurrency int, levels streak.Levels) *PipelineUsecase {
+	if syncConcurrency < 1 {
+		syncConcurrency = 1
+	}
+	return &PipelineUsecase{
+		database:           database,
+		userRepo:           userRepo,
+		repoRepo:           repoRepo,
+		repoLogRepo:        repoLogRepo,
+		userLogRepo:        userLogRepo,
+		streakRepo:         streakRepo,
+		syncRunRepo:        syncRunRepo,
+		syncUsecase:        syncUsecase,
+		aggregationUsecase: aggregationUsecase,
+		streakUsecase:      streakUsecase,
+		achievementUsecase: achievementUsecase,
+		syncConcurrency:    syncConcurrency,
+		levels:             levels,
+	}
+}
+
+// errDryRun ドライランでトランザクションをロールバックさせるためのエラー
+var errDryRun = errors.New("dry run")
+
+// RunForUser 同期→日次集計→streak再計算を1トランザクションで実行し、書き込んだ内容の差分を返す
+// GitHubからの取得はトランザクション開始前に済ませ、いずれかの書き込みが失敗した場合は全てロールバックする
+// 各リポジトリは前回の同期以降だけを取得し（SyncUsecase.SyncWindow）、全て until まで同期済みなら何も書き込まない
+// dryRun が true の場合も同じ処理で差分を計算するが、最後にロールバックしてDBには何も残さず、イベントも配信しない
+// dryRun でなければ成否を SyncJobRun に記録する。ユーザーが存在しない場合は repository.ErrNotFound を返す
+func (pipelineUsecase *PipelineUsecase) RunForUser(ctx context.Context, userID uint64, since, until time.Time, dryRun bool) (*dto.SyncPreview, error) {
+	if dryRun {
+		preview, _, err := pipelineUsecase.runForUser(ctx, userID, since, until, true, false)
+		return preview, err
+	}
+
+	run := pipelineUsecase.startRun(ctx, &userID, nil)
+	preview, _, err := pipelineUsecase.runForUser(ctx, userID, since, until, false, false)
+	processed := 0
+	if preview != nil {
+		processed = len(preview.Repositories)
+	}
+	pipelineUsecase.finishRun(ctx, run, processed, err)
+	return preview, err
+}
+
+// runForUser RunForUser の本体（実行記録は呼び出し側で行う）
+// skipFailed が true の場合、GitHubからの取得に失敗したリポジトリは書き込まずに飛ばして残りを同期し、
+// そのエラーを failed に返す（同期済みの時点も進めない）。レート制限に達した場合は skipFailed でも打ち切る
+func (pipelineUsecase *PipelineUsecase) runForUser(ctx context.Context, userID uint64, since, until time.Time, dryRun, skipFailed bool) (preview *dto.SyncPreview, failed []error, err error) {
+	if _, err := pipelineUsecase.userRepo.FindByID(ctx, userID); err != nil {
+		return nil, nil, err
+	}
+
+	listed, err := pipelineUsecase.repoRepo.ListActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 前回の同期以降だけを取得する。初回の同期で since より前まで取り込む場合は、集計もその日から作り直す
+	since = truncateToDate(since)
+	repos := make([]models.UserRepository, 0, len(listed))
+	fetched := make([][]github.DayCommits, 0, len(listed))
+	pending := 0
+	for i := range listed {
+		days, from, ok, err := pipelineUsecase.fetchForSync(ctx, &listed[i], since, until, dryRun)
+		if errors.Is(err, ErrRepositoryNoAccess) {
+			log.Printf("Skipping %s/%s: %v", listed[i].RepoOwner, listed[i].RepoName, err)
+			continue
+		}
+		if err != nil {
+			var rateLimitErr *github.RateLimitError
+			if !skipFailed || errors.As(err, &rateLimitErr) {
+				return nil, failed, err
+			}
+			failed = append(failed, err)
+			continue
+		}
+		if !ok {
+			repos = append(repos, listed[i])
+			fetched = append(fetched, nil)
+			continue
+		}
+		pending++
+		repos = append(repos, listed[i])
+		fetched = append(fetched, days)
+		if from.Before(since) {
+			since = from
+		}
+	}
+
+	if pending == 0 {
+		current, err := pipelineUsecase.snapshot(ctx, pipelineUsecase.database.WithContext(ctx), userID, repos, since, until)
+		if err != nil {
+			return nil, failed, err
+		}
+		preview := buildSyncPreview(repos, current, current)
+		preview.DryRun = dryRun
+		preview.Since = since.Format("2006-01-02")
+		preview.Until = truncateToDate(until).Format("2006-01-02")
+		return preview, failed, nil
+	}
+
+	err = withTransaction(ctx, pipelineUsecase.database, func(ctx context.Context, tx *gorm.DB) error {
+		before, err := pipelineUsecase.snapshot(ctx, tx, userID, repos, since, until)
+		if err != nil {
+			return err
+		}
+		if _, err := pipelineUsecase.writeAll(ctx, tx, userID, repos, fetched, since, until); err != nil {
+			return err
+		}
+		after, err := pipelineUsecase.snapshot(ctx, tx, userID, repos, since, until)
+		if err != nil {
+			return err
+		}
+
+		preview = buildSyncPreview(repos, before, after)
+		preview.DryRun = dryRun
+		preview.Since = truncateToDate(since).Format("2006-01-02")
+		preview.Until = truncateToDate(until).Format("2006-01-02")
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, failed, err
+	}
+	return preview, failed, nil
+}
+
+// fetchForSync 登録リポジトリの公開設定を確認してから、前回の同期以降のコミットを取得する
+// 非公開になったリポジトリは公開リポジトリのランキングから外れ、読み取れなくなったものは ErrRepositoryNoAccess を返す
+// 取得するものが無い場合は ok が false
+func (pipelineUsecase *PipelineUsecase) fetchForSync(ctx context.Context, repo *models.UserRepository, since, until time.Time, dryRun bool) (days []github.DayCommits, from time.Time, ok bool, err error) {
+	if err := pipelineUsecase.syncUsecase.RefreshAccess(ctx, repo, dryRun); err != nil {
+		if errors.Is(err, ErrRepositoryNoAccess) {
+			return nil, time.Time{}, false, err
+		}
+		return nil, time.Time{}, false, fmt.Errorf("failed to check access to %s/%s: %w", repo.RepoOwner, repo.RepoName, err)
+	}
+
+	from, ok = pipelineUsecase.syncUsecase.SyncWindow(repo, since, until)
+	if !ok {
+		return nil, from, false, nil
+	}
+	days, err = pipelineUsecase.syncUsecase.FetchRepository(ctx, repo, from, until)
+	if err != nil {
+		return nil, from, false, fmt.Errorf("failed to fetch %s/%s: %w", repo.RepoOwner, repo.RepoName, err)
+	}
+	return days, from, true, nil
+}
+
+// syncSnapshot 差分計算用の期間内のコミット数とstreak日数（日付は YYYY-MM-DD）
+type syncSnapshot struct {
+	repoCounts []map[string]int // repos と同じ順序
+	totals     map[string]int
+	streaks    repository.StreakLengths
+}
+
+// snapshot トランザクション内で期間内の現在の値を読み出す
+func (pipelineUsecase *PipelineUsecase) snapshot(ctx context.Context, tx *gorm.DB, userID uint64, repos []models.UserRepository, since, until time.Time) (*syncSnapshot, error) {
+	since, until = truncateToDate(since), truncateToDate(until)
+	snap := &syncSnapshot{repoCounts: make([]map[string]int, len(repos)), totals: make(map[string]int)}
+
+	repoLogRepo := pipelineUsecase.repoLogRepo.WithTx(tx)
+	for i := range repos {
+		logs, err := repoLogRepo.ListByUserRepoID(ctx, repos[i].ID, since, until)
+		if err != nil {
+			return nil, err
+		}
+		snap.repoCounts[i] = make(map[string]int, len(logs))
+		for _, commitLog := range logs {
+			snap.repoCounts[i][commitLog.CommitDate.UTC().Format("2006-01-02")] = commitLog.CommitCount
+		}
+	}
+
+	logs, err := pipelineUsecase.userLogRepo.WithTx(tx).ListByUserID(ctx, userID, since, until)
+	if err != nil {
+		return nil, err
+	}
+	for _, commitLog := range logs {
+		snap.totals[commitLog.Date.UTC().Format("2006-01-02")] = commitLog.TotalCommits
+	}
+
+	snap.streaks, err = pipelineUsecase.streakRepo.WithTx(tx).LengthsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// buildSyncPreview 書き込み前後のスナップショットを比べて差分を組み立てる
+func buildSyncPreview(repos []models.UserRepository, before, after *syncSnapshot) *dto.SyncPreview {
+	preview := &dto.SyncPreview{
+		Repositories: make([]dto.RepositorySyncPreview, 0, len(repos)),
+		DailyTotals:  diffCounts(before.totals, after.totals),
+		Streak: dto.StreakChange{
+			CurrentBefore: before.streaks.Current,
+			CurrentAfter:  after.streaks.Current,
+			LongestBefore: before.streaks.Longest,
+			LongestAfter:  after.streaks.Longest,
+		},
+	}
+	for i, repo := range repos {
+		preview.Repositories = append(preview.Repositories, dto.RepositorySyncPreview{
+			RepositoryID: repo.ID,
+			Owner:        repo.DisplayOwner,
+			Name:         repo.DisplayName,
+			Days:         diffCounts(before.repoCounts[i], after.repoCounts[i]),
+		})
+	}
+	return preview
+}
+
+// diffCounts 値が変わった日付を日付順に返す（無くなった日は After が0）
+func diffCounts(before, after map[string]int) []dto.DailyCountChange {
+	dates := make([]string, 0, len(before)+len(after))
+	for date := range before {
+		dates = append(dates, date)
+	}
+	for date := range after {
+		if _, ok := before[date]; !ok {
+			dates = append(dates, date)
+		}
+	}
+	sort.Strings(dates)
+
+	changes := make([]dto.DailyCountChange, 0)
+	for _, date := range dates {
+		if before[date] != after[date] {
+			changes = append(changes, dto.DailyCountChange{Date: date, Before: before[date], After: after[date]})
+		}
+	}
+	return changes
+}
+
+// RunForAllUsers 全ユーザーに対してRunForUserを実行する
+// syncConcurrency 人ずつ並行に処理し、1ユーザー（1リポジトリ）の失敗で他を止めない。
+// 失敗したユーザー・リポジトリはまとめてエラーとして返し、実行記録に残す
+// GitHubのレート制限に達した場合は github.RateLimitError を返して打ち切る
+func (pipelineUsecase *PipelineUsecase) RunForAllUsers(ctx context.Context, since, until time.Time) error {
+	run := pipelineUsecase.startRun(ctx, nil, nil)
+	processed, err := pipelineUsecase.runForAllUsers(ctx, since, until)
+	pipelineUsecase.finishRun(ctx, run, processed, err)
+	return err
+}
+
+// userSyncFailure 定期同期で失敗したユーザーとそのエラー
+type userSyncFailure struct {
+	userID uint64
+	err    error
+}
+
+// runForAllUsers RunForAllUsers の本体。同期できたリポジトリ数を返す
+func (pipelineUsecase *PipelineUsecase) runForAllUsers(ctx context.Context, since, until time.Time) (int, error) {
+	userIDs, err := pipelineUsecase.userRepo.ListIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// レート制限中は残りのユーザーも失敗するため、このサイクルを打ち切って次回に回す
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan uint64)
+	var (
+		mu           sync.Mutex
+		wg           sync.WaitGroup
+		processed    int
+		failures     []userSyncFailure
+		failedUsers  int
+		rateLimitErr error
+	)
+	for i := 0; i < min(pipelineUsecase.syncConcurrency, len(userIDs)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for userID := range jobs {
+				preview, repoErrs, err := pipelineUsecase.runForUser(ctx, userID, since, until, false, true)
+				mu.Lock()
+				var limited *github.RateLimitError
+				switch {
+				case errors.As(err, &limited):
+					if rateLimitErr == nil {
+						rateLimitErr = err
+					}
+					cancel()
+				case err != nil:
+					if ctx.Err() == nil {
+						log.Printf("Pipeline failed for user %d: %v", userID, err)
+						failedUsers++
+						failures = append(failures, userSyncFailure{userID: userID, err: err})
+					}
+				default:
+					processed += len(preview.Repositories)
+				}
+				for _, repoErr := range repoErrs {
+					log.Printf("Pipeline skipped a repository for user %d: %v", userID, repoErr)
+					failures = append(failures, userSyncFailure{userID: userID, err: repoErr})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- userID
+	}
+	close(jobs)
+	wg.Wait()
+
+	if rateLimitErr != nil {
+		return processed, rateLimitErr
+	}
+	if len(failures) > 0 {
+		sort.SliceStable(failures, func(i, j int) bool {
+			return failures[i].userID < failures[j].userID
+		})
+		errs := make([]error, 0, len(failures)+1)
+		errs = append(errs, fmt.Errorf("pipeline failed for %d of %d users (%d errors)", failedUsers, len(userIDs), len(failures)))
+		for _, failure := range failures {
+			errs = append(errs, fmt.Errorf("user %d: %w", failure.userID, failure.err))
+		}
+		return processed, errors.Join(errs...)
+	}
+	return processed, context.Cause(ctx)
+}
+
+// startRun 同期ジョブの開始を記録する（記録に失敗しても同期は止めない）
+func (pipelineUsecase *PipelineUsecase) startRun(ctx context.Context, userID, userRepoID *uint64) *models.SyncJobRun {
+	run := &models.SyncJobRun{
+		UserID:     userID,
+		UserRepoID: userRepoID,
+		Status:     models.SyncJobRunStatusRunning,
+		StartedAt:  time.Now(),
+	}
+	if err := pipelineUsecase.syncRunRepo.Create(ctx, run); err != nil {
+		log.Printf("Failed to record sync run start: %v", err)
+	}
+	return run
+}
+
+// finishRun 同期ジョブの終了を記録する。キャンセルやタイムアウトで終わった場合も残るよう ctx のキャンセルは引き継がない
+func (pipelineUsecase *PipelineUsecase) finishRun(ctx context.Context, run *models.SyncJobRun, processed int, runErr error) {
+	if run.ID == 0 {
+		return
+	}
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.ReposProcessed = processed
+	run.Status = models.SyncJobRunStatusSucceeded
+	if runErr != nil {
+		run.Status = models.SyncJobRunStatusFailed
+		run.Error = runErr.Error()
+	}
+	if err := pipelineUsecase.syncRunRepo.Update(context.WithoutCancel(ctx), run); err != nil {
+		log.Printf("Failed to record sync run %d result: %v", run.ID, err)
+	}
+}
+
+// ListSyncRuns 同期ジョブの実行記録を新しい順に取得
+func (pipelineUsecase *PipelineUsecase) ListSyncRuns(ctx context.Context, limit int) ([]dto.SyncJobRunResponse, error) {
+	runs, err := pipelineUsecase.syncRunRepo.ListRecent(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.SyncJobRunResponse, 0, len(runs))
+	for _, run := range runs {
+		res = append(res, dto.SyncJobRunResponse{
+			ID:             run.ID,
+			UserID:         run.UserID,
+			RepositoryID:   run.UserRepoID,
+			Status:         run.Status,
+			Error:          run.Error,
+			ReposProcessed: run.ReposProcessed,
+			StartedAt:      run.StartedAt,
+			FinishedAt:     run.FinishedAt,
+		})
+	}
+	return res, nil
+}
+
+// BackfillRepository 登録リポジトリの過去 days 日分のコミットを取り込み（上限は呼び出し側で limits.Limits により確認する）、
+// その期間の日次集計・streak・バッジを作り直す
+//
+// リクエスト内で同期的に実行し、backfillTimeout を超えた場合は context.DeadlineExceeded を返す。
+// 書き込みは RunForUser と同じく1トランザクションで行うため、途中で打ち切られても何も保存されない。
+// 日次ログは一意インデックスで上書きされるため、何度実行しても結果は変わらない
+func (pipelineUsecase *PipelineUsecase) BackfillRepository(ctx context.Context, userID, repoID uint64, days int) (*dto.BackfillRepositoryResponse, error) {
+	repo, err := pipelineUsecase.repoRepo.FindByID(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	if repo.UserID != userID {
+		return nil, ErrRepositoryNotOwned
+	}
+	if repo.DeactivatedAt != nil {
+		return nil, ErrRepositoryDeactivated
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, backfillTimeout)
+	defer cancel()
+
+	run := pipelineUsecase.startRun(ctx, &userID, &repo.ID)
+	// 今日のコミットも取り込むよう、GitHubからは現在時刻までを取得する
+	until := time.Now()
+	since := truncateToDate(until).AddDate(0, 0, -(days - 1))
+
+	err = pipelineUsecase.syncUsecase.RefreshAccess(ctx, repo, false)
+	var fetched []github.DayCommits
+	if err == nil {
+		fetched, err = pipelineUsecase.syncUsecase.FetchRepository(ctx, repo, since, until)
+	}
+	var stored []int
+	if err == nil {
+		repos := []models.UserRepository{*repo}
+		err = withTransaction(ctx, pipelineUsecase.database, func(ctx context.Context, tx *gorm.DB) error {
+			stored, err = pipelineUsecase.writeAll(ctx, tx, userID, repos, [][]github.DayCommits{fetched}, since, until)
+			return err
+		})
+	}
+	if err != nil {
+		pipelineUsecase.finishRun(ctx, run, 0, err)
+		return nil, err
+	}
+	pipelineUsecase.finishRun(ctx, run, 1, nil)
+
+	return &dto.BackfillRepositoryResponse{
+		RepositoryID: repo.ID,
+		Since:        since.Format("2006-01-02"),
+		Until:        truncateToDate(until).Format("2006-01-02"),
+		DaysSynced:   stored[0],
+	}, nil
+}
+
+// SyncRepository 登録リポジトリ1件を定期同期と同じく前回の同期以降だけ（since〜until の範囲内で）同期し、
+// その期間の日次集計・streak・バッジを作り直す（スケジューラーを待たずに取り直すためのもの）
+//
+// BackfillRepository と同じくリクエスト内で同期的に実行し、backfillTimeout を超えた場合は context.DeadlineExceeded を返す。
+// GitHubのレート制限に達した場合は github.RateLimitError を返す
+func (pipelineUsecase *PipelineUsecase) SyncRepository(ctx context.Context, userID, repoID uint64, days int) (*dto.SyncRepositoryResponse, error) {
+	repo, err := pipelineUsecase.repoRepo.FindByID(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	if repo.UserID != userID {
+		return nil, ErrRepositoryNotOwned
+	}
+	if repo.DeactivatedAt != nil {
+		return nil, ErrRepositoryDeactivated
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, backfillTimeout)
+	defer cancel()
+
+	run := pipelineUsecase.startRun(ctx, &userID, &repo.ID)
+	// 今日のコミットも取り込むよう、GitHubからは現在時刻までを取得する
+	until := time.Now()
+	since := truncateToDate(until).AddDate(0, 0, -(days - 1))
+	res := &dto.SyncRepositoryResponse{RepositoryID: repo.ID, Until: until.UTC().Format("2006-01-02")}
+
+	err = pipelineUsecase.syncRepository(ctx, repo, since, until, res)
+	if err != nil {
+		pipelineUsecase.finishRun(ctx, run, 0, err)
+		return nil, err
+	}
+	pipelineUsecase.finishRun(ctx, run, 1, nil)
+	return res, nil
+}
+
+// syncRepository SyncRepository の本体。取得・書き込みの結果を res に入れる
+func (pipelineUsecase *PipelineUsecase) syncRepository(ctx context.Context, repo *models.UserRepository, since, until time.Time, res *dto.SyncRepositoryResponse) error {
+	repos := []models.UserRepository{*repo}
+	fetched, from, ok, err := pipelineUsecase.fetchForSync(ctx, repo, since, until, false)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// until まで同期済みで取得するものが無い
+		current, err := pipelineUsecase.snapshot(ctx, pipelineUsecase.database.WithContext(ctx), repo.UserID, repos, until, until)
+		if err != nil {
+			return err
+		}
+		res.Since = until.UTC().Format("2006-01-02")
+		res.Streak = buildSyncPreview(repos, current, current).Streak
+		return nil
+	}
+
+	return withTransaction(ctx, pipelineUsecase.database, func(ctx context.Context, tx *gorm.DB) error {
+		before, err := pipelineUsecase.snapshot(ctx, tx, repo.UserID, repos, from, until)
+		if err != nil {
+			return err
+		}
+		stored, err := pipelineUsecase.writeAll(ctx, tx, repo.UserID, repos, [][]github.DayCommits{fetched}, from, until)
+		if err != nil {
+			return err
+		}
+		after, err := pipelineUsecase.snapshot(ctx, tx, repo.UserID, repos, from, until)
+		if err != nil {
+			return err
+		}
+
+		preview := buildSyncPreview(repos, before, after)
+		res.Since = from.Format("2006-01-02")
+		// 未来の日付で保存しなかった日は数えない
+		res.DaysWritten = stored[0]
+		res.DaysUpdated = len(preview.Repositories[0].Days)
+		res.Streak = preview.Streak
+		return nil
+	})
+}
+
+// RecomputeAll 全ユーザーの日次集計・streak（リポジトリ単位を含む）を全期間で計算し直す
+// concurrency 人ずつ並行に処理し、ユーザーごとに1トランザクションで書き込むため、スケジューラーの同期と同時に実行してもよい。
+// 失敗したユーザーは結果に記録して続行する
+func (pipelineUsecase *PipelineUsecase) RecomputeAll(ctx context.Context, concurrency int) (*dto.RecomputeResponse, error) {
+	userIDs, err := pipelineUsecase.userRepo.ListIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make(chan uint64)
+	res := &dto.RecomputeResponse{Failures: make([]dto.RecomputeFailure, 0)}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for userID := range jobs {
+				err := pipelineUsecase.recomputeUser(ctx, userID)
+				mu.Lock()
+				if err != nil {
+					log.Printf("Recompute failed for user %d: %v", userID, err)
+					res.Failed++
+					res.Failures = append(res.Failures, dto.RecomputeFailure{UserID: userID, Error: err.Error()})
+				} else {
+					res.Processed++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- userID
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.Slice(res.Failures, func(i, j int) bool {
+		return res.Failures[i].UserID < res.Failures[j].UserID
+	})
+	return res, ctx.Err()
+}
+
+// RecomputeUser 1ユーザーの日次集計とstreakを全期間で計算し直し、再計算後のstreakを返す（何度呼んでも同じ結果になる）
+// ユーザーが存在しない場合は repository.ErrNotFound を返す
+func (pipelineUsecase *PipelineUsecase) RecomputeUser(ctx context.Context, userID uint64) (*dto.StreakSummaryResponse, error) {
+	if _, err := pipelineUsecase.userRepo.FindByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if err := pipelineUsecase.recomputeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	streaks, err := pipelineUsecase.streakRepo.LengthsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.StreakSummaryResponse{
+		UserID:        userID,
+		CurrentStreak: streaks.Current,
+		LongestStreak: streaks.Longest,
+		Level:         toStreakLevel(pipelineUsecase.levels.For(streaks.Current)),
+	}, nil
+}
+
+// ReconcileUser 1ユーザーの日次集計をリポジトリ別日次ログと突き合わせ、食い違う日を直す（直した日があればstreakも計算し直す）
+// ユーザーが存在しない場合は repository.ErrNotFound を返す
+func (pipelineUsecase *PipelineUsecase) ReconcileUser(ctx context.Context, userID uint64) (*dto.ReconcileResponse, error) {
+	if _, err := pipelineUsecase.userRepo.FindByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	var result ReconcileResult
+	err := withTransaction(ctx, pipelineUsecase.database, func(ctx context.Context, tx *gorm.DB) error {
+		var err error
+		result, err = pipelineUsecase.aggregationUsecase.WithTx(tx).Reconcile(ctx, userID, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to reconcile daily logs: %w", err)
+		}
+		if result.FixedDays == 0 {
+			return nil
+		}
+		if err := pipelineUsecase.streakUsecase.WithTx(tx).RecalculateStreaks(ctx, userID); err != nil {
+			return fmt.Errorf("failed to recalculate streaks: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.FixedDays > 0 {
+		log.Printf("Reconciled user %d: fixed %d of %d days", userID, result.FixedDays, result.CheckedDays)
+	}
+	return &dto.ReconcileResponse{UserID: userID, CheckedDays: result.CheckedDays, FixedDays: result.FixedDays}, nil
+}
+
+// ReconcileAll 全ユーザーの日次集計を突き合わせて直す（定期実行用）。失敗したユーザーは記録して続行する
+func (pipelineUsecase *PipelineUsecase) ReconcileAll(ctx context.Context) error {
+	userIDs, err := pipelineUsecase.userRepo.ListIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	fixedUsers, fixedDays := 0, 0
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		res, err := pipelineUsecase.ReconcileUser(ctx, userID)
+		if err != nil {
+			// ListIDs の後に削除されたユーザーは対象外
+			if errors.Is(err, repository.ErrNotFound) {
+				continue
+			}
+			errs = append(errs, fmt.Errorf("user %d: %w", userID, err))
+			continue
+		}
+		if res.FixedDays > 0 {
+			fixedUsers++
+			fixedDays += res.FixedDays
+		}
+	}
+
+	log.Printf("Reconciliation fixed %d days for %d of %d users", fixedDays, fixedUsers, len(userIDs))
+	if len(errs) > 0 {
+		return errors.Join(append([]error{fmt.Errorf("reconciliation failed for %d users", len(errs))}, errs...)...)
+	}
+	return nil
+}
+
+// recomputeUser 1ユーザーの日次集計とstreakを全期間で作り直す（1トランザクション）
+func (pipelineUsecase *PipelineUsecase) recomputeUser(ctx context.Context, userID uint64) error {
+	return withTransaction(ctx, pipelineUsecase.database, func(ctx context.Context, tx *gorm.DB) error {
+		repos, err := pipelineUsecase.repoRepo.WithTx(tx).ListByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		streakUsecase := pipelineUsecase.streakUsecase.WithTx(tx)
+		for _, repo := range repos {
+			if err := streakUsecase.RecalculateRepoStreaks(ctx, repo.ID); err != nil {
+				return fmt.Errorf("failed to recalculate streaks for %s/%s: %w", repo.RepoOwner, repo.RepoName, err)
+			}
+		}
+
+		if err := pipelineUsecase.aggregationUsecase.WithTx(tx).RebuildRange(ctx, userID, time.Time{}, time.Now()); err != nil {
+			return fmt.Errorf("failed to aggregate commits: %w", err)
+		}
+		if err := streakUsecase.RecalculateStreaks(ctx, userID); err != nil {
+			return fmt.Errorf("failed to recalculate streaks: %w", err)
+		}
+		return nil
+	})
+}
+
+// writeAll トランザクション内で各ステップの書き込みを行う
+// stored は repos と同じ順序で、リポジトリ別日次ログに保存した日数（未来の日付として飛ばした日は含まない）
+func (pipelineUsecase *PipelineUsecase) writeAll(ctx context.Context, tx *gorm.DB, userID uint64, repos []models.UserRepository, fetched [][]github.DayCommits, since, until time.Time) (stored []int, err error) {
+	syncUsecase := pipelineUsecase.syncUsecase.WithTx(tx)
+	streakUsecase := pipelineUsecase.streakUsecase.WithTx(tx)
+	stored = make([]int, len(repos))
+	for i := range repos {
+		if stored[i], err = syncUsecase.StoreRepository(ctx, &repos[i], fetched[i], until); err != nil {
+			return nil, fmt.Errorf("failed to store commits for %s/%s: %w", repos[i].RepoOwner, repos[i].RepoName, err)
+		}
+		if err := streakUsecase.RecalculateRepoStreaks(ctx, repos[i].ID); err != nil {
+			return nil, fmt.Errorf("failed to recalculate streaks for %s/%s: %w", repos[i].RepoOwner, repos[i].RepoName, err)
+		}
+	}
+	// タイムゾーンの推定は候補を出すだけのため、失敗しても同期は続ける。
+	// 失敗した書き込みでトランザクション全体が中断しないよう、セーブポイントの中で行う
+	if syncUsecase.inferTimezone {
+		err := tx.Transaction(func(savepoint *gorm.DB) error {
+			return pipelineUsecase.syncUsecase.WithTx(savepoint).InferTimezone(ctx, userID, until)
+		})
+		if err != nil {
+			log.Printf("Failed to infer timezone for user %d: %v", userID, err)
+		}
+	}
+
+	if err := pipelineUsecase.aggregationUsecase.WithTx(tx).RebuildRange(ctx, userID, since, until); err != nil {
+		return nil, fmt.Errorf("failed to aggregate commits: %w", err)
+	}
+	if err := streakUsecase.RecalculateStreaks(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to recalculate streaks: %w", err)
+	}
+	if err := pipelineUsecase.achievementUsecase.WithTx(tx).Evaluate(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to evaluate achievements: %w", err)
+	}
+	return stored, nil
+}
@@ -0,0 +1,343 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keeee21/commit-town/api/events"
+	"github.com/keeee21/commit-town/api/internal/githubtest"
+	"github.com/keeee21/commit-town/api/models"
+	"gorm.io/gorm"
+)
+
+func TestPipelineUsecase_RunForUser_RollsBackOnStreakFailure(t *testing.T) {
+	env := newTestEnv(t, testEnvConfig{})
+	user := env.createUser(t, "alice")
+	repo := env.createRepo(t, user, "alice", "town", commitsOn("alice", 0, 1, 2)...)
+
+	// streak の書き込みだけを失敗させる
+	injected := errors.New("injected streak failure")
+	err := env.db.Callback().Create().Before("gorm:create").Register("test:fail_user_streaks", func(tx *gorm.DB) {
+		if tx.Statement.Table == "user_streaks" {
+			tx.AddError(injected)
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to register callback: %v", err)
+	}
+
+	delivered := env.recordEvents()
+
+	_, err = env.pipeline.RunForUser(context.Background(), user.ID, daysAgo(7), time.Now(), false)
+	if !errors.Is(err, injected) {
+		t.Fatalf("RunForUser error = %v, want %v", err, injected)
+	}
+	if len(*delivered) != 0 {
+		t.Errorf("rolled back sync delivered events %v, want none", *delivered)
+	}
+
+	if got := env.countRows(t, &models.RepoDailyCommitLog{}); got != 0 {
+		t.Errorf("repo_daily_commit_logs has %d rows, want 0", got)
+	}
+	if got := env.countRows(t, &models.UserDailyCommitLog{}); got != 0 {
+		t.Errorf("user_daily_commit_logs has %d rows, want 0", got)
+	}
+	reloaded, err := env.repoRepo.FindByID(context.Background(), repo.ID)
+	if err != nil {
+		t.Fatalf("failed to reload repository: %v", err)
+	}
+	if reloaded.LastSyncedAt != nil {
+		t.Errorf("LastSyncedAt = %v, want nil", *reloaded.LastSyncedAt)
+	}
+}
+
+// ドライランは差分を返すが、DBの行を何も変えない
+func TestPipelineUsecase_RunForUser_DryRunWritesNothing(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	user := env.createUser(t, "alice")
+	repo := env.createRepo(t, user, "alice", "town", commitsOn("alice", 0, 1, 2)...)
+
+	tables := []any{&models.RepoDailyCommitLog{}, &models.UserDailyCommitLog{}, &models.UserStreak{}, &models.RepoStreak{}, &models.UserAchievement{}, &models.SyncJobRun{}}
+	counts := make([]int64, len(tables))
+	for i, table := range tables {
+		counts[i] = env.countRows(t, table)
+	}
+
+	preview, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(7), time.Now(), true)
+	if err != nil {
+		t.Fatalf("RunForUser returned an error: %v", err)
+	}
+	if !preview.DryRun {
+		t.Error("DryRun = false, want true")
+	}
+	if len(preview.Repositories) != 1 || len(preview.Repositories[0].Days) != 3 {
+		t.Errorf("preview repositories = %+v, want 3 changed days for one repository", preview.Repositories)
+	}
+	if len(preview.DailyTotals) != 3 || preview.Streak.CurrentAfter != 3 {
+		t.Errorf("preview = %+v, want 3 changed daily totals and a current streak of 3", preview)
+	}
+
+	for i, table := range tables {
+		if got := env.countRows(t, table); got != counts[i] {
+			t.Errorf("%T has %d rows after the dry run, want %d", table, got, counts[i])
+		}
+	}
+	reloaded, err := env.repoRepo.FindByID(ctx, repo.ID)
+	if err != nil {
+		t.Fatalf("failed to reload repository: %v", err)
+	}
+	if reloaded.LastSyncedAt != nil {
+		t.Errorf("LastSyncedAt = %v, want nil after a dry run", *reloaded.LastSyncedAt)
+	}
+}
+
+// イベントはコミットした後に配信し、ドライランでは配信しない
+func TestPipelineUsecase_RunForUser_PublishesAfterCommit(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	user := env.createUser(t, "alice")
+	env.createRepo(t, user, "alice", "town", commitsOn("alice", 0, 1, 2)...)
+
+	// 購読者からはコミット済みの内容が読めること
+	var streaksAtDelivery int64 = -1
+	env.bus.Subscribe(events.StreakStarted, func(ctx context.Context, event events.Event) error {
+		streaksAtDelivery = env.countRows(t, &models.UserStreak{})
+		return nil
+	})
+	delivered := env.recordEvents()
+
+	if _, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(7), time.Now(), true); err != nil {
+		t.Fatalf("dry run returned an error: %v", err)
+	}
+	if len(*delivered) != 0 {
+		t.Fatalf("dry run delivered events %v, want none", *delivered)
+	}
+
+	if _, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(7), time.Now(), false); err != nil {
+		t.Fatalf("RunForUser returned an error: %v", err)
+	}
+	if want := []events.Type{events.RepositorySynced, events.StreakStarted}; !slices.Equal(*delivered, want) {
+		t.Errorf("delivered %v, want %v", *delivered, want)
+	}
+	if streaksAtDelivery != 1 {
+		t.Errorf("subscriber saw %d streaks, want the committed streak", streaksAtDelivery)
+	}
+}
+
+// 未来の日付として保存しなかった日は DaysSynced に数えず、今日のコミットは取り込む
+func TestPipelineUsecase_BackfillRepository_CountsStoredDays(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	user := env.createUser(t, "alice")
+	repo := env.createRepo(t, user, "alice", "town")
+	env.github.SetRepo("alice", "town", githubtest.Repo{Commits: commitsOn("alice", 5, 1, 0, -2), IgnoreUntil: true})
+
+	res, err := env.pipeline.BackfillRepository(ctx, user.ID, repo.ID, 30)
+	if err != nil {
+		t.Fatalf("BackfillRepository returned an error: %v", err)
+	}
+	if res.DaysSynced != 3 {
+		t.Errorf("DaysSynced = %d, want 3", res.DaysSynced)
+	}
+	if got := env.countRows(t, &models.RepoDailyCommitLog{}); got != 3 {
+		t.Errorf("repo_daily_commit_logs has %d rows, want 3", got)
+	}
+	if res.Until != dateOf(0) {
+		t.Errorf("Until = %s, want %s", res.Until, dateOf(0))
+	}
+}
+
+// 手動の同期は今日のコミットまで取り込み、DaysWritten には保存した日だけを数える（未来の日付は除く）
+func TestPipelineUsecase_SyncRepository_CountsStoredDays(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	user := env.createUser(t, "alice")
+	repo := env.createRepo(t, user, "alice", "town")
+	env.github.SetRepo("alice", "town", githubtest.Repo{Commits: commitsOn("alice", 5, 1, 0, -2), IgnoreUntil: true})
+
+	res, err := env.pipeline.SyncRepository(ctx, user.ID, repo.ID, 7)
+	if err != nil {
+		t.Fatalf("SyncRepository returned an error: %v", err)
+	}
+	if res.DaysWritten != 3 || res.DaysUpdated != 3 {
+		t.Errorf("days written/updated = %d/%d, want 3/3", res.DaysWritten, res.DaysUpdated)
+	}
+	if got := env.countRows(t, &models.RepoDailyCommitLog{}); got != 3 {
+		t.Errorf("repo_daily_commit_logs has %d rows, want 3", got)
+	}
+	if res.Since != dateOf(6) || res.Until != dateOf(0) {
+		t.Errorf("window = %s..%s, want %s..%s", res.Since, res.Until, dateOf(6), dateOf(0))
+	}
+	if total := env.userTotals(t, user)[dateOf(0)]; total != 1 {
+		t.Errorf("total today = %d, want today's commit included", total)
+	}
+
+	// 2回目は同期済みの今日だけを取り直し、数は変わらない
+	again, err := env.pipeline.SyncRepository(ctx, user.ID, repo.ID, 7)
+	if err != nil {
+		t.Fatalf("second SyncRepository returned an error: %v", err)
+	}
+	if again.DaysWritten != 1 || again.DaysUpdated != 0 {
+		t.Errorf("second days written/updated = %d/%d, want 1/0", again.DaysWritten, again.DaysUpdated)
+	}
+}
+
+// 同期済みの時点より前までの同期はGitHubから何も取得せず、何も書き込まない
+func TestPipelineUsecase_RunForUser_UnchangedCursorFetchesNothing(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	user := env.createUser(t, "alice")
+	repo := env.createRepo(t, user, "alice", "town", commitsOn("alice", 0, 1, 2)...)
+
+	if _, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(7), time.Now(), false); err != nil {
+		t.Fatalf("first RunForUser returned an error: %v", err)
+	}
+	synced, err := env.repoRepo.FindByID(ctx, repo.ID)
+	if err != nil {
+		t.Fatalf("failed to reload repository: %v", err)
+	}
+	if synced.LastSyncedAt == nil {
+		t.Fatal("LastSyncedAt was not recorded")
+	}
+
+	tables := []any{&models.RepoDailyCommitLog{}, &models.UserDailyCommitLog{}, &models.UserStreak{}, &models.RepoStreak{}}
+	counts := make([]int64, len(tables))
+	for i, table := range tables {
+		counts[i] = env.countRows(t, table)
+	}
+	totals := env.userTotals(t, user)
+
+	preview, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(7), daysAgo(1), false)
+	if err != nil {
+		t.Fatalf("second RunForUser returned an error: %v", err)
+	}
+	if got := env.github.CommitRequests("alice", "town"); got != 1 {
+		t.Errorf("commits were fetched %d times, want only the first sync", got)
+	}
+	if len(preview.Repositories) != 1 || len(preview.Repositories[0].Days) != 0 || len(preview.DailyTotals) != 0 {
+		t.Errorf("preview = %+v, want no changes", preview)
+	}
+	for i, table := range tables {
+		if got := env.countRows(t, table); got != counts[i] {
+			t.Errorf("%T has %d rows after the second sync, want %d", table, got, counts[i])
+		}
+	}
+	after := env.userTotals(t, user)
+	for date, total := range totals {
+		if after[date] != total {
+			t.Errorf("total on %s = %d, want %d", date, after[date], total)
+		}
+	}
+	reloaded, err := env.repoRepo.FindByID(ctx, repo.ID)
+	if err != nil {
+		t.Fatalf("failed to reload repository: %v", err)
+	}
+	if reloaded.LastSyncedAt == nil || !reloaded.LastSyncedAt.Equal(*synced.LastSyncedAt) {
+		t.Errorf("LastSyncedAt = %v, want unchanged %v", reloaded.LastSyncedAt, *synced.LastSyncedAt)
+	}
+}
+
+// 定期同期は syncConcurrency 人ずつ並行に処理し、失敗したリポジトリがあっても他のユーザーは同期する
+func TestPipelineUsecase_RunForAllUsers_BoundedConcurrency(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	env.github.SetLatency(50 * time.Millisecond)
+
+	logins := []string{"alice", "bob", "carol", "dave", "erin", "frank"}
+	repos := make([]*models.UserRepository, 0, len(logins))
+	for _, login := range logins {
+		user := env.createUser(t, login)
+		repos = append(repos, env.createRepo(t, user, login, "town", commitsOn(login, 0, 1)...))
+	}
+	broken := env.createUser(t, "grace")
+	env.createRepo(t, broken, "grace", "town")
+	env.github.SetRepo("grace", "town", githubtest.Repo{FailCommits: true})
+
+	err := env.pipeline.RunForAllUsers(ctx, daysAgo(7), time.Now())
+	if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("user %d", broken.ID)) {
+		t.Fatalf("RunForAllUsers error = %v, want the failure of user %d", err, broken.ID)
+	}
+	if got := env.github.MaxInFlight(); got != 2 {
+		t.Errorf("max concurrent GitHub requests = %d, want the sync concurrency of 2", got)
+	}
+
+	for _, repo := range repos {
+		reloaded, err := env.repoRepo.FindByID(ctx, repo.ID)
+		if err != nil {
+			t.Fatalf("failed to reload repository: %v", err)
+		}
+		if reloaded.LastSyncedAt == nil {
+			t.Errorf("%s/%s was not synced", repo.RepoOwner, repo.RepoName)
+		}
+	}
+	if got := env.countRows(t, &models.RepoDailyCommitLog{}); got != int64(2*len(logins)) {
+		t.Errorf("repo_daily_commit_logs has %d rows, want %d", got, 2*len(logins))
+	}
+
+	runs, err := env.syncRunRepo.ListRecent(ctx, 1)
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("ListRecent = %v, %v, want one run", runs, err)
+	}
+	if runs[0].Status != models.SyncJobRunStatusFailed || runs[0].ReposProcessed != len(logins) {
+		t.Errorf("run status/processed = %s/%d, want %s/%d", runs[0].Status, runs[0].ReposProcessed, models.SyncJobRunStatusFailed, len(logins))
+	}
+}
+
+// 食い違ったユーザー単位の日次ログだけを直し、直した後の突き合わせでは何も変えない
+func TestPipelineUsecase_ReconcileUser_FixesDrift(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	user := env.createUser(t, "alice")
+	env.createRepo(t, user, "alice", "town", commitsOn("alice", 3, 2, 2, 1)...)
+
+	if _, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(7), time.Now(), false); err != nil {
+		t.Fatalf("RunForUser returned an error: %v", err)
+	}
+	want := map[string]int{dateOf(3): 1, dateOf(2): 2, dateOf(1): 1}
+
+	// 途中で失敗した同期のずれ：合計の誤り・日次ログの欠け・リポジトリ別日次ログに無い日
+	logDate := func(days int) time.Time { return truncateToDate(daysAgo(days)) }
+	if err := env.db.Model(&models.UserDailyCommitLog{}).Where("user_id = ? AND date = ?", user.ID, logDate(2)).Update("total_commits", 5).Error; err != nil {
+		t.Fatalf("failed to corrupt the rollup: %v", err)
+	}
+	if err := env.db.Where("user_id = ? AND date = ?", user.ID, logDate(3)).Delete(&models.UserDailyCommitLog{}).Error; err != nil {
+		t.Fatalf("failed to delete the rollup: %v", err)
+	}
+	if err := env.userLogRepo.Upsert(ctx, &models.UserDailyCommitLog{UserID: user.ID, Date: logDate(5), TotalCommits: 4}); err != nil {
+		t.Fatalf("failed to add a stray rollup: %v", err)
+	}
+
+	res, err := env.pipeline.ReconcileUser(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ReconcileUser returned an error: %v", err)
+	}
+	if res.FixedDays != 3 || res.CheckedDays != 4 {
+		t.Errorf("fixed/checked = %d/%d, want 3/4", res.FixedDays, res.CheckedDays)
+	}
+	totals := env.userTotals(t, user)
+	if len(totals) != len(want) {
+		t.Errorf("totals = %v, want %v", totals, want)
+	}
+	for date, total := range want {
+		if totals[date] != total {
+			t.Errorf("total on %s = %d, want %d", date, totals[date], total)
+		}
+	}
+
+	again, err := env.pipeline.ReconcileUser(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("second ReconcileUser returned an error: %v", err)
+	}
+	if again.FixedDays != 0 || again.CheckedDays != 3 {
+		t.Errorf("second fixed/checked = %d/%d, want 0/3", again.FixedDays, again.CheckedDays)
+	}
+	if err := env.pipeline.ReconcileAll(ctx); err != nil {
+		t.Errorf("ReconcileAll returned an error: %v", err)
+	}
+}
@@ -0,0 +1,438 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/internal/github"
+	"github.com/keeee21/commit-town/api/models"
+	"github.com/keeee21/commit-town/api/pagination"
+	"github.com/keeee21/commit-town/api/repository"
+	"github.com/keeee21/commit-town/api/validator"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrRepositoryNotOwned 登録リポジトリが指定ユーザーのものではない
+	ErrRepositoryNotOwned = errors.New("repository does not belong to the user")
+	// ErrRepositoryHasLogs コミットログが残っているため cascade なしでは削除できない
+	ErrRepositoryHasLogs = errors.New("repository has commit logs")
+)
+
+type RepositoryUsecase struct {
+	database           *gorm.DB
+	userRepo           *repository.UserRepository
+	repoRepo           *repository.RepoRepository
+	repoLogRepo        *repository.RepoDailyCommitLogRepository
+	repoStreakRepo     *repository.RepoStreakRepository
+	repoValidator      *validator.RepoValidator
+	aggregationUsecase *AggregationUsecase
+	streakUsecase      *StreakUsecase
+}
+
+func NewRepositoryUsecase(database *gorm.DB, userRepo *repository.UserRepository, repoRepo *repository.RepoRepository, repoLogRepo *repository.RepoDailyCommitLogRepository, repoStreakRepo *repository.RepoStreakRepository, repoValidator *validator.RepoValidator, aggregationUsecase *AggregationUsecase, streakUsecase *StreakUsecase) *RepositoryUsecase {
+	return &RepositoryUsecase{
+		database:           database,
+		userRepo:           userRepo,
+		repoRepo:           repoRepo,
+		repoLogRepo:        repoLogRepo,
+		repoStreakRepo:     repoStreakRepo,
+		repoValidator:      repoValidator,
+		aggregationUsecase: aggregationUsecase,
+		streakUsecase:      streakUsecase,
+	}
+}
+
+// GetSyncStatus 登録リポジトリごとの最終同期日時と、staleAfter 以上同期されていないか（stale）を取得
+// 一度も同期されていないリポジトリは登録日時から数える。無効化されたリポジトリは同期対象外のため stale にしない
+func (repositoryUsecase *RepositoryUsecase) GetSyncStatus(ctx context.Context, userID uint64, staleAfter time.Duration) (*dto.RepoSyncStatusResponse, error) {
+	if _, err := repositoryUsecase.userRepo.FindByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	statuses, err := repositoryUsecase.repoRepo.ListSyncStatusByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	res := &dto.RepoSyncStatusResponse{
+		StaleAfterHours: int(staleAfter.Hours()),
+		Repositories:    make([]dto.RepoSyncStatus, 0, len(statuses)),
+	}
+	for _, status := range statuses {
+		lastSync := status.CreatedAt
+		if status.LastSyncedAt != nil {
+			lastSync = *status.LastSyncedAt
+		}
+		active := status.DeactivatedAt == nil
+		res.Repositories = append(res.Repositories, dto.RepoSyncStatus{
+			RepositoryID:     status.ID,
+			Owner:            status.DisplayOwner,
+			Name:             status.DisplayName,
+			Active:           active,
+			AccessStatus:     status.AccessStatus,
+			LatestCommitDate: formatDatePtr(status.LatestCommitDate),
+			LastSyncedAt:     status.LastSyncedAt,
+			Stale:            active && now.Sub(lastSync) > staleAfter,
+		})
+	}
+	return res, nil
+}
+
+// SearchRepositories オーナー名・リポジトリ名の部分一致で登録リポジトリを検索（管理者向け）
+func (repositoryUsecase *RepositoryUsecase) SearchRepositories(ctx context.Context, owner, name string, params pagination.ListParams) (*dto.RepositorySearchResponse, error) {
+	order := repository.RepoSearchOrder{ByCreatedAt: params.Sort == dto.RepositorySearchSortCreatedAt, Desc: params.Desc()}
+	entries, err := repositoryUsecase.repoRepo.Search(ctx, owner, name, order, params.Limit, params.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := repositoryUsecase.repoRepo.CountSearch(ctx, owner, name)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.RepositorySearchResponse{
+		Sort:         params.Sort,
+		Order:        params.Order,
+		Limit:        params.Limit,
+		Offset:       params.Offset,
+		Total:        total,
+		Repositories: make([]dto.RepositorySearchEntry, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		res.Repositories = append(res.Repositories, dto.RepositorySearchEntry{
+			ID:             entry.ID,
+			UserID:         entry.UserID,
+			GitHubUsername: entry.GitHubUsername,
+			Owner:          entry.DisplayOwner,
+			Name:           entry.DisplayName,
+			IsPublic:       entry.IsPublic,
+			Active:         entry.DeactivatedAt == nil,
+			CreatedAt:      entry.CreatedAt,
+		})
+	}
+	return res, nil
+}
+
+// BulkImport 複数のリポジトリをまとめて登録する（冪等）
+// 登録済みのものは skipped、不正・失敗したものは failed として項目ごとに結果を返し、
+// 一部の失敗でリクエスト全体を失敗させない。ユーザーが存在しない場合は repository.ErrNotFound を返す
+func (repositoryUsecase *RepositoryUsecase) BulkImport(ctx context.Context, userID uint64, req *dto.BulkImportRepositoriesRequest) (*dto.BulkImportRepositoriesResponse, error) {
+	user, err := repositoryUsecase.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.BulkImportRepositoriesResponse{
+		Results: make([]dto.BulkImportResult, 0, len(req.Repositories)),
+	}
+	for _, input := range req.Repositories {
+		result := repositoryUsecase.importOne(ctx, user, input)
+		switch result.Status {
+		case dto.BulkImportStatusCreated:
+			res.Created++
+		case dto.BulkImportStatusSkipped:
+			res.Skipped++
+		default:
+			res.Failed++
+		}
+		res.Results = append(res.Results, result)
+	}
+	return res, nil
+}
+
+// importOne 1件のリポジトリを検証して登録する
+func (repositoryUsecase *RepositoryUsecase) importOne(ctx context.Context, user *models.User, input dto.RepositoryInput) dto.BulkImportResult {
+	userID := user.ID
+	result := dto.BulkImportResult{Owner: input.Owner, Name: input.Name}
+
+	branch := ""
+	if input.Branch != nil {
+		branch = *input.Branch
+	}
+	displayOrder := 0
+	if input.DisplayOrder != nil {
+		displayOrder = *input.DisplayOrder
+	}
+	if err := repositoryUsecase.repoValidator.ValidateRepository(validator.RepositoryInput{
+		Owner:        input.Owner,
+		Name:         input.Name,
+		Branch:       branch,
+		CountMode:    input.CountMode,
+		DisplayOrder: displayOrder,
+	}); err != nil {
+		result.Status = dto.BulkImportStatusFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	repo := &models.UserRepository{
+		UserID:       userID,
+		RepoOwner:    input.Owner,
+		RepoName:     input.Name,
+		IsPublic:     input.IsPublic == nil || *input.IsPublic,
+		CountMode:    input.CountMode,
+		DisplayOrder: displayOrder,
+	}
+	if repo.CountMode == "" {
+		repo.CountMode = defaultCountMode(user, input.Owner)
+	}
+	if branch != "" {
+		repo.Branch = &branch
+	}
+	created, err := repositoryUsecase.repoRepo.CreateIfNotExists(ctx, repo)
+	if err != nil {
+		// 存在確認の後にユーザーが削除された場合
+		if errors.Is(err, repository.ErrNotFound) {
+			result.Status = dto.BulkImportStatusFailed
+			result.Error = "user not found"
+			return result
+		}
+		log.Printf("Failed to import repository %s/%s for user %d: %v", input.Owner, input.Name, userID, err)
+		result.Status = dto.BulkImportStatusFailed
+		result.Error = "failed to register repository"
+		return result
+	}
+
+	if created {
+		result.Status = dto.BulkImportStatusCreated
+		result.ID = repo.ID
+	} else {
+		result.Status = dto.BulkImportStatusSkipped
+	}
+	return result
+}
+
+// defaultCountMode count_mode 省略時の数え方
+// Organization など本人以外がオーナーのリポジトリは他のメンバーのコミットも含むため、本人が作者のコミットだけを数える
+func defaultCountMode(user *models.User, owner string) string {
+	if strings.EqualFold(owner, user.GitHubUsername) {
+		return models.CountModeAll
+	}
+	return models.CountModeAuthoredOnly
+}
+
+// DeactivateRepository 登録リポジトリを無効化する（冪等）
+//
+// 無効化の扱い:
+//   - 無効化以前のユーザー単位の日次ログ（UserDailyCommitLog）やstreakはそのまま残す
+//   - 以降の同期ではこのリポジトリを取得対象から外す
+//   - 日次集計を作り直す場合も、無効化日時より前の日付のコミットだけを合算する
+//
+// 過去の合計を消したい場合は DeleteRepository(cascade=true) を使う
+func (repositoryUsecase *RepositoryUsecase) DeactivateRepository(ctx context.Context, userID, repoID uint64) error {
+	repo, err := repositoryUsecase.repoRepo.FindByID(ctx, repoID)
+	if err != nil {
+		return err
+	}
+	if repo.UserID != userID {
+		return ErrRepositoryNotOwned
+	}
+	return repositoryUsecase.repoRepo.Deactivate(ctx, repo.ID, time.Now())
+}
+
+// DeactivateStale days 日以上コミットが記録されていない登録リポジトリを全ユーザー分まとめて無効化する（冪等）
+// 直近 days 日以内に登録したリポジトリは、まだ同期されていないだけの場合があるため対象外
+func (repositoryUsecase *RepositoryUsecase) DeactivateStale(ctx context.Context, days int, now time.Time) (*dto.DeactivateStaleResponse, error) {
+	cutoff := truncateToDate(now).AddDate(0, 0, -days)
+	deactivated, err := repositoryUsecase.repoRepo.DeactivateStale(ctx, cutoff, now)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Deactivated %d repositories with no commits since %s", deactivated, cutoff.Format("2006-01-02"))
+	return &dto.DeactivateStaleResponse{Days: days, Cutoff: cutoff, Deactivated: deactivated}, nil
+}
+
+// MinRawDataRetentionDays RawData を残す日数の下限（タイムゾーンの推定が直近の RawData を使うため）
+const MinRawDataRetentionDays = timezoneInferenceDays
+
+// rawDataCleanupBatchSize RawData の削除で1回の UPDATE で NULL にする件数
+const rawDataCleanupBatchSize = 1000
+
+// CleanupRawData days 日より前の日次ログの RawData を全ユーザー分まとめて削除する（CommitCount は残す。冪等）
+// days が MinRawDataRetentionDays 未満の場合は MinRawDataRetentionDays 日として扱う
+func (repositoryUsecase *RepositoryUsecase) CleanupRawData(ctx context.Context, days int, now time.Time) (*dto.RawDataCleanupResponse, error) {
+	days = max(days, MinRawDataRetentionDays)
+	cutoff := truncateToDate(now).AddDate(0, 0, -days)
+	cleared, err := repositoryUsecase.repoLogRepo.ClearRawDataBefore(ctx, cutoff, rawDataCleanupBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Cleared raw data of %d daily logs before %s", cleared, cutoff.Format("2006-01-02"))
+	return &dto.RawDataCleanupResponse{OlderThanDays: days, Cutoff: cutoff, Cleared: cleared}, nil
+}
+
+// PatchRepository 登録リポジトリの指定された項目だけを更新（項目が無ければ更新せずに現在の値を返す）
+func (repositoryUsecase *RepositoryUsecase) PatchRepository(ctx context.Context, userID, repoID uint64, req *dto.PatchRepositoryRequest) (*dto.RepositoryResponse, error) {
+	repo, err := repositoryUsecase.repoRepo.FindByID(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	if repo.UserID != userID {
+		return nil, ErrRepositoryNotOwned
+	}
+
+	fields := make(map[string]any)
+	if req.DisplayOrder != nil {
+		fields["display_order"] = *req.DisplayOrder
+	}
+
+	if len(fields) > 0 {
+		if err := repositoryUsecase.repoRepo.UpdateFields(ctx, repoID, fields); err != nil {
+			return nil, err
+		}
+		repo, err = repositoryUsecase.repoRepo.FindByID(ctx, repoID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return toRepositoryResponse(repo), nil
+}
+
+// toRepositoryResponse 登録リポジトリのモデルをレスポンスに変換
+func toRepositoryResponse(repo *models.UserRepository) *dto.RepositoryResponse {
+	return &dto.RepositoryResponse{
+		ID:           repo.ID,
+		UserID:       repo.UserID,
+		Owner:        repo.DisplayOwner,
+		Name:         repo.DisplayName,
+		IsPublic:     repo.IsPublic,
+		Branch:       repo.Branch,
+		CountMode:    repo.CountMode,
+		DisplayOrder: repo.DisplayOrder,
+		Active:       repo.DeactivatedAt == nil,
+		CreatedAt:    repo.CreatedAt,
+	}
+}
+
+// GetRepoStreak 登録リポジトリ単位の継続中・過去最長のstreakを取得
+// 登録リポジトリが存在しない場合は repository.ErrNotFound を返す
+func (repositoryUsecase *RepositoryUsecase) GetRepoStreak(ctx context.Context, repoID uint64) (*dto.RepoStreakResponse, error) {
+	repo, err := repositoryUsecase.repoRepo.FindByID(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	lengths, err := repositoryUsecase.repoStreakRepo.LengthsByUserRepoID(ctx, repo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.RepoStreakResponse{
+		RepositoryID:  repo.ID,
+		CurrentStreak: lengths.Current,
+		LongestStreak: lengths.Longest,
+	}
+	active, err := repositoryUsecase.repoStreakRepo.FindActiveByUserRepoID(ctx, repo.ID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if active != nil {
+		start := active.StartDate.Format("2006-01-02")
+		res.CurrentStreakStart = &start
+	}
+	return res, nil
+}
+
+// GetRecentCommits 保存した日次ログの RawData から最近のコミットを新しい順に最大 limit 件取得
+// 非公開リポジトリは登録したユーザー（userID）にだけ返す。RawData が無い・壊れている日は飛ばす
+// 登録リポジトリが存在しない場合は repository.ErrNotFound を返す
+func (repositoryUsecase *RepositoryUsecase) GetRecentCommits(ctx context.Context, userID, repoID uint64, limit int) (*dto.RecentCommitsResponse, error) {
+	repo, err := repositoryUsecase.repoRepo.FindByID(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	if !repo.IsPublic && repo.UserID != userID {
+		return nil, ErrRepositoryNotOwned
+	}
+
+	// コミットがある日は1日1件以上あるため、limit 日分あれば足りる
+	logs, err := repositoryUsecase.repoLogRepo.ListLatestActiveDaysByUserRepoID(ctx, repo.ID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := make([]dto.CommitDetail, 0, limit)
+	for _, commitLog := range logs {
+		if len(commitLog.RawData) == 0 {
+			continue
+		}
+		parsed, err := github.ParseCommits(commitLog.RawData)
+		if err != nil {
+			log.Printf("Skipping unreadable commits of repository %d on %s: %v", repo.ID, commitLog.CommitDate.Format("2006-01-02"), err)
+			continue
+		}
+		for _, commit := range parsed {
+			detail := dto.CommitDetail{
+				SHA:         commit.SHA,
+				Message:     commit.Commit.Message,
+				AuthorName:  commit.Commit.Author.Name,
+				CommittedAt: commit.Commit.Author.Date,
+			}
+			if commit.Author != nil {
+				login := commit.Author.Login
+				detail.AuthorLogin = &login
+			}
+			commits = append(commits, detail)
+		}
+	}
+
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].CommittedAt.After(commits[j].CommittedAt)
+	})
+	if len(commits) > limit {
+		commits = commits[:limit]
+	}
+	return &dto.RecentCommitsResponse{RepositoryID: repo.ID, Commits: commits}, nil
+}
+
+// DeleteRepository 登録リポジトリを物理削除する
+// cascade が true の場合はリポジトリ別日次ログも削除し、ユーザー単位の日次ログとstreakを作り直す（1トランザクション）。
+// cascade が false でログが残っている場合は ErrRepositoryHasLogs を返す
+func (repositoryUsecase *RepositoryUsecase) DeleteRepository(ctx context.Context, userID, repoID uint64, cascade bool) error {
+	repo, err := repositoryUsecase.repoRepo.FindByID(ctx, repoID)
+	if err != nil {
+		return err
+	}
+	if repo.UserID != userID {
+		return ErrRepositoryNotOwned
+	}
+
+	return withTransaction(ctx, repositoryUsecase.database, func(ctx context.Context, tx *gorm.DB) error {
+		repoLogRepo := repositoryUsecase.repoLogRepo.WithTx(tx)
+		dateRange, err := repoLogRepo.DateRangeByUserRepoID(ctx, repo.ID)
+		if err != nil {
+			return err
+		}
+		hasLogs := dateRange.First != nil
+		if hasLogs && !cascade {
+			return ErrRepositoryHasLogs
+		}
+
+		if err := repoLogRepo.DeleteByUserRepoID(ctx, repo.ID); err != nil {
+			return err
+		}
+		if err := repositoryUsecase.repoStreakRepo.WithTx(tx).ReplaceByUserRepoID(ctx, repo.ID, nil); err != nil {
+			return err
+		}
+		if err := repositoryUsecase.repoRepo.WithTx(tx).Delete(ctx, repo.ID); err != nil {
+			return err
+		}
+		if !hasLogs {
+			return nil
+		}
+
+		// 削除したログの期間だけ合算し直し、streakを再計算する
+		if err := repositoryUsecase.aggregationUsecase.WithTx(tx).RebuildRange(ctx, userID, *dateRange.First, *dateRange.Last); err != nil {
+			return err
+		}
+		return repositoryUsecase.streakUsecase.WithTx(tx).RecalculateStreaks(ctx, userID)
+	})
+}
@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/internal/githubtest"
+	"github.com/keeee21/commit-town/api/models"
+	"github.com/keeee21/commit-town/api/repository"
+)
+
+// 無効化の前の日次ログはそのまま残し、以降の同期と作り直しでは無効化したリポジトリを数えない
+func TestRepositoryUsecase_DeactivateRepository_KeepsPastRollups(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	user := env.createUser(t, "alice")
+	env.createRepo(t, user, "alice", "kept", commitsOn("alice", 3, 2)...)
+	deactivated := env.createRepo(t, user, "alice", "retired", commitsOn("alice", 3)...)
+
+	if _, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(7), time.Now(), false); err != nil {
+		t.Fatalf("first RunForUser returned an error: %v", err)
+	}
+	before := env.userTotals(t, user)
+	if before[dateOf(3)] != 2 || before[dateOf(2)] != 1 {
+		t.Fatalf("totals before deactivation = %v, want 2 on %s and 1 on %s", before, dateOf(3), dateOf(2))
+	}
+
+	if err := env.repository.DeactivateRepository(ctx, user.ID, deactivated.ID); err != nil {
+		t.Fatalf("DeactivateRepository returned an error: %v", err)
+	}
+	env.github.SetRepo("alice", "kept", githubtest.Repo{Commits: commitsOn("alice", 3, 2, 0)})
+	env.github.SetRepo("alice", "retired", githubtest.Repo{Commits: commitsOn("alice", 3, 0)})
+
+	if _, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(7), time.Now(), false); err != nil {
+		t.Fatalf("second RunForUser returned an error: %v", err)
+	}
+	if got := env.github.CommitRequests("alice", "retired"); got != 1 {
+		t.Errorf("deactivated repository was fetched %d times, want only the first sync", got)
+	}
+	after := env.userTotals(t, user)
+	want := map[string]int{dateOf(3): 2, dateOf(2): 1, dateOf(0): 1}
+	for date, total := range want {
+		if after[date] != total {
+			t.Errorf("total on %s after the next sync = %d, want %d", date, after[date], total)
+		}
+	}
+
+	if _, err := env.pipeline.RecomputeUser(ctx, user.ID); err != nil {
+		t.Fatalf("RecomputeUser returned an error: %v", err)
+	}
+	rebuilt := env.userTotals(t, user)
+	for date, total := range want {
+		if rebuilt[date] != total {
+			t.Errorf("total on %s after rebuilding = %d, want %d", date, rebuilt[date], total)
+		}
+	}
+}
+
+// 大文字小文字だけが違う登録は1行にまとまり、表示には最初に登録した表記を使う
+func TestRepositoryUsecase_BulkImport_CollapsesCasings(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	user := env.createUser(t, "alice")
+
+	res, err := env.repository.BulkImport(ctx, user.ID, &dto.BulkImportRepositoriesRequest{Repositories: []dto.RepositoryInput{
+		{Owner: "Alice", Name: "Town"},
+		{Owner: "alice", Name: "town"},
+		{Owner: "ALICE", Name: "TOWN"},
+	}})
+	if err != nil {
+		t.Fatalf("BulkImport returned an error: %v", err)
+	}
+	if res.Created != 1 || res.Skipped != 2 || res.Failed != 0 {
+		t.Errorf("created/skipped/failed = %d/%d/%d, want 1/2/0", res.Created, res.Skipped, res.Failed)
+	}
+	if got := env.countRows(t, &models.UserRepository{}); got != 1 {
+		t.Fatalf("user_repositories has %d rows, want 1", got)
+	}
+
+	repo, err := env.repoRepo.FindByUserAndName(ctx, user.ID, "aLiCe", "tOwN")
+	if err != nil {
+		t.Fatalf("FindByUserAndName returned an error: %v", err)
+	}
+	if repo.RepoOwner != "alice" || repo.RepoName != "town" {
+		t.Errorf("stored name = %s/%s, want alice/town", repo.RepoOwner, repo.RepoName)
+	}
+	if repo.DisplayOwner != "Alice" || repo.DisplayName != "Town" {
+		t.Errorf("display name = %s/%s, want Alice/Town", repo.DisplayOwner, repo.DisplayName)
+	}
+}
+
+func TestDefaultCountMode(t *testing.T) {
+	user := &models.User{GitHubUsername: "alice"}
+	tests := []struct {
+		owner string
+		want  string
+	}{
+		{"alice", models.CountModeAll},
+		{"Alice", models.CountModeAll},
+		{"town-org", models.CountModeAuthoredOnly},
+		{"bob", models.CountModeAuthoredOnly},
+	}
+	for _, tt := range tests {
+		if got := defaultCountMode(user, tt.owner); got != tt.want {
+			t.Errorf("defaultCountMode(%q) = %q, want %q", tt.owner, got, tt.want)
+		}
+	}
+}
+
+// Organization のリポジトリは本人が作者のコミットだけを日次ログに数え、本人のリポジトリは全てのコミットを数える
+func TestRepositoryUsecase_BulkImport_OrgRepositoryCountsOwnCommits(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	user := env.createUser(t, "alice")
+	env.github.SetRepo("town-org", "town", githubtest.Repo{Commits: append(append(commitsOn("alice", 2, 1), commitsOn("bob", 2, 1, 0)...), commitsOn("", 1)...)})
+	env.github.SetRepo("alice", "dotfiles", githubtest.Repo{Commits: append(commitsOn("alice", 1), commitsOn("bob", 1)...)})
+
+	res, err := env.repository.BulkImport(ctx, user.ID, &dto.BulkImportRepositoriesRequest{Repositories: []dto.RepositoryInput{
+		{Owner: "town-org", Name: "town"},
+		{Owner: "alice", Name: "dotfiles"},
+	}})
+	if err != nil {
+		t.Fatalf("BulkImport returned an error: %v", err)
+	}
+	if res.Created != 2 {
+		t.Fatalf("created = %d, want 2 (%+v)", res.Created, res.Results)
+	}
+	org, err := env.repoRepo.FindByUserAndName(ctx, user.ID, "town-org", "town")
+	if err != nil {
+		t.Fatalf("FindByUserAndName returned an error: %v", err)
+	}
+	if org.CountMode != models.CountModeAuthoredOnly {
+		t.Errorf("org repository CountMode = %q, want %q", org.CountMode, models.CountModeAuthoredOnly)
+	}
+
+	if _, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(7), time.Now(), false); err != nil {
+		t.Fatalf("RunForUser returned an error: %v", err)
+	}
+	totals := env.userTotals(t, user)
+	want := map[string]int{dateOf(2): 1, dateOf(1): 3}
+	for date, total := range want {
+		if totals[date] != total {
+			t.Errorf("total on %s = %d, want %d", date, totals[date], total)
+		}
+	}
+	if total, ok := totals[dateOf(0)]; ok && total != 0 {
+		t.Errorf("total on %s = %d, want bob's org commit left out", dateOf(0), total)
+	}
+}
+
+// 存在しないユーザーへの一括登録は500ではなく repository.ErrNotFound になる
+func TestRepositoryUsecase_BulkImport_MissingUser(t *testing.T) {
+	env := newTestEnv(t, testEnvConfig{})
+
+	_, err := env.repository.BulkImport(context.Background(), 999999999, &dto.BulkImportRepositoriesRequest{Repositories: []dto.RepositoryInput{
+		{Owner: "alice", Name: "town"},
+	}})
+	if !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("BulkImport error = %v, want %v", err, repository.ErrNotFound)
+	}
+	if got := env.countRows(t, &models.UserRepository{}); got != 0 {
+		t.Errorf("user_repositories has %d rows, want 0", got)
+	}
+}
+
+// 下限より短い保存日数は MinRawDataRetentionDays 日として扱い、その日数より前の RawData だけを消す
+func TestRepositoryUsecase_CleanupRawData_KeepsRecentAndCounts(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	user := env.createUser(t, "alice")
+	repo := env.createRepo(t, user, "alice", "town")
+	now := time.Now()
+	for _, d := range []int{MinRawDataRetentionDays + 5, MinRawDataRetentionDays + 1, MinRawDataRetentionDays, 1} {
+		commitLog := &models.RepoDailyCommitLog{UserRepoID: repo.ID, CommitDate: truncateToDate(now).AddDate(0, 0, -d), CommitCount: 2, RawData: []byte(`[{"sha":"abc"},{"sha":"def"}]`)}
+		if err := env.repoLogRepo.Upsert(ctx, commitLog); err != nil {
+			t.Fatalf("failed to store daily log: %v", err)
+		}
+	}
+
+	res, err := env.repository.CleanupRawData(ctx, 10, now)
+	if err != nil {
+		t.Fatalf("CleanupRawData returned an error: %v", err)
+	}
+	if res.OlderThanDays != MinRawDataRetentionDays || res.Cleared != 2 {
+		t.Errorf("older than/cleared = %d/%d, want %d/2", res.OlderThanDays, res.Cleared, MinRawDataRetentionDays)
+	}
+
+	logs, err := env.repoLogRepo.ListByUserRepoID(ctx, repo.ID, truncateToDate(now).AddDate(0, 0, -400), now)
+	if err != nil {
+		t.Fatalf("ListByUserRepoID returned an error: %v", err)
+	}
+	if len(logs) != 4 {
+		t.Fatalf("got %d daily logs, want 4", len(logs))
+	}
+	for _, commitLog := range logs {
+		if commitLog.CommitCount != 2 {
+			t.Errorf("%s: CommitCount = %d, want 2", commitLog.CommitDate.Format("2006-01-02"), commitLog.CommitCount)
+		}
+		if want := commitLog.CommitDate.Before(res.Cutoff); want != (len(commitLog.RawData) == 0) {
+			t.Errorf("%s: RawData = %s, want cleared only before %s", commitLog.CommitDate.Format("2006-01-02"), commitLog.RawData, res.Cutoff.Format("2006-01-02"))
+		}
+	}
+
+	again, err := env.repository.CleanupRawData(ctx, MinRawDataRetentionDays, now)
+	if err != nil {
+		t.Fatalf("second CleanupRawData returned an error: %v", err)
+	}
+	if again.Cleared != 0 {
+		t.Errorf("second run cleared %d, want 0", again.Cleared)
+	}
+}
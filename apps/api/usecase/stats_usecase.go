@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/keeee21/commit-town/api/db"
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/internal/cache"
+	"github.com/keeee21/commit-town/api/repository"
+	"gorm.io/gorm"
+)
+
+// platformStatsCacheKey サービス全体の集計は1件だけなので固定のキーでキャッシュする
+const platformStatsCacheKey = "platform"
+
+type StatsUsecase struct {
+	database    *gorm.DB
+	userRepo    *repository.UserRepository
+	repoRepo    *repository.RepoRepository
+	repoLogRepo *repository.RepoDailyCommitLogRepository
+	streakRepo  *repository.StreakRepository
+	statsCache  *cache.TTL[*dto.PlatformStatsResponse]
+}
+
+// NewStatsUsecase 集計結果は cacheTTL の間キャッシュする（0 はキャッシュしない）
+func NewStatsUsecase(database *gorm.DB, userRepo *repository.UserRepository, repoRepo *repository.RepoRepository, repoLogRepo *repository.RepoDailyCommitLogRepository, streakRepo *repository.StreakRepository, cacheTTL time.Duration) *StatsUsecase {
+	return &StatsUsecase{
+		database:    database,
+		userRepo:    userRepo,
+		repoRepo:    repoRepo,
+		repoLogRepo: repoLogRepo,
+		streakRepo:  streakRepo,
+		statsCache:  cache.NewTTL[*dto.PlatformStatsResponse](cacheTTL),
+	}
+}
+
+// GetPlatformStats ユーザー数・登録リポジトリ数・コミット数・最長の継続中streakを取得（キャッシュあり）
+// 論理削除されたユーザー・プロフィールを非公開にしたユーザーと、無効化されたリポジトリは含めない
+func (statsUsecase *StatsUsecase) GetPlatformStats(ctx context.Context) (*dto.PlatformStatsResponse, error) {
+	return statsUsecase.statsCache.Get(ctx, platformStatsCacheKey, statsUsecase.platformStats)
+}
+
+// platformStats キャッシュを通さずに集計する
+// 同期中の書き込みで数字が食い違わないよう、全ての集計を同じスナップショットから読む
+func (statsUsecase *StatsUsecase) platformStats(ctx context.Context) (*dto.PlatformStatsResponse, error) {
+	res := &dto.PlatformStatsResponse{}
+	err := db.WithReadOnlySnapshot(statsUsecase.database.WithContext(ctx), func(tx *gorm.DB) error {
+		var err error
+		if res.TotalUsers, err = statsUsecase.userRepo.WithTx(tx).CountPublic(ctx); err != nil {
+			return err
+		}
+		if res.TotalRepositories, err = statsUsecase.repoRepo.WithTx(tx).CountActive(ctx); err != nil {
+			return err
+		}
+		if res.TotalCommits, err = statsUsecase.repoLogRepo.WithTx(tx).SumActive(ctx); err != nil {
+			return err
+		}
+		res.LongestCurrentStreak, err = statsUsecase.streakRepo.WithTx(tx).MaxActiveLength(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
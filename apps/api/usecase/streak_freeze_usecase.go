@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/models"
+	"github.com/keeee21/commit-town/api/repository"
+)
+
+var (
+	// ErrStreakFreezeNotInFuture 凍結は明日以降の期間にしか予約できない
+	ErrStreakFreezeNotInFuture = errors.New("streak freeze must start in the future")
+	// ErrStreakFreezeOverlaps 予約済みの凍結期間と重なっている、または間を空けずに隣り合っている
+	ErrStreakFreezeOverlaps = errors.New("streak freeze overlaps or adjoins an existing one")
+	// ErrStreakFreezeStarted 始まった凍結は取り消せない（過去のstreakが変わってしまうため）
+	ErrStreakFreezeStarted = errors.New("streak freeze has already started")
+	// ErrStreakFreezeNotOwned 凍結期間が指定ユーザーのものではない
+	ErrStreakFreezeNotOwned = errors.New("streak freeze does not belong to the user")
+)
+
+type StreakFreezeUsecase struct {
+	userRepo   *repository.UserRepository
+	freezeRepo *repository.StreakFreezeRepository
+}
+
+func NewStreakFreezeUsecase(userRepo *repository.UserRepository, freezeRepo *repository.StreakFreezeRepository) *StreakFreezeUsecase {
+	return &StreakFreezeUsecase{userRepo: userRepo, freezeRepo: freezeRepo}
+}
+
+// ListFreezes ユーザーの凍結期間を開始日の古い順に取得
+// ユーザーが存在しない場合は repository.ErrNotFound を返す
+func (freezeUsecase *StreakFreezeUsecase) ListFreezes(ctx context.Context, userID uint64) (*dto.StreakFreezesResponse, error) {
+	if _, err := freezeUsecase.userRepo.FindByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	freezes, err := freezeUsecase.freezeRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.StreakFreezesResponse{Freezes: make([]dto.StreakFreezeResponse, 0, len(freezes))}
+	for i := range freezes {
+		res.Freezes = append(res.Freezes, toStreakFreezeResponse(&freezes[i]))
+	}
+	return res, nil
+}
+
+// ScheduleFreeze start〜end（両端を含む、日付のみ）の凍結を予約する（日数の上限は呼び出し側で確認する）
+// 開始日はユーザーのタイムゾーンでの明日以降でなければならず、予約済みの凍結期間とは重ねることも、
+// 間を空けずに続けることもできない（続けて予約して日数の上限を超えないようにする）
+// ユーザーが存在しない場合は repository.ErrNotFound を返す
+func (freezeUsecase *StreakFreezeUsecase) ScheduleFreeze(ctx context.Context, userID uint64, start, end, now time.Time) (*dto.StreakFreezeResponse, error) {
+	user, err := freezeUsecase.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !start.After(localDate(user, now)) {
+		return nil, ErrStreakFreezeNotInFuture
+	}
+
+	overlaps, err := freezeUsecase.freezeRepo.ExistsOverlappingOrAdjacent(ctx, userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if overlaps {
+		return nil, ErrStreakFreezeOverlaps
+	}
+
+	freeze := &models.StreakFreeze{UserID: userID, StartDate: start, EndDate: end}
+	if err := freezeUsecase.freezeRepo.Create(ctx, freeze); err != nil {
+		return nil, err
+	}
+	res := toStreakFreezeResponse(freeze)
+	return &res, nil
+}
+
+// CancelFreeze まだ始まっていない凍結を取り消す
+// 凍結期間が存在しない場合は repository.ErrNotFound、userID のものでない場合は ErrStreakFreezeNotOwned、
+// 既に始まっている場合は ErrStreakFreezeStarted を返す
+func (freezeUsecase *StreakFreezeUsecase) CancelFreeze(ctx context.Context, userID, freezeID uint64, now time.Time) error {
+	freeze, err := freezeUsecase.freezeRepo.FindByID(ctx, freezeID)
+	if err != nil {
+		return err
+	}
+	if freeze.UserID != userID {
+		return ErrStreakFreezeNotOwned
+	}
+
+	user, err := freezeUsecase.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !freeze.StartDate.After(localDate(user, now)) {
+		return ErrStreakFreezeStarted
+	}
+	return freezeUsecase.freezeRepo.Delete(ctx, freezeID)
+}
+
+func toStreakFreezeResponse(freeze *models.StreakFreeze) dto.StreakFreezeResponse {
+	return dto.StreakFreezeResponse{
+		ID:        freeze.ID,
+		StartDate: freeze.StartDate.UTC().Format("2006-01-02"),
+		EndDate:   freeze.EndDate.UTC().Format("2006-01-02"),
+	}
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/keeee21/commit-town/api/models"
+)
+
+// 凍結は明日以降にだけ予約でき、始まった凍結は取り消せない
+func TestStreakFreezeUsecase_ScheduleAndCancel(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	freezeUsecase := NewStreakFreezeUsecase(env.userRepo, env.freezeRepo)
+	user := env.createUser(t, "alice")
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC) }
+
+	scheduled, err := freezeUsecase.ScheduleFreeze(ctx, user.ID, day(12), day(14), now)
+	if err != nil {
+		t.Fatalf("ScheduleFreeze returned an error: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		start, end int
+		want       error
+	}{
+		{"starts today", 10, 11, ErrStreakFreezeNotInFuture},
+		{"starts in the past", 8, 12, ErrStreakFreezeNotInFuture},
+		{"overlaps the scheduled freeze", 14, 16, ErrStreakFreezeOverlaps},
+		{"inside the scheduled freeze", 13, 13, ErrStreakFreezeOverlaps},
+		{"starts the day after the scheduled freeze", 15, 20, ErrStreakFreezeOverlaps},
+		{"ends the day before the scheduled freeze", 11, 11, ErrStreakFreezeOverlaps},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := freezeUsecase.ScheduleFreeze(ctx, user.ID, day(tt.start), day(tt.end), now); !errors.Is(err, tt.want) {
+				t.Errorf("ScheduleFreeze error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// 1日でも空ければ予約できる
+	gapped, err := freezeUsecase.ScheduleFreeze(ctx, user.ID, day(16), day(18), now)
+	if err != nil {
+		t.Fatalf("ScheduleFreeze after a one-day gap returned an error: %v", err)
+	}
+	if err := freezeUsecase.CancelFreeze(ctx, user.ID, gapped.ID, now); err != nil {
+		t.Fatalf("CancelFreeze returned an error: %v", err)
+	}
+
+	// 始まった後は取り消せず、始まる前なら取り消せる
+	if err := freezeUsecase.CancelFreeze(ctx, user.ID, scheduled.ID, day(12).Add(time.Hour)); !errors.Is(err, ErrStreakFreezeStarted) {
+		t.Errorf("CancelFreeze after it started = %v, want %v", err, ErrStreakFreezeStarted)
+	}
+	other := env.createUser(t, "bob")
+	if err := freezeUsecase.CancelFreeze(ctx, other.ID, scheduled.ID, now); !errors.Is(err, ErrStreakFreezeNotOwned) {
+		t.Errorf("CancelFreeze by another user = %v, want %v", err, ErrStreakFreezeNotOwned)
+	}
+	if err := freezeUsecase.CancelFreeze(ctx, user.ID, scheduled.ID, now); err != nil {
+		t.Errorf("CancelFreeze returned an error: %v", err)
+	}
+	if got := env.countRows(t, &models.StreakFreeze{}); got != 0 {
+		t.Errorf("streak_freezes has %d rows after cancelling, want 0", got)
+	}
+}
+
+// 休みを凍結で埋めたstreakは途切れずに続く
+func TestStreakUsecase_RecalculateStreaks_FrozenGap(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	user := env.createUser(t, "alice")
+	env.createRepo(t, user, "alice", "town", commitsOn("alice", 0, 1, 5, 6)...)
+
+	// 2〜4日前を凍結する（予約の検証を通さずに過去の凍結を作る）
+	freeze := &models.StreakFreeze{UserID: user.ID, StartDate: truncateToDate(daysAgo(4)), EndDate: truncateToDate(daysAgo(2))}
+	if err := env.freezeRepo.Create(ctx, freeze); err != nil {
+		t.Fatalf("failed to create freeze: %v", err)
+	}
+
+	if _, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(7), time.Now(), false); err != nil {
+		t.Fatalf("RunForUser returned an error: %v", err)
+	}
+	current, err := env.streakRepo.FindActiveByUserID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("FindActiveByUserID returned an error: %v", err)
+	}
+	if current.Length != 4 || current.StartDate.Format("2006-01-02") != dateOf(6) {
+		t.Errorf("current streak = %d days from %s, want 4 days from %s", current.Length, current.StartDate.Format("2006-01-02"), dateOf(6))
+	}
+}
@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/events"
+	"github.com/keeee21/commit-town/api/internal/streak"
+	"github.com/keeee21/commit-town/api/models"
+	"github.com/keeee21/commit-town/api/repository"
+	"gorm.io/gorm"
+)
+
+type StreakUsecase struct {
+	userRepo       *repository.UserRepository
+	userLogRepo    *repository.UserDailyCommitLogRepository
+	streakRepo     *repository.StreakRepository
+	repoLogRepo    *repository.RepoDailyCommitLogRepository
+	repoStreakRepo *repository.RepoStreakRepository
+	freezeRepo     *repository.StreakFreezeRepository
+	bus            *events.Bus
+	graceDays      int // streakを途切れさせない休みの日数（0 は1日でも休むと途切れる）
+	minCommits     int // streakに数える1日のコミット数の下限（ユーザーが設定していない場合）
+}
+
+func NewStreakUsecase(userRepo *repository.UserRepository, userLogRepo *repository.UserDailyCommitLogRepository, streakRepo *repository.StreakRepository, repoLogRepo *repository.RepoDailyCommitLogRepository, repoStreakRepo *repository.RepoStreakRepository, freezeRepo *repository.StreakFreezeRepository, bus *events.Bus, graceDays int, minCommitsPerDay int) *StreakUsecase {
+	if graceDays < 0 {
+		graceDays = 0
+	}
+	return &StreakUsecase{
+		userRepo:       userRepo,
+		userLogRepo:    userLogRepo,
+		streakRepo:     streakRepo,
+		repoLogRepo:    repoLogRepo,
+		repoStreakRepo: repoStreakRepo,
+		freezeRepo:     freezeRepo,
+		bus:            bus,
+		graceDays:      graceDays,
+		minCommits:     max(minCommitsPerDay, 1),
+	}
+}
+
+// WithTx トランザクション内で動作するユースケースを返す
+func (streakUsecase *StreakUsecase) WithTx(tx *gorm.DB) *StreakUsecase {
+	return &StreakUsecase{
+		userRepo:       streakUsecase.userRepo.WithTx(tx),
+		userLogRepo:    streakUsecase.userLogRepo.WithTx(tx),
+		streakRepo:     streakUsecase.streakRepo.WithTx(tx),
+		repoLogRepo:    streakUsecase.repoLogRepo.WithTx(tx),
+		repoStreakRepo: streakUsecase.repoStreakRepo.WithTx(tx),
+		freezeRepo:     streakUsecase.freezeRepo.WithTx(tx),
+		bus:            streakUsecase.bus,
+		graceDays:      streakUsecase.graceDays,
+		minCommits:     streakUsecase.minCommits,
+	}
+}
+
+// RecalculateStreaks ユーザーの日次ログからstreak履歴を全件計算し直す
+// 最後の連続期間が今日または昨日まで続いていれば継続中（EndDateなし）とする
+// graceDays 日以下の休みは連続とみなすが、休んだ日は Length に数えない
+// 凍結期間（StreakFreeze）の休みは途切れにも猶予にも数えない
+// コミット数がユーザーの MinCommitsPerDay に満たない日は休みとして扱う
+// 継続中のstreakの変化は StreakStarted / StreakExtended / StreakBroken イベントとして発行する
+func (streakUsecase *StreakUsecase) RecalculateStreaks(ctx context.Context, userID uint64) error {
+	minCommits := streakUsecase.minCommits
+	user, err := streakUsecase.userRepo.FindByID(ctx, userID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if err == nil {
+		minCommits = minCommitsPerDay(user, streakUsecase.minCommits)
+	}
+
+	previous, err := streakUsecase.streakRepo.FindActiveByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+
+	logs, err := streakUsecase.userLogRepo.ListActiveDays(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	freezes, err := streakUsecase.freezeRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	streaks := computeStreaks(userID, logs, truncateToDate(time.Now()), streakUsecase.graceDays, minCommits, toFreezes(freezes))
+	if err := streakUsecase.streakRepo.ReplaceByUserID(ctx, userID, streaks); err != nil {
+		return err
+	}
+
+	var current *models.UserStreak
+	if n := len(streaks); n > 0 && streaks[n-1].Active {
+		current = &streaks[n-1]
+	}
+	for _, event := range streakChanges(userID, previous, current) {
+		streakUsecase.bus.Publish(ctx, event)
+	}
+	return nil
+}
+
+// streakChanges 再計算前後の継続中streakを比べて発行するイベントを決める
+func streakChanges(userID uint64, previous, current *models.UserStreak) []events.Event {
+	var changes []events.Event
+	sameStreak := previous != nil && current != nil && previous.StartDate.Equal(current.StartDate)
+	if previous != nil && !sameStreak {
+		changes = append(changes, events.StreakEvent{Type: events.StreakBroken, UserID: userID, StartDate: previous.StartDate, Length: previous.Length})
+	}
+	switch {
+	case current != nil && !sameStreak:
+		changes = append(changes, events.StreakEvent{Type: events.StreakStarted, UserID: userID, StartDate: current.StartDate, Length: current.Length})
+	case sameStreak && current.Length > previous.Length:
+		changes = append(changes, events.StreakEvent{Type: events.StreakExtended, UserID: userID, StartDate: current.StartDate, Length: current.Length, PreviousLength: previous.Length})
+	}
+	return changes
+}
+
+// RecalculateRepoStreaks 登録リポジトリの日次ログからリポジトリ単位のstreak履歴を全件計算し直す
+// 継続中の判定・猶予日数はユーザー単位と同じ（MinCommitsPerDay は使わず、1件でもコミットがあれば数える）
+func (streakUsecase *StreakUsecase) RecalculateRepoStreaks(ctx context.Context, userRepoID uint64) error {
+	logs, err := streakUsecase.repoLogRepo.ListActiveDaysByUserRepoID(ctx, userRepoID)
+	if err != nil {
+		return err
+	}
+
+	days := make([]streak.DayCount, 0, len(logs))
+	for _, commitLog := range logs {
+		days = append(days, streak.DayCount{Date: commitLog.CommitDate, Count: commitLog.CommitCount})
+	}
+
+	runs := streak.ComputeRuns(days, streakUsecase.graceDays)
+	today := truncateToDate(time.Now())
+	streaks := make([]models.RepoStreak, 0, len(runs))
+	for i, run := range runs {
+		repoStreak := models.RepoStreak{
+			UserRepoID: userRepoID,
+			StartDate:  run.Start,
+			Length:     run.Length,
+		}
+		if i == len(runs)-1 && run.ActiveOn(today, streakUsecase.graceDays) {
+			repoStreak.Active = true
+		} else {
+			end := run.End
+			repoStreak.EndDate = &end
+		}
+		streaks = append(streaks, repoStreak)
+	}
+	return streakUsecase.repoStreakRepo.ReplaceByUserRepoID(ctx, userRepoID, streaks)
+}
+
+// computeStreaks 日付昇順のコミット日から連続期間を組み立てる（コミット数が minCommits 未満の日は含めない）
+func computeStreaks(userID uint64, logs []models.UserDailyCommitLog, today time.Time, graceDays int, minCommits int, freezes []streak.Freeze) []models.UserStreak {
+	days := make([]streak.DayCount, 0, len(logs))
+	for _, commitLog := range logs {
+		if commitLog.TotalCommits < minCommits {
+			continue
+		}
+		days = append(days, streak.DayCount{Date: commitLog.Date, Count: commitLog.TotalCommits})
+	}
+
+	runs := streak.ComputeRunsWithFreezes(days, graceDays, freezes)
+	streaks := make([]models.UserStreak, 0, len(runs))
+	for i, run := range runs {
+		userStreak := models.UserStreak{
+			UserID:    userID,
+			StartDate: run.Start,
+			Length:    run.Length,
+			Active:    false,
+		}
+		if i == len(runs)-1 && run.ActiveOnWithFreezes(today, graceDays, freezes) {
+			userStreak.Active = true
+		} else {
+			end := run.End
+			userStreak.EndDate = &end
+		}
+		streaks = append(streaks, userStreak)
+	}
+	return streaks
+}
+
+// toFreezes 凍結期間を streak の計算用に変換する
+func toFreezes(freezes []models.StreakFreeze) []streak.Freeze {
+	res := make([]streak.Freeze, 0, len(freezes))
+	for _, freeze := range freezes {
+		res = append(res, streak.Freeze{Start: truncateToDate(freeze.StartDate), End: truncateToDate(freeze.EndDate)})
+	}
+	return res
+}
+
+// minCommitsPerDay ユーザーが設定したstreakに数える1日のコミット数の下限（未設定なら def）
+func minCommitsPerDay(user *models.User, def int) int {
+	if user.MinCommitsPerDay != nil && *user.MinCommitsPerDay > 0 {
+		return *user.MinCommitsPerDay
+	}
+	return def
+}
+
+func toStreakLevel(level streak.Level) dto.StreakLevel {
+	return dto.StreakLevel{Code: level.Code, Name: level.Name}
+}
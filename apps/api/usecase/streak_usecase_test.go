@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/keeee21/commit-town/api/models"
+)
+
+// streakSummary テストで比べるstreakの項目（日付は5月の日、継続中の場合 end は0）
+type streakSummary struct {
+	start, end, length int
+	active             bool
+}
+
+func summarizeStreaks(streaks []models.UserStreak) []streakSummary {
+	res := make([]streakSummary, 0, len(streaks))
+	for _, s := range streaks {
+		summary := streakSummary{start: s.StartDate.Day(), length: s.Length, active: s.Active}
+		if s.EndDate != nil {
+			summary.end = s.EndDate.Day()
+		}
+		res = append(res, summary)
+	}
+	return res
+}
+
+func TestComputeStreaks_GracePeriod(t *testing.T) {
+	today := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	logsOn := func(days ...int) []models.UserDailyCommitLog {
+		logs := make([]models.UserDailyCommitLog, 0, len(days))
+		for _, d := range days {
+			logs = append(logs, models.UserDailyCommitLog{Date: time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC), TotalCommits: 1})
+		}
+		return logs
+	}
+
+	tests := []struct {
+		name      string
+		days      []int
+		graceDays int
+		want      []streakSummary
+	}{
+		{"grace 0 consecutive", []int{8, 9, 10}, 0, []streakSummary{{8, 0, 3, true}}},
+		{"grace 0 single gap breaks", []int{6, 7, 9, 10}, 0, []streakSummary{{6, 7, 2, false}, {9, 0, 2, true}}},
+		{"grace 1 single gap continues without counting it", []int{6, 7, 9, 10}, 1, []streakSummary{{6, 0, 4, true}}},
+		{"grace 1 two-day gap breaks", []int{5, 6, 9, 10}, 1, []streakSummary{{5, 6, 2, false}, {9, 0, 2, true}}},
+		{"grace 0 missed yesterday ends the streak", []int{7, 8}, 0, []streakSummary{{7, 8, 2, false}}},
+		{"grace 1 missed yesterday keeps it active", []int{7, 8}, 1, []streakSummary{{7, 0, 2, true}}},
+		{"grace 1 missed two days ends the streak", []int{6, 7}, 1, []streakSummary{{6, 7, 2, false}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := summarizeStreaks(computeStreaks(1, logsOn(tt.days...), today, tt.graceDays, 1, nil))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("computeStreaks = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// コミット数が minCommits ちょうどの日は数え、1件でも足りない日は休みとして扱う
+func TestComputeStreaks_MinCommits(t *testing.T) {
+	today := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	logsWith := func(counts map[int]int) []models.UserDailyCommitLog {
+		logs := make([]models.UserDailyCommitLog, 0, len(counts))
+		for d := 1; d <= 10; d++ {
+			if count, ok := counts[d]; ok {
+				logs = append(logs, models.UserDailyCommitLog{Date: time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC), TotalCommits: count})
+			}
+		}
+		return logs
+	}
+
+	tests := []struct {
+		name       string
+		counts     map[int]int
+		minCommits int
+		want       []streakSummary
+	}{
+		{"default counts a single commit", map[int]int{9: 1, 10: 1}, 1, []streakSummary{{9, 0, 2, true}}},
+		{"exactly at the threshold", map[int]int{8: 3, 9: 3, 10: 3}, 3, []streakSummary{{8, 0, 3, true}}},
+		{"just below breaks the streak", map[int]int{8: 3, 9: 2, 10: 3}, 3, []streakSummary{{8, 8, 1, false}, {10, 0, 1, true}}},
+		{"below on every day", map[int]int{8: 2, 9: 2, 10: 2}, 3, []streakSummary{}},
+		{"just below today ends at yesterday", map[int]int{8: 5, 9: 4, 10: 3}, 4, []streakSummary{{8, 0, 2, true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := summarizeStreaks(computeStreaks(1, logsWith(tt.counts), today, 0, tt.minCommits, nil))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("computeStreaks = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMinCommitsPerDay(t *testing.T) {
+	tests := []struct {
+		name string
+		user *int
+		want int
+	}{
+		{"not set", nil, 2},
+		{"reset to the default", intPtr(0), 2},
+		{"own bar", intPtr(5), 5},
+		{"lower than the default", intPtr(1), 1},
+	}
+	for _, tt := range tests {
+		if got := minCommitsPerDay(&models.User{MinCommitsPerDay: tt.user}, 2); got != tt.want {
+			t.Errorf("%s: minCommitsPerDay = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+// 凍結した日は休みにも継続にも数えず、空白の一部だけを凍結した場合は残りの日で途切れる
+func TestComputeStreaks_Freezes(t *testing.T) {
+	today := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC) }
+	logsOn := func(days ...int) []models.UserDailyCommitLog {
+		logs := make([]models.UserDailyCommitLog, 0, len(days))
+		for _, d := range days {
+			logs = append(logs, models.UserDailyCommitLog{Date: day(d), TotalCommits: 1})
+		}
+		return logs
+	}
+	freeze := func(start, end int) models.StreakFreeze {
+		return models.StreakFreeze{StartDate: day(start), EndDate: day(end)}
+	}
+
+	tests := []struct {
+		name      string
+		days      []int
+		graceDays int
+		freezes   []models.StreakFreeze
+		want      []streakSummary
+	}{
+		{"gap fully covered by a freeze", []int{1, 2, 6, 7, 8, 9, 10}, 0, []models.StreakFreeze{freeze(3, 5)}, []streakSummary{{1, 0, 7, true}}},
+		{"gap partly covered by a freeze", []int{1, 2, 6, 7, 8, 9, 10}, 0, []models.StreakFreeze{freeze(3, 4)}, []streakSummary{{1, 2, 2, false}, {6, 0, 5, true}}},
+		{"partly covered gap within grace", []int{1, 2, 6, 7, 8, 9, 10}, 1, []models.StreakFreeze{freeze(3, 4)}, []streakSummary{{1, 0, 7, true}}},
+		{"gap covered by two freezes", []int{1, 2, 6, 7, 8, 9, 10}, 0, []models.StreakFreeze{freeze(3, 3), freeze(4, 5)}, []streakSummary{{1, 0, 7, true}}},
+		{"freeze through today keeps it active", []int{5, 6, 7}, 0, []models.StreakFreeze{freeze(8, 12)}, []streakSummary{{5, 0, 3, true}}},
+		{"missed a day after the freeze", []int{5, 6, 7}, 0, []models.StreakFreeze{freeze(8, 8)}, []streakSummary{{5, 7, 3, false}}},
+		{"commits on frozen days still count", []int{1, 2, 3}, 0, []models.StreakFreeze{freeze(2, 2)}, []streakSummary{{1, 3, 3, false}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := summarizeStreaks(computeStreaks(1, logsOn(tt.days...), today, tt.graceDays, 1, toFreezes(tt.freezes)))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("computeStreaks = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// リポジトリ単位のstreakは、そのリポジトリの日次ログだけから計算する
+func TestStreakUsecase_RecalculateRepoStreaks(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	user := env.createUser(t, "alice")
+	repo := env.createRepo(t, user, "alice", "town", commitsOn("alice", 0, 1, 2, 10, 11)...)
+	env.createRepo(t, user, "alice", "other", commitsOn("alice", 3, 4, 5, 6, 7, 8, 9)...)
+
+	if _, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(14), time.Now(), false); err != nil {
+		t.Fatalf("RunForUser returned an error: %v", err)
+	}
+	if err := env.streak.RecalculateRepoStreaks(ctx, repo.ID); err != nil {
+		t.Fatalf("RecalculateRepoStreaks returned an error: %v", err)
+	}
+
+	var streaks []models.RepoStreak
+	if err := env.db.Where("user_repo_id = ?", repo.ID).Order("start_date").Find(&streaks).Error; err != nil {
+		t.Fatalf("failed to list repository streaks: %v", err)
+	}
+	if len(streaks) != 2 {
+		t.Fatalf("got %d repository streaks, want 2: %+v", len(streaks), streaks)
+	}
+	ended, current := streaks[0], streaks[1]
+	if ended.Length != 2 || ended.Active || ended.EndDate == nil || ended.EndDate.Format("2006-01-02") != dateOf(10) {
+		t.Errorf("ended streak = %+v, want 2 days ending on %s", ended, dateOf(10))
+	}
+	if current.Length != 3 || !current.Active || current.EndDate != nil || current.StartDate.Format("2006-01-02") != dateOf(2) {
+		t.Errorf("current streak = %+v, want an active 3-day streak from %s", current, dateOf(2))
+	}
+
+	// ユーザー単位では2つのリポジトリを合わせて12日続いている
+	active, err := env.streakRepo.FindActiveByUserID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("failed to find the user's active streak: %v", err)
+	}
+	if active.Length != 12 {
+		t.Errorf("user streak length = %d, want 12", active.Length)
+	}
+}
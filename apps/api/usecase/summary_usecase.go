@@ -0,0 +1,260 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/models"
+	"github.com/keeee21/commit-town/api/pagination"
+	"github.com/keeee21/commit-town/api/repository"
+)
+
+// recentDays 直近コミット数を集計する日数（今日を含む）
+const recentDays = 7
+
+type SummaryUsecase struct {
+	userRepo    *repository.UserRepository
+	repoRepo    *repository.RepoRepository
+	userLogRepo *repository.UserDailyCommitLogRepository
+	streakRepo  *repository.StreakRepository
+	minCommits  int // streakに数える1日のコミット数の下限（ユーザーが設定していない場合）
+}
+
+func NewSummaryUsecase(userRepo *repository.UserRepository, repoRepo *repository.RepoRepository, userLogRepo *repository.UserDailyCommitLogRepository, streakRepo *repository.StreakRepository, minCommitsPerDay int) *SummaryUsecase {
+	return &SummaryUsecase{
+		userRepo:    userRepo,
+		repoRepo:    repoRepo,
+		userLogRepo: userLogRepo,
+		streakRepo:  streakRepo,
+		minCommits:  max(minCommitsPerDay, 1),
+	}
+}
+
+// GetSummary ユーザーのプロフィール・streak・コミット数・有効リポジトリ数をまとめて取得
+// ユーザーが存在しない場合のみ repository.ErrNotFound を返し、活動データが無い場合は0を返す
+func (summaryUsecase *SummaryUsecase) GetSummary(ctx context.Context, userID uint64) (*dto.UserSummaryResponse, error) {
+	user, err := summaryUsecase.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	streaks, err := summaryUsecase.streakRepo.LengthsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	recentSince := truncateToDate(time.Now()).AddDate(0, 0, -(recentDays - 1))
+	totals, err := summaryUsecase.userLogRepo.TotalsByUserID(ctx, userID, recentSince)
+	if err != nil {
+		return nil, err
+	}
+
+	activeRepos, err := summaryUsecase.repoRepo.CountActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	first, last, err := summaryUsecase.userLogRepo.ActivityBounds(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.UserSummaryResponse{
+		User:               *toUserResponse(user),
+		CurrentStreak:      streaks.Current,
+		LongestStreak:      streaks.Longest,
+		TotalCommits:       totals.All,
+		CommitsLast7Days:   totals.Recent,
+		ActiveRepositories: activeRepos,
+		FirstCommitDate:    formatDatePtr(first),
+		LastCommitDate:     formatDatePtr(last),
+		SuggestedTimezone:  toSuggestedTimezone(user),
+	}, nil
+}
+
+// GetSummaries 複数ユーザーのサマリーをまとめて取得（一覧画面の先読み用）
+// テーブルごとに IN でまとめて1クエリずつ読み、存在しないIDはエラーにせず結果から除く
+// まとめた読み込みが失敗した場合は1人ずつ読み直し、それでも失敗したユーザーだけを failed にして failed にその原因を返す
+// ユーザー自体を読めない場合のみ error を返す
+func (summaryUsecase *SummaryUsecase) GetSummaries(ctx context.Context, userIDs []uint64) (res *dto.UserSummariesResponse, failed map[uint64]error, err error) {
+	users, err := summaryUsecase.userRepo.FindByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res = &dto.UserSummariesResponse{Summaries: make(map[uint64]dto.UserSummaryResult, len(users))}
+	failed = make(map[uint64]error)
+	if len(users) == 0 {
+		return res, failed, nil
+	}
+
+	ids := make([]uint64, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+
+	streaks := lookupEach(ctx, ids, failed, summaryUsecase.streakRepo.LengthsByUserIDs)
+	recentSince := truncateToDate(time.Now()).AddDate(0, 0, -(recentDays - 1))
+	activity := lookupEach(ctx, ids, failed, func(ctx context.Context, ids []uint64) (map[uint64]repository.UserActivity, error) {
+		return summaryUsecase.userLogRepo.ActivityByUserIDs(ctx, ids, recentSince)
+	})
+	activeRepos := lookupEach(ctx, ids, failed, summaryUsecase.repoRepo.CountActiveByUserIDs)
+
+	for i := range users {
+		user := &users[i]
+		if _, ok := failed[user.ID]; ok {
+			res.Summaries[user.ID] = dto.UserSummaryResult{Status: dto.SummaryStatusFailed, Error: "Failed to get user summary"}
+			res.Failed++
+			continue
+		}
+		summary := dto.UserSummaryResponse{
+			User:               *toUserResponse(user),
+			CurrentStreak:      streaks[user.ID].Current,
+			LongestStreak:      streaks[user.ID].Longest,
+			TotalCommits:       activity[user.ID].All,
+			CommitsLast7Days:   activity[user.ID].Recent,
+			ActiveRepositories: activeRepos[user.ID],
+			FirstCommitDate:    formatDatePtr(activity[user.ID].First),
+			LastCommitDate:     formatDatePtr(activity[user.ID].Last),
+			SuggestedTimezone:  toSuggestedTimezone(user),
+		}
+		res.Summaries[user.ID] = dto.UserSummaryResult{Status: dto.SummaryStatusOK, UserSummaryResponse: &summary}
+	}
+	return res, failed, nil
+}
+
+// lookupEach batch で ids の全員分をまとめて読む。失敗した場合は1人ずつ読み直し、
+// それでも読めなかったユーザーを failed に記録する（failed に記録済みのユーザーは読まない）
+func lookupEach[T any](ctx context.Context, ids []uint64, failed map[uint64]error, batch func(context.Context, []uint64) (map[uint64]T, error)) map[uint64]T {
+	values, err := batch(ctx, ids)
+	if err == nil {
+		return values
+	}
+
+	values = make(map[uint64]T, len(ids))
+	for _, id := range ids {
+		if _, ok := failed[id]; ok {
+			continue
+		}
+		one, err := batch(ctx, []uint64{id})
+		if err != nil {
+			failed[id] = err
+			continue
+		}
+		if value, ok := one[id]; ok {
+			values[id] = value
+		}
+	}
+	return values
+}
+
+// GetToday ユーザーのローカル日付で今日のコミット状況を取得（ウィジェットのポーリング用の軽量版）
+// 今日のログや継続中のstreakが無ければ0を返す
+// committed_today はコミット数がユーザーの MinCommitsPerDay 以上の場合のみ true（streakの判定と同じ）
+func (summaryUsecase *SummaryUsecase) GetToday(ctx context.Context, userID uint64) (*dto.TodayStatusResponse, error) {
+	user, err := summaryUsecase.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := localDate(user, time.Now())
+	res := &dto.TodayStatusResponse{
+		Date:             today.Format("2006-01-02"),
+		MinCommitsPerDay: minCommitsPerDay(user, summaryUsecase.minCommits),
+	}
+
+	commitLog, err := summaryUsecase.userLogRepo.FindByUserIDAndDate(ctx, userID, today)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		res.CommitsToday = commitLog.TotalCommits
+		res.CommittedToday = commitLog.TotalCommits >= res.MinCommitsPerDay
+	}
+
+	streak, err := summaryUsecase.streakRepo.FindActiveByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		res.StreakLength = streak.Length
+	}
+
+	return res, nil
+}
+
+// GetStreakHistory ユーザーの過去を含む全streakを params の並び順で取得
+// ユーザーが存在しない場合は repository.ErrNotFound を返す
+func (summaryUsecase *SummaryUsecase) GetStreakHistory(ctx context.Context, userID uint64, params pagination.ListParams) (*dto.StreakHistoryResponse, error) {
+	if _, err := summaryUsecase.userRepo.FindByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	order := repository.StreakOrder{ByLength: params.Sort == dto.StreakHistorySortLength, Desc: params.Desc()}
+	streaks, err := summaryUsecase.streakRepo.ListByUserID(ctx, userID, order, params.Limit, params.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := summaryUsecase.streakRepo.CountByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.StreakHistoryResponse{
+		UserID:  userID,
+		Sort:    params.Sort,
+		Order:   params.Order,
+		Limit:   params.Limit,
+		Offset:  params.Offset,
+		Total:   total,
+		Streaks: make([]dto.StreakHistoryEntry, 0, len(streaks)),
+	}
+	for _, streak := range streaks {
+		res.Streaks = append(res.Streaks, dto.StreakHistoryEntry{
+			StartDate: streak.StartDate.UTC().Format("2006-01-02"),
+			EndDate:   formatDatePtr(streak.EndDate),
+			Length:    streak.Length,
+			Active:    streak.Active,
+		})
+	}
+	return res, nil
+}
+
+// formatDatePtr 日付を YYYY-MM-DD に変換（nil の場合は nil）
+func formatDatePtr(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format("2006-01-02")
+	return &s
+}
+
+// toSuggestedTimezone 推定したUTCオフセットをタイムゾーンの候補に変換する（未推定は nil）
+// 1時間単位のオフセットは Etc/GMT±N（符号が逆になるのはIANAの決まり）、UTCと同じ場合は UTC にする
+func toSuggestedTimezone(user *models.User) *dto.SuggestedTimezone {
+	if user.InferredUTCOffset == nil {
+		return nil
+	}
+	offset := *user.InferredUTCOffset
+
+	sign, abs := '+', offset
+	if offset < 0 {
+		sign, abs = '-', -offset
+	}
+	res := &dto.SuggestedTimezone{UTCOffset: fmt.Sprintf("%c%02d:%02d", sign, abs/60, abs%60)}
+
+	hours := offset / 60
+	switch {
+	case offset == 0:
+		name := "UTC"
+		res.Timezone = &name
+	case offset%60 == 0 && hours >= -12 && hours <= 14:
+		name := fmt.Sprintf("Etc/GMT%+d", -hours)
+		res.Timezone = &name
+	}
+	return res
+}
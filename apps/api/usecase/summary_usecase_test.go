@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/models"
+)
+
+func TestToSuggestedTimezone(t *testing.T) {
+	tests := []struct {
+		offset       *int
+		wantOffset   string
+		wantTimezone string // 空の場合は Timezone が nil
+	}{
+		{intPtr(540), "+09:00", "Etc/GMT-9"},
+		{intPtr(-300), "-05:00", "Etc/GMT+5"},
+		{intPtr(0), "+00:00", "UTC"},
+		{intPtr(330), "+05:30", ""},
+		{intPtr(840), "+14:00", "Etc/GMT-14"},
+		{intPtr(-720), "-12:00", "Etc/GMT+12"},
+		{intPtr(-780), "-13:00", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wantOffset, func(t *testing.T) {
+			got := toSuggestedTimezone(&models.User{InferredUTCOffset: tt.offset})
+			if got == nil {
+				t.Fatal("toSuggestedTimezone returned nil")
+			}
+			if got.UTCOffset != tt.wantOffset {
+				t.Errorf("UTCOffset = %q, want %q", got.UTCOffset, tt.wantOffset)
+			}
+			var timezone string
+			if got.Timezone != nil {
+				timezone = *got.Timezone
+			}
+			if timezone != tt.wantTimezone {
+				t.Errorf("Timezone = %q, want %q", timezone, tt.wantTimezone)
+			}
+		})
+	}
+
+	if got := toSuggestedTimezone(&models.User{}); got != nil {
+		t.Errorf("toSuggestedTimezone without an inferred offset = %+v, want nil", got)
+	}
+}
+
+// 今日の判定はユーザーの MinCommitsPerDay ちょうどで true、下回ると false。カレンダーは下限にかかわらずコミット数をそのまま返す
+func TestSummaryUsecase_GetToday_MinCommitsPerDay(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{minCommitsPerDay: 3})
+	user := env.createUser(t, "alice")
+	env.createRepo(t, user, "alice", "town", commitsOn("alice", 1, 1, 0, 0, 0)...)
+	if _, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(7), time.Now(), false); err != nil {
+		t.Fatalf("RunForUser returned an error: %v", err)
+	}
+	summary := NewSummaryUsecase(env.userRepo, env.repoRepo, env.userLogRepo, env.streakRepo, 3)
+	calendar := NewCalendarUsecase(env.userRepo, env.userLogRepo, env.repoRepo, env.repoLogRepo)
+
+	steps := []struct {
+		name          string
+		userMin       *int // PatchUser で設定する min_commits_per_day（nil は変えない）
+		wantMin       int
+		wantCommitted bool
+		wantStreak    int
+	}{
+		{"server default: exactly at", nil, 3, true, 1},
+		{"own bar: just below", intPtr(4), 4, false, 0},
+		{"own bar: yesterday now counts", intPtr(2), 2, true, 2},
+		{"reset to the default", intPtr(0), 3, true, 1},
+	}
+	for _, step := range steps {
+		if step.userMin != nil {
+			if _, err := env.user.PatchUser(ctx, user.ID, &dto.PatchUserRequest{MinCommitsPerDay: step.userMin}); err != nil {
+				t.Fatalf("%s: PatchUser returned an error: %v", step.name, err)
+			}
+		}
+		today, err := summary.GetToday(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("%s: GetToday returned an error: %v", step.name, err)
+		}
+		if today.CommitsToday != 3 || today.MinCommitsPerDay != step.wantMin || today.CommittedToday != step.wantCommitted || today.StreakLength != step.wantStreak {
+			t.Errorf("%s: today = %+v, want 3 commits, min %d, committed %v and a %d-day streak", step.name, today, step.wantMin, step.wantCommitted, step.wantStreak)
+		}
+
+		res, err := calendar.GetCalendar(ctx, user.ID, truncateToDate(daysAgo(7)), truncateToDate(time.Now()))
+		if err != nil {
+			t.Fatalf("%s: GetCalendar returned an error: %v", step.name, err)
+		}
+		days := make(map[string]int, len(res.Days))
+		for _, day := range res.Days {
+			days[day.Date] = day.TotalCommits
+		}
+		if len(days) != 2 || days[dateOf(1)] != 2 || days[dateOf(0)] != 3 {
+			t.Errorf("%s: calendar = %v, want 2 on %s and 3 on %s", step.name, days, dateOf(1), dateOf(0))
+		}
+	}
+}
+
+// まとめた読み込みが失敗した場合は1人ずつ読み直し、読めないユーザーだけを failed にして残りのユーザーの値を返す
+func TestLookupEach(t *testing.T) {
+	errBroken := errors.New("broken row")
+	tests := []struct {
+		name       string
+		broken     []uint64 // このユーザーを含む読み込みは失敗する
+		failed     []uint64 // 前の読み込みで失敗済み
+		want       map[uint64]int
+		wantFailed []uint64
+		wantCalls  int
+	}{
+		{"batch succeeds", nil, nil, map[uint64]int{1: 10, 2: 20, 3: 30}, nil, 1},
+		{"one user fails", []uint64{2}, nil, map[uint64]int{1: 10, 3: 30}, []uint64{2}, 4},
+		{"every user fails", []uint64{1, 2, 3}, nil, map[uint64]int{}, []uint64{1, 2, 3}, 4},
+		{"already failed user is not read again", []uint64{2, 3}, []uint64{2}, map[uint64]int{1: 10}, []uint64{2, 3}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failed := make(map[uint64]error)
+			for _, id := range tt.failed {
+				failed[id] = errBroken
+			}
+			calls := 0
+			batch := func(ctx context.Context, ids []uint64) (map[uint64]int, error) {
+				calls++
+				values := make(map[uint64]int, len(ids))
+				for _, id := range ids {
+					if slices.Contains(tt.broken, id) {
+						return nil, errBroken
+					}
+					values[id] = int(id) * 10
+				}
+				return values, nil
+			}
+
+			got := lookupEach(context.Background(), []uint64{1, 2, 3}, failed, batch)
+			if len(got) != len(tt.want) {
+				t.Errorf("values = %v, want %v", got, tt.want)
+			}
+			for id, want := range tt.want {
+				if got[id] != want {
+					t.Errorf("value of user %d = %d, want %d", id, got[id], want)
+				}
+			}
+			gotFailed := make([]uint64, 0, len(failed))
+			for id, err := range failed {
+				if !errors.Is(err, errBroken) {
+					t.Errorf("failed[%d] = %v, want %v", id, err, errBroken)
+				}
+				gotFailed = append(gotFailed, id)
+			}
+			slices.Sort(gotFailed)
+			if !slices.Equal(gotFailed, tt.wantFailed) && len(gotFailed)+len(tt.wantFailed) > 0 {
+				t.Errorf("failed users = %v, want %v", gotFailed, tt.wantFailed)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("batch was called %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// 読めたユーザーは ok で返し、存在しないIDは結果に含めない
+func TestSummaryUsecase_GetSummaries_PerUserStatus(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	alice := env.createUser(t, "alice")
+	bob := env.createUser(t, "bob")
+	env.createRepo(t, alice, "alice", "town", commitsOn("alice", 1, 0)...)
+	if _, err := env.pipeline.RunForUser(ctx, alice.ID, daysAgo(7), time.Now(), false); err != nil {
+		t.Fatalf("RunForUser returned an error: %v", err)
+	}
+	summary := NewSummaryUsecase(env.userRepo, env.repoRepo, env.userLogRepo, env.streakRepo, 1)
+
+	res, failed, err := summary.GetSummaries(ctx, []uint64{alice.ID, bob.ID, 999999999})
+	if err != nil {
+		t.Fatalf("GetSummaries returned an error: %v", err)
+	}
+	if len(failed) != 0 || res.Failed != 0 {
+		t.Errorf("failed = %v (%d), want none", failed, res.Failed)
+	}
+	if len(res.Summaries) != 2 {
+		t.Fatalf("got %d summaries, want alice and bob only", len(res.Summaries))
+	}
+	for _, user := range []*models.User{alice, bob} {
+		result := res.Summaries[user.ID]
+		if result.Status != dto.SummaryStatusOK || result.UserSummaryResponse == nil || result.Error != "" {
+			t.Errorf("%s = %+v, want an ok summary", user.GitHubUsername, result)
+		}
+	}
+	if got := res.Summaries[alice.ID].TotalCommits; got != 2 {
+		t.Errorf("alice TotalCommits = %d, want 2", got)
+	}
+	if got := res.Summaries[alice.ID].CurrentStreak; got != 2 {
+		t.Errorf("alice CurrentStreak = %d, want 2", got)
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
@@ -0,0 +1,303 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/keeee21/commit-town/api/events"
+	"github.com/keeee21/commit-town/api/internal/github"
+	"github.com/keeee21/commit-town/api/models"
+	"github.com/keeee21/commit-town/api/repository"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ErrRepositoryNoAccess GitHubから登録リポジトリを読み取れない（非公開になった・削除された等）
+var ErrRepositoryNoAccess = errors.New("repository is not accessible on GitHub")
+
+// timezoneInferenceDays タイムゾーンの推定に使う直近の日数（夏時間の切り替えをまたいでも最近の傾向が勝つように短めにする）
+const timezoneInferenceDays = 90
+
+type SyncUsecase struct {
+	githubClient    *github.Client
+	userRepo        *repository.UserRepository
+	repoRepo        *repository.RepoRepository
+	repoLogRepo     *repository.RepoDailyCommitLogRepository
+	bus             *events.Bus
+	initialSyncDays int  // 初回の同期で取り込む日数（今日を含む）
+	inferTimezone   bool // 同期のたびにコミットの作者日時からユーザーのUTCオフセットを推定するか
+}
+
+func NewSyncUsecase(githubClient *github.Client, userRepo *repository.UserRepository, repoRepo *repository.RepoRepository, repoLogRepo *repository.RepoDailyCommitLogRepository, bus *events.Bus, initialSyncDays int, inferTimezone bool) *SyncUsecase {
+	if initialSyncDays < 1 {
+		initialSyncDays = 1
+	}
+	return &SyncUsecase{
+		githubClient:    githubClient,
+		userRepo:        userRepo,
+		repoRepo:        repoRepo,
+		repoLogRepo:     repoLogRepo,
+		bus:             bus,
+		initialSyncDays: initialSyncDays,
+		inferTimezone:   inferTimezone,
+	}
+}
+
+// WithTx トランザクション内で動作するユースケースを返す
+func (syncUsecase *SyncUsecase) WithTx(tx *gorm.DB) *SyncUsecase {
+	return &SyncUsecase{
+		githubClient:    syncUsecase.githubClient,
+		userRepo:        syncUsecase.userRepo.WithTx(tx),
+		repoRepo:        syncUsecase.repoRepo.WithTx(tx),
+		repoLogRepo:     syncUsecase.repoLogRepo.WithTx(tx),
+		bus:             syncUsecase.bus,
+		initialSyncDays: syncUsecase.initialSyncDays,
+		inferTimezone:   syncUsecase.inferTimezone,
+	}
+}
+
+// SyncRepository GitHubから前回の同期以降のコミットを取得し、リポジトリ別日次ログに保存する
+// 保存した日数を返す
+func (syncUsecase *SyncUsecase) SyncRepository(ctx context.Context, repo *models.UserRepository, since, until time.Time) (int, error) {
+	since, ok := syncUsecase.SyncWindow(repo, since, until)
+	if !ok {
+		return 0, nil
+	}
+	days, err := syncUsecase.FetchRepository(ctx, repo, since, until)
+	if err != nil {
+		return 0, err
+	}
+	return syncUsecase.StoreRepository(ctx, repo, days, until)
+}
+
+// SyncWindow 同期済みの時点（LastSyncedAt）をもとに、since〜until のうち実際にGitHubから取得する期間の開始日を返す
+// 同期済みの日は途中までしか取り込めていない可能性があるため取り直し、それより前の日は取得しない。
+// until まで同期済みで取得するものが無い場合は false を返す
+// 未同期のリポジトリは since にかかわらず initialSyncDays 日分を取り込む
+func (syncUsecase *SyncUsecase) SyncWindow(repo *models.UserRepository, since, until time.Time) (time.Time, bool) {
+	since, until = truncateToDate(since), truncateToDate(until)
+	if repo.LastSyncedAt == nil {
+		initial := until.AddDate(0, 0, -(syncUsecase.initialSyncDays - 1))
+		if initial.Before(since) {
+			return initial, true
+		}
+		return since, true
+	}
+
+	synced := truncateToDate(*repo.LastSyncedAt)
+	if synced.After(until) {
+		return time.Time{}, false
+	}
+	if synced.After(since) {
+		return synced, true
+	}
+	return since, true
+}
+
+// RefreshAccess GitHubからリポジトリの現在の公開設定を取得し、登録リポジトリの IsPublic・AccessStatus を合わせる
+// 読み取れない場合は AccessStatusNoAccess にして ErrRepositoryNoAccess を返す（呼び出し側はこのリポジトリの同期を飛ばす）
+// dryRun の場合は repo だけを書き換えてDBには書き込まない
+func (syncUsecase *SyncUsecase) RefreshAccess(ctx context.Context, repo *models.UserRepository, dryRun bool) error {
+	isPublic, status := repo.IsPublic, models.AccessStatusOK
+	ghRepo, err := syncUsecase.githubClient.GetRepo(ctx, repo.RepoOwner, repo.RepoName)
+	switch {
+	case errors.Is(err, github.ErrRepoNotFound):
+		status = models.AccessStatusNoAccess
+	case err != nil:
+		return err
+	default:
+		isPublic = !ghRepo.Private
+	}
+
+	if isPublic != repo.IsPublic || status != repo.AccessStatus {
+		log.Printf("Repository %s/%s is now public=%t access=%s", repo.RepoOwner, repo.RepoName, isPublic, status)
+		repo.IsPublic, repo.AccessStatus = isPublic, status
+		if !dryRun {
+			err := syncUsecase.repoRepo.UpdateFields(ctx, repo.ID, map[string]any{"is_public": isPublic, "access_status": status})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if status == models.AccessStatusNoAccess {
+		return ErrRepositoryNoAccess
+	}
+	return nil
+}
+
+// FetchRepository GitHubから期間内のコミットを日付ごとに取得する（DBには書き込まない）
+// 登録したブランチが削除されている場合はデフォルトブランチから取得し直す
+// コミットはリポジトリの CountMode に従って数える
+// タイムゾーンを推定する場合は、GraphQL API で作者日時のオフセットを RawData に書き足す（取得できなくても同期は続ける）
+func (syncUsecase *SyncUsecase) FetchRepository(ctx context.Context, repo *models.UserRepository, since, until time.Time) ([]github.DayCommits, error) {
+	days, err := syncUsecase.fetchCommits(ctx, repo, since, until)
+	if err != nil || !syncUsecase.inferTimezone {
+		return days, err
+	}
+	if err := syncUsecase.githubClient.AnnotateAuthorOffsets(ctx, repo.RepoOwner, repo.RepoName, days); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.Printf("Failed to read author date offsets of %s/%s; the timezone inference skips these commits: %v", repo.RepoOwner, repo.RepoName, err)
+	}
+	return days, nil
+}
+
+// fetchCommits CountMode のフィルターを付けて、登録したブランチ（無ければデフォルトブランチ）のコミットを取得する
+func (syncUsecase *SyncUsecase) fetchCommits(ctx context.Context, repo *models.UserRepository, since, until time.Time) ([]github.DayCommits, error) {
+	filter, err := syncUsecase.commitFilter(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if repo.Branch == nil || *repo.Branch == "" {
+		return syncUsecase.githubClient.FetchCommitsByDay(ctx, repo.RepoOwner, repo.RepoName, "", since, until, filter)
+	}
+
+	days, err := syncUsecase.githubClient.FetchCommitsByDay(ctx, repo.RepoOwner, repo.RepoName, *repo.Branch, since, until, filter)
+	if errors.Is(err, github.ErrBranchNotFound) {
+		log.Printf("Branch %s of %s/%s was not found; falling back to the default branch", *repo.Branch, repo.RepoOwner, repo.RepoName)
+		return syncUsecase.githubClient.FetchCommitsByDay(ctx, repo.RepoOwner, repo.RepoName, "", since, until, filter)
+	}
+	return days, err
+}
+
+// commitFilter リポジトリの CountMode に応じたフィルター（all の場合は nil）
+func (syncUsecase *SyncUsecase) commitFilter(ctx context.Context, repo *models.UserRepository) (github.CommitFilter, error) {
+	switch repo.CountMode {
+	case models.CountModeNoMerges:
+		return func(commit *github.Commit) bool {
+			return !commit.IsMerge()
+		}, nil
+	case models.CountModeAuthoredOnly:
+		user, err := syncUsecase.userRepo.FindByID(ctx, repo.UserID)
+		if err != nil {
+			return nil, err
+		}
+		login := user.GitHubUsername
+		return func(commit *github.Commit) bool {
+			return commit.AuthoredBy(login)
+		}, nil
+	}
+	return nil, nil
+}
+
+// StoreRepository 取得済みの日別コミットをリポジトリ別日次ログに保存し（一意インデックスで冪等）、until までを同期済みとする
+// 未来の日付のコミット（時計のずれや不正なレスポンス）は streak やカレンダーを狂わせるため保存しない
+func (syncUsecase *SyncUsecase) StoreRepository(ctx context.Context, repo *models.UserRepository, days []github.DayCommits, until time.Time) (int, error) {
+	user, err := syncUsecase.userRepo.FindByID(ctx, repo.UserID)
+	if err != nil {
+		return 0, err
+	}
+	latest := latestCommitDate(user, time.Now())
+
+	stored := 0
+	for _, day := range days {
+		if day.Date.After(latest) {
+			log.Printf("Skipping future-dated commits for %s/%s on %s (%d commits)", repo.RepoOwner, repo.RepoName, day.Date.Format("2006-01-02"), day.Count)
+			continue
+		}
+		commitLog := &models.RepoDailyCommitLog{
+			UserRepoID:  repo.ID,
+			CommitDate:  day.Date,
+			CommitCount: day.Count,
+			RawData:     datatypes.JSON(day.RawData),
+		}
+		if err := syncUsecase.repoLogRepo.Upsert(ctx, commitLog); err != nil {
+			return 0, err
+		}
+		stored++
+	}
+
+	syncedAt := time.Now()
+	if until.Before(syncedAt) {
+		syncedAt = until
+	}
+	if err := syncUsecase.repoRepo.AdvanceLastSyncedAt(ctx, repo.ID, syncedAt); err != nil {
+		return 0, err
+	}
+
+	syncUsecase.bus.Publish(ctx, events.RepositorySyncedEvent{
+		UserID:     repo.UserID,
+		UserRepoID: repo.ID,
+		RepoOwner:  repo.RepoOwner,
+		RepoName:   repo.RepoName,
+		Days:       stored,
+	})
+	return stored, nil
+}
+
+// latestCommitDate 保存を認める最新のコミット日
+// コミット日はUTCの日付で集計しているため、UTCの今日とユーザーのタイムゾーンでの今日のうち遅い方までを認める
+func latestCommitDate(user *models.User, now time.Time) time.Time {
+	utcToday := truncateToDate(now)
+	localToday := localDate(user, now)
+	if localToday.After(utcToday) {
+		return localToday
+	}
+	return utcToday
+}
+
+// InferTimezone 直近 timezoneInferenceDays 日に保存したコミットを作者日時のUTCオフセットごとに数え、
+// 最も多いオフセットを User.InferredUTCOffset に保存する。宣言されたタイムゾーン（Timezone）は変えない
+// 数えるのは日次ログに保存したコミット（CountMode で除いたものは含まない）のうち、オフセットを取得できたもの。読めない RawData の日は飛ばす。
+// 該当するコミットが無ければ前回の推定を残す。日次ログの日付はオフセットにかかわらずUTCのまま
+func (syncUsecase *SyncUsecase) InferTimezone(ctx context.Context, userID uint64, until time.Time) error {
+	repos, err := syncUsecase.repoRepo.ListActiveByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	until = truncateToDate(until)
+	since := until.AddDate(0, 0, -(timezoneInferenceDays - 1))
+	counts := make(map[int]int)
+	for _, repo := range repos {
+		logs, err := syncUsecase.repoLogRepo.ListByUserRepoID(ctx, repo.ID, since, until)
+		if err != nil {
+			return err
+		}
+		for _, commitLog := range logs {
+			if len(commitLog.RawData) == 0 {
+				continue
+			}
+			commits, err := github.ParseCommits(commitLog.RawData)
+			if err != nil {
+				log.Printf("Skipping unreadable commits of repository %d on %s: %v", repo.ID, commitLog.CommitDate.Format("2006-01-02"), err)
+				continue
+			}
+			for i := range commits {
+				if offset, ok := commits[i].AuthorUTCOffset(); ok {
+					counts[offset]++
+				}
+			}
+		}
+	}
+
+	offset, ok := mostCommonOffset(counts)
+	if !ok {
+		return nil
+	}
+	// 変わっていなければ書き込まない（同期のたびに User.Version を上げないため）
+	user, err := syncUsecase.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.InferredUTCOffset != nil && *user.InferredUTCOffset == offset {
+		return nil
+	}
+	return syncUsecase.userRepo.UpdateFields(ctx, userID, map[string]any{"inferred_utc_offset": offset})
+}
+
+// mostCommonOffset コミット数が最も多いUTCオフセット（分）。同数の場合は小さい方にして結果を安定させる
+func mostCommonOffset(counts map[int]int) (int, bool) {
+	best, bestCount := 0, 0
+	for offset, count := range counts {
+		if count > bestCount || count == bestCount && offset < best {
+			best, bestCount = offset, count
+		}
+	}
+	return best, bestCount > 0
+}
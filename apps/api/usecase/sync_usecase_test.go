@@ -0,0 +1,265 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/keeee21/commit-town/api/internal/github"
+	"github.com/keeee21/commit-town/api/internal/githubtest"
+	"github.com/keeee21/commit-town/api/models"
+	"gorm.io/datatypes"
+)
+
+func TestLatestCommitDate(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name     string
+		timezone string
+		now      time.Time
+		want     time.Time
+	}{
+		{"utc user", "UTC", time.Date(2024, 5, 10, 23, 0, 0, 0, time.UTC), day(10)},
+		{"ahead of utc after local midnight", "Asia/Tokyo", time.Date(2024, 5, 10, 16, 0, 0, 0, time.UTC), day(11)},
+		{"ahead of utc before local midnight", "Asia/Tokyo", time.Date(2024, 5, 10, 14, 0, 0, 0, time.UTC), day(10)},
+		{"behind utc keeps the utc date", "America/New_York", time.Date(2024, 5, 10, 2, 0, 0, 0, time.UTC), day(10)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := latestCommitDate(&models.User{Timezone: tt.timezone}, tt.now)
+			if !got.Equal(tt.want) {
+				t.Errorf("latestCommitDate = %s, want %s", got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestSyncUsecase_CommitFilter_NoMerges(t *testing.T) {
+	commits, err := github.ParseCommits([]byte(`[
+		{"sha": "merge", "parents": [{"sha": "a"}, {"sha": "b"}]},
+		{"sha": "single", "parents": [{"sha": "a"}]},
+		{"sha": "root", "parents": []}
+	]`))
+	if err != nil {
+		t.Fatalf("failed to parse commits: %v", err)
+	}
+	syncUsecase := &SyncUsecase{}
+
+	all, err := syncUsecase.commitFilter(context.Background(), &models.UserRepository{CountMode: models.CountModeAll})
+	if err != nil || all != nil {
+		t.Errorf("commitFilter(all) = %v, %v, want no filter", all != nil, err)
+	}
+
+	noMerges, err := syncUsecase.commitFilter(context.Background(), &models.UserRepository{CountMode: models.CountModeNoMerges})
+	if err != nil {
+		t.Fatalf("commitFilter(no_merges) returned an error: %v", err)
+	}
+	want := map[string]bool{"merge": false, "single": true, "root": true}
+	for i := range commits {
+		if got := noMerges(&commits[i]); got != want[commits[i].SHA] {
+			t.Errorf("no_merges counts %s = %v, want %v", commits[i].SHA, got, want[commits[i].SHA])
+		}
+	}
+}
+
+// 未来の日付のコミットはリポジトリ別日次ログにもユーザー単位の日次ログにも入らない
+func TestSyncUsecase_StoreRepository_SkipsFutureCommits(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	user := env.createUser(t, "alice")
+	repo := env.createRepo(t, user, "alice", "town")
+	env.github.SetRepo("alice", "town", githubtest.Repo{Commits: commitsOn("alice", 1, -1, -3), IgnoreUntil: true})
+
+	if _, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(7), time.Now(), false); err != nil {
+		t.Fatalf("RunForUser returned an error: %v", err)
+	}
+
+	logs, err := env.repoLogRepo.ListByUserRepoID(ctx, repo.ID, daysAgo(30), daysAgo(-30))
+	if err != nil {
+		t.Fatalf("failed to list repository logs: %v", err)
+	}
+	if len(logs) != 1 || logs[0].CommitDate.Format("2006-01-02") != dateOf(1) {
+		t.Errorf("repository logs = %v, want only %s", logs, dateOf(1))
+	}
+	totals := env.userTotals(t, user)
+	for _, future := range []int{-1, -3} {
+		if _, ok := totals[dateOf(future)]; ok {
+			t.Errorf("user rollup has the future date %s", dateOf(future))
+		}
+	}
+	if totals[dateOf(1)] != 1 {
+		t.Errorf("total on %s = %d, want 1", dateOf(1), totals[dateOf(1)])
+	}
+}
+
+func TestSyncUsecase_SyncWindow(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC) }
+	at := func(d, hour int) *time.Time {
+		t := day(d).Add(time.Duration(hour) * time.Hour)
+		return &t
+	}
+	syncUsecase := &SyncUsecase{initialSyncDays: 5}
+
+	tests := []struct {
+		name         string
+		lastSyncedAt *time.Time
+		since, until time.Time
+		want         time.Time
+		wantOK       bool
+	}{
+		{"first sync backfills initial days", nil, day(9), day(10), day(6), true},
+		{"first sync keeps an earlier since", nil, day(1), day(10), day(1), true},
+		{"refetches the partly synced day", at(8, 15), day(3), day(10), day(8), true},
+		{"synced before since", at(1, 15), day(3), day(10), day(3), true},
+		{"synced today", at(10, 9), day(3), day(10), day(10), true},
+		{"synced past until", at(10, 9), day(3), day(9), time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := syncUsecase.SyncWindow(&models.UserRepository{LastSyncedAt: tt.lastSyncedAt}, tt.since, tt.until)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("SyncWindow = (%s, %v), want (%s, %v)", got.Format("2006-01-02"), ok, tt.want.Format("2006-01-02"), tt.wantOK)
+			}
+		})
+	}
+}
+
+// 同期のたびにGitHubの公開設定を IsPublic に反映し、読み取れないリポジトリは no_access にして飛ばす
+func TestSyncUsecase_RefreshAccess_TracksVisibility(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	user := env.createUser(t, "alice")
+	repo := env.createRepo(t, user, "alice", "town", commitsOn("alice", 1)...)
+
+	steps := []struct {
+		name       string
+		github     *githubtest.Repo // nil の場合はGitHubから消す（404）
+		dryRun     bool
+		wantPublic bool
+		wantAccess string
+		wantFetch  bool
+	}{
+		{"public", &githubtest.Repo{Commits: commitsOn("alice", 1)}, false, true, models.AccessStatusOK, true},
+		{"dry run keeps the stored flag", &githubtest.Repo{Private: true, Commits: commitsOn("alice", 1)}, true, true, models.AccessStatusOK, true},
+		{"flipped to private", &githubtest.Repo{Private: true, Commits: commitsOn("alice", 1, 0)}, false, false, models.AccessStatusOK, true},
+		{"no longer readable", nil, false, false, models.AccessStatusNoAccess, false},
+		{"readable and public again", &githubtest.Repo{Commits: commitsOn("alice", 1, 0)}, false, true, models.AccessStatusOK, true},
+	}
+	for _, step := range steps {
+		if step.github == nil {
+			env.github.RemoveRepo("alice", "town")
+		} else {
+			env.github.SetRepo("alice", "town", *step.github)
+		}
+		fetches := env.github.CommitRequests("alice", "town")
+
+		if _, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(7), time.Now(), step.dryRun); err != nil {
+			t.Fatalf("%s: RunForUser returned an error: %v", step.name, err)
+		}
+		reloaded, err := env.repoRepo.FindByID(ctx, repo.ID)
+		if err != nil {
+			t.Fatalf("%s: failed to reload repository: %v", step.name, err)
+		}
+		if reloaded.IsPublic != step.wantPublic || reloaded.AccessStatus != step.wantAccess {
+			t.Errorf("%s: IsPublic/AccessStatus = %t/%s, want %t/%s", step.name, reloaded.IsPublic, reloaded.AccessStatus, step.wantPublic, step.wantAccess)
+		}
+		if fetched := env.github.CommitRequests("alice", "town") > fetches; fetched != step.wantFetch {
+			t.Errorf("%s: fetched commits = %t, want %t", step.name, fetched, step.wantFetch)
+		}
+	}
+}
+
+func TestMostCommonOffset(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts map[int]int
+		want   int
+		wantOK bool
+	}{
+		{"no commits", map[int]int{}, 0, false},
+		{"single offset", map[int]int{540: 3}, 540, true},
+		{"most commits wins", map[int]int{540: 3, -300: 5, 0: 1}, -300, true},
+		{"tie picks the smaller offset", map[int]int{540: 2, -300: 2, 0: 2}, -300, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := mostCommonOffset(tt.counts)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("mostCommonOffset(%v) = (%d, %v), want (%d, %v)", tt.counts, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// 作者日時のオフセットは GraphQL API から読み、最も多いオフセットを保存する。日次ログの日付はUTCのまま
+func TestSyncUsecase_InferTimezone_UsesAuthorDateOffsets(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{inferTimezone: true})
+	user := env.createUser(t, "alice")
+	inZone := func(minutes int, commits []githubtest.Commit) []githubtest.Commit {
+		for i := range commits {
+			commits[i].Date = commits[i].Date.In(time.FixedZone("", minutes*60))
+		}
+		return commits
+	}
+	env.createRepo(t, user, "alice", "tokyo", inZone(540, commitsOn("alice", 3, 2, 1))...)
+	env.createRepo(t, user, "alice", "newyork", inZone(-300, commitsOn("alice", 2, 1))...)
+	env.createRepo(t, user, "alice", "utc", commitsOn("alice", 1)...)
+	// GraphQL API で読めないリポジトリのコミットは推定に数えない（REST API のUTCを0として数えない）
+	env.createRepo(t, user, "alice", "unreadable")
+	env.github.SetRepo("alice", "unreadable", githubtest.Repo{Commits: commitsOn("alice", 3, 3, 2, 2, 1, 1), FailGraphQL: true})
+
+	if _, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(7), time.Now(), false); err != nil {
+		t.Fatalf("RunForUser returned an error: %v", err)
+	}
+	stored, err := env.userRepo.FindByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("FindByID returned an error: %v", err)
+	}
+	if stored.InferredUTCOffset == nil || *stored.InferredUTCOffset != 540 {
+		t.Errorf("InferredUTCOffset = %v, want 540", stored.InferredUTCOffset)
+	}
+	if stored.Timezone != "UTC" {
+		t.Errorf("Timezone = %q, want the declared UTC to stay", stored.Timezone)
+	}
+
+	totals := env.userTotals(t, user)
+	want := map[string]int{dateOf(3): 3, dateOf(2): 4, dateOf(1): 5}
+	for date, total := range want {
+		if totals[date] != total {
+			t.Errorf("total on %s = %d, want %d", date, totals[date], total)
+		}
+	}
+}
+
+// 読めない RawData の日は推定で飛ばし、同期は失敗させない
+func TestSyncUsecase_InferTimezone_SkipsUnreadableRawData(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{inferTimezone: true})
+	user := env.createUser(t, "alice")
+	commits := commitsOn("alice", 2, 1)
+	for i := range commits {
+		commits[i].Date = commits[i].Date.In(time.FixedZone("", 540*60))
+	}
+	repo := env.createRepo(t, user, "alice", "town", commits...)
+	// 同期の期間より前の、配列でない RawData（推定の期間には入る）
+	broken := &models.RepoDailyCommitLog{UserRepoID: repo.ID, CommitDate: truncateToDate(daysAgo(20)), CommitCount: 1, RawData: datatypes.JSON(`{"sha":1}`)}
+	if err := env.repoLogRepo.Upsert(ctx, broken); err != nil {
+		t.Fatalf("failed to store daily log: %v", err)
+	}
+
+	if _, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(7), time.Now(), false); err != nil {
+		t.Fatalf("RunForUser returned an error: %v", err)
+	}
+	stored, err := env.userRepo.FindByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("FindByID returned an error: %v", err)
+	}
+	if stored.InferredUTCOffset == nil || *stored.InferredUTCOffset != 540 {
+		t.Errorf("InferredUTCOffset = %v, want 540", stored.InferredUTCOffset)
+	}
+	totals := env.userTotals(t, user)
+	if totals[dateOf(2)] != 1 || totals[dateOf(1)] != 1 {
+		t.Errorf("totals = %v, want 1 commit on %s and %s", totals, dateOf(2), dateOf(1))
+	}
+}
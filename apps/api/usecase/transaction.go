@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/keeee21/commit-town/api/db"
+	"github.com/keeee21/commit-town/api/events"
+	"gorm.io/gorm"
+)
+
+// withTransaction db.WithTransaction と同じく fn をトランザクション内で実行する
+// fn の中で発行したイベントはコミットした後に配信し、ロールバックした場合（ドライランを含む）は配信しない。
+// fn には Publish がイベントを溜めるコンテキストを渡すので、fn の中ではこの ctx を使うこと
+func withTransaction(ctx context.Context, database *gorm.DB, fn func(ctx context.Context, tx *gorm.DB) error) error {
+	txCtx, pending := events.Defer(ctx)
+	err := db.WithTransaction(database.WithContext(txCtx), func(tx *gorm.DB) error {
+		return fn(txCtx, tx)
+	})
+	if err != nil {
+		return err
+	}
+	pending.Flush(ctx)
+	return nil
+}
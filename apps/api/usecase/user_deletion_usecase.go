@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/repository"
+	"gorm.io/gorm"
+)
+
+type UserDeletionUsecase struct {
+	database           *gorm.DB
+	userRepo           *repository.UserRepository
+	repoRepo           *repository.RepoRepository
+	repoLogRepo        *repository.RepoDailyCommitLogRepository
+	repoStreakRepo     *repository.RepoStreakRepository
+	userLogRepo        *repository.UserDailyCommitLogRepository
+	streakRepo         *repository.StreakRepository
+	achievementRepo    *repository.AchievementRepository
+	goalRepo           *repository.GoalRepository
+	freezeRepo         *repository.StreakFreezeRepository
+	apiKeyRepo         *repository.APIKeyRepository
+	leaderboardUsecase *LeaderboardUsecase
+}
+
+func NewUserDeletionUsecase(database *gorm.DB, userRepo *repository.UserRepository, repoRepo *repository.RepoRepository, repoLogRepo *repository.RepoDailyCommitLogRepository, repoStreakRepo *repository.RepoStreakRepository, userLogRepo *repository.UserDailyCommitLogRepository, streakRepo *repository.StreakRepository, achievementRepo *repository.AchievementRepository, goalRepo *repository.GoalRepository, freezeRepo *repository.StreakFreezeRepository, apiKeyRepo *repository.APIKeyRepository, leaderboardUsecase *LeaderboardUsecase) *UserDeletionUsecase {
+	return &UserDeletionUsecase{
+		database:           database,
+		userRepo:           userRepo,
+		repoRepo:           repoRepo,
+		repoLogRepo:        repoLogRepo,
+		repoStreakRepo:     repoStreakRepo,
+		userLogRepo:        userLogRepo,
+		streakRepo:         streakRepo,
+		achievementRepo:    achievementRepo,
+		goalRepo:           goalRepo,
+		freezeRepo:         freezeRepo,
+		apiKeyRepo:         apiKeyRepo,
+		leaderboardUsecase: leaderboardUsecase,
+	}
+}
+
+// DeleteUser ユーザーを削除する
+//
+//   - purge が false の場合は論理削除のみ（同じGitHubアカウントで再登録すると元のIDと履歴が戻る）
+//   - purge が true の場合は1トランザクションで、ユーザーと登録リポジトリ・日次ログ・streak・バッジ・目標・streakの凍結期間を物理削除する。
+//     論理削除済みのユーザーも対象にする
+//
+// 削除したユーザーがランキングに残らないよう、どちらの場合もランキングのキャッシュを捨てる
+// ユーザーが存在しない（論理削除では削除済み、purge では物理削除済み）場合は repository.ErrNotFound を返す
+func (userDeletionUsecase *UserDeletionUsecase) DeleteUser(ctx context.Context, userID uint64, purge bool) (*dto.DeleteUserResponse, error) {
+	res := &dto.DeleteUserResponse{UserID: userID, Purged: purge}
+	if !purge {
+		if _, err := userDeletionUsecase.userRepo.FindByID(ctx, userID); err != nil {
+			return nil, err
+		}
+		if err := userDeletionUsecase.userRepo.Delete(ctx, userID); err != nil {
+			return nil, err
+		}
+		userDeletionUsecase.leaderboardUsecase.ClearCache()
+		res.Deleted.Users = 1
+		return res, nil
+	}
+
+	err := withTransaction(ctx, userDeletionUsecase.database, func(ctx context.Context, tx *gorm.DB) error {
+		userRepo := userDeletionUsecase.userRepo.WithTx(tx)
+		if _, err := userRepo.FindByIDUnscoped(ctx, userID); err != nil {
+			return err
+		}
+
+		// 外部キーで参照している側から順に削除する
+		var counts dto.DeletedRowCounts
+		var err error
+		if counts.RepoDailyCommitLogs, err = userDeletionUsecase.repoLogRepo.WithTx(tx).DeleteByUserID(ctx, userID); err != nil {
+			return err
+		}
+		if counts.RepoStreaks, err = userDeletionUsecase.repoStreakRepo.WithTx(tx).DeleteByUserID(ctx, userID); err != nil {
+			return err
+		}
+		if counts.Repositories, err = userDeletionUsecase.repoRepo.WithTx(tx).DeleteByUserID(ctx, userID); err != nil {
+			return err
+		}
+		if counts.UserDailyCommitLogs, err = userDeletionUsecase.userLogRepo.WithTx(tx).DeleteByUserID(ctx, userID); err != nil {
+			return err
+		}
+		if counts.UserStreaks, err = userDeletionUsecase.streakRepo.WithTx(tx).DeleteByUserID(ctx, userID); err != nil {
+			return err
+		}
+		if counts.Achievements, err = userDeletionUsecase.achievementRepo.WithTx(tx).DeleteByUserID(ctx, userID); err != nil {
+			return err
+		}
+		if counts.Goals, err = userDeletionUsecase.goalRepo.WithTx(tx).DeleteByUserID(ctx, userID); err != nil {
+			return err
+		}
+		if counts.StreakFreezes, err = userDeletionUsecase.freezeRepo.WithTx(tx).DeleteByUserID(ctx, userID); err != nil {
+			return err
+		}
+		if counts.APIKeys, err = userDeletionUsecase.apiKeyRepo.WithTx(tx).DeleteByUserID(ctx, userID); err != nil {
+			return err
+		}
+		if err := userRepo.Purge(ctx, userID); err != nil {
+			return err
+		}
+		counts.Users = 1
+		res.Deleted = counts
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	userDeletionUsecase.leaderboardUsecase.ClearCache()
+	return res, nil
+}
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/models"
+	"github.com/keeee21/commit-town/api/repository"
+	"gorm.io/gorm"
+)
+
+// ErrMergeSameUser 同じユーザー同士は統合できない
+var ErrMergeSameUser = errors.New("cannot merge a user into itself")
+
+type UserMergeUsecase struct {
+	database           *gorm.DB
+	userRepo           *repository.UserRepository
+	repoRepo           *repository.RepoRepository
+	repoLogRepo        *repository.RepoDailyCommitLogRepository
+	repoStreakRepo     *repository.RepoStreakRepository
+	userLogRepo        *repository.UserDailyCommitLogRepository
+	streakRepo         *repository.StreakRepository
+	aggregationUsecase *AggregationUsecase
+	streakUsecase      *StreakUsecase
+	achievementUsecase *AchievementUsecase
+	leaderboardUsecase *LeaderboardUsecase
+}
+
+func NewUserMergeUsecase(database *gorm.DB, userRepo *repository.UserRepository, repoRepo *repository.RepoRepository, repoLogRepo *repository.RepoDailyCommitLogRepository, repoStreakRepo *repository.RepoStreakRepository, userLogRepo *repository.UserDailyCommitLogRepository, streakRepo *repository.StreakRepository, aggregationUsecase *AggregationUsecase, streakUsecase *StreakUsecase, achievementUsecase *AchievementUsecase, leaderboardUsecase *LeaderboardUsecase) *UserMergeUsecase {
+	return &UserMergeUsecase{
+		database:           database,
+		userRepo:           userRepo,
+		repoRepo:           repoRepo,
+		repoLogRepo:        repoLogRepo,
+		repoStreakRepo:     repoStreakRepo,
+		userLogRepo:        userLogRepo,
+		streakRepo:         streakRepo,
+		aggregationUsecase: aggregationUsecase,
+		streakUsecase:      streakUsecase,
+		achievementUsecase: achievementUsecase,
+		leaderboardUsecase: leaderboardUsecase,
+	}
+}
+
+// MergeUsers 重複して作られたユーザー removeID を keepID に統合する（1トランザクション）
+//
+//   - 登録リポジトリを keepID に付け替える。両方に同じリポジトリがある場合は keepID 側を残し、
+//     removeID 側はコミットログごと削除する（同じGitHubのコミットを二重に数えないため）
+//   - ユーザー単位の日次ログを keepID に移し、同じ日付がある場合はコミット数を合算する
+//   - removeID のstreakを削除して論理削除し、keepID のstreakとバッジを計算し直す
+//   - ランキングのキャッシュを捨てる
+//
+// いずれかのユーザーが存在しない場合は repository.ErrNotFound を返す
+func (userMergeUsecase *UserMergeUsecase) MergeUsers(ctx context.Context, keepID, removeID uint64) (*dto.UserResponse, error) {
+	if keepID == removeID {
+		return nil, ErrMergeSameUser
+	}
+
+	var kept *models.User
+	err := withTransaction(ctx, userMergeUsecase.database, func(ctx context.Context, tx *gorm.DB) error {
+		userRepo := userMergeUsecase.userRepo.WithTx(tx)
+		repoRepo := userMergeUsecase.repoRepo.WithTx(tx)
+		repoLogRepo := userMergeUsecase.repoLogRepo.WithTx(tx)
+
+		if _, err := userRepo.FindByID(ctx, keepID); err != nil {
+			return err
+		}
+		if _, err := userRepo.FindByID(ctx, removeID); err != nil {
+			return err
+		}
+
+		// 重複するリポジトリを削除し、合算をやり直す期間を記録する
+		removedRepos, err := repoRepo.ListByUserID(ctx, removeID)
+		if err != nil {
+			return err
+		}
+		var rebuild []repository.DateRange
+		for _, repo := range removedRepos {
+			_, err := repoRepo.FindByUserAndName(ctx, keepID, repo.RepoOwner, repo.RepoName)
+			if errors.Is(err, repository.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			dateRange, err := repoLogRepo.DateRangeByUserRepoID(ctx, repo.ID)
+			if err != nil {
+				return err
+			}
+			if dateRange.First != nil {
+				rebuild = append(rebuild, dateRange)
+			}
+			if err := repoLogRepo.DeleteByUserRepoID(ctx, repo.ID); err != nil {
+				return err
+			}
+			if err := userMergeUsecase.repoStreakRepo.WithTx(tx).ReplaceByUserRepoID(ctx, repo.ID, nil); err != nil {
+				return err
+			}
+			if err := repoRepo.Delete(ctx, repo.ID); err != nil {
+				return err
+			}
+		}
+
+		if err := repoRepo.ReassignUser(ctx, removeID, keepID); err != nil {
+			return err
+		}
+		if err := userMergeUsecase.userLogRepo.WithTx(tx).MergeInto(ctx, removeID, keepID); err != nil {
+			return err
+		}
+		if err := userMergeUsecase.streakRepo.WithTx(tx).ReplaceByUserID(ctx, removeID, nil); err != nil {
+			return err
+		}
+		if err := userRepo.Delete(ctx, removeID); err != nil {
+			return err
+		}
+
+		// 重複していたリポジトリの期間は、残したリポジトリのログから合算し直す
+		aggregationUsecase := userMergeUsecase.aggregationUsecase.WithTx(tx)
+		for _, dateRange := range rebuild {
+			if err := aggregationUsecase.RebuildRange(ctx, keepID, *dateRange.First, *dateRange.Last); err != nil {
+				return err
+			}
+		}
+		if err := userMergeUsecase.streakUsecase.WithTx(tx).RecalculateStreaks(ctx, keepID); err != nil {
+			return err
+		}
+		if err := userMergeUsecase.achievementUsecase.WithTx(tx).Evaluate(ctx, keepID); err != nil {
+			return err
+		}
+
+		kept, err = userRepo.FindByID(ctx, keepID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	// removeID が消え、keepID の集計が変わるため、ランキングのキャッシュを捨てる
+	userMergeUsecase.leaderboardUsecase.ClearCache()
+	return toUserResponse(kept), nil
+}
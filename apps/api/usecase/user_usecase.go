@@ -1,37 +1,170 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"log"
+
 	"github.com/keeee21/commit-town/api/dto"
 	"github.com/keeee21/commit-town/api/models"
 	"github.com/keeee21/commit-town/api/repository"
+	"gorm.io/gorm"
 )
 
 type UserUsecase struct {
-	userRepo *repository.UserRepository
+	database           *gorm.DB
+	userRepo           *repository.UserRepository
+	repoRepo           *repository.RepoRepository
+	streakUsecase      *StreakUsecase
+	leaderboardUsecase *LeaderboardUsecase
+}
+
+func NewUserUsecase(database *gorm.DB, userRepo *repository.UserRepository, repoRepo *repository.RepoRepository, streakUsecase *StreakUsecase, leaderboardUsecase *LeaderboardUsecase) *UserUsecase {
+	return &UserUsecase{
+		database:           database,
+		userRepo:           userRepo,
+		repoRepo:           repoRepo,
+		streakUsecase:      streakUsecase,
+		leaderboardUsecase: leaderboardUsecase,
+	}
 }
 
-func NewUserUsecase(userRepo *repository.UserRepository) *UserUsecase {
-	return &UserUsecase{userRepo: userRepo}
+// WithTx トランザクション内で操作するユースケースを返す
+func (userUsecase *UserUsecase) WithTx(tx *gorm.DB) *UserUsecase {
+	return &UserUsecase{
+		database:      tx,
+		userRepo:      userUsecase.userRepo.WithTx(tx),
+		repoRepo:      userUsecase.repoRepo.WithTx(tx),
+		streakUsecase: userUsecase.streakUsecase.WithTx(tx),
+	}
 }
 
-// UpsertUser ユーザーを作成または更新
-func (userUsecase *UserUsecase) UpsertUser(req *dto.UpsertUserRequest) (*dto.UserResponse, error) {
+// UpsertUser ユーザーを作成または更新（GitHubでユーザー名が変わっていれば登録リポジトリのオーナーも合わせる）
+func (userUsecase *UserUsecase) UpsertUser(ctx context.Context, req *dto.UpsertUserRequest) (*dto.UserResponse, error) {
 	user := &models.User{
 		GitHubUserID:   req.GitHubUserID,
 		GitHubUsername: req.GitHubUsername,
 		Email:          req.Email,
 	}
 
-	if err := userUsecase.userRepo.Upsert(user); err != nil {
+	err := withTransaction(ctx, userUsecase.database, func(ctx context.Context, tx *gorm.DB) error {
+		txUsecase := userUsecase.WithTx(tx)
+		if err := txUsecase.ReconcileUsername(ctx, req.GitHubUserID, req.GitHubUsername); err != nil {
+			return err
+		}
+		return txUsecase.userRepo.Upsert(ctx, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toUserResponse(user), nil
+}
+
+// ReconcileUsername GitHubでのユーザー名変更を反映する
+// GitHub User ID は変わらないため、保存済みのユーザー名と newLogin が異なればユーザー名を更新し、
+// 旧ユーザー名がオーナーの登録リポジトリ（本人のリポジトリ）のオーナーも newLogin に変更する。未登録のユーザーは何もしない
+func (userUsecase *UserUsecase) ReconcileUsername(ctx context.Context, githubUserID uint64, newLogin string) error {
+	user, err := userUsecase.userRepo.FindByGitHubUserID(ctx, githubUserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	oldLogin := user.GitHubUsername
+	if oldLogin == newLogin {
+		return nil
+	}
+
+	user.GitHubUsername = newLogin
+	if err := userUsecase.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	renamed, err := userUsecase.repoRepo.RenameOwner(ctx, user.ID, oldLogin, newLogin)
+	if err != nil {
+		return err
+	}
+	log.Printf("GitHub user %d renamed from %s to %s; updated %d repositories", githubUserID, oldLogin, newLogin, renamed)
+	return nil
+}
+
+// PatchUser 指定された項目だけを更新（項目が無ければ更新せずに現在の値を返す）
+// min_commits_per_day を指定した場合はstreakを再計算し、is_public_profile を指定した場合はランキングのキャッシュを捨てる
+func (userUsecase *UserUsecase) PatchUser(ctx context.Context, userID uint64, req *dto.PatchUserRequest) (*dto.UserResponse, error) {
+	fields := make(map[string]any)
+	if req.Email != nil {
+		fields["email"] = *req.Email
+	}
+	if req.Timezone != nil {
+		fields["timezone"] = *req.Timezone
+	}
+	if req.NotificationsEnabled != nil {
+		fields["notifications_enabled"] = *req.NotificationsEnabled
+	}
+	if req.IsPublicProfile != nil {
+		fields["is_public_profile"] = *req.IsPublicProfile
+	}
+	if req.MinCommitsPerDay != nil {
+		if *req.MinCommitsPerDay == 0 {
+			fields["min_commits_per_day"] = nil
+		} else {
+			fields["min_commits_per_day"] = *req.MinCommitsPerDay
+		}
+	}
+
+	if len(fields) > 0 {
+		// streakに数える日が変わるため、下限を変えた場合は同じトランザクションでstreakを再計算する
+		err := withTransaction(ctx, userUsecase.database, func(ctx context.Context, tx *gorm.DB) error {
+			if err := userUsecase.userRepo.WithTx(tx).UpdateFields(ctx, userID, fields); err != nil {
+				return err
+			}
+			if req.MinCommitsPerDay == nil {
+				return nil
+			}
+			return userUsecase.streakUsecase.WithTx(tx).RecalculateStreaks(ctx, userID)
+		})
+		if err != nil {
+			return nil, err
+		}
+		// 非公開にしたユーザーがキャッシュの残る間ランキングに出続けないようにする
+		if req.IsPublicProfile != nil {
+			userUsecase.leaderboardUsecase.ClearCache()
+		}
+	}
+
+	user, err := userUsecase.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toUserResponse(user), nil
+}
+
+// RevokeTokens トークンの版を上げ、それ以前に発行したトークン・APIキーを全て無効にする
+// ユーザーが存在しない場合は repository.ErrNotFound を返す
+func (userUsecase *UserUsecase) RevokeTokens(ctx context.Context, userID uint64) (*dto.RevokeTokensResponse, error) {
+	version, err := userUsecase.userRepo.BumpTokenVersion(ctx, userID)
+	if err != nil {
 		return nil, err
 	}
+	log.Printf("Revoked tokens of user %d (token version is now %d)", userID, version)
+	return &dto.RevokeTokensResponse{UserID: userID, TokenVersion: version}, nil
+}
 
+// toUserResponse ユーザーモデルをレスポンスに変換（日時はユーザーのタイムゾーンで返す）
+func toUserResponse(user *models.User) *dto.UserResponse {
+	loc := userLocation(user)
 	return &dto.UserResponse{
-		ID:             user.ID,
-		GitHubUserID:   user.GitHubUserID,
-		GitHubUsername: user.GitHubUsername,
-		Email:          user.Email,
-		CreatedAt:      user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:      user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-	}, nil
+		ID:                   user.ID,
+		GitHubUserID:         user.GitHubUserID,
+		GitHubUsername:       user.GitHubUsername,
+		Email:                user.Email,
+		Timezone:             user.Timezone,
+		NotificationsEnabled: user.NotificationsEnabled,
+		IsPublicProfile:      user.IsPublicProfile,
+		MinCommitsPerDay:     user.MinCommitsPerDay,
+		CreatedAt:            user.CreatedAt.In(loc),
+		UpdatedAt:            user.UpdatedAt.In(loc),
+	}
 }
@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/models"
+	"github.com/keeee21/commit-town/api/repository"
+)
+
+func TestToUserResponse_Timestamps(t *testing.T) {
+	created := time.Date(2024, 5, 1, 15, 30, 0, 0, time.UTC)
+	updated := time.Date(2024, 12, 1, 15, 30, 0, 0, time.UTC)
+	tests := []struct {
+		timezone    string
+		wantCreated string
+		wantUpdated string
+	}{
+		{"", "2024-05-01T15:30:00Z", "2024-12-01T15:30:00Z"},
+		{"UTC", "2024-05-01T15:30:00Z", "2024-12-01T15:30:00Z"},
+		{"Asia/Tokyo", "2024-05-02T00:30:00+09:00", "2024-12-02T00:30:00+09:00"},
+		// 夏時間の有無で日時ごとにオフセットが変わる
+		{"America/New_York", "2024-05-01T11:30:00-04:00", "2024-12-01T10:30:00-05:00"},
+		{"Not/AZone", "2024-05-01T15:30:00Z", "2024-12-01T15:30:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.timezone, func(t *testing.T) {
+			res := toUserResponse(&models.User{Timezone: tt.timezone, CreatedAt: created, UpdatedAt: updated})
+			b, err := json.Marshal(res)
+			if err != nil {
+				t.Fatalf("failed to marshal response: %v", err)
+			}
+			var body struct {
+				CreatedAt string `json:"created_at"`
+				UpdatedAt string `json:"updated_at"`
+			}
+			if err := json.Unmarshal(b, &body); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if body.CreatedAt != tt.wantCreated || body.UpdatedAt != tt.wantUpdated {
+				t.Errorf("timestamps = %s, %s, want %s, %s", body.CreatedAt, body.UpdatedAt, tt.wantCreated, tt.wantUpdated)
+			}
+			if !res.CreatedAt.Equal(created) {
+				t.Errorf("CreatedAt = %v, want the same instant as %v", res.CreatedAt, created)
+			}
+		})
+	}
+}
+
+// GitHubでユーザー名が変わると、本人がオーナーの登録リポジトリだけオーナーを新しいユーザー名にする
+func TestUserUsecase_UpsertUser_ReconcilesRenamedOwner(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	user := env.createUser(t, "alice")
+	own := env.createRepo(t, user, "alice", "town")
+	foreign := env.createRepo(t, user, "bob", "town")
+	other := env.createUser(t, "carol")
+	othersFork := env.createRepo(t, other, "alice", "town")
+
+	res, err := env.user.UpsertUser(ctx, &dto.UpsertUserRequest{GitHubUserID: user.GitHubUserID, GitHubUsername: "alice-renamed", Email: user.Email})
+	if err != nil {
+		t.Fatalf("UpsertUser returned an error: %v", err)
+	}
+	if res.ID != user.ID || res.GitHubUsername != "alice-renamed" {
+		t.Errorf("response = user %d %s, want user %d alice-renamed", res.ID, res.GitHubUsername, user.ID)
+	}
+
+	wantOwners := map[uint64]string{own.ID: "alice-renamed", foreign.ID: "bob", othersFork.ID: "alice"}
+	for id, want := range wantOwners {
+		repo, err := env.repoRepo.FindByID(ctx, id)
+		if err != nil {
+			t.Fatalf("failed to reload repository %d: %v", id, err)
+		}
+		if repo.RepoOwner != want {
+			t.Errorf("repository %d owner = %s, want %s", id, repo.RepoOwner, want)
+		}
+	}
+}
+
+// 非公開プロフィールのユーザーはランキング（キャッシュ済みのものも）とサービス全体の集計に出ないが、本人のIDでは取得できる
+func TestUserUsecase_PatchUser_PrivateProfileLeavesLeaderboards(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	alice := env.createUser(t, "alice")
+	bob := env.createUser(t, "bob")
+	env.createRepo(t, alice, "alice", "town", commitsOn("alice", 1, 0)...)
+	env.createRepo(t, bob, "bob", "city", commitsOn("bob", 2, 1, 0)...)
+	for _, user := range []*models.User{alice, bob} {
+		if _, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(7), time.Now(), false); err != nil {
+			t.Fatalf("RunForUser(%s) returned an error: %v", user.GitHubUsername, err)
+		}
+	}
+
+	// 非公開にする前のランキングをキャッシュに載せておく
+	if _, err := env.leaderboard.TopCommitters(ctx, daysAgo(7), time.Now(), 10, false); err != nil {
+		t.Fatalf("TopCommitters returned an error: %v", err)
+	}
+	if _, err := env.leaderboard.TopStreaks(ctx, dto.StreakSortCurrent, 10, 0); err != nil {
+		t.Fatalf("TopStreaks returned an error: %v", err)
+	}
+
+	private := false
+	patched, err := env.user.PatchUser(ctx, bob.ID, &dto.PatchUserRequest{IsPublicProfile: &private})
+	if err != nil {
+		t.Fatalf("PatchUser returned an error: %v", err)
+	}
+	if patched.IsPublicProfile {
+		t.Error("IsPublicProfile = true after hiding the profile")
+	}
+
+	leaderboard := env.leaderboard
+	commits, err := leaderboard.TopCommitters(ctx, daysAgo(7), time.Now(), 10, false)
+	if err != nil {
+		t.Fatalf("TopCommitters returned an error: %v", err)
+	}
+	if len(commits.Entries) != 1 || commits.Entries[0].UserID != alice.ID {
+		t.Errorf("commit leaderboard = %+v, want only alice", commits.Entries)
+	}
+	streaks, err := leaderboard.TopStreaks(ctx, dto.StreakSortCurrent, 10, 0)
+	if err != nil {
+		t.Fatalf("TopStreaks returned an error: %v", err)
+	}
+	if len(streaks.Entries) != 1 || streaks.Entries[0].UserID != alice.ID {
+		t.Errorf("streak leaderboard = %+v, want only alice", streaks.Entries)
+	}
+
+	stats, err := NewStatsUsecase(env.db, env.userRepo, env.repoRepo, env.repoLogRepo, env.streakRepo, 0).GetPlatformStats(ctx)
+	if err != nil {
+		t.Fatalf("GetPlatformStats returned an error: %v", err)
+	}
+	if stats.TotalUsers != 1 || stats.TotalCommits != 2 || stats.LongestCurrentStreak != 2 {
+		t.Errorf("stats = %+v, want only alice's 1 user, 2 commits and 2-day streak", stats)
+	}
+
+	summary, err := NewSummaryUsecase(env.userRepo, env.repoRepo, env.userLogRepo, env.streakRepo, 1).GetSummary(ctx, bob.ID)
+	if err != nil {
+		t.Fatalf("GetSummary for the private user returned an error: %v", err)
+	}
+	if summary.User.ID != bob.ID || summary.TotalCommits != 3 || summary.CurrentStreak != 3 {
+		t.Errorf("summary = %+v, want bob's 3 commits and 3-day streak", summary)
+	}
+}
+
+// 統合・削除したユーザーは、キャッシュの残る間もランキングに出ない
+func TestUserUsecase_MergeAndDelete_ClearLeaderboardCache(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	alice := env.createUser(t, "alice")
+	duplicate := env.createUser(t, "alice-duplicate")
+	env.createRepo(t, alice, "alice", "town", commitsOn("alice", 1, 0)...)
+	env.createRepo(t, duplicate, "alice", "city", commitsOn("alice", 2)...)
+	for _, user := range []*models.User{alice, duplicate} {
+		if _, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(7), time.Now(), false); err != nil {
+			t.Fatalf("RunForUser(%s) returned an error: %v", user.GitHubUsername, err)
+		}
+	}
+	committers := func() []uint64 {
+		t.Helper()
+		res, err := env.leaderboard.TopCommitters(ctx, daysAgo(7), time.Now(), 10, false)
+		if err != nil {
+			t.Fatalf("TopCommitters returned an error: %v", err)
+		}
+		ids := make([]uint64, 0, len(res.Entries))
+		for _, entry := range res.Entries {
+			ids = append(ids, entry.UserID)
+		}
+		return ids
+	}
+	if got := committers(); len(got) != 2 {
+		t.Fatalf("committers = %v, want both users", got)
+	}
+
+	merge := NewUserMergeUsecase(env.db, env.userRepo, env.repoRepo, env.repoLogRepo, env.repoStreakRepo, env.userLogRepo, env.streakRepo, env.aggregation, env.streak, env.achievement, env.leaderboard)
+	if _, err := merge.MergeUsers(ctx, alice.ID, duplicate.ID); err != nil {
+		t.Fatalf("MergeUsers returned an error: %v", err)
+	}
+	if got := committers(); len(got) != 1 || got[0] != alice.ID {
+		t.Errorf("committers after merging = %v, want only alice (%d)", got, alice.ID)
+	}
+
+	deletion := NewUserDeletionUsecase(env.db, env.userRepo, env.repoRepo, env.repoLogRepo, env.repoStreakRepo, env.userLogRepo, env.streakRepo,
+		repository.NewAchievementRepository(env.db), repository.NewGoalRepository(env.db), env.freezeRepo, env.apiKeyRepo, env.leaderboard)
+	if _, err := deletion.DeleteUser(ctx, alice.ID, false); err != nil {
+		t.Fatalf("DeleteUser returned an error: %v", err)
+	}
+	if got := committers(); len(got) != 0 {
+		t.Errorf("committers after deleting = %v, want none", got)
+	}
+}
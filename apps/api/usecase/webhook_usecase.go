@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/events"
+	"github.com/keeee21/commit-town/api/gateway"
+	"github.com/keeee21/commit-town/api/repository"
+)
+
+// streakMilestones StreakExtended でWebhookを送るstreakの日数
+var streakMilestones = []int{7, 30, 100}
+
+// defaultWebhookRetryDelays 送信に失敗したときの再試行までの待ち時間（使い切ったらログに出して諦める）
+var defaultWebhookRetryDelays = []time.Duration{time.Second, 5 * time.Second, 30 * time.Second}
+
+type WebhookUsecase struct {
+	userRepo    *repository.UserRepository
+	sender      *gateway.WebhookSender
+	retryDelays []time.Duration
+}
+
+func NewWebhookUsecase(userRepo *repository.UserRepository, sender *gateway.WebhookSender) *WebhookUsecase {
+	return &WebhookUsecase{userRepo: userRepo, sender: sender, retryDelays: defaultWebhookRetryDelays}
+}
+
+// SetWebhook 送信先URLを設定し、署名用のシークレットを発行し直す（シークレットはこのレスポンスでのみ返す）
+// ユーザーが存在しない場合は repository.ErrNotFound を返す
+func (webhookUsecase *WebhookUsecase) SetWebhook(ctx context.Context, userID uint64, url string) (*dto.WebhookResponse, error) {
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := webhookUsecase.userRepo.UpdateFields(ctx, userID, map[string]any{"webhook_url": url, "webhook_secret": secret}); err != nil {
+		return nil, err
+	}
+	return &dto.WebhookResponse{URL: url, Secret: secret}, nil
+}
+
+// DeleteWebhook 送信先URLとシークレットを削除する（冪等）
+// ユーザーが存在しない場合は repository.ErrNotFound を返す
+func (webhookUsecase *WebhookUsecase) DeleteWebhook(ctx context.Context, userID uint64) error {
+	return webhookUsecase.userRepo.UpdateFields(ctx, userID, map[string]any{"webhook_url": nil, "webhook_secret": nil})
+}
+
+// Subscribe streakの節目（StreakExtended で7・30・100日に達した場合）と StreakBroken をWebhookで送る購読者を登録する
+// 同期中のイベントはトランザクションをコミットした後に届くため、ロールバックした同期の分は送らない
+func (webhookUsecase *WebhookUsecase) Subscribe(bus *events.Bus) {
+	bus.Subscribe(events.StreakExtended, webhookUsecase.handleStreakEvent)
+	bus.Subscribe(events.StreakBroken, webhookUsecase.handleStreakEvent)
+}
+
+// handleStreakEvent 送信は再試行を含めて時間がかかるため、同期処理を待たせないようバックグラウンドで行う
+func (webhookUsecase *WebhookUsecase) handleStreakEvent(ctx context.Context, event events.Event) error {
+	streakEvent, ok := event.(events.StreakEvent)
+	if !ok {
+		return nil
+	}
+
+	milestone := 0
+	if streakEvent.Type == events.StreakExtended {
+		milestone = reachedMilestone(streakEvent.PreviousLength, streakEvent.Length)
+		if milestone == 0 {
+			return nil
+		}
+	}
+
+	go webhookUsecase.deliver(streakEvent, milestone)
+	return nil
+}
+
+// reachedMilestone previous 日から length 日に伸びたときに達した節目（複数に達した場合は最も大きいもの、無ければ0）
+func reachedMilestone(previous, length int) int {
+	reached := 0
+	for _, milestone := range streakMilestones {
+		if previous < milestone && milestone <= length {
+			reached = milestone
+		}
+	}
+	return reached
+}
+
+// deliver Webhookが設定されていれば送信し、失敗したら retryDelays の間隔で再試行する
+// 呼び出し元のリクエストが終わっても続けるため、コンテキストは新しく作る
+func (webhookUsecase *WebhookUsecase) deliver(event events.StreakEvent, milestone int) {
+	ctx := context.Background()
+	user, err := webhookUsecase.userRepo.FindByID(ctx, event.UserID)
+	if err != nil {
+		log.Printf("Failed to load user %d for webhook: %v", event.UserID, err)
+		return
+	}
+	if user.WebhookURL == nil || user.WebhookSecret == nil {
+		return
+	}
+
+	body, err := json.Marshal(dto.StreakWebhookPayload{
+		Event:          string(event.Type),
+		UserID:         user.ID,
+		GitHubUsername: user.GitHubUsername,
+		StartDate:      event.StartDate.UTC().Format("2006-01-02"),
+		Length:         event.Length,
+		Milestone:      milestone,
+		OccurredAt:     time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("Failed to encode webhook payload for user %d: %v", user.ID, err)
+		return
+	}
+
+	for attempt := 0; ; attempt++ {
+		err = webhookUsecase.sender.Send(ctx, *user.WebhookURL, *user.WebhookSecret, string(event.Type), body)
+		if err == nil {
+			return
+		}
+		if attempt == len(webhookUsecase.retryDelays) {
+			log.Printf("Giving up webhook %s for user %d after %d attempts: %v", event.Type, user.ID, attempt+1, err)
+			return
+		}
+		log.Printf("Webhook %s for user %d failed, retrying in %s: %v", event.Type, user.ID, webhookUsecase.retryDelays[attempt], err)
+		time.Sleep(webhookUsecase.retryDelays[attempt])
+	}
+}
+
+// newWebhookSecret 署名用のランダムなシークレット（64文字の16進数）
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/keeee21/commit-town/api/dto"
+	"github.com/keeee21/commit-town/api/events"
+	"github.com/keeee21/commit-town/api/gateway"
+	"github.com/keeee21/commit-town/api/internal/githubtest"
+	"github.com/keeee21/commit-town/api/models"
+	"gorm.io/gorm"
+)
+
+func TestReachedMilestone(t *testing.T) {
+	tests := []struct {
+		previous, length int
+		want             int
+	}{
+		{0, 6, 0},
+		{6, 7, 7},
+		{7, 8, 0},
+		{5, 10, 7},
+		{29, 30, 30},
+		{6, 31, 30},
+		{99, 100, 100},
+		{100, 101, 0},
+		{0, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := reachedMilestone(tt.previous, tt.length); got != tt.want {
+			t.Errorf("reachedMilestone(%d, %d) = %d, want %d", tt.previous, tt.length, got, tt.want)
+		}
+	}
+}
+
+// webhookRequest 受信したWebhook
+type webhookRequest struct {
+	event     string
+	signature string
+	body      []byte
+}
+
+// webhookReceiver 最初の failures 回は500を返し、その後に受信したWebhookを返すサーバー
+func webhookReceiver(t *testing.T, failures int32) (*httptest.Server, <-chan webhookRequest, *atomic.Int32) {
+	t.Helper()
+	received := make(chan webhookRequest, 16)
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		received <- webhookRequest{event: r.Header.Get(gateway.HeaderWebhookEvent), signature: r.Header.Get(gateway.HeaderWebhookSignature), body: body}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+	return server, received, &calls
+}
+
+// newTestWebhookUsecase 再試行を待たない WebhookUsecase（httptest のループバックへ送れるよう制限のないクライアントを使う）
+func (env *testEnv) newTestWebhookUsecase() *WebhookUsecase {
+	webhookUsecase := NewWebhookUsecase(env.userRepo, gateway.NewWebhookSenderWithClient(&http.Client{Timeout: 10 * time.Second}))
+	webhookUsecase.retryDelays = []time.Duration{0, 0, 0}
+	return webhookUsecase
+}
+
+// 失敗した送信は再試行し、ボディはユーザーのシークレットで署名する
+func TestWebhookUsecase_DeliverRetriesAndSigns(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	user := env.createUser(t, "alice")
+	webhookUsecase := env.newTestWebhookUsecase()
+	server, received, calls := webhookReceiver(t, 2)
+
+	webhook, err := webhookUsecase.SetWebhook(ctx, user.ID, server.URL)
+	if err != nil {
+		t.Fatalf("SetWebhook returned an error: %v", err)
+	}
+
+	start := truncateToDate(time.Now()).AddDate(0, 0, -6)
+	webhookUsecase.deliver(events.StreakEvent{Type: events.StreakExtended, UserID: user.ID, StartDate: start, Length: 7, PreviousLength: 6}, 7)
+
+	if got := calls.Load(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+	var req webhookRequest
+	select {
+	case req = <-received:
+	default:
+		t.Fatal("no webhook was accepted")
+	}
+	if req.event != string(events.StreakExtended) {
+		t.Errorf("%s = %q, want %q", gateway.HeaderWebhookEvent, req.event, events.StreakExtended)
+	}
+	if want := gateway.Sign(webhook.Secret, req.body); req.signature != want {
+		t.Errorf("%s = %q, want %q", gateway.HeaderWebhookSignature, req.signature, want)
+	}
+
+	var payload dto.StreakWebhookPayload
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("failed to decode payload %s: %v", req.body, err)
+	}
+	if payload.UserID != user.ID || payload.GitHubUsername != "alice" || payload.Length != 7 || payload.Milestone != 7 || payload.StartDate != start.Format("2006-01-02") {
+		t.Errorf("payload = %+v, want alice's 7-day milestone from %s", payload, start.Format("2006-01-02"))
+	}
+}
+
+// 再試行を使い切ったら諦める
+func TestWebhookUsecase_DeliverGivesUp(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	user := env.createUser(t, "alice")
+	webhookUsecase := env.newTestWebhookUsecase()
+	server, received, calls := webhookReceiver(t, 100)
+
+	if _, err := webhookUsecase.SetWebhook(ctx, user.ID, server.URL); err != nil {
+		t.Fatalf("SetWebhook returned an error: %v", err)
+	}
+	webhookUsecase.deliver(events.StreakEvent{Type: events.StreakBroken, UserID: user.ID, StartDate: daysAgo(10), Length: 9}, 0)
+
+	if got, want := calls.Load(), int32(len(webhookUsecase.retryDelays)+1); got != want {
+		t.Errorf("requests = %d, want %d", got, want)
+	}
+	if len(received) != 0 {
+		t.Errorf("%d webhooks were accepted, want none", len(received))
+	}
+}
+
+// ロールバックした同期のイベントは送らず、コミットした同期の節目だけを送る
+func TestWebhookUsecase_SendsOnlyCommittedEvents(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testEnvConfig{})
+	user := env.createUser(t, "alice")
+	env.createRepo(t, user, "alice", "town", commitsOn("alice", 1, 2, 3, 4, 5, 6)...)
+	webhookUsecase := env.newTestWebhookUsecase()
+	webhookUsecase.Subscribe(env.bus)
+	server, received, calls := webhookReceiver(t, 0)
+	if _, err := webhookUsecase.SetWebhook(ctx, user.ID, server.URL); err != nil {
+		t.Fatalf("SetWebhook returned an error: %v", err)
+	}
+
+	// 6日のstreakは節目ではないため送らない
+	if _, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(7), time.Now(), false); err != nil {
+		t.Fatalf("first RunForUser returned an error: %v", err)
+	}
+
+	// 7日目のコミットを取り込む同期を、streakを書いた後のバッジの保存で失敗させる
+	var failAchievements atomic.Bool
+	failAchievements.Store(true)
+	injected := errors.New("injected achievement failure")
+	err := env.db.Callback().Create().Before("gorm:create").Register("test:fail_user_achievements", func(tx *gorm.DB) {
+		if failAchievements.Load() && tx.Statement.Table == "user_achievements" {
+			tx.AddError(injected)
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to register callback: %v", err)
+	}
+	env.github.SetRepo("alice", "town", githubtest.Repo{Commits: commitsOn("alice", 0, 1, 2, 3, 4, 5, 6)})
+
+	if _, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(7), time.Now(), false); !errors.Is(err, injected) {
+		t.Fatalf("second RunForUser error = %v, want %v", err, injected)
+	}
+	if got := env.countRows(t, &models.UserAchievement{}); got != 0 {
+		t.Fatalf("user_achievements has %d rows after the rollback, want 0", got)
+	}
+
+	failAchievements.Store(false)
+	if _, err := env.pipeline.RunForUser(ctx, user.ID, daysAgo(7), time.Now(), false); err != nil {
+		t.Fatalf("third RunForUser returned an error: %v", err)
+	}
+
+	var req webhookRequest
+	select {
+	case req = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the committed milestone was not sent")
+	}
+	var payload dto.StreakWebhookPayload
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("failed to decode payload %s: %v", req.body, err)
+	}
+	if payload.Event != string(events.StreakExtended) || payload.Milestone != 7 {
+		t.Errorf("payload = %+v, want the 7-day milestone", payload)
+	}
+
+	// 送信はバックグラウンドのため、ロールバックした分が遅れて届かないことも確かめる
+	time.Sleep(100 * time.Millisecond)
+	if got := calls.Load(); got != 1 {
+		t.Errorf("received %d webhooks, want only the committed milestone", got)
+	}
+}
@@ -0,0 +1,96 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/keeee21/commit-town/api/models"
+)
+
+var (
+	// GitHubのユーザー名/組織名: 英数字とハイフン（先頭・末尾のハイフン不可）、最大39文字
+	repoOwnerRegex = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]{0,37}[A-Za-z0-9])?$`)
+	// GitHubのリポジトリ名: 英数字・ピリオド・アンダースコア・ハイフン、最大100文字
+	repoNameRegex = regexp.MustCompile(`^[A-Za-z0-9._-]{1,100}$`)
+	// ブランチ名に使えない文字（git check-ref-format に準拠）: 空白・制御文字・~^:?*[\
+	branchInvalidCharRegex = regexp.MustCompile(`[\x00-\x20\x7f~^:?*\[\\]`)
+)
+
+type RepoValidator struct{}
+
+func NewRepoValidator() *RepoValidator {
+	return &RepoValidator{}
+}
+
+type RepositoryInput struct {
+	Owner        string
+	Name         string
+	Branch       string // 空の場合はデフォルトブランチ
+	CountMode    string // 空の場合は all
+	DisplayOrder int
+}
+
+// ValidateRepository validates the owner and name of a GitHub repository
+func (v *RepoValidator) ValidateRepository(input RepositoryInput) error {
+	if err := v.ValidateOwner(input.Owner); err != nil {
+		return err
+	}
+
+	if input.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	if input.Name == "." || input.Name == ".." || !repoNameRegex.MatchString(input.Name) {
+		return fmt.Errorf("name must be 1-100 characters of letters, digits, '.', '_' or '-'")
+	}
+
+	if input.Branch != "" && !isValidBranchName(input.Branch) {
+		return fmt.Errorf("branch must be a valid git branch name")
+	}
+
+	if input.DisplayOrder < 0 {
+		return fmt.Errorf("display_order must be a non-negative integer")
+	}
+
+	switch input.CountMode {
+	case "", models.CountModeAll, models.CountModeAuthoredOnly, models.CountModeNoMerges:
+	default:
+		return fmt.Errorf("count_mode must be one of all, authored_only or no_merges")
+	}
+
+	return nil
+}
+
+// ValidateOwner validates a GitHub user or organization name
+func (v *RepoValidator) ValidateOwner(owner string) error {
+	if owner == "" {
+		return fmt.Errorf("owner is required")
+	}
+
+	if !repoOwnerRegex.MatchString(owner) {
+		return fmt.Errorf("owner must be 1-39 alphanumeric characters or hyphens, and cannot start or end with a hyphen")
+	}
+
+	return nil
+}
+
+// isValidBranchName checks the rules of git check-ref-format for a branch name (max 255 characters)
+func isValidBranchName(branch string) bool {
+	if len(branch) > 255 || branchInvalidCharRegex.MatchString(branch) {
+		return false
+	}
+	if strings.HasPrefix(branch, "/") || strings.HasPrefix(branch, "-") ||
+		strings.HasSuffix(branch, "/") || strings.HasSuffix(branch, ".") || strings.HasSuffix(branch, ".lock") {
+		return false
+	}
+	if strings.Contains(branch, "..") || strings.Contains(branch, "//") || strings.Contains(branch, "@{") || branch == "@" {
+		return false
+	}
+	for _, part := range strings.Split(branch, "/") {
+		if strings.HasPrefix(part, ".") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	playground "github.com/go-playground/validator/v10"
+)
+
+// RequestValidator リクエストDTOの validate タグを検証する（Echo の e.Validator として登録する）
+type RequestValidator struct {
+	validate *playground.Validate
+}
+
+func NewRequestValidator() *RequestValidator {
+	validate := playground.New()
+	// エラーの項目名はJSONのキー名で返す
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return &RequestValidator{validate: validate}
+}
+
+// Validate 検証に失敗した場合は全項目のエラーを ValidationErrors で返す
+func (v *RequestValidator) Validate(i interface{}) error {
+	err := v.validate.Struct(i)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs playground.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+
+	errs := make(ValidationErrors, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		errs.Add(fieldErr.Field(), fieldMessage(fieldErr))
+	}
+	return errs.Err()
+}
+
+// fieldMessage タグごとのエラーメッセージ
+func fieldMessage(fieldErr playground.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "email", "eq=|email":
+		return "must be a valid email address"
+	case "https_url":
+		return "must be a valid https URL"
+	case "timezone":
+		return "must be a valid IANA timezone name"
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", strings.ReplaceAll(fieldErr.Param(), " ", ", "))
+	case "min":
+		switch fieldErr.Kind() {
+		case reflect.Slice:
+			return fmt.Sprintf("must have at least %s items", fieldErr.Param())
+		case reflect.String:
+			return fmt.Sprintf("must be at least %s characters", fieldErr.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fieldErr.Param())
+	case "max":
+		switch fieldErr.Kind() {
+		case reflect.Slice:
+			return fmt.Sprintf("must have at most %s items", fieldErr.Param())
+		case reflect.String:
+			return fmt.Sprintf("must be at most %s characters", fieldErr.Param())
+		}
+		// 数値（int・uint・float）は値の上限
+		return fmt.Sprintf("must be at most %s", fieldErr.Param())
+	}
+	return fmt.Sprintf("failed the %s rule", fieldErr.Tag())
+}
@@ -0,0 +1,43 @@
+package validator
+
+import (
+	"errors"
+	"testing"
+)
+
+// 上限・下限のメッセージは項目の種類（文字列・件数・数値）に合わせる
+func TestRequestValidator_MinMaxMessages(t *testing.T) {
+	type request struct {
+		Name   string   `json:"name" validate:"min=2,max=5"`
+		IDs    []uint64 `json:"ids" validate:"min=1,max=2"`
+		Count  *int     `json:"count" validate:"omitempty,min=1,max=1000"`
+		Amount float64  `json:"amount" validate:"max=1.5"`
+	}
+	count := func(n int) *int { return &n }
+	tests := []struct {
+		name  string
+		req   request
+		field string
+		want  string
+	}{
+		{"long string", request{Name: "abcdef", IDs: []uint64{1}}, "name", "must be at most 5 characters"},
+		{"short string", request{Name: "a", IDs: []uint64{1}}, "name", "must be at least 2 characters"},
+		{"too many items", request{Name: "abc", IDs: []uint64{1, 2, 3}}, "ids", "must have at most 2 items"},
+		{"too few items", request{Name: "abc", IDs: []uint64{}}, "ids", "must have at least 1 items"},
+		{"large int", request{Name: "abc", IDs: []uint64{1}, Count: count(1001)}, "count", "must be at most 1000"},
+		{"small int", request{Name: "abc", IDs: []uint64{1}, Count: count(0)}, "count", "must be at least 1"},
+		{"large float", request{Name: "abc", IDs: []uint64{1}, Amount: 2}, "amount", "must be at most 1.5"},
+	}
+	validator := NewRequestValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs ValidationErrors
+			if err := validator.Validate(&tt.req); !errors.As(err, &errs) {
+				t.Fatalf("Validate returned %v, want ValidationErrors", err)
+			}
+			if len(errs) != 1 || errs[0].Field != tt.field || errs[0].Message != tt.want {
+				t.Errorf("errors = %+v, want %s %q", errs, tt.field, tt.want)
+			}
+		})
+	}
+}
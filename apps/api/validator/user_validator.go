@@ -1,7 +1,6 @@
 package validator
 
 import (
-	"fmt"
 	"regexp"
 )
 
@@ -22,47 +21,50 @@ type UpdateUserInput struct {
 }
 
 // ValidateCreateUser validates input for creating a user
+// Every failure is collected; the returned error is a ValidationErrors when any field is invalid
 func (v *UserValidator) ValidateCreateUser(input CreateUserInput) error {
-	if input.Name == "" {
-		return fmt.Errorf("name is required")
-	}
-
-	if len(input.Name) < 2 {
-		return fmt.Errorf("name must be at least 2 characters")
-	}
+	var errs ValidationErrors
 
-	if len(input.Name) > 100 {
-		return fmt.Errorf("name must be less than 100 characters")
+	if input.Name == "" {
+		errs.Add("name", "is required")
+	} else {
+		validateName(&errs, input.Name)
 	}
 
 	if input.Email == "" {
-		return fmt.Errorf("email is required")
-	}
-
-	if !isValidEmail(input.Email) {
-		return fmt.Errorf("invalid email format")
+		errs.Add("email", "is required")
+	} else if !isValidEmail(input.Email) {
+		errs.Add("email", "must be a valid email address")
 	}
 
-	return nil
+	return errs.Err()
 }
 
 // ValidateUpdateUser validates input for updating a user
+// Empty fields are not updated and therefore not validated
 func (v *UserValidator) ValidateUpdateUser(input UpdateUserInput) error {
-	if input.Name != "" {
-		if len(input.Name) < 2 {
-			return fmt.Errorf("name must be at least 2 characters")
-		}
+	var errs ValidationErrors
 
-		if len(input.Name) > 100 {
-			return fmt.Errorf("name must be less than 100 characters")
-		}
+	if input.Name != "" {
+		validateName(&errs, input.Name)
 	}
 
 	if input.Email != "" && !isValidEmail(input.Email) {
-		return fmt.Errorf("invalid email format")
+		errs.Add("email", "must be a valid email address")
 	}
 
-	return nil
+	return errs.Err()
+}
+
+// validateName checks the length of a non-empty name
+func validateName(errs *ValidationErrors, name string) {
+	if len(name) < 2 {
+		errs.Add("name", "must be at least 2 characters")
+	}
+
+	if len(name) > 100 {
+		errs.Add("name", "must be less than 100 characters")
+	}
 }
 
 // isValidEmail checks if email format is valid
@@ -0,0 +1,37 @@
+package validator
+
+import (
+	"strings"
+
+	"github.com/keeee21/commit-town/api/httperr"
+)
+
+// ValidationErrors 検証で見つかった全ての項目エラー（最初の1件で止めずに溜める）
+// コントローラーはそのまま返せば httperr.Handler が400で全項目を返す
+type ValidationErrors []httperr.FieldError
+
+// Add 項目エラーを追加
+func (errs *ValidationErrors) Add(field, message string) {
+	*errs = append(*errs, httperr.FieldError{Field: field, Message: message})
+}
+
+// Err エラーが1件以上あれば自身を、無ければ nil を返す
+func (errs ValidationErrors) Err() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, 0, len(errs))
+	for _, fieldErr := range errs {
+		messages = append(messages, fieldErr.Field+" "+fieldErr.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// FieldErrors httperr.Handler がレスポンスの fields に使う
+func (errs ValidationErrors) FieldErrors() []httperr.FieldError {
+	return errs
+}